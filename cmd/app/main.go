@@ -2,45 +2,74 @@ package main
 
 import (
 	"ProjectGolang/internal/config"
+	"ProjectGolang/pkg/captcha"
 	"ProjectGolang/pkg/google"
+	jwtPkg "ProjectGolang/pkg/jwt"
 	"ProjectGolang/pkg/log"
 	"ProjectGolang/pkg/redis"
 	"ProjectGolang/pkg/smtp"
 	websocketPkg "ProjectGolang/pkg/websocket"
-	"github.com/joho/godotenv"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 )
 
 func main() {
-	logger := log.NewLogger()
-	if err := godotenv.Load(); err != nil {
-		logger.Fatalf("Error loading .env file: %v", err)
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	logger := log.NewLogger(cfg.Log)
+	logger.WithFields(cfg.Fields()).Infof("Loaded %s configuration", cfg.Env)
+
+	if len(args) > 0 && args[0] == config.CommandMigrate {
+		if err := runMigrate(logger, cfg, args[1:]); err != nil {
+			logger.Fatal(err)
+		}
+		return
 	}
 
 	fiberApp := config.NewFiber(logger)
 	validator := config.NewValidator()
-	googleProvider := google.New()
-	redisServer := redis.New()
-	smtpMailer := smtp.New()
-	websocket := websocketPkg.NewAIWebSocketClient()
+	googleProvider := google.New(cfg.Google)
+	redisServer := redis.New(cfg.Redis)
+	smtpMailer := smtp.New(cfg.SMTP)
+	websocket := websocketPkg.NewAIWebSocketClient(cfg.AI)
+	captchaVerifier := captcha.New(cfg.Captcha)
+	jwt := jwtPkg.New(cfg.JWT)
+	sessions := jwtPkg.NewSessions(redisServer)
 
 	server, err := config.NewServer(
+		config.WithConfig(cfg),
 		config.WithFiber(fiberApp),
 		config.WithLogger(logger),
 		config.WithValidator(validator),
-		config.WithDatabase(),
+		config.WithDatabase(cfg.Database),
 		config.WithGoogleProvider(googleProvider),
 		config.WithRedisServer(redisServer),
 		config.WithSMTPMailer(smtpMailer),
 		config.WithWebSocket(websocket),
+		config.WithJWT(jwt),
+		config.WithSessions(sessions),
 		config.WithMiddleware(),
-		config.WithS3Client(),
-		config.WithWhatsappClient(),
-		config.WithGeminiClient(),
+		config.WithS3Client(cfg.Storage, cfg.AWS),
+		config.WithWhatsappClient(cfg.Database),
+		config.WithSMSSender(cfg.SMS),
+		config.WithGeminiClient(cfg.Gemini),
+		config.WithFaceMatcher(cfg.FaceMatch, cfg.AWS),
+		config.WithSpeechSynthesizer(cfg.TTS),
 		config.WithBcryptUtils(),
 		config.WithUtils(),
+		config.WithCaptcha(captchaVerifier),
 	)
 	if err != nil {
 		logger.Fatal(err)
@@ -48,6 +77,10 @@ func main() {
 
 	server.RegisterHandler()
 
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	server.StartWorkers(workerCtx)
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
@@ -61,4 +94,6 @@ func main() {
 
 	<-sigChan
 	logger.Info("Shutting down server...")
+	stopWorkers()
+	websocket.CloseConnections()
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"ProjectGolang/database/migrate"
+	"ProjectGolang/database/migrations"
+	"ProjectGolang/database/postgres"
+	"ProjectGolang/internal/config"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"math"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "usage: app migrate up | down [steps|all] | status"
+
+// runMigrate handles `app migrate`, applying the migrations embedded in the
+// binary. down rolls back one migration unless told how many.
+func runMigrate(logger *logrus.Logger, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := postgres.New(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, logger, migrations.FS)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Applied %d migration(s)", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if args[1] == "all" {
+				steps = math.MaxInt
+			} else if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Rolled back %d migration(s)", rolledBack)
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(status)
+
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
+
+func printStatus(status migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "version:\t%d\n", status.Version)
+	fmt.Fprintf(w, "dirty:\t%t\n\n", status.Dirty)
+	for _, migration := range status.Applied {
+		fmt.Fprintf(w, "applied\t%d\t%s\n", migration.Version, migration.Name)
+	}
+	for _, migration := range status.Pending {
+		fmt.Fprintf(w, "pending\t%d\t%s\n", migration.Version, migration.Name)
+	}
+}
@@ -0,0 +1,257 @@
+// Package migrate applies the SQL migrations to Postgres. It keeps the
+// version in the schema_migrations table the way the golang-migrate CLI does,
+// so either can be used on the same database.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// lockID keys the advisory lock that keeps two instances from migrating at
+// once.
+const lockID = 7261934015
+
+var (
+	ErrDirty          = errors.New("database is dirty after a failed migration; fix it and force the version with the migrate CLI")
+	ErrUnknownVersion = errors.New("database is at a version this binary has no migration for")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is one version of the schema.
+type Migration struct {
+	Version uint64
+	Name    string
+	up      string
+	down    string
+}
+
+// Status is where the database stands against the known migrations.
+type Status struct {
+	// Version is 0 when no migration has been applied.
+	Version uint64
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	log        *logrus.Logger
+	migrations []Migration
+}
+
+// New reads the migrations from source, which holds an up and a down file
+// for each version.
+func New(db *sqlx.DB, log *logrus.Logger, source fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		body, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.up = string(body)
+		} else {
+			migration.down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return &Migrator{
+		db:         db,
+		log:        log,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies every pending migration, each in its own transaction, and
+// returns how many it applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		status, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if status.Dirty {
+			return ErrDirty
+		}
+
+		for _, migration := range status.Pending {
+			if err := m.apply(ctx, conn, migration.up, migration.Version, migration); err != nil {
+				return err
+			}
+			m.log.WithFields(logrus.Fields{
+				"version": migration.Version,
+				"name":    migration.Name,
+			}).Info("Applied migration")
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the last steps migrations applied and returns how many it
+// rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	rolledBack := 0
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		status, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if status.Dirty {
+			return ErrDirty
+		}
+
+		for i := len(status.Applied) - 1; i >= 0 && rolledBack < steps; i-- {
+			migration := status.Applied[i]
+			var previous uint64
+			if i > 0 {
+				previous = status.Applied[i-1].Version
+			}
+
+			if err := m.apply(ctx, conn, migration.down, previous, migration); err != nil {
+				return err
+			}
+			m.log.WithFields(logrus.Fields{
+				"version": migration.Version,
+				"name":    migration.Name,
+			}).Info("Rolled back migration")
+			rolledBack++
+		}
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// Status reports the version of the database and which migrations are
+// applied and pending.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	var status Status
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		var err error
+		status, err = m.status(ctx, conn)
+		return err
+	})
+
+	return status, err
+}
+
+// locked runs fn on a single connection holding the migration lock, creating
+// the version table first if need be.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, querySchemaLock, lockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), querySchemaUnlock, lockID); err != nil {
+			m.log.WithError(err).Warn("Failed to release migration lock")
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, queryCreateSchemaTable); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) status(ctx context.Context, conn *sql.Conn) (Status, error) {
+	var status Status
+	err := conn.QueryRowContext(ctx, queryGetVersion).Scan(&status.Version, &status.Dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Status{}, fmt.Errorf("read schema version: %w", err)
+	}
+
+	known := status.Version == 0
+	for _, migration := range m.migrations {
+		if migration.Version <= status.Version {
+			status.Applied = append(status.Applied, migration)
+			known = known || migration.Version == status.Version
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	if !known {
+		return status, ErrUnknownVersion
+	}
+
+	return status, nil
+}
+
+// apply runs body and moves the version to version in one transaction, so a
+// migration that fails leaves nothing behind. Version 0 clears it.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, body string, version uint64, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, queryClearVersion); err != nil {
+		return fmt.Errorf("clear schema version: %w", err)
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, querySetVersion, version); err != nil {
+			return fmt.Errorf("set schema version: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,27 @@
+package migrate
+
+const (
+	querySchemaLock = `SELECT pg_advisory_lock($1)`
+
+	querySchemaUnlock = `SELECT pg_advisory_unlock($1)`
+
+	queryCreateSchemaTable = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			dirty BOOLEAN NOT NULL
+		)
+	`
+
+	queryGetVersion = `
+		SELECT version, dirty
+		FROM schema_migrations
+		LIMIT 1
+	`
+
+	queryClearVersion = `DELETE FROM schema_migrations`
+
+	querySetVersion = `
+		INSERT INTO schema_migrations (version, dirty)
+		VALUES ($1, false)
+	`
+)
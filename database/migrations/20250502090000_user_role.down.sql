@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS admin_audit_logs;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_role_check,
+    DROP COLUMN IF EXISTS locked_reason,
+    DROP COLUMN IF EXISTS locked_at,
+    DROP COLUMN IF EXISTS role;
@@ -0,0 +1,24 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS locked_at TIMESTAMP DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS locked_reason TEXT DEFAULT NULL;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'support', 'admin'));
+
+CREATE TABLE IF NOT EXISTS admin_audit_logs (
+    id VARCHAR(26) PRIMARY KEY,
+    actor_id VARCHAR(26) NOT NULL,
+    actor_role VARCHAR(20) NOT NULL,
+    action VARCHAR(100) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_id VARCHAR(100),
+    status VARCHAR(20) NOT NULL,
+    metadata JSONB,
+    ip_address VARCHAR(64),
+    request_id VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_actor_id ON admin_audit_logs (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_target ON admin_audit_logs (target_type, target_id, created_at);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS account_deletion_requests (
+    id VARCHAR(26) PRIMARY KEY,
+    user_id VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    confirmed_by VARCHAR(20) NOT NULL,
+    reason TEXT,
+    requested_at TIMESTAMP NOT NULL DEFAULT now(),
+    scheduled_for TIMESTAMP NOT NULL,
+    cancelled_at TIMESTAMP DEFAULT NULL,
+    completed_at TIMESTAMP DEFAULT NULL,
+    CONSTRAINT account_deletion_requests_status_check CHECK (status IN ('pending', 'cancelled', 'completed')),
+    CONSTRAINT account_deletion_requests_confirmed_by_check CHECK (confirmed_by IN ('pin', 'otp'))
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_deletion_requests_pending_user
+    ON account_deletion_requests (user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_account_deletion_requests_due
+    ON account_deletion_requests (scheduled_for) WHERE status = 'pending';
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS kyc_verifications (
+    user_id VARCHAR(50) PRIMARY KEY,
+    status VARCHAR(30) NOT NULL DEFAULT 'not_started',
+    level SMALLINT NOT NULL DEFAULT 0,
+    ktp_image_url TEXT,
+    selfie_url TEXT,
+    face_similarity NUMERIC(5, 2),
+    rejection_reason TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    submitted_at TIMESTAMP DEFAULT NULL,
+    face_matched_at TIMESTAMP DEFAULT NULL,
+    reviewed_at TIMESTAMP DEFAULT NULL,
+    reviewed_by VARCHAR(26) DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT kyc_verifications_status_check CHECK (status IN ('not_started', 'documents_submitted', 'face_matched', 'approved', 'rejected')),
+    CONSTRAINT kyc_verifications_level_check CHECK (level IN (0, 1))
+    );
+
+CREATE INDEX IF NOT EXISTS idx_kyc_verifications_status ON kyc_verifications (status, submitted_at);
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id VARCHAR(50) PRIMARY KEY,
+    language VARCHAR(5) NOT NULL DEFAULT 'id',
+    channels JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT notification_preferences_language_check CHECK (language IN ('id', 'en'))
+    );
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id VARCHAR(26) PRIMARY KEY,
+    user_id VARCHAR(50) NOT NULL,
+    template VARCHAR(50) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    read_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user
+    ON notifications (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread
+    ON notifications (user_id) WHERE read_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id VARCHAR(26) PRIMARY KEY,
+    user_id VARCHAR(50),
+    template VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    recipient VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT notification_deliveries_channel_check CHECK (channel IN ('whatsapp', 'email', 'sms', 'in_app')),
+    CONSTRAINT notification_deliveries_status_check CHECK (status IN ('sent', 'failed', 'skipped'))
+    );
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_user
+    ON notification_deliveries (user_id, created_at DESC);
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id VARCHAR(26) PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    request_id VARCHAR(64),
+    run_at TIMESTAMP NOT NULL DEFAULT now(),
+    locked_until TIMESTAMP DEFAULT NULL,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT jobs_status_check CHECK (status IN ('pending', 'running')),
+    CONSTRAINT jobs_attempts_check CHECK (max_attempts > 0)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs (run_at);
+
+CREATE TABLE IF NOT EXISTS dead_letter_jobs (
+    id VARCHAR(26) PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT,
+    request_id VARCHAR(64),
+    created_at TIMESTAMP NOT NULL,
+    failed_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_dead_letter_jobs_type ON dead_letter_jobs (type, failed_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id VARCHAR(26) PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    user_id VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    request_id VARCHAR(64),
+    occurred_at TIMESTAMP NOT NULL DEFAULT now(),
+    dispatched_at TIMESTAMP DEFAULT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending
+    ON outbox_events (id) WHERE dispatched_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_user
+    ON outbox_events (user_id, id);
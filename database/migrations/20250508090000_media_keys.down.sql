@@ -0,0 +1,3 @@
+-- Keys cannot be turned back into URLs without the storage endpoint, and the
+-- application reads keys and URLs alike, so they are left as they are.
+SELECT 1;
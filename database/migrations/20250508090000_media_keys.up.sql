@@ -0,0 +1,26 @@
+-- Media is private and linked per request, so only object keys are stored.
+-- Keys under a per-user prefix are cut from where the prefix starts, whatever
+-- the endpoint; older objects sit at the root of the bucket, behind the host.
+CREATE FUNCTION pg_temp.media_key(location TEXT) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN location IS NULL OR location !~ '^https?://' THEN location
+        WHEN location ~ '/users/[^/]+/' THEN substring(location FROM '/(users/[^/]+/[^?]*)')
+        ELSE replace(replace(replace(replace(replace(replace(
+            regexp_replace(regexp_replace(location, '^https?://[^/]+/', ''), '\?.*$', ''),
+            '%20', ' '), '%2B', '+'), '%3A', ':'), '%3D', '='), '%2C', ','), '%25', '%')
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+UPDATE users
+SET profile_photo_url = pg_temp.media_key(profile_photo_url),
+    face_photo_url = pg_temp.media_key(face_photo_url)
+WHERE profile_photo_url ~ '^https?://' OR face_photo_url ~ '^https?://';
+
+UPDATE budget_transactions
+SET audio_link = pg_temp.media_key(audio_link)
+WHERE audio_link ~ '^https?://';
+
+UPDATE kyc_verifications
+SET ktp_image_url = pg_temp.media_key(ktp_image_url),
+    selfie_url = pg_temp.media_key(selfie_url)
+WHERE ktp_image_url ~ '^https?://' OR selfie_url ~ '^https?://';
@@ -0,0 +1,2 @@
+ALTER TABLE budget_transactions
+    DROP COLUMN IF EXISTS audio_duration_ms;
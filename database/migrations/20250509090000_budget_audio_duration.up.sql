@@ -0,0 +1,2 @@
+ALTER TABLE budget_transactions
+    ADD COLUMN IF NOT EXISTS audio_duration_ms INTEGER DEFAULT NULL;
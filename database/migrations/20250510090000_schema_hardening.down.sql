@@ -0,0 +1,45 @@
+DROP INDEX IF EXISTS idx_wallet_transactions_user_created;
+
+ALTER TABLE wallet_transactions
+    DROP CONSTRAINT IF EXISTS wallet_transactions_amount_check,
+    DROP CONSTRAINT IF EXISTS wallet_transactions_status_check,
+    DROP CONSTRAINT IF EXISTS wallet_transactions_type_check,
+    DROP CONSTRAINT IF EXISTS wallet_transactions_owner_check,
+    DROP CONSTRAINT IF EXISTS wallet_transactions_user_id_fkey;
+
+UPDATE wallet_transactions
+SET user_id = pseudonym
+WHERE user_id IS NULL;
+
+ALTER TABLE wallet_transactions
+    ALTER COLUMN user_id SET NOT NULL,
+    DROP COLUMN IF EXISTS pseudonym;
+
+ALTER TABLE wallets
+    DROP CONSTRAINT IF EXISTS wallets_balance_check,
+    DROP CONSTRAINT IF EXISTS wallets_user_id_fkey;
+
+DROP INDEX IF EXISTS idx_budget_transactions_user_created;
+
+ALTER TABLE budget_transactions
+    DROP CONSTRAINT IF EXISTS budget_transactions_audio_duration_ms_check,
+    DROP CONSTRAINT IF EXISTS budget_transactions_nominal_check,
+    DROP CONSTRAINT IF EXISTS budget_transactions_type_check,
+    DROP CONSTRAINT IF EXISTS budget_transactions_user_id_fkey,
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at DROP DEFAULT,
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at DROP DEFAULT;
+
+-- Cleared audio durations stay cleared.
+ALTER TABLE invalid_budget_transactions DROP COLUMN archived_at;
+INSERT INTO budget_transactions SELECT * FROM invalid_budget_transactions;
+DROP TABLE IF EXISTS invalid_budget_transactions;
+
+ALTER TABLE orphaned_wallets DROP COLUMN archived_at;
+INSERT INTO wallets SELECT * FROM orphaned_wallets;
+DROP TABLE IF EXISTS orphaned_wallets;
+
+ALTER TABLE orphaned_budget_transactions DROP COLUMN archived_at;
+INSERT INTO budget_transactions SELECT * FROM orphaned_budget_transactions;
+DROP TABLE IF EXISTS orphaned_budget_transactions;
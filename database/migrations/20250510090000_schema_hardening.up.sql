@@ -0,0 +1,142 @@
+-- Rows left behind by users deleted before the purge existed have no owner
+-- and would block the foreign keys. They are moved to archive tables for an
+-- operator to reconcile rather than dropped; the down migration moves them
+-- back.
+CREATE TABLE IF NOT EXISTS orphaned_budget_transactions (
+    LIKE budget_transactions INCLUDING DEFAULTS,
+    archived_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+INSERT INTO orphaned_budget_transactions
+SELECT t.*, now()
+FROM budget_transactions t
+WHERE t.user_id NOT IN (SELECT id FROM users);
+
+DELETE FROM budget_transactions
+WHERE id IN (SELECT id FROM orphaned_budget_transactions);
+
+-- Wallets of users deleted the same way still hold money, so they are
+-- archived with their balance for finance to settle.
+CREATE TABLE IF NOT EXISTS orphaned_wallets (
+    LIKE wallets INCLUDING DEFAULTS,
+    archived_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+INSERT INTO orphaned_wallets
+SELECT w.*, now()
+FROM wallets w
+WHERE w.user_id NOT IN (SELECT id FROM users);
+
+DELETE FROM wallets
+WHERE id IN (SELECT id FROM orphaned_wallets);
+
+-- Budget records the checks below reject are archived the same way. A
+-- non-positive audio duration only means the duration is unknown, so it is
+-- cleared instead.
+CREATE TABLE IF NOT EXISTS invalid_budget_transactions (
+    LIKE budget_transactions INCLUDING DEFAULTS,
+    archived_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+INSERT INTO invalid_budget_transactions
+SELECT t.*, now()
+FROM budget_transactions t
+WHERE t.type NOT IN ('income', 'expense')
+   OR t.nominal <= 0;
+
+DELETE FROM budget_transactions
+WHERE id IN (SELECT id FROM invalid_budget_transactions);
+
+UPDATE budget_transactions
+SET audio_duration_ms = NULL
+WHERE audio_duration_ms <= 0;
+
+UPDATE budget_transactions
+SET created_at = COALESCE(created_at, updated_at, now())
+WHERE created_at IS NULL;
+
+UPDATE budget_transactions
+SET updated_at = created_at
+WHERE updated_at IS NULL;
+
+ALTER TABLE budget_transactions
+    ALTER COLUMN created_at SET DEFAULT now(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT now(),
+    ALTER COLUMN updated_at SET NOT NULL,
+    ADD CONSTRAINT budget_transactions_user_id_fkey
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD CONSTRAINT budget_transactions_type_check CHECK (type IN ('income', 'expense')),
+    ADD CONSTRAINT budget_transactions_nominal_check CHECK (nominal > 0),
+    ADD CONSTRAINT budget_transactions_audio_duration_ms_check CHECK (audio_duration_ms > 0);
+
+CREATE INDEX IF NOT EXISTS idx_budget_transactions_user_created
+    ON budget_transactions (user_id, created_at);
+
+-- A wallet holds money, so its user cannot be deleted until the purge has
+-- removed it. Wallets and their transactions are money and are never
+-- archived or rewritten here: their checks are added NOT VALID, so they hold
+-- for every new or changed row at once, and existing rows are validated at
+-- the end of this migration when none break them.
+ALTER TABLE wallets
+    ADD CONSTRAINT wallets_user_id_fkey
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT,
+    ADD CONSTRAINT wallets_balance_check CHECK (balance >= 0) NOT VALID;
+
+-- Wallet transactions outlive their user for bookkeeping. They used to keep
+-- the pseudonym of a deleted user in user_id, which a foreign key cannot
+-- allow, so it moves to a column of its own.
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS pseudonym VARCHAR(50) DEFAULT NULL,
+    ALTER COLUMN user_id DROP NOT NULL;
+
+UPDATE wallet_transactions
+SET pseudonym = user_id,
+    user_id = NULL
+WHERE user_id NOT IN (SELECT id FROM users);
+
+ALTER TABLE wallet_transactions
+    ADD CONSTRAINT wallet_transactions_user_id_fkey
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT,
+    ADD CONSTRAINT wallet_transactions_owner_check CHECK (user_id IS NOT NULL OR pseudonym IS NOT NULL),
+    ADD CONSTRAINT wallet_transactions_type_check CHECK (type IN ('topup')) NOT VALID,
+    ADD CONSTRAINT wallet_transactions_status_check
+        CHECK (status IN ('pending', 'processing', 'success', 'failed', 'expired', 'refunded')) NOT VALID,
+    ADD CONSTRAINT wallet_transactions_amount_check CHECK (amount > 0) NOT VALID;
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_user_created
+    ON wallet_transactions (user_id, created_at);
+
+-- Rows that break a check are left for finance to settle. Once they are,
+-- ALTER TABLE ... VALIDATE CONSTRAINT marks the check valid.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM wallets WHERE NOT (balance >= 0)) THEN
+        RAISE WARNING 'wallets with a negative balance, wallets_balance_check left NOT VALID';
+    ELSE
+        ALTER TABLE wallets VALIDATE CONSTRAINT wallets_balance_check;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM wallet_transactions WHERE NOT (type IN ('topup'))) THEN
+        RAISE WARNING 'wallet transactions of unknown type, wallet_transactions_type_check left NOT VALID';
+    ELSE
+        ALTER TABLE wallet_transactions VALIDATE CONSTRAINT wallet_transactions_type_check;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1
+        FROM wallet_transactions
+        WHERE NOT (status IN ('pending', 'processing', 'success', 'failed', 'expired', 'refunded'))
+    ) THEN
+        RAISE WARNING 'wallet transactions of unknown status, wallet_transactions_status_check left NOT VALID';
+    ELSE
+        ALTER TABLE wallet_transactions VALIDATE CONSTRAINT wallet_transactions_status_check;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM wallet_transactions WHERE NOT (amount > 0)) THEN
+        RAISE WARNING 'wallet transactions without a positive amount, wallet_transactions_amount_check left NOT VALID';
+    ELSE
+        ALTER TABLE wallet_transactions VALIDATE CONSTRAINT wallet_transactions_amount_check;
+    END IF;
+END
+$$;
@@ -0,0 +1,2 @@
+-- Redacted payloads cannot be restored.
+DROP INDEX IF EXISTS idx_dead_letter_jobs_failed_at;
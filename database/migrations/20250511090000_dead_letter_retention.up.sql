@@ -0,0 +1,11 @@
+-- Dead letters are deleted once they are past the retention period.
+CREATE INDEX IF NOT EXISTS idx_dead_letter_jobs_failed_at ON dead_letter_jobs (failed_at);
+
+-- Notifications that already failed for good keep only their template and
+-- user, like the ones that fail from now on.
+UPDATE dead_letter_jobs
+SET payload = jsonb_build_object('message', jsonb_strip_nulls(jsonb_build_object(
+    'template', payload->'message'->'template',
+    'user_id', payload->'message'->'user_id'
+)))
+WHERE type = 'notification';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_outbox_events_user_sequence;
+
+ALTER TABLE outbox_events
+    DROP COLUMN IF EXISTS sequence;
+
+DROP SEQUENCE IF EXISTS outbox_events_sequence;
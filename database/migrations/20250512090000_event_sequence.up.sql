@@ -0,0 +1,22 @@
+-- Events are numbered when they are dispatched, in commit order, so a
+-- reconnecting client resumes without skipping an event whose transaction
+-- committed after one with a later ID.
+CREATE SEQUENCE IF NOT EXISTS outbox_events_sequence;
+
+ALTER TABLE outbox_events
+    ADD COLUMN IF NOT EXISTS sequence BIGINT UNIQUE;
+
+UPDATE outbox_events o
+SET sequence = numbered.sequence
+FROM (
+    SELECT id, row_number() OVER (ORDER BY id) AS sequence
+    FROM outbox_events
+    WHERE dispatched_at IS NOT NULL
+) numbered
+WHERE o.id = numbered.id;
+
+SELECT setval('outbox_events_sequence', COALESCE(MAX(sequence), 0) + 1, false)
+FROM outbox_events;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_user_sequence
+    ON outbox_events (user_id, sequence);
@@ -0,0 +1,11 @@
+// Package migrations embeds the SQL migrations so the binary can apply them
+// without the files on disk.
+package migrations
+
+import "embed"
+
+// FS holds the migrations in the golang-migrate layout, a
+// <version>_<name>.up.sql and .down.sql pair for each version.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -3,11 +3,20 @@ package postgres
 import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
-	"os"
 )
 
-func New() (*sqlx.DB, error) {
-	dsn := FormatDSN()
+// Config is where the database is and how to log in to it.
+type Config struct {
+	Host     string `env:"DB_HOST" default:"localhost"`
+	Port     string `env:"DB_PORT" default:"5432"`
+	User     string `env:"DB_USER"`
+	Password string `env:"DB_PASSWORD" secret:"true"`
+	Name     string `env:"DB_NAME"`
+	SSLMode  string `env:"DB_SSLMODE" default:"disable"`
+}
+
+func New(cfg Config) (*sqlx.DB, error) {
+	dsn := FormatDSN(cfg)
 
 	db, err := sqlx.Connect("postgres", dsn)
 	if err != nil {
@@ -17,13 +26,13 @@ func New() (*sqlx.DB, error) {
 	return db, nil
 }
 
-func FormatDSN() string {
+func FormatDSN(cfg Config) string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_SSLMODE"),
+		cfg.Host,
+		cfg.Port,
+		cfg.User,
+		cfg.Password,
+		cfg.Name,
+		cfg.SSLMode,
 	)
 }
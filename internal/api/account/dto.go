@@ -0,0 +1,115 @@
+package account
+
+import "time"
+
+const (
+	DeletionStatusPending   = "pending"
+	DeletionStatusCancelled = "cancelled"
+	DeletionStatusCompleted = "completed"
+
+	ConfirmedByPIN = "pin"
+	ConfirmedByOTP = "otp"
+)
+
+type DeletionRequest struct {
+	ID           string
+	UserID       string
+	Status       string
+	ConfirmedBy  string
+	Reason       string
+	RequestedAt  time.Time
+	ScheduledFor time.Time
+	CancelledAt  time.Time
+	CompletedAt  time.Time
+}
+
+type RequestDeletionRequest struct {
+	PIN    string `json:"personal_identification_number" validate:"omitempty,len=6,numeric"`
+	Code   string `json:"code" validate:"required_without=PIN"`
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type DeletionStatusResponse struct {
+	ID           string    `json:"id"`
+	Status       string    `json:"status"`
+	RequestedAt  time.Time `json:"requested_at"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+	CanCancel    bool      `json:"can_cancel"`
+}
+
+// UserMedia lists the stored objects that belong to a user and have to be
+// removed from S3 once the account is purged.
+type UserMedia struct {
+	ProfilePhotoURL string
+	FacePhotoURL    string
+	AudioLinks      []string
+	KYCDocuments    []string
+}
+
+type DataExport struct {
+	ExportedAt         time.Time                 `json:"exported_at"`
+	Profile            ProfileExport             `json:"profile"`
+	Wallet             *WalletExport             `json:"wallet"`
+	WalletTransactions []WalletTransactionExport `json:"wallet_transactions"`
+	BudgetTransactions []BudgetTransactionExport `json:"budget_transactions"`
+}
+
+type ProfileExport struct {
+	ID                        string    `json:"id"`
+	Email                     string    `json:"email"`
+	Name                      string    `json:"name"`
+	NationalIdentityNumber    string    `json:"national_identity_number"`
+	BirthPlace                string    `json:"birth_place"`
+	BirthDate                 time.Time `json:"birth_date"`
+	Gender                    string    `json:"gender"`
+	Address                   string    `json:"address"`
+	NeighborhoodCommunityUnit string    `json:"neighborhood_community_unit"`
+	Village                   string    `json:"village"`
+	District                  string    `json:"district"`
+	Religion                  string    `json:"religion"`
+	MaritalStatus             string    `json:"marital_status"`
+	Profession                string    `json:"profession"`
+	Citizenship               string    `json:"citizenship"`
+	CardValidUntil            time.Time `json:"card_valid_until"`
+	PhoneNumber               string    `json:"phone_number"`
+	ProfilePhotoURL           string    `json:"profile_photo_url,omitempty"`
+	FacePhotoURL              string    `json:"face_photo_url,omitempty"`
+	GoogleLinked              bool      `json:"google_linked"`
+	TouchIDEnabled            bool      `json:"touch_id_enabled"`
+	IsVerified                bool      `json:"is_verified"`
+	CreatedAt                 time.Time `json:"created_at"`
+	UpdatedAt                 time.Time `json:"updated_at"`
+}
+
+type WalletExport struct {
+	ID        string    `json:"id"`
+	Balance   float64   `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WalletTransactionExport struct {
+	ID            string    `json:"id"`
+	Amount        float64   `json:"amount"`
+	Type          string    `json:"type"`
+	ReferenceNo   string    `json:"reference_no"`
+	PaymentMethod string    `json:"payment_method"`
+	Status        string    `json:"status"`
+	BankAccount   string    `json:"bank_account,omitempty"`
+	BankName      string    `json:"bank_name,omitempty"`
+	Description   string    `json:"description,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type BudgetTransactionExport struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Nominal     float64   `json:"nominal"`
+	Type        string    `json:"type"`
+	Category    string    `json:"category"`
+	AudioLink   string    `json:"audio_link,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
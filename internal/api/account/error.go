@@ -0,0 +1,17 @@
+package account
+
+import (
+	"ProjectGolang/pkg/response"
+	"net/http"
+)
+
+var (
+	ErrDeletionNotFound       = response.NewError(http.StatusNotFound, "no pending account deletion")
+	ErrDeletionAlreadyPending = response.NewError(http.StatusConflict, "account deletion is already scheduled")
+	ErrInvalidPIN             = response.NewError(http.StatusUnauthorized, "invalid personal identification number")
+	ErrPINNotSet              = response.NewError(http.StatusBadRequest, "personal identification number is not set, confirm with an OTP instead")
+	ErrInvalidOTP             = response.NewError(http.StatusUnauthorized, "invalid or expired OTP")
+	ErrTooManyOTPAttempts     = response.NewError(http.StatusTooManyRequests, "too many wrong OTPs, please request a new one later")
+	ErrWalletNotEmpty         = response.NewError(http.StatusConflict, "wallet still holds a balance, contact support to have it refunded before deleting the account")
+	ErrPhoneNumberNotSet      = response.NewError(http.StatusBadRequest, "account has no phone number to send the OTP to")
+)
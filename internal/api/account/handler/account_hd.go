@@ -0,0 +1,148 @@
+package accountHandler
+
+import (
+	"ProjectGolang/internal/api/account"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/handlerUtil"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+	"time"
+)
+
+func (h *AccountHandler) RequestDeletionOTP(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	if err := h.accountService.RequestDeletionOTP(c, userData.ID); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "request_deletion_otp")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, fiber.Map{
+			"message": "OTP sent to your phone number",
+		})
+	}
+}
+
+func (h *AccountHandler) RequestDeletion(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var req account.RequestDeletionRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	res, err := h.accountService.RequestDeletion(c, userData.ID, req)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "request_deletion")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusAccepted, res)
+	}
+}
+
+func (h *AccountHandler) GetDeletionStatus(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	res, err := h.accountService.GetDeletionStatus(c, userData.ID)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_deletion_status")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *AccountHandler) CancelDeletion(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	if err := h.accountService.CancelDeletion(c, userData.ID); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "cancel_deletion")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, fiber.Map{
+			"message": "Account deletion cancelled",
+		})
+	}
+}
+
+func (h *AccountHandler) ExportData(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	export, err := h.accountService.ExportData(c, userData.ID)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "export_data")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		ctx.Attachment("sentra-data-export-" + export.ExportedAt.Format("20060102150405") + ".json")
+		return ctx.Status(fiber.StatusOK).JSON(export)
+	}
+}
@@ -0,0 +1,40 @@
+package accountHandler
+
+import (
+	accountService "ProjectGolang/internal/api/account/service"
+	"ProjectGolang/internal/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AccountHandler struct {
+	log            *logrus.Logger
+	validator      *validator.Validate
+	middleware     middleware.Middleware
+	accountService accountService.IAccountService
+}
+
+func New(
+	log *logrus.Logger,
+	validate *validator.Validate,
+	middleware middleware.Middleware,
+	accountService accountService.IAccountService,
+) *AccountHandler {
+	return &AccountHandler{
+		log:            log,
+		validator:      validate,
+		middleware:     middleware,
+		accountService: accountService,
+	}
+}
+
+func (h *AccountHandler) Start(srv fiber.Router) {
+	accountGroup := srv.Group("/account", h.middleware.NewTokenMiddleware)
+
+	accountGroup.Post("/deletion/otp", h.RequestDeletionOTP)
+	accountGroup.Post("/deletion", h.RequestDeletion)
+	accountGroup.Get("/deletion", h.GetDeletionStatus)
+	accountGroup.Delete("/deletion", h.CancelDeletion)
+	accountGroup.Get("/export", h.ExportData)
+}
@@ -0,0 +1,245 @@
+package accountRepository
+
+import (
+	"ProjectGolang/internal/api/account"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+type DeletionRequestDB struct {
+	ID           sql.NullString `db:"id"`
+	UserID       sql.NullString `db:"user_id"`
+	Status       sql.NullString `db:"status"`
+	ConfirmedBy  sql.NullString `db:"confirmed_by"`
+	Reason       sql.NullString `db:"reason"`
+	RequestedAt  sql.NullTime   `db:"requested_at"`
+	ScheduledFor sql.NullTime   `db:"scheduled_for"`
+	CancelledAt  sql.NullTime   `db:"cancelled_at"`
+	CompletedAt  sql.NullTime   `db:"completed_at"`
+}
+
+func (r *deletionRepository) CreateDeletionRequest(ctx context.Context, req account.DeletionRequest) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"id":            req.ID,
+		"user_id":       req.UserID,
+		"status":        req.Status,
+		"confirmed_by":  req.ConfirmedBy,
+		"reason":        sql.NullString{String: req.Reason, Valid: req.Reason != ""},
+		"requested_at":  req.RequestedAt,
+		"scheduled_for": req.ScheduledFor,
+	}
+
+	query, args, err := sqlx.Named(queryCreateDeletionRequest, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CreateDeletionRequest named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CreateDeletionRequest execution err")
+		return err
+	}
+
+	return nil
+}
+
+func (r *deletionRepository) GetPendingDeletion(ctx context.Context, userID string) (account.DeletionRequest, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var deletion DeletionRequestDB
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryGetPendingDeletion, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetPendingDeletion named query preparation err")
+		return account.DeletionRequest{}, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).StructScan(&deletion); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return account.DeletionRequest{}, account.ErrDeletionNotFound
+		}
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetPendingDeletion execution err")
+		return account.DeletionRequest{}, err
+	}
+
+	return r.makeDeletionRequest(deletion), nil
+}
+
+func (r *deletionRepository) CancelDeletion(ctx context.Context, id string, userID string, cancelledAt time.Time) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"id":           id,
+		"user_id":      userID,
+		"cancelled_at": cancelledAt,
+	}
+
+	query, args, err := sqlx.Named(queryCancelDeletion, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CancelDeletion named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	result, err := r.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CancelDeletion execution err")
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"id":         id,
+		}).Warn("CancelDeletion no rows found")
+		return account.ErrDeletionNotFound
+	}
+
+	return nil
+}
+
+func (r *deletionRepository) GetDueDeletionIDs(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var ids []string
+
+	argsKV := map[string]interface{}{
+		"now":   now,
+		"limit": limit,
+	}
+
+	query, args, err := sqlx.Named(queryGetDueDeletionIDs, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetDueDeletionIDs named query preparation err")
+		return nil, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.SelectContext(ctx, &ids, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetDueDeletionIDs execution err")
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// LockDueDeletion locks a due pending request for the current transaction.
+// Requests already claimed by another worker, cancelled in the meantime or
+// not yet due are reported as account.ErrDeletionNotFound.
+func (r *deletionRepository) LockDueDeletion(ctx context.Context, id string, now time.Time) (account.DeletionRequest, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var deletion DeletionRequestDB
+
+	argsKV := map[string]interface{}{
+		"id":  id,
+		"now": now,
+	}
+
+	query, args, err := sqlx.Named(queryLockDueDeletion, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("LockDueDeletion named query preparation err")
+		return account.DeletionRequest{}, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).StructScan(&deletion); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return account.DeletionRequest{}, account.ErrDeletionNotFound
+		}
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("LockDueDeletion execution err")
+		return account.DeletionRequest{}, err
+	}
+
+	return r.makeDeletionRequest(deletion), nil
+}
+
+func (r *deletionRepository) CompleteDeletion(ctx context.Context, id string, pseudonym string, completedAt time.Time) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"id":           id,
+		"pseudonym":    pseudonym,
+		"completed_at": completedAt,
+	}
+
+	query, args, err := sqlx.Named(queryCompleteDeletion, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CompleteDeletion named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CompleteDeletion execution err")
+		return err
+	}
+
+	return nil
+}
+
+func (r *deletionRepository) makeDeletionRequest(deletion DeletionRequestDB) account.DeletionRequest {
+	return account.DeletionRequest{
+		ID:           deletion.ID.String,
+		UserID:       deletion.UserID.String,
+		Status:       deletion.Status.String,
+		ConfirmedBy:  deletion.ConfirmedBy.String,
+		Reason:       deletion.Reason.String,
+		RequestedAt:  deletion.RequestedAt.Time,
+		ScheduledFor: deletion.ScheduledFor.Time,
+		CancelledAt:  deletion.CancelledAt.Time,
+		CompletedAt:  deletion.CompletedAt.Time,
+	}
+}
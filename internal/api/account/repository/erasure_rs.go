@@ -0,0 +1,346 @@
+package accountRepository
+
+import (
+	"ProjectGolang/internal/api/account"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+type UserPhotosDB struct {
+	ProfilePhotoURL sql.NullString `db:"profile_photo_url"`
+	FacePhotoURL    sql.NullString `db:"face_photo_url"`
+}
+
+type KYCDocumentsDB struct {
+	KTPImageURL sql.NullString `db:"ktp_image_url"`
+	SelfieURL   sql.NullString `db:"selfie_url"`
+}
+
+func (r *erasureRepository) GetUserMedia(ctx context.Context, userID string) (account.UserMedia, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var photos UserPhotosDB
+	var kycDocuments KYCDocumentsDB
+	var audioLinks []string
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryGetUserPhotos, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetUserPhotos named query preparation err")
+		return account.UserMedia{}, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).StructScan(&photos); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetUserPhotos execution err")
+		return account.UserMedia{}, err
+	}
+
+	query, args, err = sqlx.Named(queryGetBudgetAudioLinks, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetBudgetAudioLinks named query preparation err")
+		return account.UserMedia{}, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.SelectContext(ctx, &audioLinks, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetBudgetAudioLinks execution err")
+		return account.UserMedia{}, err
+	}
+
+	query, args, err = sqlx.Named(queryGetKYCDocuments, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetKYCDocuments named query preparation err")
+		return account.UserMedia{}, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).StructScan(&kycDocuments); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetKYCDocuments execution err")
+		return account.UserMedia{}, err
+	}
+
+	return account.UserMedia{
+		ProfilePhotoURL: photos.ProfilePhotoURL.String,
+		FacePhotoURL:    photos.FacePhotoURL.String,
+		AudioLinks:      audioLinks,
+		KYCDocuments:    []string{kycDocuments.KTPImageURL.String, kycDocuments.SelfieURL.String},
+	}, nil
+}
+
+func (r *erasureRepository) GetWalletBalance(ctx context.Context, userID string) (float64, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var balance float64
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryGetWalletBalance, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetWalletBalance named query preparation err")
+		return 0, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).Scan(&balance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetWalletBalance execution err")
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func (r *erasureRepository) DeleteBudgetTransactions(ctx context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	// Records archived by the schema hardening are the user's too.
+	for _, namedQuery := range []string{queryDeleteBudgetTransactions, queryDeleteInvalidBudgetTransactions} {
+		query, args, err := sqlx.Named(namedQuery, argsKV)
+		if err != nil {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("DeleteBudgetTransactions named query preparation err")
+			return err
+		}
+
+		query = r.q.Rebind(query)
+
+		if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("DeleteBudgetTransactions execution err")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *erasureRepository) AnonymizeWalletTransactions(ctx context.Context, userID string, pseudonym string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"user_id":    userID,
+		"pseudonym":  pseudonym,
+		"updated_at": time.Now(),
+	}
+
+	query, args, err := sqlx.Named(queryAnonymizeWalletTransactions, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("AnonymizeWalletTransactions named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("AnonymizeWalletTransactions execution err")
+		return err
+	}
+
+	return nil
+}
+
+func (r *erasureRepository) DeleteKYCVerification(ctx context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryDeleteKYCVerification, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("DeleteKYCVerification named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("DeleteKYCVerification execution err")
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNotifications removes the user's notification preferences, inbox and
+// delivery log.
+func (r *erasureRepository) DeleteNotifications(ctx context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	for _, namedQuery := range []string{queryDeleteNotificationPreferences, queryDeleteNotifications, queryDeleteNotificationDeliveries} {
+		query, args, err := sqlx.Named(namedQuery, argsKV)
+		if err != nil {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("DeleteNotifications named query preparation err")
+			return err
+		}
+
+		query = r.q.Rebind(query)
+
+		if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("DeleteNotifications execution err")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteEvents removes the user's domain events together with their queued
+// and dead-lettered deliveries, so none of them is dispatched after erasure.
+func (r *erasureRepository) DeleteEvents(ctx context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	for _, namedQuery := range []string{queryDeleteOutboxEvents, queryDeleteEventDeliveries, queryDeleteDeadEventDeliveries} {
+		query, args, err := sqlx.Named(namedQuery, argsKV)
+		if err != nil {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("DeleteEvents named query preparation err")
+			return err
+		}
+
+		query = r.q.Rebind(query)
+
+		if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("DeleteEvents execution err")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *erasureRepository) DeleteWallet(ctx context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryDeleteWallet, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("DeleteWallet named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("DeleteWallet execution err")
+		return err
+	}
+
+	return nil
+}
+
+func (r *erasureRepository) DeleteUser(ctx context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryDeleteUser, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("DeleteUser named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("DeleteUser execution err")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package accountRepository
+
+import (
+	"ProjectGolang/internal/api/account"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+type WalletExportDB struct {
+	ID        sql.NullString  `db:"id"`
+	Balance   sql.NullFloat64 `db:"balance"`
+	CreatedAt sql.NullTime    `db:"created_at"`
+	UpdatedAt sql.NullTime    `db:"updated_at"`
+}
+
+type WalletTransactionExportDB struct {
+	ID            sql.NullString  `db:"id"`
+	Amount        sql.NullFloat64 `db:"amount"`
+	Type          sql.NullString  `db:"type"`
+	ReferenceNo   sql.NullString  `db:"reference_no"`
+	PaymentMethod sql.NullString  `db:"payment_method"`
+	Status        sql.NullString  `db:"status"`
+	BankAccount   sql.NullString  `db:"bank_account"`
+	BankName      sql.NullString  `db:"bank_name"`
+	Description   sql.NullString  `db:"description"`
+	CreatedAt     sql.NullTime    `db:"created_at"`
+	UpdatedAt     sql.NullTime    `db:"updated_at"`
+}
+
+type BudgetTransactionExportDB struct {
+	ID          sql.NullString  `db:"id"`
+	Title       sql.NullString  `db:"title"`
+	Description sql.NullString  `db:"description"`
+	Nominal     sql.NullFloat64 `db:"nominal"`
+	Type        sql.NullString  `db:"type"`
+	Category    sql.NullString  `db:"category"`
+	AudioLink   sql.NullString  `db:"audio_link"`
+	CreatedAt   sql.NullTime    `db:"created_at"`
+	UpdatedAt   sql.NullTime    `db:"updated_at"`
+}
+
+func (r *exportRepository) GetWallet(ctx context.Context, userID string) (*account.WalletExport, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var wallet WalletExportDB
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryExportWallet, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("ExportWallet named query preparation err")
+		return nil, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).StructScan(&wallet); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("ExportWallet execution err")
+		return nil, err
+	}
+
+	return &account.WalletExport{
+		ID:        wallet.ID.String,
+		Balance:   wallet.Balance.Float64,
+		CreatedAt: wallet.CreatedAt.Time,
+		UpdatedAt: wallet.UpdatedAt.Time,
+	}, nil
+}
+
+func (r *exportRepository) GetWalletTransactions(ctx context.Context, userID string) ([]account.WalletTransactionExport, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var transactions []WalletTransactionExportDB
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryExportWalletTransactions, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("ExportWalletTransactions named query preparation err")
+		return nil, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.SelectContext(ctx, &transactions, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("ExportWalletTransactions execution err")
+		return nil, err
+	}
+
+	result := make([]account.WalletTransactionExport, 0, len(transactions))
+	for _, transaction := range transactions {
+		result = append(result, account.WalletTransactionExport{
+			ID:            transaction.ID.String,
+			Amount:        transaction.Amount.Float64,
+			Type:          transaction.Type.String,
+			ReferenceNo:   transaction.ReferenceNo.String,
+			PaymentMethod: transaction.PaymentMethod.String,
+			Status:        transaction.Status.String,
+			BankAccount:   transaction.BankAccount.String,
+			BankName:      transaction.BankName.String,
+			Description:   transaction.Description.String,
+			CreatedAt:     transaction.CreatedAt.Time,
+			UpdatedAt:     transaction.UpdatedAt.Time,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *exportRepository) GetBudgetTransactions(ctx context.Context, userID string) ([]account.BudgetTransactionExport, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var transactions []BudgetTransactionExportDB
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryExportBudgetTransactions, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("ExportBudgetTransactions named query preparation err")
+		return nil, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.SelectContext(ctx, &transactions, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("ExportBudgetTransactions execution err")
+		return nil, err
+	}
+
+	result := make([]account.BudgetTransactionExport, 0, len(transactions))
+	for _, transaction := range transactions {
+		result = append(result, account.BudgetTransactionExport{
+			ID:          transaction.ID.String,
+			Title:       transaction.Title.String,
+			Description: transaction.Description.String,
+			Nominal:     transaction.Nominal.Float64,
+			Type:        transaction.Type.String,
+			Category:    transaction.Category.String,
+			AudioLink:   transaction.AudioLink.String,
+			CreatedAt:   transaction.CreatedAt.Time,
+			UpdatedAt:   transaction.UpdatedAt.Time,
+		})
+	}
+
+	return result, nil
+}
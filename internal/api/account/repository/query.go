@@ -0,0 +1,219 @@
+package accountRepository
+
+const (
+	queryCreateDeletionRequest = `
+		INSERT INTO account_deletion_requests (
+			id,
+			user_id,
+			status,
+			confirmed_by,
+			reason,
+			requested_at,
+			scheduled_for
+		) VALUES (
+			:id,
+			:user_id,
+			:status,
+			:confirmed_by,
+			:reason,
+			:requested_at,
+			:scheduled_for
+		)
+	`
+
+	queryGetPendingDeletion = `
+		SELECT
+			id,
+			user_id,
+			status,
+			confirmed_by,
+			reason,
+			requested_at,
+			scheduled_for,
+			cancelled_at,
+			completed_at
+		FROM account_deletion_requests
+		WHERE user_id = :user_id AND status = 'pending'
+	`
+
+	queryCancelDeletion = `
+		UPDATE account_deletion_requests
+		SET
+			status = 'cancelled',
+			cancelled_at = :cancelled_at
+		WHERE id = :id AND user_id = :user_id AND status = 'pending'
+	`
+
+	queryGetDueDeletionIDs = `
+		SELECT id
+		FROM account_deletion_requests
+		WHERE status = 'pending' AND scheduled_for <= :now
+		ORDER BY scheduled_for
+		LIMIT :limit
+	`
+
+	queryLockDueDeletion = `
+		SELECT
+			id,
+			user_id,
+			status,
+			confirmed_by,
+			reason,
+			requested_at,
+			scheduled_for,
+			cancelled_at,
+			completed_at
+		FROM account_deletion_requests
+		WHERE id = :id AND status = 'pending' AND scheduled_for <= :now
+		FOR UPDATE SKIP LOCKED
+	`
+
+	queryCompleteDeletion = `
+		UPDATE account_deletion_requests
+		SET
+			status = 'completed',
+			user_id = :pseudonym,
+			reason = NULL,
+			completed_at = :completed_at
+		WHERE id = :id
+	`
+
+	queryGetUserPhotos = `
+		SELECT profile_photo_url, face_photo_url
+		FROM users
+		WHERE id = :user_id
+	`
+
+	queryGetBudgetAudioLinks = `
+		SELECT audio_link
+		FROM budget_transactions
+		WHERE user_id = :user_id AND audio_link IS NOT NULL AND audio_link <> ''
+		UNION
+		SELECT audio_link
+		FROM invalid_budget_transactions
+		WHERE user_id = :user_id AND audio_link IS NOT NULL AND audio_link <> ''
+	`
+
+	queryGetKYCDocuments = `
+		SELECT ktp_image_url, selfie_url
+		FROM kyc_verifications
+		WHERE user_id = :user_id
+	`
+
+	queryGetWalletBalance = `
+		SELECT balance
+		FROM wallets
+		WHERE user_id = :user_id
+	`
+
+	queryDeleteBudgetTransactions = `
+		DELETE FROM budget_transactions
+		WHERE user_id = :user_id
+	`
+
+	queryDeleteInvalidBudgetTransactions = `
+		DELETE FROM invalid_budget_transactions
+		WHERE user_id = :user_id
+	`
+
+	queryAnonymizeWalletTransactions = `
+		UPDATE wallet_transactions
+		SET
+			user_id = NULL,
+			pseudonym = :pseudonym,
+			bank_account = NULL,
+			description = NULL,
+			updated_at = :updated_at
+		WHERE user_id = :user_id
+	`
+
+	queryDeleteKYCVerification = `
+		DELETE FROM kyc_verifications
+		WHERE user_id = :user_id
+	`
+
+	queryDeleteNotificationPreferences = `
+		DELETE FROM notification_preferences
+		WHERE user_id = :user_id
+	`
+
+	queryDeleteNotifications = `
+		DELETE FROM notifications
+		WHERE user_id = :user_id
+	`
+
+	queryDeleteNotificationDeliveries = `
+		DELETE FROM notification_deliveries
+		WHERE user_id = :user_id
+	`
+
+	queryDeleteOutboxEvents = `
+		DELETE FROM outbox_events
+		WHERE user_id = :user_id
+	`
+
+	// Queued deliveries carry a copy of their event, see events.Record.
+	queryDeleteEventDeliveries = `
+		DELETE FROM jobs
+		WHERE type = 'deliver_event' AND payload->'event'->>'user_id' = :user_id
+	`
+
+	queryDeleteDeadEventDeliveries = `
+		DELETE FROM dead_letter_jobs
+		WHERE type = 'deliver_event' AND payload->'event'->>'user_id' = :user_id
+	`
+
+	queryDeleteWallet = `
+		DELETE FROM wallets
+		WHERE user_id = :user_id
+	`
+
+	queryDeleteUser = `
+		DELETE FROM users
+		WHERE id = :user_id
+	`
+
+	queryExportWallet = `
+		SELECT
+			id,
+			balance,
+			created_at,
+			updated_at
+		FROM wallets
+		WHERE user_id = :user_id
+	`
+
+	queryExportWalletTransactions = `
+		SELECT
+			id,
+			amount,
+			type,
+			reference_no,
+			payment_method,
+			status,
+			bank_account,
+			bank_name,
+			description,
+			created_at,
+			updated_at
+		FROM wallet_transactions
+		WHERE user_id = :user_id
+		ORDER BY created_at
+	`
+
+	queryExportBudgetTransactions = `
+		SELECT
+			id,
+			title,
+			description,
+			nominal,
+			type,
+			category,
+			audio_link,
+			created_at,
+			updated_at
+		FROM budget_transactions
+		WHERE user_id = :user_id
+		ORDER BY created_at
+	`
+)
@@ -0,0 +1,112 @@
+package accountRepository
+
+import (
+	"ProjectGolang/internal/api/account"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+type SQLExecutor interface {
+	sqlx.ExtContext
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row
+	Rebind(query string) string
+}
+
+func New(db *sqlx.DB, log *logrus.Logger) Repository {
+	return &repository{
+		DB:  db,
+		log: log,
+	}
+}
+
+type repository struct {
+	DB  *sqlx.DB
+	log *logrus.Logger
+}
+
+type Repository interface {
+	NewClient(tx bool) (Client, error)
+}
+
+func (r *repository) NewClient(tx bool) (Client, error) {
+	var sqlExecutor SQLExecutor
+	var commitFunc, rollbackFunc func() error
+
+	sqlExecutor = r.DB
+
+	if tx {
+		var err error
+		txx, err := r.DB.Beginx()
+		if err != nil {
+			return Client{}, err
+		}
+
+		sqlExecutor = txx
+		commitFunc = txx.Commit
+		rollbackFunc = txx.Rollback
+	} else {
+		commitFunc = func() error { return nil }
+		rollbackFunc = func() error { return nil }
+	}
+
+	return Client{
+		Deletions: &deletionRepository{q: sqlExecutor, log: r.log},
+		Erasure:   &erasureRepository{q: sqlExecutor, log: r.log},
+		Export:    &exportRepository{q: sqlExecutor, log: r.log},
+		Commit:    commitFunc,
+		Rollback:  rollbackFunc,
+	}, nil
+}
+
+type Client struct {
+	Deletions interface {
+		CreateDeletionRequest(ctx context.Context, req account.DeletionRequest) error
+		GetPendingDeletion(ctx context.Context, userID string) (account.DeletionRequest, error)
+		CancelDeletion(ctx context.Context, id string, userID string, cancelledAt time.Time) error
+		GetDueDeletionIDs(ctx context.Context, now time.Time, limit int) ([]string, error)
+		LockDueDeletion(ctx context.Context, id string, now time.Time) (account.DeletionRequest, error)
+		CompleteDeletion(ctx context.Context, id string, pseudonym string, completedAt time.Time) error
+	}
+
+	// Erasure purges a user's personal data across the domain tables. Wallet
+	// transactions are kept for the statutory retention period, detached from
+	// the user and stripped of personal details.
+	Erasure interface {
+		GetUserMedia(ctx context.Context, userID string) (account.UserMedia, error)
+		GetWalletBalance(ctx context.Context, userID string) (float64, error)
+		DeleteBudgetTransactions(ctx context.Context, userID string) error
+		AnonymizeWalletTransactions(ctx context.Context, userID string, pseudonym string) error
+		DeleteKYCVerification(ctx context.Context, userID string) error
+		DeleteNotifications(ctx context.Context, userID string) error
+		DeleteEvents(ctx context.Context, userID string) error
+		DeleteWallet(ctx context.Context, userID string) error
+		DeleteUser(ctx context.Context, userID string) error
+	}
+
+	Export interface {
+		GetWallet(ctx context.Context, userID string) (*account.WalletExport, error)
+		GetWalletTransactions(ctx context.Context, userID string) ([]account.WalletTransactionExport, error)
+		GetBudgetTransactions(ctx context.Context, userID string) ([]account.BudgetTransactionExport, error)
+	}
+
+	Commit   func() error
+	Rollback func() error
+}
+
+type deletionRepository struct {
+	q   SQLExecutor
+	log *logrus.Logger
+}
+
+type erasureRepository struct {
+	q   SQLExecutor
+	log *logrus.Logger
+}
+
+type exportRepository struct {
+	q   SQLExecutor
+	log *logrus.Logger
+}
@@ -0,0 +1,265 @@
+package accountService
+
+import (
+	"ProjectGolang/internal/api/account"
+	"ProjectGolang/internal/api/auth"
+	"ProjectGolang/internal/api/notification"
+	"ProjectGolang/internal/entity"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/otp"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"math/rand"
+	"time"
+)
+
+const (
+	deletionGracePeriod = 30 * 24 * time.Hour
+	deletionOTPTTL      = 5 * time.Minute
+)
+
+func deletionOTPKey(userID string) string {
+	return "account_deletion:" + userID
+}
+
+func (s *accountService) RequestDeletionOTP(ctx context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.PhoneNumber == "" {
+		return account.ErrPhoneNumberNotSet
+	}
+
+	verificationCode := fmt.Sprintf("%05d", 10000+rand.Intn(90000))
+	if err := s.redisServer.SetOTP(ctx, deletionOTPKey(userID), verificationCode, deletionOTPTTL); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to set account deletion OTP in Redis")
+		return err
+	}
+
+	err = s.notifier.Notify(ctx, notification.Message{
+		Template: notification.TemplateAccountDeletionOTP,
+		UserID:   userID,
+		Phone:    user.PhoneNumber,
+		CodeKey:  deletionOTPKey(userID),
+		Data:     map[string]interface{}{"minutes": int(deletionOTPTTL / time.Minute)},
+	})
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to send account deletion OTP")
+		return err
+	}
+
+	return nil
+}
+
+func (s *accountService) RequestDeletion(ctx context.Context, userID string, req account.RequestDeletionRequest) (*account.DeletionStatusResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.accountRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+
+	if _, err := repo.Deletions.GetPendingDeletion(ctx, userID); err == nil {
+		return nil, account.ErrDeletionAlreadyPending
+	} else if !errors.Is(err, account.ErrDeletionNotFound) {
+		return nil, err
+	}
+
+	balance, err := repo.Erasure.GetWalletBalance(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if balance != 0 {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"balance":    balance,
+		}).Warn("Account deletion requested with non-empty wallet")
+		return nil, account.ErrWalletNotEmpty
+	}
+
+	// Confirmed last, as a matching OTP is used up.
+	confirmedBy, err := s.confirmDeletion(ctx, user, req)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.utils.NewULIDFromTimestamp(time.Now())
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to generate ULID")
+		return nil, err
+	}
+
+	now := time.Now()
+	deletion := account.DeletionRequest{
+		ID:           id,
+		UserID:       userID,
+		Status:       account.DeletionStatusPending,
+		ConfirmedBy:  confirmedBy,
+		Reason:       req.Reason,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(deletionGracePeriod),
+	}
+
+	if err := repo.Deletions.CreateDeletionRequest(ctx, deletion); err != nil {
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"request_id":    requestID,
+		"user_id":       userID,
+		"scheduled_for": deletion.ScheduledFor,
+	}).Info("Account deletion scheduled")
+
+	return makeDeletionStatus(deletion), nil
+}
+
+func (s *accountService) GetDeletionStatus(ctx context.Context, userID string) (*account.DeletionStatusResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.accountRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+
+	deletion, err := repo.Deletions.GetPendingDeletion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeDeletionStatus(deletion), nil
+}
+
+func (s *accountService) CancelDeletion(ctx context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.accountRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return err
+	}
+
+	deletion, err := repo.Deletions.GetPendingDeletion(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.Deletions.CancelDeletion(ctx, deletion.ID, userID, time.Now()); err != nil {
+		return err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"user_id":    userID,
+	}).Info("Account deletion cancelled")
+
+	return nil
+}
+
+// confirmDeletion checks the PIN when one is given, otherwise the OTP sent by
+// RequestDeletionOTP, and reports which of the two confirmed the request.
+// Wrong OTPs are limited per account, as for unlocking, so the code cannot be
+// guessed to schedule an erasure.
+func (s *accountService) confirmDeletion(ctx context.Context, user entity.User, req account.RequestDeletionRequest) (string, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	if req.PIN != "" {
+		if user.PersonalIdentificationNumber == "" {
+			return "", account.ErrPINNotSet
+		}
+		if err := s.bcryptUtils.ComparePassword(user.PersonalIdentificationNumber, req.PIN); err != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"user_id":    user.ID,
+			}).Warn("Invalid PIN for account deletion")
+			return "", account.ErrInvalidPIN
+		}
+		return account.ConfirmedByPIN, nil
+	}
+
+	if err := otp.Verify(ctx, s.redisServer, deletionOTPKey(user.ID), req.Code); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    user.ID,
+			"error":      err.Error(),
+		}).Warn("Invalid OTP for account deletion")
+		switch {
+		case errors.Is(err, otp.ErrTooManyAttempts):
+			return "", account.ErrTooManyOTPAttempts
+		case errors.Is(err, otp.ErrExpired), errors.Is(err, otp.ErrInvalid):
+			return "", account.ErrInvalidOTP
+		default:
+			return "", err
+		}
+	}
+
+	return account.ConfirmedByOTP, nil
+}
+
+func (s *accountService) getUser(ctx context.Context, userID string) (entity.User, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.authService.GetRepository().NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return entity.User{}, err
+	}
+
+	user, err := repo.Users.GetByID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, auth.ErrUserNotFound) {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"user_id":    userID,
+				"error":      err.Error(),
+			}).Error("Failed to get user by ID")
+		}
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+func makeDeletionStatus(deletion account.DeletionRequest) *account.DeletionStatusResponse {
+	return &account.DeletionStatusResponse{
+		ID:           deletion.ID,
+		Status:       deletion.Status,
+		RequestedAt:  deletion.RequestedAt,
+		ScheduledFor: deletion.ScheduledFor,
+		CanCancel:    deletion.Status == account.DeletionStatusPending,
+	}
+}
@@ -0,0 +1,98 @@
+package accountService
+
+import (
+	"ProjectGolang/internal/api/account"
+	contextPkg "ProjectGolang/pkg/context"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+// ExportData collects everything stored about the user into a single
+// document. Stored files are included as short-lived presigned links.
+func (s *accountService) ExportData(ctx context.Context, userID string) (*account.DataExport, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.accountRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+
+	wallet, err := repo.Export.GetWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	walletTransactions, err := repo.Export.GetWalletTransactions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	budgetTransactions, err := repo.Export.GetBudgetTransactions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range budgetTransactions {
+		budgetTransactions[i].AudioLink = s.presign(requestID, userID, budgetTransactions[i].AudioLink)
+	}
+
+	return &account.DataExport{
+		ExportedAt: time.Now(),
+		Profile: account.ProfileExport{
+			ID:                        user.ID,
+			Email:                     user.Email,
+			Name:                      user.Name,
+			NationalIdentityNumber:    user.NationalIdentityNumber,
+			BirthPlace:                user.BirthPlace,
+			BirthDate:                 user.BirthDate,
+			Gender:                    user.Gender,
+			Address:                   user.Address,
+			NeighborhoodCommunityUnit: user.NeighborhoodCommunityUnit,
+			Village:                   user.Village,
+			District:                  user.District,
+			Religion:                  user.Religion,
+			MaritalStatus:             user.MaritalStatus,
+			Profession:                user.Profession,
+			Citizenship:               user.Citizenship,
+			CardValidUntil:            user.CardValidUntil,
+			PhoneNumber:               user.PhoneNumber,
+			ProfilePhotoURL:           s.presign(requestID, userID, user.ProfilePhotoURL),
+			FacePhotoURL:              s.presign(requestID, userID, user.FacePhotoURL),
+			GoogleLinked:              user.GoogleSubject != "",
+			TouchIDEnabled:            user.EnableTouchID,
+			IsVerified:                user.IsVerified,
+			CreatedAt:                 user.CreatedAt,
+			UpdatedAt:                 user.UpdatedAt,
+		},
+		Wallet:             wallet,
+		WalletTransactions: walletTransactions,
+		BudgetTransactions: budgetTransactions,
+	}, nil
+}
+
+func (s *accountService) presign(requestID string, userID string, fileKey string) string {
+	if fileKey == "" {
+		return ""
+	}
+
+	presignedURL, err := s.s3Client.PresignUrl(userID, fileKey)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Warn("Failed to presign URL for data export")
+		return ""
+	}
+
+	return presignedURL
+}
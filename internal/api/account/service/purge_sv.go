@@ -0,0 +1,194 @@
+package accountService
+
+import (
+	"ProjectGolang/internal/api/account"
+	"ProjectGolang/internal/jobs"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/imaging"
+	"ProjectGolang/pkg/s3"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+const (
+	purgeInterval  = time.Hour
+	purgeBatchSize = 50
+)
+
+// Run purges accounts whose grace period has elapsed, once at start-up and
+// then every purgeInterval, until ctx is cancelled.
+func (s *accountService) Run(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := s.PurgeDueAccounts(ctx); err != nil {
+			s.log.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("Account purge run failed")
+		} else if purged > 0 {
+			s.log.WithFields(logrus.Fields{
+				"purged": purged,
+			}).Info("Purged deleted accounts")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeDueAccounts erases every account whose deletion is due and returns how
+// many were purged. Each account is purged in its own transaction so one
+// failure does not hold back the rest of the batch.
+func (s *accountService) PurgeDueAccounts(ctx context.Context) (int, error) {
+	repo, err := s.accountRepository.NewClient(false)
+	if err != nil {
+		return 0, err
+	}
+
+	ids, err := repo.Deletions.GetDueDeletionIDs(ctx, time.Now(), purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+
+		ok, err := s.purgeAccount(ctx, id)
+		if err != nil {
+			s.log.WithFields(logrus.Fields{
+				"deletion_id": id,
+				"error":       err.Error(),
+			}).Error("Failed to purge account")
+			continue
+		}
+		if ok {
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// purgeAccount removes the user's personal data in one transaction: budget
+// records, the KYC verification, notifications, domain events with their
+// queued deliveries, the wallet and the user row are deleted, wallet
+// transactions are kept for bookkeeping under a random pseudonym without bank
+// details, and the deletion request itself only keeps that pseudonym. Stored
+// files are removed from S3 after the commit.
+func (s *accountService) purgeAccount(ctx context.Context, deletionID string) (bool, error) {
+	repo, err := s.accountRepository.NewClient(true)
+	if err != nil {
+		return false, err
+	}
+	defer repo.Rollback()
+
+	deletion, err := repo.Deletions.LockDueDeletion(ctx, deletionID, time.Now())
+	if err != nil {
+		if errors.Is(err, account.ErrDeletionNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	userID := deletion.UserID
+
+	balance, err := repo.Erasure.GetWalletBalance(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if balance != 0 {
+		s.log.WithFields(logrus.Fields{
+			"deletion_id": deletionID,
+			"user_id":     userID,
+			"balance":     balance,
+		}).Warn("Account purge postponed, wallet balance must be settled by support first")
+		return false, nil
+	}
+
+	media, err := repo.Erasure.GetUserMedia(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	pseudonymID, err := s.utils.NewULIDFromTimestamp(time.Now())
+	if err != nil {
+		return false, err
+	}
+	pseudonym := "deleted_" + pseudonymID
+
+	if err := repo.Erasure.DeleteBudgetTransactions(ctx, userID); err != nil {
+		return false, err
+	}
+	if err := repo.Erasure.AnonymizeWalletTransactions(ctx, userID, pseudonym); err != nil {
+		return false, err
+	}
+	if err := repo.Erasure.DeleteKYCVerification(ctx, userID); err != nil {
+		return false, err
+	}
+	if err := repo.Erasure.DeleteNotifications(ctx, userID); err != nil {
+		return false, err
+	}
+	if err := repo.Erasure.DeleteEvents(ctx, userID); err != nil {
+		return false, err
+	}
+	if err := repo.Erasure.DeleteWallet(ctx, userID); err != nil {
+		return false, err
+	}
+	if err := repo.Erasure.DeleteUser(ctx, userID); err != nil {
+		return false, err
+	}
+	if err := repo.Deletions.CompleteDeletion(ctx, deletionID, pseudonym, time.Now()); err != nil {
+		return false, err
+	}
+
+	if err := repo.Commit(); err != nil {
+		return false, err
+	}
+
+	s.deleteMedia(ctx, deletionID, media)
+	s.authService.Auth().ResetLoginAttempts(ctx, userID)
+
+	s.log.WithFields(logrus.Fields{
+		"deletion_id": deletionID,
+		"pseudonym":   pseudonym,
+	}).Info("Account purged")
+
+	return true, nil
+}
+
+func (s *accountService) deleteMedia(ctx context.Context, deletionID string, media account.UserMedia) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	links := append([]string{media.ProfilePhotoURL, media.FacePhotoURL}, media.AudioLinks...)
+	links = append(links, media.KYCDocuments...)
+	// Photos are also stored in smaller sizes next to the full one.
+	for _, photo := range []string{media.ProfilePhotoURL, media.FacePhotoURL} {
+		for _, size := range imaging.PhotoSizes[1:] {
+			if photo != "" {
+				links = append(links, s3.SizeKey(photo, size.Name))
+			}
+		}
+	}
+	for _, link := range links {
+		if link == "" {
+			continue
+		}
+
+		if err := s.jobs.Enqueue(ctx, jobs.DeleteFile{FileName: link}); err != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id":  requestID,
+				"deletion_id": deletionID,
+				"file_name":   link,
+				"error":       err.Error(),
+			}).Error("Failed to queue deletion of file of purged account")
+		}
+	}
+}
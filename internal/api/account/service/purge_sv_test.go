@@ -0,0 +1,128 @@
+package accountService
+
+import (
+	accountRepository "ProjectGolang/internal/api/account/repository"
+	authRepository "ProjectGolang/internal/api/auth/repository"
+	authService "ProjectGolang/internal/api/auth/service"
+	"ProjectGolang/internal/jobs"
+	"ProjectGolang/pkg/redis"
+	"ProjectGolang/pkg/utils"
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	deletionID = "deletion-1"
+	userID     = "user-1"
+)
+
+// recordedJobs keeps the jobs enqueued instead of running them.
+type recordedJobs struct {
+	mutex sync.Mutex
+	jobs  []jobs.Job
+}
+
+func (r *recordedJobs) Enqueue(_ context.Context, job jobs.Job, _ ...jobs.Option) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.jobs = append(r.jobs, job)
+	return nil
+}
+
+func newPurgeTest(t *testing.T) (IAccountService, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	redisServer := redis.NewFake()
+	auth := authService.New(log, authRepository.New(sqlxDB, log), nil, redisServer, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	service := NewAccountService(log, accountRepository.New(sqlxDB, log), auth, redisServer, nil, nil, &recordedJobs{}, nil, utils.New())
+	return service, mock
+}
+
+// expectErasureUntilEvents expects the purge of userID up to, but not
+// including, the deletion of its domain events.
+func expectErasureUntilEvents(mock sqlmock.Sqlmock) {
+	now := time.Now()
+
+	mock.ExpectQuery(`FROM account_deletion_requests\s+WHERE status = 'pending'`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(deletionID))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM account_deletion_requests\s+WHERE id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status", "requested_at", "scheduled_for"}).
+			AddRow(deletionID, userID, "pending", now.Add(-31*24*time.Hour), now.Add(-time.Hour)))
+	mock.ExpectQuery(`SELECT balance\s+FROM wallets`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(0))
+	mock.ExpectQuery(`SELECT profile_photo_url, face_photo_url`).
+		WillReturnRows(sqlmock.NewRows([]string{"profile_photo_url", "face_photo_url"}))
+	mock.ExpectQuery(`SELECT audio_link`).
+		WillReturnRows(sqlmock.NewRows([]string{"audio_link"}))
+	mock.ExpectQuery(`SELECT ktp_image_url, selfie_url`).
+		WillReturnRows(sqlmock.NewRows([]string{"ktp_image_url", "selfie_url"}))
+	mock.ExpectExec(`DELETE FROM budget_transactions`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM invalid_budget_transactions`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE wallet_transactions`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM kyc_verifications`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM notification_preferences`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM notifications`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM notification_deliveries`).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestPurgeDeletesDomainEvents(t *testing.T) {
+	service, mock := newPurgeTest(t)
+	expectErasureUntilEvents(mock)
+
+	mock.ExpectExec(`DELETE FROM outbox_events\s+WHERE user_id = \$1`).
+		WithArgs(userID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`DELETE FROM jobs\s+WHERE type = 'deliver_event' AND payload->'event'->>'user_id' = \$1`).
+		WithArgs(userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM dead_letter_jobs\s+WHERE type = 'deliver_event' AND payload->'event'->>'user_id' = \$1`).
+		WithArgs(userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM wallets`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM users`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE account_deletion_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	purged, err := service.PurgeDueAccounts(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeKeepsAccountWhenEventsCannotBeDeleted(t *testing.T) {
+	service, mock := newPurgeTest(t)
+	expectErasureUntilEvents(mock)
+
+	mock.ExpectExec(`DELETE FROM outbox_events`).
+		WithArgs(userID).
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	purged, err := service.PurgeDueAccounts(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+	assert.NoError(t, mock.ExpectationsWereMet(), "the erasure must be rolled back as a whole")
+}
@@ -0,0 +1,61 @@
+package accountService
+
+import (
+	"ProjectGolang/internal/api/account"
+	accountRepository "ProjectGolang/internal/api/account/repository"
+	authService "ProjectGolang/internal/api/auth/service"
+	"ProjectGolang/internal/api/notification"
+	"ProjectGolang/internal/jobs"
+	"ProjectGolang/pkg/bcrypt"
+	"ProjectGolang/pkg/redis"
+	"ProjectGolang/pkg/s3"
+	"ProjectGolang/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+type IAccountService interface {
+	RequestDeletionOTP(ctx context.Context, userID string) error
+	RequestDeletion(ctx context.Context, userID string, req account.RequestDeletionRequest) (*account.DeletionStatusResponse, error)
+	GetDeletionStatus(ctx context.Context, userID string) (*account.DeletionStatusResponse, error)
+	CancelDeletion(ctx context.Context, userID string) error
+	ExportData(ctx context.Context, userID string) (*account.DataExport, error)
+	PurgeDueAccounts(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
+
+type accountService struct {
+	log               *logrus.Logger
+	accountRepository accountRepository.Repository
+	authService       authService.AuthService
+	redisServer       redis.IRedis
+	notifier          notification.Notifier
+	s3Client          s3.ItfS3
+	jobs              jobs.Enqueuer
+	bcryptUtils       bcrypt.IBcrypt
+	utils             utils.IUtils
+}
+
+func NewAccountService(
+	log *logrus.Logger,
+	ar accountRepository.Repository,
+	as authService.AuthService,
+	redisServer redis.IRedis,
+	notifier notification.Notifier,
+	s3Client s3.ItfS3,
+	jobs jobs.Enqueuer,
+	bcryptUtils bcrypt.IBcrypt,
+	utils utils.IUtils,
+) IAccountService {
+	return &accountService{
+		log:               log,
+		accountRepository: ar,
+		authService:       as,
+		redisServer:       redisServer,
+		notifier:          notifier,
+		s3Client:          s3Client,
+		jobs:              jobs,
+		bcryptUtils:       bcryptUtils,
+		utils:             utils,
+	}
+}
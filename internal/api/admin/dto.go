@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ActionSearchUsers            = "user.search"
+	ActionViewWalletTransactions = "wallet.transactions.view"
+	ActionSettleTopUp            = "wallet.topup.settle"
+	ActionRefundTopUp            = "wallet.topup.refund"
+	ActionLockUser               = "user.lock"
+	ActionUnlockUser             = "user.unlock"
+	ActionUpdateUserRole         = "user.role.update"
+	ActionViewAuditLogs          = "audit_log.view"
+	ActionListKYC                = "kyc.list"
+	ActionViewKYC                = "kyc.view"
+	ActionApproveKYC             = "kyc.approve"
+	ActionRejectKYC              = "kyc.reject"
+
+	TargetUser              = "user"
+	TargetWalletTransaction = "wallet_transaction"
+	TargetAuditLog          = "audit_log"
+	TargetKYCVerification   = "kyc_verification"
+
+	AuditStatusSuccess = "success"
+	AuditStatusFailed  = "failed"
+)
+
+type SearchUsersRequest struct {
+	Query string `query:"q" validate:"omitempty,max=255"`
+	Role  string `query:"role" validate:"omitempty,oneof=user support admin"`
+	Page  int    `query:"page"`
+	Limit int    `query:"limit"`
+}
+
+type UserSummary struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Email        string     `json:"email,omitempty"`
+	PhoneNumber  string     `json:"phone_number,omitempty"`
+	Role         string     `json:"role"`
+	IsVerified   bool       `json:"is_verified"`
+	LockedAt     *time.Time `json:"locked_at,omitempty"`
+	LockedReason string     `json:"locked_reason,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type SearchUsersResponse struct {
+	Users []UserSummary `json:"users"`
+	Total int           `json:"total"`
+}
+
+type LockUserRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+type TopUpActionRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+type RejectKYCRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user support admin"`
+}
+
+type AuditLog struct {
+	ID         string          `json:"id"`
+	ActorID    string          `json:"actor_id"`
+	ActorRole  string          `json:"actor_role"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id,omitempty"`
+	Status     string          `json:"status"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	IPAddress  string          `json:"ip_address,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AuditLogFilter struct {
+	ActorID  string `query:"actor_id"`
+	TargetID string `query:"target_id"`
+	Action   string `query:"action"`
+	Page     int    `query:"page"`
+	Limit    int    `query:"limit"`
+}
+
+type AuditLogResponse struct {
+	Logs  []AuditLog `json:"logs"`
+	Total int        `json:"total"`
+}
@@ -0,0 +1,13 @@
+package admin
+
+import (
+	"ProjectGolang/pkg/response"
+	"net/http"
+)
+
+var (
+	ErrUserNotFound      = response.NewError(http.StatusNotFound, "user not found")
+	ErrCannotModifySelf  = response.NewError(http.StatusBadRequest, "administrators cannot lock or change the role of their own account")
+	ErrUserAlreadyLocked = response.NewError(http.StatusConflict, "user is already locked")
+	ErrUserNotLocked     = response.NewError(http.StatusConflict, "user is not locked")
+)
@@ -0,0 +1,270 @@
+package adminHandler
+
+import (
+	"ProjectGolang/internal/api/admin"
+	"ProjectGolang/internal/entity"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/handlerUtil"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+	"strconv"
+	"time"
+)
+
+func (h *AdminHandler) SearchUsers(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var req admin.SearchUsersRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_query")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	res, err := h.adminService.SearchUsers(c, actor, req)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "search_users")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *AdminHandler) GetUserWalletTransactions(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	page, err := strconv.Atoi(ctx.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(ctx.Query("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	res, err := h.adminService.GetUserWalletTransactions(c, actor, ctx.Params("id"), page, limit)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_user_wallet_transactions")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *AdminHandler) LockUser(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var req admin.LockUserRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	if err := h.adminService.LockUser(c, actor, ctx.Params("id"), req.Reason); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "lock_user")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, nil)
+	}
+}
+
+func (h *AdminHandler) UnlockUser(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	if err := h.adminService.UnlockUser(c, actor, ctx.Params("id")); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "unlock_user")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, nil)
+	}
+}
+
+func (h *AdminHandler) UpdateUserRole(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var req admin.UpdateUserRoleRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	if err := h.adminService.UpdateUserRole(c, actor, ctx.Params("id"), entity.Role(req.Role)); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "update_user_role")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, nil)
+	}
+}
+
+func (h *AdminHandler) SettleTopUp(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var req admin.TopUpActionRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	res, err := h.adminService.SettleTopUp(c, actor, ctx.Params("reference_no"), req.Reason)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "settle_topup")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *AdminHandler) RefundTopUp(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var req admin.TopUpActionRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	res, err := h.adminService.RefundTopUp(c, actor, ctx.Params("reference_no"), req.Reason)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "refund_topup")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *AdminHandler) GetAuditLogs(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var filter admin.AuditLogFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_query")
+	}
+
+	res, err := h.adminService.GetAuditLogs(c, actor, filter)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_audit_logs")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
@@ -0,0 +1,54 @@
+package adminHandler
+
+import (
+	adminService "ProjectGolang/internal/api/admin/service"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AdminHandler struct {
+	log          *logrus.Logger
+	validator    *validator.Validate
+	middleware   middleware.Middleware
+	adminService adminService.IAdminService
+}
+
+func New(
+	log *logrus.Logger,
+	validate *validator.Validate,
+	middleware middleware.Middleware,
+	adminService adminService.IAdminService,
+) *AdminHandler {
+	return &AdminHandler{
+		log:          log,
+		validator:    validate,
+		middleware:   middleware,
+		adminService: adminService,
+	}
+}
+
+func (h *AdminHandler) Start(srv fiber.Router) {
+	staff := h.middleware.RequireRole(entity.RoleSupport, entity.RoleAdmin)
+	adminOnly := h.middleware.RequireRole(entity.RoleAdmin)
+
+	admin := srv.Group("/admin", h.middleware.NewTokenMiddleware)
+
+	admin.Get("/users", staff, h.SearchUsers)
+	admin.Get("/users/:id/wallet/transactions", staff, h.GetUserWalletTransactions)
+	admin.Post("/users/:id/lock", adminOnly, h.LockUser)
+	admin.Post("/users/:id/unlock", adminOnly, h.UnlockUser)
+	admin.Patch("/users/:id/role", adminOnly, h.UpdateUserRole)
+
+	admin.Get("/kyc", staff, h.ListKYCVerifications)
+	admin.Get("/users/:id/kyc", staff, h.GetKYCVerification)
+	admin.Post("/users/:id/kyc/approve", staff, h.ApproveKYC)
+	admin.Post("/users/:id/kyc/reject", staff, h.RejectKYC)
+
+	admin.Post("/wallet/transactions/:reference_no/settle", adminOnly, h.SettleTopUp)
+	admin.Post("/wallet/transactions/:reference_no/refund", adminOnly, h.RefundTopUp)
+
+	admin.Get("/audit-logs", adminOnly, h.GetAuditLogs)
+}
@@ -0,0 +1,130 @@
+package adminHandler
+
+import (
+	"ProjectGolang/internal/api/admin"
+	"ProjectGolang/internal/api/kyc"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/handlerUtil"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+	"time"
+)
+
+func (h *AdminHandler) ListKYCVerifications(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var req kyc.VerificationListRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_query")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	res, err := h.adminService.ListKYCVerifications(c, actor, req)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "list_kyc_verifications")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *AdminHandler) GetKYCVerification(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	res, err := h.adminService.GetKYCVerification(c, actor, ctx.Params("id"))
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_kyc_verification")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *AdminHandler) ApproveKYC(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	res, err := h.adminService.ApproveKYC(c, actor, ctx.Params("id"))
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "approve_kyc")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *AdminHandler) RejectKYC(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	actor, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var req admin.RejectKYCRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	res, err := h.adminService.RejectKYC(c, actor, ctx.Params("id"), req.Reason)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "reject_kyc")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
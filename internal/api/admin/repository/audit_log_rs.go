@@ -0,0 +1,150 @@
+package adminRepository
+
+import (
+	"ProjectGolang/internal/api/admin"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"encoding/json"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+type AuditLogDB struct {
+	ID         sql.NullString `db:"id"`
+	ActorID    sql.NullString `db:"actor_id"`
+	ActorRole  sql.NullString `db:"actor_role"`
+	Action     sql.NullString `db:"action"`
+	TargetType sql.NullString `db:"target_type"`
+	TargetID   sql.NullString `db:"target_id"`
+	Status     sql.NullString `db:"status"`
+	Metadata   []byte         `db:"metadata"`
+	IPAddress  sql.NullString `db:"ip_address"`
+	RequestID  sql.NullString `db:"request_id"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func (r *auditLogRepository) CreateAuditLog(ctx context.Context, log admin.AuditLog) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	var metadata interface{}
+	if len(log.Metadata) > 0 {
+		metadata = string(log.Metadata)
+	}
+
+	argsKV := map[string]interface{}{
+		"id":          log.ID,
+		"actor_id":    log.ActorID,
+		"actor_role":  log.ActorRole,
+		"action":      log.Action,
+		"target_type": log.TargetType,
+		"target_id":   sql.NullString{String: log.TargetID, Valid: log.TargetID != ""},
+		"status":      log.Status,
+		"metadata":    metadata,
+		"ip_address":  log.IPAddress,
+		"request_id":  log.RequestID,
+		"created_at":  log.CreatedAt,
+	}
+
+	query, args, err := sqlx.Named(queryCreateAuditLog, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CreateAuditLog named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CreateAuditLog execution err")
+		return err
+	}
+
+	return nil
+}
+
+func (r *auditLogRepository) GetAuditLogs(ctx context.Context, filter admin.AuditLogFilter, limit, offset int) ([]admin.AuditLog, int, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var logs []AuditLogDB
+	var total int
+
+	argsKV := map[string]interface{}{
+		"actor_id":  filter.ActorID,
+		"target_id": filter.TargetID,
+		"action":    filter.Action,
+		"limit":     limit,
+		"offset":    offset,
+	}
+
+	countQuery, countArgs, err := sqlx.Named(queryCountAuditLogs, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CountAuditLogs named query preparation err")
+		return nil, 0, err
+	}
+
+	countQuery = r.q.Rebind(countQuery)
+
+	if err := r.q.QueryRowxContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CountAuditLogs execution err")
+		return nil, 0, err
+	}
+
+	query, args, err := sqlx.Named(queryGetAuditLogs, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetAuditLogs named query preparation err")
+		return nil, 0, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.SelectContext(ctx, &logs, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetAuditLogs execution err")
+		return nil, 0, err
+	}
+
+	result := make([]admin.AuditLog, 0, len(logs))
+	for _, log := range logs {
+		result = append(result, r.makeAuditLog(log))
+	}
+
+	return result, total, nil
+}
+
+func (r *auditLogRepository) makeAuditLog(log AuditLogDB) admin.AuditLog {
+	var metadata json.RawMessage
+	if len(log.Metadata) > 0 {
+		metadata = json.RawMessage(log.Metadata)
+	}
+
+	return admin.AuditLog{
+		ID:         log.ID.String,
+		ActorID:    log.ActorID.String,
+		ActorRole:  log.ActorRole.String,
+		Action:     log.Action.String,
+		TargetType: log.TargetType.String,
+		TargetID:   log.TargetID.String,
+		Status:     log.Status.String,
+		Metadata:   metadata,
+		IPAddress:  log.IPAddress.String,
+		RequestID:  log.RequestID.String,
+		CreatedAt:  log.CreatedAt,
+	}
+}
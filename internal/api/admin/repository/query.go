@@ -0,0 +1,110 @@
+package adminRepository
+
+const (
+	querySearchUsers = `
+		SELECT
+			id,
+			name,
+			email,
+			phone_number,
+			role,
+			is_verified,
+			locked_at,
+			locked_reason,
+			created_at
+		FROM users
+		WHERE (:query = ''
+			OR id = :query
+			OR name ILIKE :pattern
+			OR email ILIKE :pattern
+			OR phone_number ILIKE :pattern)
+		  AND (:role = '' OR role = :role)
+		ORDER BY created_at DESC
+		LIMIT :limit OFFSET :offset
+	`
+
+	queryCountSearchUsers = `
+		SELECT COUNT(*)
+		FROM users
+		WHERE (:query = ''
+			OR id = :query
+			OR name ILIKE :pattern
+			OR email ILIKE :pattern
+			OR phone_number ILIKE :pattern)
+		  AND (:role = '' OR role = :role)
+	`
+
+	querySetLocked = `
+		UPDATE users
+		SET
+			locked_at = :locked_at,
+			locked_reason = :locked_reason,
+			updated_at = :updated_at
+		WHERE id = :id
+	`
+
+	queryUpdateRole = `
+		UPDATE users
+		SET
+			role = :role,
+			updated_at = :updated_at
+		WHERE id = :id
+	`
+
+	queryCreateAuditLog = `
+		INSERT INTO admin_audit_logs (
+			id,
+			actor_id,
+			actor_role,
+			action,
+			target_type,
+			target_id,
+			status,
+			metadata,
+			ip_address,
+			request_id,
+			created_at
+		) VALUES (
+			:id,
+			:actor_id,
+			:actor_role,
+			:action,
+			:target_type,
+			:target_id,
+			:status,
+			:metadata,
+			:ip_address,
+			:request_id,
+			:created_at
+		)
+	`
+
+	queryGetAuditLogs = `
+		SELECT
+			id,
+			actor_id,
+			actor_role,
+			action,
+			target_type,
+			target_id,
+			status,
+			metadata,
+			ip_address,
+			request_id,
+			created_at
+		FROM admin_audit_logs
+		WHERE (:actor_id = '' OR actor_id = :actor_id)
+		  AND (:target_id = '' OR target_id = :target_id)
+		  AND (:action = '' OR action = :action)
+		ORDER BY created_at DESC
+		LIMIT :limit OFFSET :offset
+	`
+
+	queryCountAuditLogs = `
+		SELECT COUNT(*)
+		FROM admin_audit_logs
+		WHERE (:actor_id = '' OR actor_id = :actor_id)
+		  AND (:target_id = '' OR target_id = :target_id)
+		  AND (:action = '' OR action = :action)
+	`
+)
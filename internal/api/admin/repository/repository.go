@@ -0,0 +1,87 @@
+package adminRepository
+
+import (
+	"ProjectGolang/internal/api/admin"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+type SQLExecutor interface {
+	sqlx.ExtContext
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row
+	Rebind(query string) string
+}
+
+func New(db *sqlx.DB, log *logrus.Logger) Repository {
+	return &repository{
+		DB:  db,
+		log: log,
+	}
+}
+
+type repository struct {
+	DB  *sqlx.DB
+	log *logrus.Logger
+}
+
+type Repository interface {
+	NewClient(tx bool) (Client, error)
+}
+
+func (r *repository) NewClient(tx bool) (Client, error) {
+	var sqlExecutor SQLExecutor
+	var commitFunc, rollbackFunc func() error
+
+	sqlExecutor = r.DB
+
+	if tx {
+		var err error
+		txx, err := r.DB.Beginx()
+		if err != nil {
+			return Client{}, err
+		}
+
+		sqlExecutor = txx
+		commitFunc = txx.Commit
+		rollbackFunc = txx.Rollback
+	} else {
+		commitFunc = func() error { return nil }
+		rollbackFunc = func() error { return nil }
+	}
+
+	return Client{
+		Users:     &userRepository{q: sqlExecutor, log: r.log},
+		AuditLogs: &auditLogRepository{q: sqlExecutor, log: r.log},
+		Commit:    commitFunc,
+		Rollback:  rollbackFunc,
+	}, nil
+}
+
+type Client struct {
+	Users interface {
+		SearchUsers(ctx context.Context, query string, role string, limit, offset int) ([]admin.UserSummary, int, error)
+		SetLocked(ctx context.Context, id string, lockedAt *time.Time, reason string) error
+		UpdateRole(ctx context.Context, id string, role string) error
+	}
+
+	AuditLogs interface {
+		CreateAuditLog(ctx context.Context, log admin.AuditLog) error
+		GetAuditLogs(ctx context.Context, filter admin.AuditLogFilter, limit, offset int) ([]admin.AuditLog, int, error)
+	}
+
+	Commit   func() error
+	Rollback func() error
+}
+
+type userRepository struct {
+	q   SQLExecutor
+	log *logrus.Logger
+}
+
+type auditLogRepository struct {
+	q   SQLExecutor
+	log *logrus.Logger
+}
@@ -0,0 +1,183 @@
+package adminRepository
+
+import (
+	"ProjectGolang/internal/api/admin"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+type UserSummaryDB struct {
+	ID           sql.NullString `db:"id"`
+	Name         sql.NullString `db:"name"`
+	Email        sql.NullString `db:"email"`
+	PhoneNumber  sql.NullString `db:"phone_number"`
+	Role         sql.NullString `db:"role"`
+	IsVerified   sql.NullBool   `db:"is_verified"`
+	LockedAt     sql.NullTime   `db:"locked_at"`
+	LockedReason sql.NullString `db:"locked_reason"`
+	CreatedAt    sql.NullTime   `db:"created_at"`
+}
+
+func (r *userRepository) SearchUsers(ctx context.Context, query string, role string, limit, offset int) ([]admin.UserSummary, int, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var users []UserSummaryDB
+	var total int
+
+	argsKV := map[string]interface{}{
+		"query":   query,
+		"pattern": "%" + query + "%",
+		"role":    role,
+		"limit":   limit,
+		"offset":  offset,
+	}
+
+	countQuery, countArgs, err := sqlx.Named(queryCountSearchUsers, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CountSearchUsers named query preparation err")
+		return nil, 0, err
+	}
+
+	countQuery = r.q.Rebind(countQuery)
+
+	if err := r.q.QueryRowxContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CountSearchUsers execution err")
+		return nil, 0, err
+	}
+
+	selectQuery, args, err := sqlx.Named(querySearchUsers, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("SearchUsers named query preparation err")
+		return nil, 0, err
+	}
+
+	selectQuery = r.q.Rebind(selectQuery)
+
+	if err := r.q.SelectContext(ctx, &users, selectQuery, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("SearchUsers execution err")
+		return nil, 0, err
+	}
+
+	result := make([]admin.UserSummary, 0, len(users))
+	for _, user := range users {
+		result = append(result, r.makeUserSummary(user))
+	}
+
+	return result, total, nil
+}
+
+// SetLocked locks the user when lockedAt is set and unlocks it when nil.
+func (r *userRepository) SetLocked(ctx context.Context, id string, lockedAt *time.Time, reason string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"id":            id,
+		"locked_at":     lockedAt,
+		"locked_reason": sql.NullString{String: reason, Valid: lockedAt != nil},
+		"updated_at":    time.Now(),
+	}
+
+	query, args, err := sqlx.Named(querySetLocked, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("SetLocked named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	result, err := r.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("SetLocked execution err")
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    id,
+		}).Warn("SetLocked no rows found")
+		return admin.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id string, role string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"id":         id,
+		"role":       role,
+		"updated_at": time.Now(),
+	}
+
+	query, args, err := sqlx.Named(queryUpdateRole, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("UpdateRole named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	result, err := r.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("UpdateRole execution err")
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    id,
+		}).Warn("UpdateRole no rows found")
+		return admin.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) makeUserSummary(user UserSummaryDB) admin.UserSummary {
+	var lockedAt *time.Time
+	if user.LockedAt.Valid {
+		lockedAt = &user.LockedAt.Time
+	}
+
+	return admin.UserSummary{
+		ID:           user.ID.String,
+		Name:         user.Name.String,
+		Email:        user.Email.String,
+		PhoneNumber:  user.PhoneNumber.String,
+		Role:         user.Role.String,
+		IsVerified:   user.IsVerified.Bool,
+		LockedAt:     lockedAt,
+		LockedReason: user.LockedReason.String,
+		CreatedAt:    user.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,305 @@
+package adminService
+
+import (
+	"ProjectGolang/internal/api/admin"
+	"ProjectGolang/internal/api/auth"
+	sentrapay "ProjectGolang/internal/api/sentra_pay"
+	"ProjectGolang/internal/entity"
+	contextPkg "ProjectGolang/pkg/context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"strings"
+	"time"
+)
+
+func (s *adminService) SearchUsers(ctx context.Context, actor entity.UserLoginData, req admin.SearchUsersRequest) (*admin.SearchUsersResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	query := strings.TrimSpace(req.Query)
+	metadata := map[string]interface{}{"query": query, "role": req.Role}
+
+	repo, err := s.adminRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+
+	limit, offset := paginate(req.Page, req.Limit)
+	users, total, err := repo.Users.SearchUsers(ctx, query, req.Role, limit, offset)
+	s.audit(ctx, actor, admin.ActionSearchUsers, admin.TargetUser, "", metadata, err)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to search users")
+		return nil, err
+	}
+
+	return &admin.SearchUsersResponse{Users: users, Total: total}, nil
+}
+
+func (s *adminService) GetUserWalletTransactions(ctx context.Context, actor entity.UserLoginData, userID string, page, limit int) (*sentrapay.TransactionHistoryResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		s.audit(ctx, actor, admin.ActionViewWalletTransactions, admin.TargetUser, userID, nil, err)
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	history, err := s.sentraPayService.GetTransactionHistory(ctx, userID, page, limit)
+	s.audit(ctx, actor, admin.ActionViewWalletTransactions, admin.TargetUser, userID, nil, err)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to get wallet transactions")
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (s *adminService) SettleTopUp(ctx context.Context, actor entity.UserLoginData, referenceNo string, reason string) (*sentrapay.WalletTransaction, error) {
+	transaction, err := s.sentraPayService.SettleTopUp(ctx, referenceNo)
+	s.audit(ctx, actor, admin.ActionSettleTopUp, admin.TargetWalletTransaction, referenceNo, map[string]interface{}{
+		"reason": reason,
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (s *adminService) RefundTopUp(ctx context.Context, actor entity.UserLoginData, referenceNo string, reason string) (*sentrapay.WalletTransaction, error) {
+	transaction, err := s.sentraPayService.RefundTopUp(ctx, referenceNo)
+	s.audit(ctx, actor, admin.ActionRefundTopUp, admin.TargetWalletTransaction, referenceNo, map[string]interface{}{
+		"reason": reason,
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (s *adminService) LockUser(ctx context.Context, actor entity.UserLoginData, userID string, reason string) error {
+	err := s.lockUser(ctx, actor, userID, reason)
+	s.audit(ctx, actor, admin.ActionLockUser, admin.TargetUser, userID, map[string]interface{}{
+		"reason": reason,
+	}, err)
+	return err
+}
+
+func (s *adminService) lockUser(ctx context.Context, actor entity.UserLoginData, userID string, reason string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	if actor.ID == userID {
+		return admin.ErrCannotModifySelf
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsLocked() {
+		return admin.ErrUserAlreadyLocked
+	}
+
+	repo, err := s.adminRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return err
+	}
+
+	now := time.Now()
+	if err := repo.Users.SetLocked(ctx, userID, &now, reason); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to lock user")
+		return err
+	}
+
+	return s.revokeSessions(ctx, userID)
+}
+
+func (s *adminService) UnlockUser(ctx context.Context, actor entity.UserLoginData, userID string) error {
+	err := s.unlockUser(ctx, userID)
+	s.audit(ctx, actor, admin.ActionUnlockUser, admin.TargetUser, userID, nil, err)
+	return err
+}
+
+// unlockUser lifts an administrative lock as well as any temporary lockout
+// left behind by failed login attempts.
+func (s *adminService) unlockUser(ctx context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsLocked() {
+		repo, err := s.adminRepository.NewClient(false)
+		if err != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("Failed to create repository client")
+			return err
+		}
+
+		if err := repo.Users.SetLocked(ctx, userID, nil, ""); err != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"user_id":    userID,
+				"error":      err.Error(),
+			}).Error("Failed to unlock user")
+			return err
+		}
+	}
+
+	s.authService.Auth().ResetLoginAttempts(ctx, userID)
+
+	return nil
+}
+
+func (s *adminService) UpdateUserRole(ctx context.Context, actor entity.UserLoginData, userID string, role entity.Role) error {
+	metadata := map[string]interface{}{"role": string(role)}
+
+	previousRole, err := s.updateUserRole(ctx, actor, userID, role)
+	if previousRole != "" {
+		metadata["previous_role"] = string(previousRole)
+	}
+
+	s.audit(ctx, actor, admin.ActionUpdateUserRole, admin.TargetUser, userID, metadata, err)
+	return err
+}
+
+func (s *adminService) updateUserRole(ctx context.Context, actor entity.UserLoginData, userID string, role entity.Role) (entity.Role, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	if actor.ID == userID {
+		return "", admin.ErrCannotModifySelf
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	repo, err := s.adminRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return user.Role, err
+	}
+
+	if err := repo.Users.UpdateRole(ctx, userID, string(role)); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to update user role")
+		return user.Role, err
+	}
+
+	return user.Role, s.revokeSessions(ctx, userID)
+}
+
+// revokeSessions signs the user out everywhere, so a lock or a role change
+// takes effect now rather than when their tokens expire.
+func (s *adminService) revokeSessions(ctx context.Context, userID string) error {
+	if err := s.sessions.RevokeAll(ctx, userID); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to revoke sessions")
+		return err
+	}
+
+	return nil
+}
+
+func (s *adminService) GetAuditLogs(ctx context.Context, actor entity.UserLoginData, filter admin.AuditLogFilter) (*admin.AuditLogResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.adminRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+
+	limit, offset := paginate(filter.Page, filter.Limit)
+	logs, total, err := repo.AuditLogs.GetAuditLogs(ctx, filter, limit, offset)
+	s.audit(ctx, actor, admin.ActionViewAuditLogs, admin.TargetAuditLog, "", map[string]interface{}{
+		"actor_id":  filter.ActorID,
+		"target_id": filter.TargetID,
+		"action":    filter.Action,
+	}, err)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to get audit logs")
+		return nil, err
+	}
+
+	return &admin.AuditLogResponse{Logs: logs, Total: total}, nil
+}
+
+func (s *adminService) getUser(ctx context.Context, userID string) (entity.User, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.authService.GetRepository().NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return entity.User{}, err
+	}
+
+	user, err := repo.Users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return entity.User{}, admin.ErrUserNotFound
+		}
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to get user by ID")
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *adminService) ensureUserExists(ctx context.Context, userID string) error {
+	_, err := s.getUser(ctx, userID)
+	return err
+}
@@ -0,0 +1,100 @@
+package adminService
+
+import (
+	"ProjectGolang/internal/api/admin"
+	"ProjectGolang/internal/entity"
+	contextPkg "ProjectGolang/pkg/context"
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+// audit records an admin action together with its outcome. A failure to write
+// the audit entry is logged but does not undo the action itself.
+func (s *adminService) audit(ctx context.Context, actor entity.UserLoginData, action string, targetType string, targetID string, metadata map[string]interface{}, actionErr error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	status := admin.AuditStatusSuccess
+	if actionErr != nil {
+		status = admin.AuditStatusFailed
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		metadata["error"] = actionErr.Error()
+	}
+
+	var rawMetadata json.RawMessage
+	if len(metadata) > 0 {
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("Failed to encode audit metadata")
+		} else {
+			rawMetadata = encoded
+		}
+	}
+
+	ULID, err := s.utils.NewULIDFromTimestamp(time.Now())
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to generate ULID")
+		return
+	}
+
+	entry := admin.AuditLog{
+		ID:         ULID,
+		ActorID:    actor.ID,
+		ActorRole:  string(actor.Role),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Status:     status,
+		Metadata:   rawMetadata,
+		IPAddress:  contextPkg.GetClientIP(ctx),
+		RequestID:  requestID,
+		CreatedAt:  time.Now(),
+	}
+
+	repo, err := s.adminRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return
+	}
+
+	if err := repo.AuditLogs.CreateAuditLog(ctx, entry); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"action":     action,
+			"actor_id":   actor.ID,
+			"error":      err.Error(),
+		}).Error("Failed to write audit log")
+		return
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"request_id":  requestID,
+		"action":      action,
+		"actor_id":    actor.ID,
+		"target_type": targetType,
+		"target_id":   targetID,
+		"status":      status,
+	}).Info("Admin action audited")
+}
+
+func paginate(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return limit, (page - 1) * limit
+}
@@ -0,0 +1,52 @@
+package adminService
+
+import (
+	"ProjectGolang/internal/api/admin"
+	"ProjectGolang/internal/api/kyc"
+	"ProjectGolang/internal/entity"
+	"golang.org/x/net/context"
+)
+
+func (s *adminService) ListKYCVerifications(ctx context.Context, actor entity.UserLoginData, req kyc.VerificationListRequest) (*kyc.VerificationListResponse, error) {
+	res, err := s.kycService.ListVerifications(ctx, req)
+	s.audit(ctx, actor, admin.ActionListKYC, admin.TargetKYCVerification, "", map[string]interface{}{
+		"status": req.Status,
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *adminService) GetKYCVerification(ctx context.Context, actor entity.UserLoginData, userID string) (*kyc.VerificationDetail, error) {
+	res, err := s.kycService.GetVerification(ctx, userID)
+	s.audit(ctx, actor, admin.ActionViewKYC, admin.TargetKYCVerification, userID, nil, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *adminService) ApproveKYC(ctx context.Context, actor entity.UserLoginData, userID string) (*kyc.StatusResponse, error) {
+	res, err := s.kycService.Approve(ctx, actor.ID, userID)
+	s.audit(ctx, actor, admin.ActionApproveKYC, admin.TargetKYCVerification, userID, nil, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *adminService) RejectKYC(ctx context.Context, actor entity.UserLoginData, userID string, reason string) (*kyc.StatusResponse, error) {
+	res, err := s.kycService.Reject(ctx, actor.ID, userID, reason)
+	s.audit(ctx, actor, admin.ActionRejectKYC, admin.TargetKYCVerification, userID, map[string]interface{}{
+		"reason": reason,
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,61 @@
+package adminService
+
+import (
+	"ProjectGolang/internal/api/admin"
+	adminRepository "ProjectGolang/internal/api/admin/repository"
+	authService "ProjectGolang/internal/api/auth/service"
+	"ProjectGolang/internal/api/kyc"
+	kycService "ProjectGolang/internal/api/kyc/service"
+	sentrapay "ProjectGolang/internal/api/sentra_pay"
+	sentrapayService "ProjectGolang/internal/api/sentra_pay/service"
+	"ProjectGolang/internal/entity"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"ProjectGolang/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+type IAdminService interface {
+	SearchUsers(ctx context.Context, actor entity.UserLoginData, req admin.SearchUsersRequest) (*admin.SearchUsersResponse, error)
+	GetUserWalletTransactions(ctx context.Context, actor entity.UserLoginData, userID string, page, limit int) (*sentrapay.TransactionHistoryResponse, error)
+	SettleTopUp(ctx context.Context, actor entity.UserLoginData, referenceNo string, reason string) (*sentrapay.WalletTransaction, error)
+	RefundTopUp(ctx context.Context, actor entity.UserLoginData, referenceNo string, reason string) (*sentrapay.WalletTransaction, error)
+	LockUser(ctx context.Context, actor entity.UserLoginData, userID string, reason string) error
+	UnlockUser(ctx context.Context, actor entity.UserLoginData, userID string) error
+	UpdateUserRole(ctx context.Context, actor entity.UserLoginData, userID string, role entity.Role) error
+	GetAuditLogs(ctx context.Context, actor entity.UserLoginData, filter admin.AuditLogFilter) (*admin.AuditLogResponse, error)
+	ListKYCVerifications(ctx context.Context, actor entity.UserLoginData, req kyc.VerificationListRequest) (*kyc.VerificationListResponse, error)
+	GetKYCVerification(ctx context.Context, actor entity.UserLoginData, userID string) (*kyc.VerificationDetail, error)
+	ApproveKYC(ctx context.Context, actor entity.UserLoginData, userID string) (*kyc.StatusResponse, error)
+	RejectKYC(ctx context.Context, actor entity.UserLoginData, userID string, reason string) (*kyc.StatusResponse, error)
+}
+
+type adminService struct {
+	log              *logrus.Logger
+	adminRepository  adminRepository.Repository
+	authService      authService.AuthService
+	sentraPayService sentrapayService.ISentraPayService
+	kycService       kycService.IKYCService
+	sessions         jwtPkg.ISessions
+	utils            utils.IUtils
+}
+
+func NewAdminService(
+	log *logrus.Logger,
+	ar adminRepository.Repository,
+	as authService.AuthService,
+	sps sentrapayService.ISentraPayService,
+	ks kycService.IKYCService,
+	sessions jwtPkg.ISessions,
+	utils utils.IUtils,
+) IAdminService {
+	return &adminService{
+		log:              log,
+		adminRepository:  ar,
+		authService:      as,
+		sentraPayService: sps,
+		kycService:       ks,
+		sessions:         sessions,
+		utils:            utils,
+	}
+}
@@ -29,43 +29,60 @@ type UpdateProfilePhotoRequest struct {
 }
 
 type ProfilePhotoResponse struct {
-	ID              string `json:"id"`
-	ProfilePhotoURL string `json:"profile_photo_url"`
+	ID               string            `json:"id"`
+	ProfilePhotoURL  string            `json:"profile_photo_url"`
+	ProfilePhotoURLs map[string]string `json:"profile_photo_urls,omitempty"`
 }
 
 type UserResponse struct {
-	ID                        string    `json:"id"`
-	Email                     string    `json:"email,omitempty"`
-	Name                      string    `json:"name"`
-	NationalIdentityNumber    string    `json:"national_identity_number,omitempty"`
-	BirthPlace                string    `json:"birth_place,omitempty"`
-	BirthDate                 time.Time `json:"birth_date,omitempty"`
-	Gender                    string    `json:"gender,omitempty"`
-	Address                   string    `json:"address,omitempty"`
-	NeighborhoodCommunityUnit string    `json:"neighborhood_community_unit,omitempty"`
-	Village                   string    `json:"village,omitempty"`
-	District                  string    `json:"district,omitempty"`
-	Religion                  string    `json:"religion,omitempty"`
-	MaritalStatus             string    `json:"marital_status,omitempty"`
-	Profession                string    `json:"profession,omitempty"`
-	Citizenship               string    `json:"citizenship,omitempty"`
-	CardValidUntil            time.Time `json:"card_valid_until,omitempty"`
-	PhoneNumber               string    `json:"phone_number,omitempty"`
-	ProfilePhotoURL           string    `json:"profile_photo_url,omitempty"`
-	IsVerified                bool      `json:"is_verified"`
-	CreatedAt                 time.Time `json:"created_at"`
-	UpdatedAt                 time.Time `json:"updated_at"`
+	ID                        string            `json:"id"`
+	Email                     string            `json:"email,omitempty"`
+	Name                      string            `json:"name"`
+	NationalIdentityNumber    string            `json:"national_identity_number,omitempty"`
+	BirthPlace                string            `json:"birth_place,omitempty"`
+	BirthDate                 time.Time         `json:"birth_date,omitempty"`
+	Gender                    string            `json:"gender,omitempty"`
+	Address                   string            `json:"address,omitempty"`
+	NeighborhoodCommunityUnit string            `json:"neighborhood_community_unit,omitempty"`
+	Village                   string            `json:"village,omitempty"`
+	District                  string            `json:"district,omitempty"`
+	Religion                  string            `json:"religion,omitempty"`
+	MaritalStatus             string            `json:"marital_status,omitempty"`
+	Profession                string            `json:"profession,omitempty"`
+	Citizenship               string            `json:"citizenship,omitempty"`
+	CardValidUntil            time.Time         `json:"card_valid_until,omitempty"`
+	PhoneNumber               string            `json:"phone_number,omitempty"`
+	ProfilePhotoURL           string            `json:"profile_photo_url,omitempty"`
+	ProfilePhotoURLs          map[string]string `json:"profile_photo_urls,omitempty"`
+	IsVerified                bool              `json:"is_verified"`
+	CreatedAt                 time.Time         `json:"created_at"`
+	UpdatedAt                 time.Time         `json:"updated_at"`
 }
 
 type LoginUserRequest struct {
-	Email       string `json:"email" validate:"omitempty,email"`
-	PhoneNumber string `json:"phone_number" validate:"omitempty,min=10,max=13"`
-	Password    string `json:"password" validate:"required"`
+	Email        string `json:"email" validate:"omitempty,email"`
+	PhoneNumber  string `json:"phone_number" validate:"omitempty,min=10,max=13"`
+	Password     string `json:"password" validate:"required"`
+	CaptchaToken string `json:"captcha_token"`
 }
 
 type TouchIDLoginRequest struct {
-	ID        string `json:"id"`
-	PlainText string `json:"plain_text"`
+	ID           string `json:"id"`
+	PlainText    string `json:"plain_text"`
+	CaptchaToken string `json:"captcha_token"`
+}
+
+// RequestAccountUnlockRequest names the account by phone number or, for
+// accounts without one, by email; the code goes to that address.
+type RequestAccountUnlockRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required_without=Email,omitempty,min=10,max=13"`
+	Email       string `json:"email" validate:"required_without=PhoneNumber,omitempty,email"`
+}
+
+type UnlockAccountRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required_without=Email,omitempty,min=10,max=13"`
+	Email       string `json:"email" validate:"required_without=PhoneNumber,omitempty,email"`
+	Code        string `json:"code" validate:"required,min=5,max=5"`
 }
 
 type ResetPassword struct {
@@ -74,20 +91,19 @@ type ResetPassword struct {
 	Password    string `json:"password" validate:"required,min=8,max=32"`
 }
 
-type LoginUserGoogle struct {
-	Email string `json:"email"`
+type LoginUserResponse struct {
+	AccessToken      string  `json:"accessToken"`
+	ExpiresInMinutes float64 `json:"expiresInHour"`
 }
 
-type UserGoogle struct {
-	ID            string `json:"id"`
-	Email         string `json:"email"`
-	VerifiedEmail bool   `json:"verified_email"`
-	Picture       string `json:"picture"`
+type GoogleLoginResponse struct {
+	LoginUserResponse
+	IsNewUser bool `json:"is_new_user"`
+	Linked    bool `json:"linked"`
 }
 
-type LoginUserResponse struct {
-	AccessToken      string  `json:"accessToken"`
-	ExpiresInMinutes float64 `json:"expiresInHour"`
+type GoogleAuthURLResponse struct {
+	URL string `json:"url"`
 }
 
 type UpdateUserRequest struct {
@@ -6,20 +6,36 @@ import (
 )
 
 var (
-	ErrPhoneNumberAlreadyExists = response.NewError(http.StatusConflict, "phone number already exists")
-	ErrEmailAlreadyExists       = response.NewError(http.StatusConflict, "email already exists")
-	ErrInvalidEmailOrPassword   = response.NewError(http.StatusBadRequest, "email or password is wrong")
-	ErrUserNotFound             = response.NewError(http.StatusNotFound, "user not found")
-	ErrorInvalidToken           = response.NewError(http.StatusUnauthorized, "invalid token")
-	ErrUserWithEmailNotFound    = response.NewError(http.StatusNotFound, "user with email not found")
-	ErrPasswordSame             = response.NewError(http.StatusBadRequest, "password same as before")
-	ErrorTokenExpired           = response.NewError(http.StatusBadRequest, "token expired or not found")
-	ErrInvalidPhoneNumber       = response.NewError(http.StatusBadRequest, "invalid phone number")
-	ErrInvalidOTP               = response.NewError(http.StatusBadRequest, "invalid otp")
-	ErrInvalidToken             = response.NewError(http.StatusBadRequest, "invalid token")
-	ErrInvalidFileType          = response.NewError(http.StatusBadRequest, "invalid file type")
-	ErrFileTooLarge             = response.NewError(http.StatusBadRequest, "file too large")
-	ErrFailedToUploadFile       = response.NewError(http.StatusInternalServerError, "failed to upload file")
-	ErrInvalidEmail             = response.NewError(http.StatusBadRequest, "invalid email")
-	ErrEmailAlreadyInUse        = response.NewError(http.StatusConflict, "email already in use by another user")
+	ErrPhoneNumberAlreadyExists   = response.NewError(http.StatusConflict, "phone number already exists")
+	ErrEmailAlreadyExists         = response.NewError(http.StatusConflict, "email already exists")
+	ErrInvalidEmailOrPassword     = response.NewError(http.StatusBadRequest, "email or password is wrong")
+	ErrUserNotFound               = response.NewError(http.StatusNotFound, "user not found")
+	ErrorInvalidToken             = response.NewError(http.StatusUnauthorized, "invalid token")
+	ErrUserWithEmailNotFound      = response.NewError(http.StatusNotFound, "user with email not found")
+	ErrPasswordSame               = response.NewError(http.StatusBadRequest, "password same as before")
+	ErrorTokenExpired             = response.NewError(http.StatusBadRequest, "token expired or not found")
+	ErrInvalidPhoneNumber         = response.NewError(http.StatusBadRequest, "invalid phone number")
+	ErrInvalidOTP                 = response.NewError(http.StatusBadRequest, "invalid otp")
+	ErrTooManyOTPAttempts         = response.NewError(http.StatusTooManyRequests, "too many wrong codes, please request a new one later")
+	ErrInvalidToken               = response.NewError(http.StatusBadRequest, "invalid token")
+	ErrInvalidFileType            = response.NewError(http.StatusBadRequest, "invalid file type")
+	ErrFileTooLarge               = response.NewError(http.StatusBadRequest, "file too large")
+	ErrImageTooLarge              = response.NewError(http.StatusBadRequest, "image dimensions too large")
+	ErrFailedToUploadFile         = response.NewError(http.StatusInternalServerError, "failed to upload file")
+	ErrInvalidEmail               = response.NewError(http.StatusBadRequest, "invalid email")
+	ErrEmailAlreadyInUse          = response.NewError(http.StatusConflict, "email already in use by another user")
+	ErrAccountLocked              = response.NewError(http.StatusLocked, "account temporarily locked due to too many failed login attempts")
+	ErrTooManyLoginAttempts       = response.NewError(http.StatusTooManyRequests, "too many login attempts, please try again later")
+	ErrCaptchaRequired            = response.NewError(http.StatusPreconditionRequired, "captcha verification required")
+	ErrInvalidCaptcha             = response.NewError(http.StatusBadRequest, "invalid captcha")
+	ErrAccountSuspended           = response.NewError(http.StatusLocked, "account has been locked by an administrator")
+	ErrInvalidOAuthState          = response.NewError(http.StatusBadRequest, "invalid or expired oauth state")
+	ErrInvalidGoogleToken         = response.NewError(http.StatusUnauthorized, "invalid google id token")
+	ErrGoogleEmailNotVerified     = response.NewError(http.StatusForbidden, "google email is not verified")
+	ErrGoogleAccountAlreadyLinked = response.NewError(http.StatusConflict, "google account already linked to another user")
+	ErrGoogleNotLinked            = response.NewError(http.StatusBadRequest, "google account is not linked")
+	ErrOtherGoogleAccountLinked   = response.NewError(http.StatusConflict, "account is already linked to a different google account, unlink it first")
+	ErrLivenessRequired           = response.NewError(http.StatusPreconditionRequired, "a liveness check is required, capture the face photo through the face scanner")
+	ErrInvalidLivenessProof       = response.NewError(http.StatusForbidden, "liveness proof is invalid, expired, already used or does not match the photo")
+	ErrCannotUnlinkGoogle         = response.NewError(http.StatusBadRequest, "cannot unlink google from an account without a phone number and password")
 )
@@ -314,3 +314,59 @@ func (h *AuthHandler) HandleVerifyPhoneOTP(ctx *fiber.Ctx) error {
 		return errHandler.HandleSuccess(ctx, fiber.StatusOK, nil)
 	}
 }
+
+func (h *AuthHandler) HandleRequestAccountUnlock(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	var req auth.RequestAccountUnlockRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	if err := h.authService.Auth().RequestAccountUnlock(c, req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "request_account_unlock")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, nil)
+	}
+}
+
+func (h *AuthHandler) HandleUnlockAccount(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	var req auth.UnlockAccountRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	if err := h.authService.Auth().UnlockAccount(c, req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "unlock_account")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, nil)
+	}
+}
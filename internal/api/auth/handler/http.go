@@ -42,10 +42,14 @@ func New(
 
 func (h *AuthHandler) Start(srv fiber.Router) {
 	auth := srv.Group("/auth")
-	auth.Post("/login", h.HandleLogin)
-	auth.Post("/login-touch-id", h.LoginTouchID)
+	auth.Post("/login", h.middleware.NewRateLimiter, h.HandleLogin)
+	auth.Post("/login-touch-id", h.middleware.NewRateLimiter, h.LoginTouchID)
+	auth.Post("/unlock/request-otp", h.middleware.NewRateLimiter, h.HandleRequestAccountUnlock)
+	auth.Post("/unlock", h.middleware.NewRateLimiter, h.HandleUnlockAccount)
 	auth.Get("/login-gl", h.HandleGoogleLogin)
 	auth.Get("/callback-gl", h.CallBackFromGoogle)
+	auth.Post("/google/link", h.middleware.NewTokenMiddleware, h.HandleLinkGoogle)
+	auth.Delete("/google/link", h.middleware.NewTokenMiddleware, h.HandleUnlinkGoogle)
 	auth.Patch("/enable-touch-id", h.middleware.NewTokenMiddleware, h.EnableTouchID)
 
 	users := srv.Group("/users")
@@ -55,7 +59,6 @@ func (h *AuthHandler) Start(srv fiber.Router) {
 	users.Get("/profile-photo", h.middleware.NewTokenMiddleware, h.HandleGetProfilePhoto)
 	users.Get("/:id", h.middleware.NewTokenMiddleware, h.HandleGetUserById)
 	users.Patch("/", h.middleware.NewTokenMiddleware, h.HandleUpdateUser)
-	users.Delete("/:id", h.HandleDeleteUser)
 
 	password := srv.Group("/password")
 	password.Patch("/reset-password", h.HandleResetPassword)
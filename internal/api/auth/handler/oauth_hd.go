@@ -4,18 +4,36 @@ import (
 	"ProjectGolang/internal/api/auth"
 	contextPkg "ProjectGolang/pkg/context"
 	"ProjectGolang/pkg/handlerUtil"
+	jwtPkg "ProjectGolang/pkg/jwt"
 	"ProjectGolang/pkg/log"
-	"encoding/json"
 	"errors"
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/net/context"
-	"io/ioutil"
-	"net/http"
-	"net/url"
-	"os"
 	"time"
 )
 
+// googleBindingCookie carries the binding of a Google authorization, tying
+// the callback to the browser that started it.
+const (
+	googleBindingCookie = "google_oauth_binding"
+	// googleBindingTTL matches how long the service keeps the authorization.
+	googleBindingTTL = 10 * time.Minute
+)
+
+func setGoogleBinding(ctx *fiber.Ctx, binding string, expires time.Time) {
+	ctx.Cookie(&fiber.Cookie{
+		Name:     googleBindingCookie,
+		Value:    binding,
+		Path:     "/",
+		Expires:  expires,
+		Secure:   true,
+		HTTPOnly: true,
+		// Lax still sends the cookie on the top-level redirect back from
+		// Google.
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
 func (h *AuthHandler) HandleGoogleLogin(ctx *fiber.Ctx) error {
 	requestID := h.middleware.GetRequestID(ctx)
 	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
@@ -23,10 +41,11 @@ func (h *AuthHandler) HandleGoogleLogin(ctx *fiber.Ctx) error {
 
 	errHandler := handlerUtil.New(h.log)
 
-	url, err := h.authService.Auth().LoginGoogle()
+	url, binding, err := h.authService.Auth().LoginGoogle(c)
 	if err != nil {
 		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "google_login")
 	}
+	setGoogleBinding(ctx, binding, time.Now().Add(googleBindingTTL))
 
 	select {
 	case <-c.Done():
@@ -43,21 +62,14 @@ func (h *AuthHandler) CallBackFromGoogle(ctx *fiber.Ctx) error {
 
 	errHandler := handlerUtil.New(h.log)
 
-	state := ctx.FormValue("state")
-	if state != os.Getenv("GOOGLE_STATE") {
-		h.log.WithFields(log.Fields{
-			"request_id": requestID,
-			"state":      state,
-			"path":       ctx.Path(),
-		}).Warn("Invalid state parameter")
-		return ctx.Redirect("/", fiber.StatusTemporaryRedirect)
-	}
-
 	code := ctx.FormValue("code")
 
 	if code == "" {
 		reason := ctx.FormValue("error_reason")
-		if reason == "user_denied" {
+		if reason == "" {
+			reason = ctx.FormValue("error")
+		}
+		if reason == "user_denied" || reason == "access_denied" {
 			h.log.WithFields(log.Fields{
 				"request_id": requestID,
 				"reason":     reason,
@@ -70,54 +82,68 @@ func (h *AuthHandler) CallBackFromGoogle(ctx *fiber.Ctx) error {
 			errors.New("No authorization code provided"), ctx.Path())
 	}
 
-	gConfig := h.googleProvider.GetConfig()
-	token, err := gConfig.Exchange(c, code)
+	binding := ctx.Cookies(googleBindingCookie)
+	setGoogleBinding(ctx, "", time.Unix(0, 0))
+
+	res, err := h.authService.Auth().GoogleCallback(c, ctx.FormValue("state"), code, binding)
 	if err != nil {
-		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "exchange_token")
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "google_callback")
 	}
 
-	resp, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + url.QueryEscape(token.AccessToken))
-	if err != nil {
-		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_user_info")
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
 	}
-	defer resp.Body.Close()
+}
+
+func (h *AuthHandler) HandleLinkGoogle(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
 
-	response, err := ioutil.ReadAll(resp.Body)
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
 	if err != nil {
-		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "read_response")
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
 	}
 
-	var userInfo auth.UserGoogle
-	if err := json.Unmarshal(response, &userInfo); err != nil {
-		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "unmarshal_user_info")
+	url, binding, err := h.authService.Auth().LinkGoogle(c, userData.ID)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "link_google")
 	}
+	setGoogleBinding(ctx, binding, time.Now().Add(googleBindingTTL))
 
-	_, err = h.authService.User().GetByEmail(c, userInfo.Email)
-	if errors.Is(err, auth.ErrUserWithEmailNotFound) {
-		if err := h.authService.User().RegisterUser(c, auth.CreateUserRequest{
-			Name:        userInfo.Email,
-			PhoneNumber: "",
-			Password:    "",
-		}); err != nil {
-			return errHandler.Handle(ctx, requestID, err, ctx.Path(), "register_google_user")
-		}
-	} else if err != nil && !errors.Is(err, auth.ErrUserWithEmailNotFound) {
-		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "check_user_email")
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, auth.GoogleAuthURLResponse{URL: url.String()})
 	}
+}
 
-	jwtToken, err := h.authService.Auth().Login(c, auth.LoginUserRequest{
-		Email: userInfo.Email,
-	})
+func (h *AuthHandler) HandleUnlinkGoogle(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
 	if err != nil {
-		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "login_user")
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	if err := h.authService.Auth().UnlinkGoogle(c, userData.ID); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "unlink_google")
 	}
 
 	select {
 	case <-c.Done():
 		return errHandler.HandleRequestTimeout(ctx)
 	default:
-		return errHandler.HandleSuccess(ctx, fiber.StatusOK, fiber.Map{
-			"token":   jwtToken.AccessToken,
-			"expires": jwtToken.ExpiresInMinutes})
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, nil)
 	}
 }
@@ -2,8 +2,10 @@ package authHandler
 
 import (
 	"ProjectGolang/internal/api/auth"
+	"ProjectGolang/internal/entity"
 	contextPkg "ProjectGolang/pkg/context"
 	"ProjectGolang/pkg/handlerUtil"
+	"ProjectGolang/pkg/imaging"
 	jwtPkg "ProjectGolang/pkg/jwt"
 	"ProjectGolang/pkg/log"
 	"github.com/gofiber/fiber/v2"
@@ -77,27 +79,6 @@ func (h *AuthHandler) HandleUpdateUser(ctx *fiber.Ctx) error {
 	}
 }
 
-func (h *AuthHandler) HandleDeleteUser(ctx *fiber.Ctx) error {
-	requestID := h.middleware.GetRequestID(ctx)
-	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
-	defer cancel()
-
-	errHandler := handlerUtil.New(h.log)
-
-	id := ctx.Params("id")
-
-	if err := h.authService.User().DeleteUser(c, id); err != nil {
-		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "delete_user")
-	}
-
-	select {
-	case <-c.Done():
-		return errHandler.HandleRequestTimeout(ctx)
-	default:
-		return errHandler.HandleSuccess(ctx, fiber.StatusOK, nil)
-	}
-}
-
 func (h *AuthHandler) HandleUpdateProfilePhoto(ctx *fiber.Ctx) error {
 	requestID := h.middleware.GetRequestID(ctx)
 	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 30*time.Second)
@@ -145,19 +126,21 @@ func (h *AuthHandler) HandleGetUserById(ctx *fiber.Ctx) error {
 		"path":       ctx.Path(),
 	}).Debug("Processing get user by id request")
 
-	var userId string
-	paramId := ctx.Params("id")
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
 
-	if paramId != "" && paramId != "me" {
-		userId = paramId
-	} else {
-		userData, err := jwtPkg.GetUserLoginData(ctx)
-		if err != nil {
-			return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
-		}
+	userId := ctx.Params("id")
+	if userId == "" || userId == "me" {
 		userId = userData.ID
 	}
 
+	// Other users' profiles are reported as missing so IDs cannot be probed.
+	if !userData.CanAccess(userId, entity.RoleSupport, entity.RoleAdmin) {
+		return errHandler.Handle(ctx, requestID, auth.ErrUserNotFound, ctx.Path(), "get_user_by_id")
+	}
+
 	repo, err := h.authService.GetRepository().NewClient(false)
 	if err != nil {
 		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "create_repository")
@@ -168,18 +151,11 @@ func (h *AuthHandler) HandleGetUserById(ctx *fiber.Ctx) error {
 		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_user_by_id")
 	}
 
-	profilePhotoURL := user.ProfilePhotoURL
-	if profilePhotoURL != "" {
-		presignedURL, err := h.s3Client.PresignUrl(profilePhotoURL)
-		if err != nil {
-			h.log.WithFields(log.Fields{
-				"request_id": requestID,
-				"error":      err.Error(),
-				"path":       ctx.Path(),
-			}).Warn("Failed to presign URL for profile photo")
-		} else {
-			profilePhotoURL = presignedURL
-		}
+	// Photos are only linked for their owner, not for staff viewing the
+	// profile.
+	var photoURLs map[string]string
+	if user.ID == userData.ID {
+		photoURLs = h.authService.User().PhotoURLs(c, user.ID, user.ProfilePhotoURL)
 	}
 
 	response := auth.UserResponse{
@@ -200,7 +176,8 @@ func (h *AuthHandler) HandleGetUserById(ctx *fiber.Ctx) error {
 		Citizenship:               user.Citizenship,
 		CardValidUntil:            user.CardValidUntil,
 		PhoneNumber:               user.PhoneNumber,
-		ProfilePhotoURL:           profilePhotoURL,
+		ProfilePhotoURL:           photoURLs[imaging.Full.Name],
+		ProfilePhotoURLs:          photoURLs,
 		IsVerified:                user.IsVerified,
 		CreatedAt:                 user.CreatedAt,
 		UpdatedAt:                 user.UpdatedAt,
@@ -241,23 +218,12 @@ func (h *AuthHandler) HandleGetProfilePhoto(ctx *fiber.Ctx) error {
 		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_user_by_id")
 	}
 
-	profilePhotoURL := user.ProfilePhotoURL
-	if profilePhotoURL != "" {
-		presignedURL, err := h.s3Client.PresignUrl(profilePhotoURL)
-		if err != nil {
-			h.log.WithFields(log.Fields{
-				"request_id": requestID,
-				"error":      err.Error(),
-				"path":       ctx.Path(),
-			}).Warn("Failed to presign URL for profile photo")
-		} else {
-			profilePhotoURL = presignedURL
-		}
-	}
+	photoURLs := h.authService.User().PhotoURLs(c, user.ID, user.ProfilePhotoURL)
 
 	result := auth.ProfilePhotoResponse{
-		ID:              user.ID,
-		ProfilePhotoURL: profilePhotoURL,
+		ID:               user.ID,
+		ProfilePhotoURL:  photoURLs[imaging.Full.Name],
+		ProfilePhotoURLs: photoURLs,
 	}
 
 	select {
@@ -290,7 +256,7 @@ func (h *AuthHandler) HandleUpdateFacePhoto(ctx *fiber.Ctx) error {
 		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_form_file")
 	}
 
-	err = h.authService.User().UpdateFacePhoto(c, userData.ID, file)
+	err = h.authService.User().UpdateFacePhoto(c, userData.ID, file, ctx.FormValue("liveness_token"))
 	if err != nil {
 		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "update_face_photo")
 	}
@@ -10,17 +10,18 @@ SELECT id, email, name, national_identity_number, birth_place, birth_date, gende
        address, neighborhood_community_unit, village, district, religion, 
        marital_status, profession, citizenship, card_valid_until, password, 
        phone_number, personal_identification_number, enable_touch_id, hash_touch_id, 
-       profile_photo_url, is_verified, created_at, updated_at, face_photo_url
+       profile_photo_url, is_verified, created_at, updated_at, face_photo_url, google_subject,
+       role, locked_at, locked_reason
 FROM Users
     WHERE id = :id`
 
 	queryGetByPhoneNumber = `
-SELECT id, email, name, password, phone_number, is_verified
+SELECT id, email, name, password, phone_number, is_verified, role, locked_at
 FROM Users
     WHERE phone_number = :phone_number`
 
 	queryGetByEmail = `
-SELECT id, email, name, national_identity_number, birth_place, birth_date, gender, address, neighborhood_community_unit, village, district, religion, marital_status, profession, citizenship, card_valid_until, password, phone_number, personal_identification_number, enable_touch_id, hash_touch_id, is_verified, face_photo_url, profile_photo_url, created_at, updated_at
+SELECT id, email, name, national_identity_number, birth_place, birth_date, gender, address, neighborhood_community_unit, village, district, religion, marital_status, profession, citizenship, card_valid_until, password, phone_number, personal_identification_number, enable_touch_id, hash_touch_id, is_verified, face_photo_url, profile_photo_url, google_subject, role, locked_at, locked_reason, created_at, updated_at
 FROM Users
     WHERE email = :email`
 
@@ -44,10 +45,6 @@ SET name = :name,
     updated_at = :updated_at
 WHERE id = :id`
 
-	queryDeleteUser = `
-DELETE FROM Users
-WHERE id = :id`
-
 	queryUpdateUserPassword = `
 		UPDATE Users
 SET password = :password
@@ -79,4 +76,19 @@ WHERE phone_number = :phone_number`
 		SET face_photo_url = :face_photo_url,
 			updated_at = :updated_at
 		WHERE id = :id`
+
+	queryCreateGoogleUser = `
+INSERT INTO Users (id, email, name, google_subject, created_at)
+VALUES (:id, :email, :name, :google_subject, :created_at)`
+
+	queryGetByGoogleSubject = `
+SELECT id, email, name, password, phone_number, is_verified, google_subject, role, locked_at
+FROM Users
+    WHERE google_subject = :google_subject`
+
+	queryUpdateGoogleSubject = `
+		UPDATE Users
+		SET google_subject = :google_subject,
+			updated_at = :updated_at
+		WHERE id = :id`
 )
@@ -2,6 +2,7 @@ package authRepository
 
 import (
 	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/events"
 	"github.com/jmoiron/sqlx"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
@@ -46,6 +47,7 @@ func (r *repository) NewClient(tx bool) (Client, error) {
 
 	return Client{
 		Users:    &userRepository{q: db, log: r.log},
+		Outbox:   events.NewOutbox(db, r.log),
 		Commit:   commitFunc,
 		Rollback: rollbackFunc,
 	}, nil
@@ -60,12 +62,16 @@ type Client struct {
 		UpdateUser(ctx context.Context, user entity.User) error
 		UpdateUserPIN(ctx context.Context, phoneNum string, pin string) error
 		UpdateUserPassword(ctx context.Context, phoneNum string, password string) error
-		DeleteUser(ctx context.Context, id string) error
 		EnableTouchID(ctx context.Context, id string, hash string) error
 		UpdateProfilePhoto(ctx context.Context, id string, photoURL string) error
 		UpdateFacePhoto(ctx context.Context, id string, facePhotoURL string) error
+		CreateGoogleUser(ctx context.Context, user entity.User) error
+		GetByGoogleSubject(ctx context.Context, subject string) (entity.User, error)
+		UpdateGoogleSubject(ctx context.Context, id string, subject string) error
 	}
 
+	Outbox events.Outbox
+
 	Commit   func() error
 	Rollback func() error
 }
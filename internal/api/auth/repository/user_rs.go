@@ -37,6 +37,10 @@ type UserDB struct {
 	HashTouchID                  sql.NullString `db:"hash_touch_id"`
 	ProfilePhotoURL              sql.NullString `db:"profile_photo_url"`
 	FacePhotoURL                 sql.NullString `db:"face_photo_url"`
+	GoogleSubject                sql.NullString `db:"google_subject"`
+	Role                         sql.NullString `db:"role"`
+	LockedAt                     sql.NullTime   `db:"locked_at"`
+	LockedReason                 sql.NullString `db:"locked_reason"`
 	IsVerified                   bool           `db:"is_verified"`
 	CreatedAt                    sql.NullTime   `db:"created_at"`
 	UpdatedAt                    sql.NullTime   `db:"updated_at"`
@@ -222,47 +226,9 @@ func (r *userRepository) UpdateUser(c context.Context, user entity.User) error {
 	return nil
 }
 
-func (r *userRepository) DeleteUser(ctx context.Context, id string) error {
-	requestID := contextPkg.GetRequestID(ctx)
-	argsKV := map[string]interface{}{
-		"id": id,
-	}
-
-	query, args, err := sqlx.Named(queryDeleteUser, argsKV)
-	if err != nil {
-		r.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-			"error":      err.Error(),
-		}).Error("DeleteUser named query preparation err")
-
-		return err
-	}
-
-	query = r.q.Rebind(query)
-
-	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			r.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"error":      err.Error(),
-			}).Warn("DeleteUser no rows found")
-
-			return auth.ErrUserNotFound
-		}
-		r.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-			"error":      err.Error(),
-		}).Error("DeleteUser execution err")
-
-		return err
-	}
-
-	return nil
-}
-
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (entity.User, error) {
 	requestID := contextPkg.GetRequestID(ctx)
-	var user entity.User
+	var user UserDB
 
 	argsKV := map[string]interface{}{
 		"email": email,
@@ -298,7 +264,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (entity.U
 		return entity.User{}, err
 	}
 
-	return user, nil
+	return r.makeUser(user), nil
 }
 
 func (r *userRepository) UpdateUserPassword(ctx context.Context, phoneNum string, password string) error {
@@ -514,6 +480,16 @@ func (r *userRepository) makeUser(user UserDB) entity.User {
 		cardValidUntil = user.CardValidUntil.Time
 	}
 
+	var lockedAt time.Time
+	if user.LockedAt.Valid {
+		lockedAt = user.LockedAt.Time
+	}
+
+	role := entity.RoleUser
+	if user.Role.Valid && user.Role.String != "" {
+		role = entity.Role(user.Role.String)
+	}
+
 	userRes := entity.User{
 		ID:                           user.ID.String,
 		Email:                        user.Email.String,
@@ -537,6 +513,11 @@ func (r *userRepository) makeUser(user UserDB) entity.User {
 		EnableTouchID:                user.EnableTouchID,
 		HashTouchID:                  user.HashTouchID.String,
 		ProfilePhotoURL:              user.ProfilePhotoURL.String,
+		FacePhotoURL:                 user.FacePhotoURL.String,
+		GoogleSubject:                user.GoogleSubject.String,
+		Role:                         role,
+		LockedAt:                     lockedAt,
+		LockedReason:                 user.LockedReason.String,
 		IsVerified:                   user.IsVerified,
 		CreatedAt:                    createdAt,
 		UpdatedAt:                    updatedAt,
@@ -544,3 +525,138 @@ func (r *userRepository) makeUser(user UserDB) entity.User {
 
 	return userRes
 }
+
+func (r *userRepository) CreateGoogleUser(ctx context.Context, user entity.User) error {
+	requestID := contextPkg.GetRequestID(ctx)
+	argsKV := map[string]interface{}{
+		"id":             user.ID,
+		"email":          user.Email,
+		"name":           user.Name,
+		"google_subject": user.GoogleSubject,
+		"created_at":     time.Now(),
+	}
+
+	query, args, err := sqlx.Named(queryCreateGoogleUser, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CreateGoogleUser named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			switch pqErr.Constraint {
+			case "users_email_key":
+				r.log.WithFields(logrus.Fields{
+					"request_id": requestID,
+					"error":      err.Error(),
+				}).Warn("Email already exists")
+				return auth.ErrEmailAlreadyExists
+			case "users_google_subject_key":
+				r.log.WithFields(logrus.Fields{
+					"request_id": requestID,
+					"error":      err.Error(),
+				}).Warn("Google account already linked")
+				return auth.ErrGoogleAccountAlreadyLinked
+			}
+		}
+
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CreateGoogleUser execution err")
+		return err
+	}
+
+	return nil
+}
+
+func (r *userRepository) GetByGoogleSubject(ctx context.Context, subject string) (entity.User, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var user UserDB
+
+	argsKV := map[string]interface{}{
+		"google_subject": subject,
+	}
+
+	query, args, err := sqlx.Named(queryGetByGoogleSubject, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetByGoogleSubject named query preparation err")
+		return entity.User{}, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).StructScan(&user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Warn("GetByGoogleSubject no rows found")
+			return entity.User{}, auth.ErrUserNotFound
+		}
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetByGoogleSubject execution err")
+		return entity.User{}, err
+	}
+
+	return r.makeUser(user), nil
+}
+
+// UpdateGoogleSubject links the Google account to the user, or unlinks it when
+// subject is empty.
+func (r *userRepository) UpdateGoogleSubject(ctx context.Context, id string, subject string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+	argsKV := map[string]interface{}{
+		"id":             id,
+		"google_subject": sql.NullString{String: subject, Valid: subject != ""},
+		"updated_at":     time.Now(),
+	}
+
+	query, args, err := sqlx.Named(queryUpdateGoogleSubject, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("UpdateGoogleSubject named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	result, err := r.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Warn("Google account already linked")
+			return auth.ErrGoogleAccountAlreadyLinked
+		}
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("UpdateGoogleSubject execution err")
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).Warn("UpdateGoogleSubject no rows found")
+		return auth.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -2,17 +2,15 @@ package authService
 
 import (
 	"ProjectGolang/internal/api/auth"
+	"ProjectGolang/internal/api/notification"
 	"ProjectGolang/internal/entity"
 	contextPkg "ProjectGolang/pkg/context"
-	jwtPkg "ProjectGolang/pkg/jwt"
+	"ProjectGolang/pkg/otp"
 	"context"
 	"errors"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"math/rand"
-	"net/url"
-	"os"
-	"strings"
 	"time"
 )
 
@@ -28,11 +26,13 @@ func (s *authDomainImpl) Login(c context.Context, req auth.LoginUserRequest) (au
 	}
 
 	var user entity.User
+	var identifier string
 	switch {
 	case req.Email != "":
+		identifier = req.Email
 		user, err = repo.Users.GetByEmail(c, req.Email)
 		if err != nil {
-			if errors.Is(err, auth.ErrUserNotFound) {
+			if errors.Is(err, auth.ErrUserNotFound) || errors.Is(err, auth.ErrUserWithEmailNotFound) {
 				s.log.WithFields(logrus.Fields{
 					"request_id": requestID,
 					"error":      err.Error(),
@@ -49,6 +49,7 @@ func (s *authDomainImpl) Login(c context.Context, req auth.LoginUserRequest) (au
 			}
 		}
 	case req.PhoneNumber != "":
+		identifier = req.PhoneNumber
 		user, err = repo.Users.GetByPhoneNumber(c, req.PhoneNumber)
 		if err != nil {
 			if errors.Is(err, auth.ErrUserNotFound) {
@@ -74,91 +75,33 @@ func (s *authDomainImpl) Login(c context.Context, req auth.LoginUserRequest) (au
 		return auth.LoginUserResponse{}, auth.ErrInvalidEmailOrPassword
 	}
 
+	account := loginAccountKey(user, identifier)
+	if err := s.loginGuard.check(c, account, req.CaptchaToken); err != nil {
+		return auth.LoginUserResponse{}, err
+	}
+
 	if err := s.bcryptUtils.ComparePassword(user.Password, req.Password); err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"error":      err.Error(),
 		}).Warn("Password comparison failed")
+		s.loginGuard.registerFailure(c, account)
 		return auth.LoginUserResponse{}, auth.ErrInvalidEmailOrPassword
 	}
 
-	userData := MakeUserData(user)
+	s.loginGuard.reset(c, account)
 
-	token, expired, err := jwtPkg.Sign(userData, time.Hour*1)
-	if err != nil {
+	if user.IsLocked() {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
-			"error":      err.Error(),
-		}).Error("Failed to sign token")
-		return auth.LoginUserResponse{}, err
-	}
-
-	s.log.WithFields(logrus.Fields{
-		"request_id": requestID,
-	}).Info("Token created")
-
-	res := auth.LoginUserResponse{
-		AccessToken:      token,
-		ExpiresInMinutes: time.Until(time.Unix(expired, 0)).Minutes(),
-	}
-
-	return res, nil
-}
-
-func (s *authDomainImpl) LoginGoogle() (*url.URL, error) {
-	gConfig := s.googleProvider.GetConfig()
-	URL, err := url.Parse(gConfig.Endpoint.AuthURL)
-	if err != nil {
-		fmt.Printf("Error parsing URL: %v", err)
-		return nil, err
-	}
-
-	parameters := url.Values{}
-	parameters.Add("client_id", os.Getenv("GOOGLE_CLIENT_ID"))
-	parameters.Add("scope", strings.Join(gConfig.Scopes, " "))
-	parameters.Add("redirect_uri", gConfig.RedirectURL)
-	parameters.Add("response_type", "code")
-	parameters.Add("state", os.Getenv("GOOGLE_STATE"))
-	URL.RawQuery = parameters.Encode()
-
-	return URL, nil
-}
-
-func (s *authDomainImpl) UserLoginGoogle(c context.Context, req auth.LoginUserGoogle) (auth.LoginUserResponse, error) {
-	requestID := contextPkg.GetRequestID(c)
-	repo, err := s.repo.NewClient(false)
-	if err != nil {
-
-		s.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-			"error":      err.Error(),
-		}).Error("Failed to create repository client")
-
-		return auth.LoginUserResponse{}, err
-	}
-
-	user, err := repo.Users.GetByEmail(c, req.Email)
-	if err != nil {
-		if errors.Is(err, auth.ErrUserNotFound) {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"error":      err.Error(),
-			}).Warn("Failed to get user by email")
-
-			err = auth.ErrInvalidEmailOrPassword
-		} else {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"error":      err.Error(),
-			}).Error("Failed to get user by email")
-
-			return auth.LoginUserResponse{}, err
-		}
+			"user_id":    user.ID,
+		}).Warn("Login attempt on administratively locked account")
+		return auth.LoginUserResponse{}, auth.ErrAccountSuspended
 	}
 
 	userData := MakeUserData(user)
 
-	token, expired, err := jwtPkg.Sign(userData, time.Hour*1)
+	token, expired, err := s.jwt.Sign(userData, time.Hour*1)
 	if err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
@@ -191,11 +134,17 @@ func (s *authDomainImpl) PhoneNumberVerification(c context.Context, phoneNumber
 		return err
 	}
 
-	if err := s.whatsappSender.SendMessage(c, phoneNumber, verificationCode); err != nil {
+	err := s.notifier.Notify(c, notification.Message{
+		Template: notification.TemplatePhoneOTP,
+		Phone:    phoneNumber,
+		CodeKey:  phoneNumber,
+		Data:     map[string]interface{}{"minutes": 1},
+	})
+	if err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"error":      err.Error(),
-		}).Error("Failed to send WhatsApp message")
+		}).Error("Failed to send phone OTP")
 		return err
 	}
 
@@ -274,8 +223,13 @@ func (s *authDomainImpl) SendEmailOTP(c context.Context, email string) error {
 		return err
 	}
 
-	// Send the OTP via email
-	if err := s.smtpMailer.CreateSmtp(email, verificationCode); err != nil {
+	err := s.notifier.Notify(c, notification.Message{
+		Template: notification.TemplateEmailOTP,
+		Email:    email,
+		CodeKey:  email,
+		Data:     map[string]interface{}{"minutes": 5},
+	})
+	if err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"error":      err.Error(),
@@ -456,3 +410,131 @@ func (s *authDomainImpl) VerifyPhoneOTP(c context.Context, userID string, phoneN
 
 	return nil
 }
+
+func (s *authDomainImpl) RequestAccountUnlock(c context.Context, req auth.RequestAccountUnlockRequest) error {
+	requestID := contextPkg.GetRequestID(c)
+
+	user, err := s.unlockUser(c, req.PhoneNumber, req.Email)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Warn("Unlock requested for unknown account")
+			return nil
+		}
+		return err
+	}
+
+	verificationCode := fmt.Sprintf("%05d", 10000+rand.Intn(90000))
+	if err := s.redisServer.SetOTP(c, unlockOTPKey(user.ID), verificationCode, 5*time.Minute); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to set unlock OTP in Redis")
+		return err
+	}
+
+	err = s.notifier.Notify(c, notification.Message{
+		Template: notification.TemplateAccountUnlockOTP,
+		UserID:   user.ID,
+		Phone:    req.PhoneNumber,
+		Email:    req.Email,
+		CodeKey:  unlockOTPKey(user.ID),
+		Data:     map[string]interface{}{"minutes": 5},
+	})
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to send account unlock OTP")
+		return err
+	}
+
+	return nil
+}
+
+func (s *authDomainImpl) UnlockAccount(c context.Context, req auth.UnlockAccountRequest) error {
+	requestID := contextPkg.GetRequestID(c)
+
+	user, err := s.unlockUser(c, req.PhoneNumber, req.Email)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return auth.ErrorTokenExpired
+		}
+		return err
+	}
+
+	if err := otp.Verify(c, s.redisServer, unlockOTPKey(user.ID), req.Code); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    user.ID,
+			"error":      err.Error(),
+		}).Warn("Invalid unlock OTP")
+		return otpError(err)
+	}
+
+	s.loginGuard.reset(c, loginAccountKey(user, ""))
+
+	s.log.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"user_id":    user.ID,
+	}).Info("Account unlocked")
+
+	return nil
+}
+
+// unlockUser finds the account to unlock by phone number, or by email when no
+// phone number is given.
+func (s *authDomainImpl) unlockUser(c context.Context, phoneNumber string, email string) (entity.User, error) {
+	requestID := contextPkg.GetRequestID(c)
+
+	repo, err := s.repo.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return entity.User{}, err
+	}
+
+	var user entity.User
+	if phoneNumber != "" {
+		user, err = repo.Users.GetByPhoneNumber(c, phoneNumber)
+	} else {
+		user, err = repo.Users.GetByEmail(c, email)
+		if errors.Is(err, auth.ErrUserWithEmailNotFound) {
+			err = auth.ErrUserNotFound
+		}
+	}
+	if err != nil && !errors.Is(err, auth.ErrUserNotFound) {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to get user to unlock")
+	}
+
+	return user, err
+}
+
+func unlockOTPKey(userID string) string {
+	return "unlock:" + userID
+}
+
+// otpError turns a failed otp.Verify into the error for the client.
+func otpError(err error) error {
+	switch {
+	case errors.Is(err, otp.ErrExpired):
+		return auth.ErrorTokenExpired
+	case errors.Is(err, otp.ErrInvalid):
+		return auth.ErrInvalidOTP
+	case errors.Is(err, otp.ErrTooManyAttempts):
+		return auth.ErrTooManyOTPAttempts
+	default:
+		return err
+	}
+}
+
+func (s *authDomainImpl) ResetLoginAttempts(c context.Context, userID string) {
+	s.loginGuard.reset(c, loginAccountKey(entity.User{ID: userID}, ""))
+}
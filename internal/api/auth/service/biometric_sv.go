@@ -3,7 +3,6 @@ package authService
 import (
 	"ProjectGolang/internal/api/auth"
 	contextPkg "ProjectGolang/pkg/context"
-	jwtPkg "ProjectGolang/pkg/jwt"
 	"errors"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
@@ -29,6 +28,12 @@ func (s *biometricDomainImpl) LoginTouchID(ctx context.Context, req auth.TouchID
 				"error":      err.Error(),
 			}).Warn("User not found")
 
+			account := loginAccountKey(user, "touch-id:"+req.ID)
+			if err := s.loginGuard.check(ctx, account, req.CaptchaToken); err != nil {
+				return auth.LoginUserResponse{}, err
+			}
+			s.loginGuard.registerFailure(ctx, account)
+
 			return auth.LoginUserResponse{}, auth.ErrInvalidEmailOrPassword
 		}
 		logrus.WithFields(logrus.Fields{
@@ -38,17 +43,33 @@ func (s *biometricDomainImpl) LoginTouchID(ctx context.Context, req auth.TouchID
 		return auth.LoginUserResponse{}, err
 	}
 
+	account := loginAccountKey(user, req.ID)
+	if err := s.loginGuard.check(ctx, account, req.CaptchaToken); err != nil {
+		return auth.LoginUserResponse{}, err
+	}
+
 	if err := s.bcryptUtils.ComparePassword(user.HashTouchID, req.PlainText); err != nil {
 		logrus.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"error":      err.Error(),
 		}).Error("Failed to compare password")
+		s.loginGuard.registerFailure(ctx, account)
 		return auth.LoginUserResponse{}, auth.ErrInvalidEmailOrPassword
 	}
 
+	s.loginGuard.reset(ctx, account)
+
+	if user.IsLocked() {
+		logrus.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    user.ID,
+		}).Warn("Login attempt on administratively locked account")
+		return auth.LoginUserResponse{}, auth.ErrAccountSuspended
+	}
+
 	userData := MakeUserData(user)
 
-	token, expired, err := jwtPkg.Sign(userData, time.Hour*1)
+	token, expired, err := s.jwt.Sign(userData, time.Hour*1)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"request_id": requestID,
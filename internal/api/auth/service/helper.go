@@ -10,14 +10,21 @@ func GetUserDifferenceData(DbUser entity.User, NewUser auth.UpdateUserRequest) (
 	// Start with a copy of all existing user data
 	result := DbUser
 
-	birthDate, err := time.Parse("2006-01-02", NewUser.BirthDate)
-	if err != nil {
-		return entity.User{}, err
+	var birthDate, cardValidUntil time.Time
+	var err error
+
+	if NewUser.BirthDate != "" {
+		birthDate, err = time.Parse("2006-01-02", NewUser.BirthDate)
+		if err != nil {
+			return entity.User{}, err
+		}
 	}
 
-	cardValidUntil, err := time.Parse("2006-01-02", NewUser.CardValidUntil)
-	if err != nil {
-		return entity.User{}, err
+	if NewUser.CardValidUntil != "" {
+		cardValidUntil, err = time.Parse("2006-01-02", NewUser.CardValidUntil)
+		if err != nil {
+			return entity.User{}, err
+		}
 	}
 
 	// Then only override the fields that changed
@@ -86,9 +93,15 @@ func GetUserDifferenceData(DbUser entity.User, NewUser auth.UpdateUserRequest) (
 }
 
 func MakeUserData(user entity.User) map[string]interface{} {
+	role := user.Role
+	if !role.IsValid() {
+		role = entity.RoleUser
+	}
+
 	return map[string]interface{}{
 		"id":       user.ID,
 		"email":    user.Email,
 		"username": user.Name,
+		"role":     string(role),
 	}
 }
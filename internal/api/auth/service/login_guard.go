@@ -0,0 +1,180 @@
+package authService
+
+import (
+	"ProjectGolang/internal/api/auth"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/pkg/captcha"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/redis"
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	loginFailureWindow      = 15 * time.Minute
+	loginCaptchaThreshold   = 3
+	loginDelayThreshold     = 3
+	loginLockThreshold      = 10
+	loginLockDuration       = 30 * time.Minute
+	loginIPFailureThreshold = 50
+	loginBaseDelay          = 2 * time.Second
+	loginMaxDelay           = 5 * time.Minute
+)
+
+// loginGuard tracks failed login attempts per account and per client IP in
+// Redis. After a few failures the account has to wait an exponentially growing
+// delay between attempts (and solve a CAPTCHA when one is configured), and
+// after loginLockThreshold failures it is locked for loginLockDuration.
+type loginGuard struct {
+	log         *logrus.Logger
+	redisServer redis.IRedis
+	captcha     captcha.ICaptcha
+}
+
+func newLoginGuard(log *logrus.Logger, redisServer redis.IRedis, captchaVerifier captcha.ICaptcha) *loginGuard {
+	return &loginGuard{log: log, redisServer: redisServer, captcha: captchaVerifier}
+}
+
+// loginAccountKey identifies the account being logged into. Known users are
+// tracked by ID so password and Touch ID attempts share one counter; unknown
+// identifiers are tracked as-is so probing nonexistent accounts is throttled
+// the same way.
+func loginAccountKey(user entity.User, identifier string) string {
+	if user.ID != "" {
+		return "user:" + user.ID
+	}
+	return "identifier:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+func (g *loginGuard) check(ctx context.Context, account string, captchaToken string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+	clientIP := contextPkg.GetClientIP(ctx)
+
+	if ttl, err := g.redisServer.TTL(ctx, "login:lock:"+account); err != nil {
+		g.logRedisError(requestID, err)
+	} else if ttl > 0 {
+		g.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"account":    account,
+			"retry_in":   ttl.String(),
+		}).Warn("Login attempt on locked account")
+		return auth.ErrAccountLocked
+	}
+
+	if count, err := g.redisServer.Count(ctx, "login:fail:ip:"+clientIP); err != nil {
+		g.logRedisError(requestID, err)
+	} else if count >= loginIPFailureThreshold {
+		g.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"client_ip":  clientIP,
+		}).Warn("Login attempt from throttled IP")
+		return auth.ErrTooManyLoginAttempts
+	}
+
+	if ttl, err := g.redisServer.TTL(ctx, "login:delay:"+account); err != nil {
+		g.logRedisError(requestID, err)
+	} else if ttl > 0 {
+		g.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"account":    account,
+			"retry_in":   ttl.String(),
+		}).Warn("Login attempt before progressive delay elapsed")
+		return auth.ErrTooManyLoginAttempts
+	}
+
+	if !g.captcha.Enabled() {
+		return nil
+	}
+
+	failures, err := g.redisServer.Count(ctx, "login:fail:account:"+account)
+	if err != nil {
+		g.logRedisError(requestID, err)
+		return nil
+	}
+
+	if failures < loginCaptchaThreshold {
+		return nil
+	}
+
+	if captchaToken == "" {
+		return auth.ErrCaptchaRequired
+	}
+
+	if err := g.captcha.Verify(ctx, captchaToken, clientIP); err != nil {
+		g.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Warn("Captcha verification failed")
+		if errors.Is(err, captcha.ErrCaptchaFailed) {
+			return auth.ErrInvalidCaptcha
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (g *loginGuard) registerFailure(ctx context.Context, account string) {
+	requestID := contextPkg.GetRequestID(ctx)
+	clientIP := contextPkg.GetClientIP(ctx)
+
+	if _, err := g.redisServer.Incr(ctx, "login:fail:ip:"+clientIP, loginFailureWindow); err != nil {
+		g.logRedisError(requestID, err)
+	}
+
+	failures, err := g.redisServer.Incr(ctx, "login:fail:account:"+account, loginFailureWindow)
+	if err != nil {
+		g.logRedisError(requestID, err)
+		return
+	}
+
+	switch {
+	case failures >= loginLockThreshold:
+		if _, err := g.redisServer.Incr(ctx, "login:lock:"+account, loginLockDuration); err != nil {
+			g.logRedisError(requestID, err)
+			return
+		}
+
+		g.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"account":    account,
+			"client_ip":  clientIP,
+			"failures":   failures,
+		}).Warn("Account locked after repeated failed logins")
+	case failures >= loginDelayThreshold:
+		delay := loginBaseDelay << (failures - loginDelayThreshold)
+		if delay > loginMaxDelay || delay <= 0 {
+			delay = loginMaxDelay
+		}
+
+		if _, err := g.redisServer.Incr(ctx, "login:delay:"+account, delay); err != nil {
+			g.logRedisError(requestID, err)
+		}
+	}
+}
+
+func (g *loginGuard) reset(ctx context.Context, account string) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	for _, key := range []string{
+		"login:fail:account:" + account,
+		"login:delay:" + account,
+		"login:lock:" + account,
+	} {
+		if err := g.redisServer.DeleteOTP(ctx, key); err != nil {
+			g.logRedisError(requestID, err)
+		}
+	}
+}
+
+// logRedisError records a Redis failure. The guard fails open so an outage of
+// Redis does not lock every user out.
+func (g *loginGuard) logRedisError(requestID string, err error) {
+	g.log.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"error":      err.Error(),
+	}).Error("Login guard redis operation failed")
+}
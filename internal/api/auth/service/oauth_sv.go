@@ -0,0 +1,460 @@
+package authService
+
+import (
+	"ProjectGolang/internal/api/auth"
+	authRepository "ProjectGolang/internal/api/auth/repository"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/events"
+	contextPkg "ProjectGolang/pkg/context"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"net/url"
+	"time"
+)
+
+const googleStateTTL = 10 * time.Minute
+
+// googleAuthorization is kept in Redis under the OAuth state for the duration
+// of one authorization round trip. Binding is also given to the browser that
+// started it, which must present it on the callback, so a state cannot be
+// completed from another browser. UserID is set when an authenticated user is
+// linking Google to their existing account.
+type googleAuthorization struct {
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	Binding      string `json:"binding"`
+	UserID       string `json:"user_id,omitempty"`
+}
+
+func googleStateKey(state string) string {
+	return "oauth:google:state:" + state
+}
+
+// LoginGoogle returns the Google consent page and the binding the browser
+// must send back with the callback.
+func (s *authDomainImpl) LoginGoogle(c context.Context) (*url.URL, string, error) {
+	return s.startGoogleAuthorization(c, "")
+}
+
+// LinkGoogle is LoginGoogle for linking Google to the account of userID.
+func (s *authDomainImpl) LinkGoogle(c context.Context, userID string) (*url.URL, string, error) {
+	return s.startGoogleAuthorization(c, userID)
+}
+
+func (s *authDomainImpl) startGoogleAuthorization(c context.Context, userID string) (*url.URL, string, error) {
+	requestID := contextPkg.GetRequestID(c)
+
+	state, err := randomURLToken(32)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to generate oauth state")
+		return nil, "", err
+	}
+
+	nonce, err := randomURLToken(16)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to generate oauth nonce")
+		return nil, "", err
+	}
+
+	binding, err := randomURLToken(32)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to generate oauth browser binding")
+		return nil, "", err
+	}
+
+	authorization := googleAuthorization{
+		CodeVerifier: oauth2.GenerateVerifier(),
+		Nonce:        nonce,
+		Binding:      binding,
+		UserID:       userID,
+	}
+
+	payload, err := json.Marshal(authorization)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.redisServer.SetOTP(c, googleStateKey(state), string(payload), googleStateTTL); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to store oauth state in Redis")
+		return nil, "", err
+	}
+
+	URL, err := url.Parse(s.googleProvider.AuthCodeURL(state, authorization.CodeVerifier, nonce))
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to parse google auth URL")
+		return nil, "", err
+	}
+
+	return URL, binding, nil
+}
+
+// GoogleCallback completes the authorization of state. binding is what the
+// browser was given when the authorization started.
+func (s *authDomainImpl) GoogleCallback(c context.Context, state string, code string, binding string) (auth.GoogleLoginResponse, error) {
+	requestID := contextPkg.GetRequestID(c)
+
+	authorization, err := s.consumeGoogleState(c, state)
+	if err != nil {
+		return auth.GoogleLoginResponse{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(binding), []byte(authorization.Binding)) != 1 {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    authorization.UserID,
+		}).Warn("OAuth callback from a browser that did not start the authorization")
+		return auth.GoogleLoginResponse{}, auth.ErrInvalidOAuthState
+	}
+
+	claims, err := s.googleProvider.ExchangeIDToken(c, code, authorization.CodeVerifier)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Warn("Failed to exchange google authorization code")
+		return auth.GoogleLoginResponse{}, auth.ErrInvalidGoogleToken
+	}
+
+	if claims.Nonce != authorization.Nonce {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).Warn("Google id token nonce mismatch")
+		return auth.GoogleLoginResponse{}, auth.ErrInvalidGoogleToken
+	}
+
+	repo, err := s.repo.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return auth.GoogleLoginResponse{}, err
+	}
+
+	if authorization.UserID != "" {
+		return s.linkGoogleSubject(c, repo, authorization.UserID, claims.Subject)
+	}
+
+	user, err := repo.Users.GetByGoogleSubject(c, claims.Subject)
+	if err == nil {
+		return s.googleLoginResponse(c, user, false, false)
+	}
+	if !errors.Is(err, auth.ErrUserNotFound) {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to get user by google subject")
+		return auth.GoogleLoginResponse{}, err
+	}
+
+	if !claims.EmailVerified || claims.Email == "" {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).Warn("Google email is not verified")
+		return auth.GoogleLoginResponse{}, auth.ErrGoogleEmailNotVerified
+	}
+
+	user, err = repo.Users.GetByEmail(c, claims.Email)
+	switch {
+	case err == nil:
+		if user.GoogleSubject != "" && user.GoogleSubject != claims.Subject {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"user_id":    user.ID,
+			}).Warn("User already linked to a different google account")
+			return auth.GoogleLoginResponse{}, auth.ErrGoogleAccountAlreadyLinked
+		}
+
+		if err := repo.Users.UpdateGoogleSubject(c, user.ID, claims.Subject); err != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("Failed to link google account by email")
+			return auth.GoogleLoginResponse{}, err
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    user.ID,
+		}).Info("Google account linked by verified email")
+
+		return s.googleLoginResponse(c, user, false, true)
+	case errors.Is(err, auth.ErrUserWithEmailNotFound):
+		name := claims.Name
+		if name == "" {
+			name = claims.Email
+		}
+
+		ULID, err := s.utils.NewULIDFromTimestamp(time.Now())
+		if err != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("Failed to generate ULID")
+			return auth.GoogleLoginResponse{}, err
+		}
+
+		user = entity.User{
+			ID:            ULID,
+			Email:         claims.Email,
+			Name:          name,
+			GoogleSubject: claims.Subject,
+		}
+
+		if err := s.registerGoogleUser(c, user); err != nil {
+			return auth.GoogleLoginResponse{}, err
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    user.ID,
+		}).Info("Registered new user from google")
+
+		return s.googleLoginResponse(c, user, true, true)
+	default:
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to get user by email")
+		return auth.GoogleLoginResponse{}, err
+	}
+}
+
+func (s *authDomainImpl) UnlinkGoogle(c context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(c)
+	repo, err := s.repo.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return err
+	}
+
+	user, err := repo.Users.GetByID(c, userID)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to get user by ID")
+		return err
+	}
+
+	if user.GoogleSubject == "" {
+		return auth.ErrGoogleNotLinked
+	}
+
+	// Google must not be the only way back into the account.
+	if user.PhoneNumber == "" || user.Password == "" {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+		}).Warn("Refusing to unlink google from account without phone login")
+		return auth.ErrCannotUnlinkGoogle
+	}
+
+	if err := repo.Users.UpdateGoogleSubject(c, userID, ""); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to unlink google account")
+		return err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"user_id":    userID,
+	}).Info("Google account unlinked")
+
+	return nil
+}
+
+// consumeGoogleState loads and deletes the authorization stored for state, so
+// every state can be redeemed at most once.
+func (s *authDomainImpl) consumeGoogleState(c context.Context, state string) (googleAuthorization, error) {
+	requestID := contextPkg.GetRequestID(c)
+
+	if state == "" {
+		return googleAuthorization{}, auth.ErrInvalidOAuthState
+	}
+
+	payload, err := s.redisServer.GetOTP(c, googleStateKey(state))
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Warn("OAuth state not found")
+		return googleAuthorization{}, auth.ErrInvalidOAuthState
+	}
+
+	if err := s.redisServer.DeleteOTP(c, googleStateKey(state)); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to delete oauth state from Redis")
+		return googleAuthorization{}, err
+	}
+
+	var authorization googleAuthorization
+	if err := json.Unmarshal([]byte(payload), &authorization); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to decode oauth state")
+		return googleAuthorization{}, auth.ErrInvalidOAuthState
+	}
+
+	return authorization, nil
+}
+
+func (s *authDomainImpl) linkGoogleSubject(c context.Context, repo authRepository.Client, userID string, subject string) (auth.GoogleLoginResponse, error) {
+	requestID := contextPkg.GetRequestID(c)
+
+	existing, err := repo.Users.GetByGoogleSubject(c, subject)
+	if err == nil && existing.ID != userID {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+		}).Warn("Google account already linked to another user")
+		return auth.GoogleLoginResponse{}, auth.ErrGoogleAccountAlreadyLinked
+	} else if err != nil && !errors.Is(err, auth.ErrUserNotFound) {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to get user by google subject")
+		return auth.GoogleLoginResponse{}, err
+	}
+
+	user, err := repo.Users.GetByID(c, userID)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to get user by ID")
+		return auth.GoogleLoginResponse{}, err
+	}
+
+	if user.GoogleSubject != "" && user.GoogleSubject != subject {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+		}).Warn("User already linked to a different google account")
+		return auth.GoogleLoginResponse{}, auth.ErrOtherGoogleAccountLinked
+	}
+
+	if err := repo.Users.UpdateGoogleSubject(c, userID, subject); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to link google account")
+		return auth.GoogleLoginResponse{}, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"user_id":    userID,
+	}).Info("Google account linked")
+
+	return s.googleLoginResponse(c, user, false, true)
+}
+
+func (s *authDomainImpl) googleLoginResponse(c context.Context, user entity.User, isNewUser bool, linked bool) (auth.GoogleLoginResponse, error) {
+	requestID := contextPkg.GetRequestID(c)
+
+	if user.IsLocked() {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    user.ID,
+		}).Warn("Google login on administratively locked account")
+		return auth.GoogleLoginResponse{}, auth.ErrAccountSuspended
+	}
+
+	token, expired, err := s.jwt.Sign(MakeUserData(user), time.Hour*1)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to sign token")
+		return auth.GoogleLoginResponse{}, err
+	}
+
+	return auth.GoogleLoginResponse{
+		LoginUserResponse: auth.LoginUserResponse{
+			AccessToken:      token,
+			ExpiresInMinutes: time.Until(time.Unix(expired, 0)).Minutes(),
+		},
+		IsNewUser: isNewUser,
+		Linked:    linked,
+	}, nil
+}
+
+func randomURLToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// registerGoogleUser creates the account of a first Google sign-in together
+// with its registration event.
+func (s *authDomainImpl) registerGoogleUser(c context.Context, user entity.User) error {
+	requestID := contextPkg.GetRequestID(c)
+
+	repo, err := s.repo.NewClient(true)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return err
+	}
+	defer repo.Rollback()
+
+	if err := repo.Users.CreateGoogleUser(c, user); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to register google user")
+		return err
+	}
+
+	if err := repo.Outbox.Append(c, user.ID, events.UserRegistered{Method: events.RegistrationGoogle}); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to record registration event")
+		return err
+	}
+
+	if err := repo.Commit(); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,365 @@
+package authService
+
+import (
+	"ProjectGolang/internal/api/auth"
+	authRepository "ProjectGolang/internal/api/auth/repository"
+	"ProjectGolang/pkg/google"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"ProjectGolang/pkg/redis"
+	"ProjectGolang/pkg/utils"
+	"context"
+	"database/sql/driver"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/url"
+	"testing"
+)
+
+const googleSubject = "google-subject-1"
+
+type oauthTest struct {
+	service *authDomainImpl
+	google  *google.FakeProvider
+	mock    sqlmock.Sqlmock
+}
+
+func newOAuthTest(t *testing.T) *oauthTest {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	provider := google.NewFake()
+
+	return &oauthTest{
+		service: &authDomainImpl{
+			log:            log,
+			repo:           authRepository.New(sqlx.NewDb(db, "postgres"), log),
+			googleProvider: provider,
+			redisServer:    redis.NewFake(),
+			jwt:            jwtPkg.New(jwtPkg.Config{AccessTokenSecret: "oauth-test-secret"}),
+			utils:          utils.New(),
+		},
+		google: provider,
+		mock:   mock,
+	}
+}
+
+// googleCallback is what the browser brings back from Google.
+type googleCallback struct {
+	state   string
+	code    string
+	binding string
+}
+
+// authorize starts a Google login and has the fake provider consent as
+// identity.
+func (o *oauthTest) authorize(t *testing.T, identity google.IDTokenClaims) googleCallback {
+	t.Helper()
+
+	authURL, binding, err := o.service.LoginGoogle(context.Background())
+	require.NoError(t, err)
+	return o.consent(t, authURL, binding, identity)
+}
+
+// authorizeLink is authorize for linking Google to the account of userID.
+func (o *oauthTest) authorizeLink(t *testing.T, userID string, identity google.IDTokenClaims) googleCallback {
+	t.Helper()
+
+	authURL, binding, err := o.service.LinkGoogle(context.Background(), userID)
+	require.NoError(t, err)
+	return o.consent(t, authURL, binding, identity)
+}
+
+func (o *oauthTest) consent(t *testing.T, authURL *url.URL, binding string, identity google.IDTokenClaims) googleCallback {
+	t.Helper()
+
+	state := authURL.Query().Get("state")
+	require.NotEmpty(t, state)
+	require.NotEmpty(t, binding)
+
+	redirect, err := o.google.Authorize(state, identity)
+	require.NoError(t, err)
+	parsed, err := url.Parse(redirect)
+	require.NoError(t, err)
+
+	return googleCallback{state: state, code: parsed.Query().Get("code"), binding: binding}
+}
+
+func (o *oauthTest) callback(cb googleCallback) (auth.GoogleLoginResponse, error) {
+	return o.service.GoogleCallback(context.Background(), cb.state, cb.code, cb.binding)
+}
+
+func (o *oauthTest) expectUserByGoogleSubject(rows *sqlmock.Rows) {
+	o.mock.ExpectQuery(`WHERE google_subject = \$1`).
+		WithArgs(googleSubject).
+		WillReturnRows(rows)
+}
+
+func linkedUserRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "email", "name", "password", "phone_number", "is_verified", "google_subject", "role", "locked_at"}).
+		AddRow("user-1", "user@example.com", "User", "", "", true, googleSubject, "user", nil)
+}
+
+func TestGoogleCallbackSignsInLinkedUser(t *testing.T) {
+	o := newOAuthTest(t)
+	cb := o.authorize(t, google.IDTokenClaims{Subject: googleSubject, Email: "user@example.com", EmailVerified: true})
+	o.expectUserByGoogleSubject(linkedUserRows())
+
+	res, err := o.callback(cb)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.AccessToken)
+	assert.False(t, res.IsNewUser)
+	assert.NoError(t, o.mock.ExpectationsWereMet())
+}
+
+func TestGoogleCallbackRejectsReplayedState(t *testing.T) {
+	o := newOAuthTest(t)
+	cb := o.authorize(t, google.IDTokenClaims{Subject: googleSubject, Email: "user@example.com", EmailVerified: true})
+	o.expectUserByGoogleSubject(linkedUserRows())
+
+	_, err := o.callback(cb)
+	require.NoError(t, err)
+
+	_, err = o.callback(cb)
+	assert.ErrorIs(t, err, auth.ErrInvalidOAuthState)
+	assert.NoError(t, o.mock.ExpectationsWereMet())
+}
+
+func TestGoogleCallbackRejectsUnknownState(t *testing.T) {
+	o := newOAuthTest(t)
+	cb := o.authorize(t, google.IDTokenClaims{Subject: googleSubject, EmailVerified: true})
+	cb.state = "forged-state"
+
+	_, err := o.callback(cb)
+
+	assert.ErrorIs(t, err, auth.ErrInvalidOAuthState)
+}
+
+func TestGoogleCallbackRejectsOtherBrowser(t *testing.T) {
+	tests := []struct {
+		name    string
+		binding string
+	}{
+		{name: "no cookie", binding: ""},
+		{name: "cookie of another authorization", binding: "binding-of-another-authorization"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOAuthTest(t)
+			// A link started by the victim, completed in the attacker's
+			// browser, must not put the attacker's Google on their account.
+			cb := o.authorizeLink(t, "user-1", google.IDTokenClaims{Subject: googleSubject, Email: "attacker@example.com", EmailVerified: true})
+			cb.binding = tt.binding
+
+			_, err := o.callback(cb)
+
+			assert.ErrorIs(t, err, auth.ErrInvalidOAuthState)
+			assert.NoError(t, o.mock.ExpectationsWereMet(), "no account may be touched")
+		})
+	}
+}
+
+func TestGoogleCallbackRejectsReplayedCode(t *testing.T) {
+	o := newOAuthTest(t)
+	cb := o.authorize(t, google.IDTokenClaims{Subject: googleSubject, Email: "user@example.com", EmailVerified: true})
+	o.expectUserByGoogleSubject(linkedUserRows())
+
+	_, err := o.callback(cb)
+	require.NoError(t, err)
+
+	// A fresh state does not make a used code redeemable again.
+	second := o.authorize(t, google.IDTokenClaims{Subject: googleSubject, EmailVerified: true})
+	second.code = cb.code
+	_, err = o.callback(second)
+	assert.ErrorIs(t, err, auth.ErrInvalidGoogleToken)
+	assert.NoError(t, o.mock.ExpectationsWereMet())
+}
+
+func TestGoogleCallbackRejectsNonceMismatch(t *testing.T) {
+	o := newOAuthTest(t)
+	cb := o.authorize(t, google.IDTokenClaims{
+		Subject:       googleSubject,
+		Email:         "user@example.com",
+		EmailVerified: true,
+		Nonce:         "nonce-of-another-authorization",
+	})
+
+	_, err := o.callback(cb)
+
+	assert.ErrorIs(t, err, auth.ErrInvalidGoogleToken)
+	assert.NoError(t, o.mock.ExpectationsWereMet(), "no user may be looked up for a mismatched nonce")
+}
+
+func TestGoogleCallbackRejectsUnverifiedEmail(t *testing.T) {
+	o := newOAuthTest(t)
+	cb := o.authorize(t, google.IDTokenClaims{Subject: googleSubject, Email: "victim@example.com", EmailVerified: false})
+	o.expectUserByGoogleSubject(sqlmock.NewRows([]string{"id"}))
+
+	_, err := o.callback(cb)
+
+	assert.ErrorIs(t, err, auth.ErrGoogleEmailNotVerified)
+	assert.NoError(t, o.mock.ExpectationsWereMet(), "an unverified email must not be matched to an account")
+}
+
+func userRows(id string, googleSubject string, phoneNumber string, password string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "email", "name", "password", "phone_number", "google_subject", "role"}).
+		AddRow(id, "budi@example.com", "Budi", password, phoneNumber, googleSubject, "user")
+}
+
+func (o *oauthTest) expectUserByID(rows *sqlmock.Rows) {
+	o.mock.ExpectQuery(`FROM Users\s+WHERE id = \$1`).
+		WillReturnRows(rows)
+}
+
+// expectGoogleSubjectUpdate expects subject to be stored for userID; an
+// unlinked subject is stored as nil.
+func (o *oauthTest) expectGoogleSubjectUpdate(userID string, subject driver.Value) {
+	o.mock.ExpectExec(`UPDATE Users\s+SET google_subject = \$1`).
+		WithArgs(subject, sqlmock.AnyArg(), userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestGoogleCallbackLinksAccount(t *testing.T) {
+	o := newOAuthTest(t)
+	cb := o.authorizeLink(t, "user-2", google.IDTokenClaims{Subject: googleSubject, Email: "budi@gmail.com", EmailVerified: true})
+	o.expectUserByGoogleSubject(sqlmock.NewRows([]string{"id"}))
+	o.expectUserByID(userRows("user-2", "", "+6281234567890", "hash"))
+	o.expectGoogleSubjectUpdate("user-2", googleSubject)
+
+	res, err := o.callback(cb)
+
+	require.NoError(t, err)
+	assert.True(t, res.Linked)
+	assert.False(t, res.IsNewUser)
+	assert.NoError(t, o.mock.ExpectationsWereMet())
+}
+
+func TestGoogleCallbackLinkConflicts(t *testing.T) {
+	t.Run("Google account linked to another user", func(t *testing.T) {
+		o := newOAuthTest(t)
+		cb := o.authorizeLink(t, "user-2", google.IDTokenClaims{Subject: googleSubject, EmailVerified: true})
+		o.expectUserByGoogleSubject(linkedUserRows())
+
+		_, err := o.callback(cb)
+
+		assert.ErrorIs(t, err, auth.ErrGoogleAccountAlreadyLinked)
+		assert.NoError(t, o.mock.ExpectationsWereMet(), "neither account may be changed")
+	})
+
+	t.Run("account linked to a different Google account", func(t *testing.T) {
+		o := newOAuthTest(t)
+		cb := o.authorizeLink(t, "user-2", google.IDTokenClaims{Subject: googleSubject, EmailVerified: true})
+		o.expectUserByGoogleSubject(sqlmock.NewRows([]string{"id"}))
+		o.expectUserByID(userRows("user-2", "google-subject-2", "+6281234567890", "hash"))
+
+		_, err := o.callback(cb)
+
+		assert.ErrorIs(t, err, auth.ErrOtherGoogleAccountLinked)
+		assert.NoError(t, o.mock.ExpectationsWereMet(), "the linked Google account must not be replaced")
+	})
+}
+
+func TestGoogleCallbackLinksAccountByVerifiedEmail(t *testing.T) {
+	o := newOAuthTest(t)
+	cb := o.authorize(t, google.IDTokenClaims{Subject: googleSubject, Email: "budi@example.com", EmailVerified: true})
+	o.expectUserByGoogleSubject(sqlmock.NewRows([]string{"id"}))
+	o.mock.ExpectQuery(`FROM Users\s+WHERE email = \$1`).
+		WithArgs("budi@example.com").
+		WillReturnRows(userRows("user-2", "", "+6281234567890", "hash"))
+	o.expectGoogleSubjectUpdate("user-2", googleSubject)
+
+	res, err := o.callback(cb)
+
+	require.NoError(t, err)
+	assert.True(t, res.Linked)
+	assert.False(t, res.IsNewUser)
+	assert.NoError(t, o.mock.ExpectationsWereMet())
+}
+
+func TestGoogleCallbackRegistersNewUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		claimed  string
+		expected string
+	}{
+		{name: "with name", claimed: "Budi Santoso", expected: "Budi Santoso"},
+		{name: "without name", claimed: "", expected: "budi@gmail.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOAuthTest(t)
+			cb := o.authorize(t, google.IDTokenClaims{Subject: googleSubject, Email: "budi@gmail.com", EmailVerified: true, Name: tt.claimed})
+			o.expectUserByGoogleSubject(sqlmock.NewRows([]string{"id"}))
+			o.mock.ExpectQuery(`FROM Users\s+WHERE email = \$1`).
+				WithArgs("budi@gmail.com").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			o.mock.ExpectBegin()
+			o.mock.ExpectExec(`INSERT INTO Users \(id, email, name, google_subject, created_at\)`).
+				WithArgs(sqlmock.AnyArg(), "budi@gmail.com", tt.expected, googleSubject, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			o.mock.ExpectExec(`INSERT INTO outbox_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+			o.mock.ExpectCommit()
+
+			res, err := o.callback(cb)
+
+			require.NoError(t, err)
+			assert.True(t, res.IsNewUser)
+			assert.NotEmpty(t, res.AccessToken)
+			assert.NoError(t, o.mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUnlinkGoogle(t *testing.T) {
+	tests := []struct {
+		name        string
+		phoneNumber string
+		password    string
+		err         error
+	}{
+		{name: "phone login", phoneNumber: "+6281234567890", password: "hash"},
+		{name: "no phone number", password: "hash", err: auth.ErrCannotUnlinkGoogle},
+		{name: "no password", phoneNumber: "+6281234567890", err: auth.ErrCannotUnlinkGoogle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOAuthTest(t)
+			o.expectUserByID(userRows("user-1", googleSubject, tt.phoneNumber, tt.password))
+			if tt.err == nil {
+				o.expectGoogleSubjectUpdate("user-1", nil)
+			}
+
+			err := o.service.UnlinkGoogle(context.Background(), "user-1")
+
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, o.mock.ExpectationsWereMet(), "Google may only be unlinked when the phone login is left")
+		})
+	}
+}
+
+func TestUnlinkGoogleNotLinked(t *testing.T) {
+	o := newOAuthTest(t)
+	o.expectUserByID(userRows("user-1", "", "+6281234567890", "hash"))
+
+	err := o.service.UnlinkGoogle(context.Background(), "user-1")
+
+	assert.ErrorIs(t, err, auth.ErrGoogleNotLinked)
+	assert.NoError(t, o.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,153 @@
+package authService
+
+import (
+	"ProjectGolang/internal/api/auth"
+	"ProjectGolang/internal/jobs"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/imaging"
+	"ProjectGolang/pkg/s3"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"mime/multipart"
+)
+
+// storePhoto decodes an uploaded photo and stores it, upright and stripped of
+// its metadata, in every photo size. It returns the key of the full size.
+func (s *userDomainImpl) storePhoto(ctx context.Context, userID string, purpose s3.Purpose, file *multipart.FileHeader) (string, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	src, err := file.Open()
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to open uploaded photo")
+		return "", auth.ErrFailedToUploadFile
+	}
+	defer src.Close()
+
+	variants, err := imaging.Process(src, imaging.PhotoSizes...)
+	if err != nil {
+		if rejected := photoError(err); rejected != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"user_id":    userID,
+				"error":      err.Error(),
+			}).Warn("Photo rejected")
+			return "", rejected
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to process photo")
+		return "", auth.ErrFailedToUploadFile
+	}
+
+	sizes := make([]s3.Size, 0, len(variants))
+	for _, variant := range variants {
+		sizes = append(sizes, s3.Size{Name: variant.Size.Name, Data: variant.Data})
+	}
+
+	photoKey, err := s.s3Client.UploadSizes(userID, purpose, sizes)
+	if err != nil {
+		if rejected := photoError(err); rejected != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"user_id":    userID,
+				"error":      err.Error(),
+			}).Warn("Photo rejected by storage")
+			return "", rejected
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to upload file to S3")
+		return "", auth.ErrFailedToUploadFile
+	}
+
+	return photoKey, nil
+}
+
+// PhotoURLs presigns every size of a photo for its owner, by size name.
+// Photos stored before sizes were kept only have the full size.
+func (s *userDomainImpl) PhotoURLs(ctx context.Context, userID string, photoKey string) map[string]string {
+	if photoKey == "" {
+		return nil
+	}
+
+	urls := make(map[string]string, len(imaging.PhotoSizes))
+	for _, key := range photoKeys(photoKey) {
+		presignedURL, err := s.s3Client.PresignUrl(userID, key.key)
+		if err != nil {
+			if key.size == imaging.Full.Name {
+				s.log.WithFields(logrus.Fields{
+					"request_id": contextPkg.GetRequestID(ctx),
+					"user_id":    userID,
+					"error":      err.Error(),
+				}).Warn("Failed to presign URL for photo")
+			}
+			continue
+		}
+		urls[key.size] = presignedURL
+	}
+
+	return urls
+}
+
+// deletePhoto queues the removal of every size of a photo the user no longer
+// has, either the one just replaced or an upload whose update failed.
+func (s *userDomainImpl) deletePhoto(ctx context.Context, userID string, photoKey string) {
+	if photoKey == "" {
+		return
+	}
+
+	for _, key := range photoKeys(photoKey) {
+		if err := s.jobs.Enqueue(ctx, jobs.DeleteFile{FileName: key.key}); err != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": contextPkg.GetRequestID(ctx),
+				"user_id":    userID,
+				"file_name":  key.key,
+				"error":      err.Error(),
+			}).Warn("Failed to queue photo deletion")
+		}
+	}
+}
+
+type sizeKey struct {
+	size string
+	key  string
+}
+
+// photoKeys lists the keys of the sizes of a photo, the full size being
+// stored under the photo key itself.
+func photoKeys(photoKey string) []sizeKey {
+	keys := make([]sizeKey, 0, len(imaging.PhotoSizes))
+	for i, size := range imaging.PhotoSizes {
+		key := photoKey
+		if i > 0 {
+			key = s3.SizeKey(photoKey, size.Name)
+		}
+		keys = append(keys, sizeKey{size: size.Name, key: key})
+	}
+	return keys
+}
+
+// photoError returns the error for a photo refused for its content, or nil if
+// it failed for another reason.
+func photoError(err error) error {
+	switch {
+	case errors.Is(err, imaging.ErrInvalidImage), errors.Is(err, s3.ErrUnsupportedFileType):
+		return auth.ErrInvalidFileType
+	case errors.Is(err, imaging.ErrImageTooLarge):
+		return auth.ErrImageTooLarge
+	case errors.Is(err, s3.ErrFileTooLarge):
+		return auth.ErrFileTooLarge
+	}
+	return nil
+}
@@ -3,14 +3,17 @@ package authService
 import (
 	"ProjectGolang/internal/api/auth"
 	authRepository "ProjectGolang/internal/api/auth/repository"
+	"ProjectGolang/internal/api/notification"
 	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/jobs"
 	"ProjectGolang/pkg/bcrypt"
+	"ProjectGolang/pkg/captcha"
 	"ProjectGolang/pkg/google"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"ProjectGolang/pkg/liveness"
 	"ProjectGolang/pkg/redis"
 	"ProjectGolang/pkg/s3"
-	"ProjectGolang/pkg/smtp"
 	"ProjectGolang/pkg/utils"
-	"ProjectGolang/pkg/whatsapp"
 	"context"
 	"github.com/sirupsen/logrus"
 	"mime/multipart"
@@ -30,20 +33,25 @@ type UserDomain interface {
 	GetByEmail(c context.Context, email string) (entity.User, error)
 	UpdateUser(c context.Context, user entity.UserLoginData, req auth.UpdateUserRequest) error
 	UpdateUserVerifiedStatusAndPIN(ctx context.Context, user auth.VerifyUserUsingOTP) error
-	DeleteUser(c context.Context, id string) error
 	UpdateProfilePhoto(c context.Context, userID string, photoFile *multipart.FileHeader) (*auth.ProfilePhotoResponse, error)
-	UpdateFacePhoto(ctx context.Context, userID string, facePhotoFile *multipart.FileHeader) error
+	UpdateFacePhoto(ctx context.Context, userID string, facePhotoFile *multipart.FileHeader, livenessToken string) error
+	PhotoURLs(ctx context.Context, userID string, photoKey string) map[string]string
 }
 
 type AuthDomain interface {
 	Login(c context.Context, req auth.LoginUserRequest) (auth.LoginUserResponse, error)
-	LoginGoogle() (*url.URL, error)
-	UserLoginGoogle(c context.Context, req auth.LoginUserGoogle) (auth.LoginUserResponse, error)
+	LoginGoogle(c context.Context) (*url.URL, string, error)
+	LinkGoogle(c context.Context, userID string) (*url.URL, string, error)
+	GoogleCallback(c context.Context, state string, code string, binding string) (auth.GoogleLoginResponse, error)
+	UnlinkGoogle(c context.Context, userID string) error
 	PhoneNumberVerification(c context.Context, phoneNumber string) error
 	VerifyOTPandUpdatePIN(c context.Context, req auth.OTPPINRequest) error
 	SendEmailOTP(c context.Context, email string) error
 	VerifyEmailOTP(c context.Context, userID string, email string, code string) error
 	VerifyPhoneOTP(c context.Context, userID string, phoneNumber string, code string) error
+	RequestAccountUnlock(c context.Context, req auth.RequestAccountUnlockRequest) error
+	UnlockAccount(c context.Context, req auth.UnlockAccountRequest) error
+	ResetLoginAttempts(c context.Context, userID string)
 }
 
 type PasswordDomain interface {
@@ -59,11 +67,9 @@ type authService struct {
 	log            *logrus.Logger
 	authRepository authRepository.Repository
 	googleProvider google.ItfGoogle
-	smtpMailer     smtp.ItfSmtp
 	redisServer    redis.IRedis
-	whatsappSender whatsapp.IWhatsappSender
+	notifier       notification.Notifier
 	s3Client       s3.ItfS3
-	smtpClient     smtp.ItfSmtp
 	bcryptUtils    bcrypt.IBcrypt
 	utils          utils.IUtils
 
@@ -98,9 +104,10 @@ type userDomainImpl struct {
 	repo        authRepository.Repository
 	redisServer redis.IRedis
 	s3Client    s3.ItfS3
-	smtpCLient  smtp.ItfSmtp
+	jobs        jobs.Enqueuer
 	bcryptUtils bcrypt.IBcrypt
 	utils       utils.IUtils
+	liveness    liveness.ITokens
 }
 
 type authDomainImpl struct {
@@ -108,15 +115,16 @@ type authDomainImpl struct {
 	repo           authRepository.Repository
 	googleProvider google.ItfGoogle
 	redisServer    redis.IRedis
-	whatsappSender whatsapp.IWhatsappSender
-	smtpMailer     smtp.ItfSmtp
+	notifier       notification.Notifier
+	jwt            jwtPkg.IJWT
 	bcryptUtils    bcrypt.IBcrypt
+	utils          utils.IUtils
+	loginGuard     *loginGuard
 }
 
 type passwordDomainImpl struct {
 	log         *logrus.Logger
 	repo        authRepository.Repository
-	smtpMailer  smtp.ItfSmtp
 	redisServer redis.IRedis
 	bcryptUtils bcrypt.IBcrypt
 }
@@ -125,36 +133,40 @@ type biometricDomainImpl struct {
 	log         *logrus.Logger
 	repo        authRepository.Repository
 	redisServer redis.IRedis
+	jwt         jwtPkg.IJWT
 	bcryptUtils bcrypt.IBcrypt
 	utils       utils.IUtils
+	loginGuard  *loginGuard
 }
 
 func New(log *logrus.Logger,
 	authRepo authRepository.Repository,
 	googleProvider google.ItfGoogle,
-	smtpMailer smtp.ItfSmtp,
 	redisServer redis.IRedis,
-	whatsappSender whatsapp.IWhatsappSender,
+	notifier notification.Notifier,
 	s3Client s3.ItfS3,
-	smtp smtp.ItfSmtp,
+	jobs jobs.Enqueuer,
+	jwt jwtPkg.IJWT,
 	bcryptUtils bcrypt.IBcrypt,
 	utils utils.IUtils,
+	captchaVerifier captcha.ICaptcha,
+	livenessTokens liveness.ITokens,
 ) AuthService {
+	guard := newLoginGuard(log, redisServer, captchaVerifier)
+
 	return &authService{
 		log:            log,
 		authRepository: authRepo,
 		googleProvider: googleProvider,
-		smtpMailer:     smtpMailer,
 		redisServer:    redisServer,
-		whatsappSender: whatsappSender,
+		notifier:       notifier,
 		s3Client:       s3Client,
-		smtpClient:     smtp,
 		bcryptUtils:    bcryptUtils,
 		utils:          utils,
 
-		userDomain:      &userDomainImpl{log: log, repo: authRepo, redisServer: redisServer, s3Client: s3Client, smtpCLient: smtp, bcryptUtils: bcryptUtils, utils: utils},
-		authDomain:      &authDomainImpl{log: log, repo: authRepo, googleProvider: googleProvider, redisServer: redisServer, whatsappSender: whatsappSender, smtpMailer: smtpMailer, bcryptUtils: bcryptUtils},
-		passwordDomain:  &passwordDomainImpl{log: log, repo: authRepo, smtpMailer: smtpMailer, redisServer: redisServer, bcryptUtils: bcryptUtils},
-		biometricDomain: &biometricDomainImpl{log: log, repo: authRepo, redisServer: redisServer, bcryptUtils: bcryptUtils, utils: utils},
+		userDomain:      &userDomainImpl{log: log, repo: authRepo, redisServer: redisServer, s3Client: s3Client, jobs: jobs, bcryptUtils: bcryptUtils, utils: utils, liveness: livenessTokens},
+		authDomain:      &authDomainImpl{log: log, repo: authRepo, googleProvider: googleProvider, redisServer: redisServer, notifier: notifier, jwt: jwt, bcryptUtils: bcryptUtils, utils: utils, loginGuard: guard},
+		passwordDomain:  &passwordDomainImpl{log: log, repo: authRepo, redisServer: redisServer, bcryptUtils: bcryptUtils},
+		biometricDomain: &biometricDomainImpl{log: log, repo: authRepo, redisServer: redisServer, jwt: jwt, bcryptUtils: bcryptUtils, utils: utils, loginGuard: guard},
 	}
 }
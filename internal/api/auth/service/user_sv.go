@@ -3,10 +3,15 @@ package authService
 import (
 	"ProjectGolang/internal/api/auth"
 	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/events"
 	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/imaging"
+	"ProjectGolang/pkg/liveness"
+	"ProjectGolang/pkg/s3"
 	"errors"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
+	"io"
 	"mime/multipart"
 	"strings"
 	"time"
@@ -14,7 +19,7 @@ import (
 
 func (s *userDomainImpl) RegisterUser(ctx context.Context, req auth.CreateUserRequest) error {
 	requestID := contextPkg.GetRequestID(ctx)
-	repo, err := s.repo.NewClient(false)
+	repo, err := s.repo.NewClient(true)
 	if err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
@@ -22,6 +27,7 @@ func (s *userDomainImpl) RegisterUser(ctx context.Context, req auth.CreateUserRe
 		}).Error("Failed to create repository client")
 		return err
 	}
+	defer repo.Rollback()
 
 	hashedPassword, err := s.bcryptUtils.HashPassword(req.Password)
 	if err != nil {
@@ -56,6 +62,22 @@ func (s *userDomainImpl) RegisterUser(ctx context.Context, req auth.CreateUserRe
 		return err
 	}
 
+	if err := repo.Outbox.Append(ctx, user.ID, events.UserRegistered{Method: events.RegistrationPhone}); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to record registration event")
+		return err
+	}
+
+	if err := repo.Commit(); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to commit transaction")
+		return err
+	}
+
 	return nil
 }
 
@@ -221,38 +243,6 @@ func (s *userDomainImpl) UpdateUserVerifiedStatusAndPIN(ctx context.Context, use
 	return nil
 }
 
-func (s *userDomainImpl) DeleteUser(ctx context.Context, id string) error {
-	requestID := contextPkg.GetRequestID(ctx)
-	repo, err := s.repo.NewClient(false)
-	if err != nil {
-		s.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-			"error":      err.Error(),
-		}).Error("Failed to create repository client")
-		return err
-	}
-
-	if err := repo.Users.DeleteUser(ctx, id); err != nil {
-		if errors.Is(err, auth.ErrUserNotFound) {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"error":      err.Error(),
-			}).Warn("User not found")
-
-			return auth.ErrUserNotFound
-		} else {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"error":      err.Error(),
-			}).Error("Failed to delete user")
-
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (s *userDomainImpl) UpdateProfilePhoto(ctx context.Context, userID string, photoFile *multipart.FileHeader) (*auth.ProfilePhotoResponse, error) {
 	requestID := contextPkg.GetRequestID(ctx)
 
@@ -311,64 +301,37 @@ func (s *userDomainImpl) UpdateProfilePhoto(ctx context.Context, userID string,
 		return nil, err
 	}
 
-	uploadedFileURL, err := s.s3Client.UploadFile(photoFile)
+	photoKey, err := s.storePhoto(ctx, userID, s3.PurposeProfilePhoto, photoFile)
 	if err != nil {
-		s.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-			"user_id":    userID,
-			"error":      err.Error(),
-		}).Error("Failed to upload file to S3")
-		return nil, auth.ErrFailedToUploadFile
-	}
-
-	if userData.ProfilePhotoURL != "" {
-		oldPhotoURL := userData.ProfilePhotoURL
-		parts := strings.Split(oldPhotoURL, "/")
-		if len(parts) > 0 {
-			fileName := parts[len(parts)-1]
-			go func() {
-				if err := s.s3Client.DeleteFile(fileName); err != nil {
-					s.log.WithFields(logrus.Fields{
-						"request_id": requestID,
-						"user_id":    userID,
-						"file_name":  fileName,
-						"error":      err.Error(),
-					}).Warn("Failed to delete old profile photo")
-				}
-			}()
-		}
+		return nil, err
 	}
 
-	if err := repo.Users.UpdateProfilePhoto(ctx, userID, uploadedFileURL); err != nil {
+	if err := repo.Users.UpdateProfilePhoto(ctx, userID, photoKey); err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"user_id":    userID,
 			"error":      err.Error(),
 		}).Error("Failed to update profile photo URL in database")
 
-		parts := strings.Split(uploadedFileURL, "/")
-		if len(parts) > 0 {
-			fileName := parts[len(parts)-1]
-			if err := s.s3Client.DeleteFile(fileName); err != nil {
-				s.log.WithFields(logrus.Fields{
-					"request_id": requestID,
-					"user_id":    userID,
-					"file_name":  fileName,
-					"error":      err.Error(),
-				}).Warn("Failed to delete uploaded file after database update failure")
-			}
-		}
+		s.deletePhoto(ctx, userID, photoKey)
 
 		return nil, err
 	}
 
+	s.deletePhoto(ctx, userID, userData.ProfilePhotoURL)
+
+	photoURLs := s.PhotoURLs(ctx, userID, photoKey)
+
 	return &auth.ProfilePhotoResponse{
-		ID:              userID,
-		ProfilePhotoURL: uploadedFileURL,
+		ID:               userID,
+		ProfilePhotoURL:  photoURLs[imaging.Full.Name],
+		ProfilePhotoURLs: photoURLs,
 	}, nil
 }
 
-func (s *userDomainImpl) UpdateFacePhoto(ctx context.Context, userID string, facePhotoFile *multipart.FileHeader) error {
+// UpdateFacePhoto replaces the face photo with a frame that passed the liveness
+// check of the face scanner; livenessToken is the signed result for it.
+func (s *userDomainImpl) UpdateFacePhoto(ctx context.Context, userID string, facePhotoFile *multipart.FileHeader, livenessToken string) error {
 	requestID := contextPkg.GetRequestID(ctx)
 
 	if facePhotoFile == nil {
@@ -398,6 +361,10 @@ func (s *userDomainImpl) UpdateFacePhoto(ctx context.Context, userID string, fac
 		return auth.ErrInvalidFileType
 	}
 
+	if err := s.redeemLiveness(ctx, userID, facePhotoFile, livenessToken); err != nil {
+		return err
+	}
+
 	repo, err := s.repo.NewClient(false)
 	if err != nil {
 		s.log.WithFields(logrus.Fields{
@@ -426,58 +393,67 @@ func (s *userDomainImpl) UpdateFacePhoto(ctx context.Context, userID string, fac
 		return err
 	}
 
-	uploadedFileURL, err := s.s3Client.UploadFile(facePhotoFile)
+	photoKey, err := s.storePhoto(ctx, userID, s3.PurposeFacePhoto, facePhotoFile)
 	if err != nil {
-		s.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-			"user_id":    userID,
-			"error":      err.Error(),
-		}).Error("Failed to upload file to S3")
-		return auth.ErrFailedToUploadFile
-	}
-
-	if userData.FacePhotoURL != "" {
-		oldPhotoURL := userData.FacePhotoURL
-		parts := strings.Split(oldPhotoURL, "/")
-		if len(parts) > 0 {
-			fileName := parts[len(parts)-1]
-			go func() {
-				if err := s.s3Client.DeleteFile(fileName); err != nil {
-					s.log.WithFields(logrus.Fields{
-						"request_id": requestID,
-						"user_id":    userID,
-						"file_name":  fileName,
-						"error":      err.Error(),
-					}).Warn("Failed to delete old face photo")
-				}
-			}()
-		}
+		return err
 	}
 
-	if err := repo.Users.UpdateFacePhoto(ctx, userID, uploadedFileURL); err != nil {
+	if err := repo.Users.UpdateFacePhoto(ctx, userID, photoKey); err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"user_id":    userID,
 			"error":      err.Error(),
 		}).Error("Failed to update face photo URL in database")
-		parts := strings.Split(uploadedFileURL, "/")
-		if len(parts) > 0 {
-			fileName := parts[len(parts)-1]
-			if err := s.s3Client.DeleteFile(fileName); err != nil {
-				s.log.WithFields(logrus.Fields{
-					"request_id": requestID,
-					"user_id":    userID,
-					"file_name":  fileName,
-					"error":      err.Error(),
-				}).Warn("Failed to delete uploaded file after database update failure")
-			}
-		}
+		s.deletePhoto(ctx, userID, photoKey)
 
 		return err
 	}
+
+	s.deletePhoto(ctx, userID, userData.FacePhotoURL)
+
 	s.log.WithFields(logrus.Fields{
 		"request_id": requestID,
 		"user_id":    userID,
 	}).Info("Successfully updated face photo URL in database")
 	return nil
 }
+
+// redeemLiveness checks that photo is the frame vouched for by livenessToken
+// and consumes the token.
+func (s *userDomainImpl) redeemLiveness(ctx context.Context, userID string, photo *multipart.FileHeader, livenessToken string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	if livenessToken == "" {
+		return auth.ErrLivenessRequired
+	}
+
+	src, err := photo.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	image, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.liveness.Redeem(ctx, livenessToken, userID, image); err != nil {
+		if errors.Is(err, liveness.ErrSecretNotConfigured) {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("Liveness tokens are not configured")
+			return err
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Warn("Liveness proof rejected")
+		return auth.ErrInvalidLivenessProof
+	}
+
+	return nil
+}
@@ -1,5 +1,7 @@
 package budget_manager
 
+import "io"
+
 type CreateTransactionRequest struct {
 	UserID      string  `json:"user_id" validate:"required"`
 	Title       string  `json:"title" validate:"required"`
@@ -21,16 +23,29 @@ type UpdateTransactionRequest struct {
 }
 
 type TransactionResponse struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"user_id"`
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Nominal     float64 `json:"nominal"`
-	Type        string  `json:"type"`
-	Category    string  `json:"category"`
-	AudioLink   string  `json:"audio_link,omitempty"`
-	CreatedAt   string  `json:"created_at"`
-	UpdatedAt   string  `json:"updated_at"`
+	ID              string  `json:"id"`
+	UserID          string  `json:"user_id"`
+	Title           string  `json:"title"`
+	Description     string  `json:"description"`
+	Nominal         float64 `json:"nominal"`
+	Type            string  `json:"type"`
+	Category        string  `json:"category"`
+	AudioLink       string  `json:"audio_link,omitempty"`
+	AudioDurationMs int64   `json:"audio_duration_ms,omitempty"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+// AudioStream is the part of a voice note a playback request asked for,
+// from Start to End inclusive out of Size bytes. Partial is set when that is
+// less than the whole note because of a Range header.
+type AudioStream struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+	Start       int64
+	End         int64
+	Partial     bool
 }
 
 type TransactionListResponse struct {
@@ -12,7 +12,12 @@ var (
 	ErrCreateTransaction      = response.NewError(500, "failed to create transaction")
 	ErrUpdateTransaction      = response.NewError(500, "failed to update transaction")
 	ErrDeleteTransaction      = response.NewError(500, "failed to delete transaction")
-	ErrTransactionNotOwned    = response.NewError(403, "transaction does not belong to user")
 	ErrInvalidAudioFile       = response.NewError(400, "invalid audio file type")
+	ErrAudioFileTooLarge      = response.NewError(400, "audio file too large")
+	ErrUnreadableAudioFile    = response.NewError(400, "audio file could not be read")
+	ErrAudioTooLong           = response.NewError(400, "audio recording too long")
 	ErrFailedToUploadAudio    = response.NewError(500, "failed to upload audio file")
+	ErrAudioNotFound          = response.NewError(404, "transaction has no audio")
+	ErrRangeNotSatisfiable    = response.NewError(416, "requested range not satisfiable")
+	ErrStreamAudio            = response.NewError(500, "failed to stream audio file")
 )
@@ -7,6 +7,7 @@ import (
 	jwtPkg "ProjectGolang/pkg/jwt"
 	"ProjectGolang/pkg/log"
 	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/net/context"
 	"time"
@@ -74,22 +75,28 @@ func (h *BudgetHandler) GetTransactionByID(ctx *fiber.Ctx) error {
 			errors.New("transaction ID is required"), ctx.Path())
 	}
 
-	transaction, err := h.budgetService.GetTransactionByID(c, id)
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	transaction, err := h.budgetService.GetTransactionByID(c, id, userData.ID)
 	if err != nil {
 		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_transaction")
 	}
 
 	response := budget_manager.TransactionResponse{
-		ID:          transaction.ID,
-		UserID:      transaction.UserID,
-		Title:       transaction.Title,
-		Description: transaction.Description,
-		Nominal:     transaction.Nominal,
-		Type:        transaction.Type,
-		Category:    transaction.Category,
-		AudioLink:   transaction.AudioLink,
-		CreatedAt:   transaction.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   transaction.UpdatedAt.Format(time.RFC3339),
+		ID:              transaction.ID,
+		UserID:          transaction.UserID,
+		Title:           transaction.Title,
+		Description:     transaction.Description,
+		Nominal:         transaction.Nominal,
+		Type:            transaction.Type,
+		Category:        transaction.Category,
+		AudioLink:       transaction.AudioLink,
+		AudioDurationMs: transaction.AudioDurationMs,
+		CreatedAt:       transaction.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       transaction.UpdatedAt.Format(time.RFC3339),
 	}
 
 	select {
@@ -100,6 +107,54 @@ func (h *BudgetHandler) GetTransactionByID(ctx *fiber.Ctx) error {
 	}
 }
 
+// StreamAudio plays back the voice note of a transaction. It honours a single
+// byte range so players can seek and start playing before the whole note
+// has arrived.
+func (h *BudgetHandler) StreamAudio(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	id := ctx.Params("id")
+	if id == "" {
+		return errHandler.HandleValidationError(ctx, requestID,
+			errors.New("transaction ID is required"), ctx.Path())
+	}
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	stream, err := h.budgetService.StreamAudio(c, id, userData.ID, ctx.Get(fiber.HeaderRange))
+	if errors.Is(err, budget_manager.ErrRangeNotSatisfiable) {
+		ctx.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", stream.Size))
+	}
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "stream_audio")
+	}
+
+	select {
+	case <-c.Done():
+		stream.Body.Close()
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		ctx.Set(fiber.HeaderContentType, stream.ContentType)
+		ctx.Set(fiber.HeaderAcceptRanges, "bytes")
+		ctx.Set(fiber.HeaderCacheControl, "private, no-store")
+
+		status := fiber.StatusOK
+		if stream.Partial {
+			status = fiber.StatusPartialContent
+			ctx.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", stream.Start, stream.End, stream.Size))
+		}
+		// The body is closed once it has been sent.
+		return ctx.Status(status).SendStream(stream.Body, int(stream.End-stream.Start+1))
+	}
+}
+
 func (h *BudgetHandler) GetTransactionsByUserID(ctx *fiber.Ctx) error {
 	requestID := h.middleware.GetRequestID(ctx)
 	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
@@ -130,16 +185,17 @@ func (h *BudgetHandler) GetTransactionsByUserID(ctx *fiber.Ctx) error {
 
 	for _, transaction := range transactions {
 		transactionResponses = append(transactionResponses, budget_manager.TransactionResponse{
-			ID:          transaction.ID,
-			UserID:      transaction.UserID,
-			Title:       transaction.Title,
-			Description: transaction.Description,
-			Nominal:     transaction.Nominal,
-			Type:        transaction.Type,
-			Category:    transaction.Category,
-			AudioLink:   transaction.AudioLink,
-			CreatedAt:   transaction.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   transaction.UpdatedAt.Format(time.RFC3339),
+			ID:              transaction.ID,
+			UserID:          transaction.UserID,
+			Title:           transaction.Title,
+			Description:     transaction.Description,
+			Nominal:         transaction.Nominal,
+			Type:            transaction.Type,
+			Category:        transaction.Category,
+			AudioLink:       transaction.AudioLink,
+			AudioDurationMs: transaction.AudioDurationMs,
+			CreatedAt:       transaction.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:       transaction.UpdatedAt.Format(time.RFC3339),
 		})
 
 		if transaction.Type == "income" {
@@ -201,16 +257,17 @@ func (h *BudgetHandler) GetTransactionsByPeriod(ctx *fiber.Ctx) error {
 
 	for _, transaction := range transactions {
 		transactionResponses = append(transactionResponses, budget_manager.TransactionResponse{
-			ID:          transaction.ID,
-			UserID:      transaction.UserID,
-			Title:       transaction.Title,
-			Description: transaction.Description,
-			Nominal:     transaction.Nominal,
-			Type:        transaction.Type,
-			Category:    transaction.Category,
-			AudioLink:   transaction.AudioLink,
-			CreatedAt:   transaction.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   transaction.UpdatedAt.Format(time.RFC3339),
+			ID:              transaction.ID,
+			UserID:          transaction.UserID,
+			Title:           transaction.Title,
+			Description:     transaction.Description,
+			Nominal:         transaction.Nominal,
+			Type:            transaction.Type,
+			Category:        transaction.Category,
+			AudioLink:       transaction.AudioLink,
+			AudioDurationMs: transaction.AudioDurationMs,
+			CreatedAt:       transaction.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:       transaction.UpdatedAt.Format(time.RFC3339),
 		})
 
 		if transaction.Type == "income" {
@@ -351,16 +408,17 @@ func (h *BudgetHandler) GetTransactionsByTypeAndCategory(ctx *fiber.Ctx) error {
 
 	for _, transaction := range transactions {
 		transactionResponses = append(transactionResponses, budget_manager.TransactionResponse{
-			ID:          transaction.ID,
-			UserID:      transaction.UserID,
-			Title:       transaction.Title,
-			Description: transaction.Description,
-			Nominal:     transaction.Nominal,
-			Type:        transaction.Type,
-			Category:    transaction.Category,
-			AudioLink:   transaction.AudioLink,
-			CreatedAt:   transaction.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   transaction.UpdatedAt.Format(time.RFC3339),
+			ID:              transaction.ID,
+			UserID:          transaction.UserID,
+			Title:           transaction.Title,
+			Description:     transaction.Description,
+			Nominal:         transaction.Nominal,
+			Type:            transaction.Type,
+			Category:        transaction.Category,
+			AudioLink:       transaction.AudioLink,
+			AudioDurationMs: transaction.AudioDurationMs,
+			CreatedAt:       transaction.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:       transaction.UpdatedAt.Format(time.RFC3339),
 		})
 
 		total += transaction.Nominal
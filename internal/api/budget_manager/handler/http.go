@@ -36,7 +36,8 @@ func (h *BudgetHandler) Start(srv fiber.Router) {
 	budget.Get("/transactions", h.middleware.NewTokenMiddleware, h.GetTransactionsByUserID)
 	budget.Get("/transactions/period", h.middleware.NewTokenMiddleware, h.GetTransactionsByPeriod)
 	budget.Get("/transactions/filter", h.middleware.NewTokenMiddleware, h.GetTransactionsByTypeAndCategory)
-	budget.Get("/transactions/:id", h.GetTransactionByID)
+	budget.Get("/transactions/:id", h.middleware.NewTokenMiddleware, h.GetTransactionByID)
+	budget.Get("/transactions/:id/audio", h.middleware.NewTokenMiddleware, h.StreamAudio)
 	budget.Put("/transactions", h.middleware.NewTokenMiddleware, h.UpdateTransaction)
 	budget.Delete("/transactions/:id", h.middleware.NewTokenMiddleware, h.DeleteTransaction)
 }
@@ -13,31 +13,33 @@ import (
 )
 
 type BudgetTransactionDB struct {
-	ID          sql.NullString  `db:"id"`
-	UserID      sql.NullString  `db:"user_id"`
-	Title       sql.NullString  `db:"title"`
-	Description sql.NullString  `db:"description"`
-	Nominal     sql.NullFloat64 `db:"nominal"`
-	Type        sql.NullString  `db:"type"`
-	Category    sql.NullString  `db:"category"`
-	AudioLink   sql.NullString  `db:"audio_link"`
-	CreatedAt   time.Time       `db:"created_at"`
-	UpdatedAt   time.Time       `db:"updated_at"`
+	ID              sql.NullString  `db:"id"`
+	UserID          sql.NullString  `db:"user_id"`
+	Title           sql.NullString  `db:"title"`
+	Description     sql.NullString  `db:"description"`
+	Nominal         sql.NullFloat64 `db:"nominal"`
+	Type            sql.NullString  `db:"type"`
+	Category        sql.NullString  `db:"category"`
+	AudioLink       sql.NullString  `db:"audio_link"`
+	AudioDurationMs sql.NullInt64   `db:"audio_duration_ms"`
+	CreatedAt       time.Time       `db:"created_at"`
+	UpdatedAt       time.Time       `db:"updated_at"`
 }
 
 func (r *budgetRepository) CreateTransaction(c context.Context, transaction entity.BudgetTransaction) error {
 	requestID := contextPkg.GetRequestID(c)
 	argsKV := map[string]interface{}{
-		"id":          transaction.ID,
-		"user_id":     transaction.UserID,
-		"title":       transaction.Title,
-		"description": transaction.Description,
-		"nominal":     transaction.Nominal,
-		"type":        transaction.Type,
-		"category":    transaction.Category,
-		"audio_link":  transaction.AudioLink,
-		"created_at":  time.Now(),
-		"updated_at":  time.Now(),
+		"id":                transaction.ID,
+		"user_id":           transaction.UserID,
+		"title":             transaction.Title,
+		"description":       transaction.Description,
+		"nominal":           transaction.Nominal,
+		"type":              transaction.Type,
+		"category":          transaction.Category,
+		"audio_link":        transaction.AudioLink,
+		"audio_duration_ms": audioDurationMs(transaction),
+		"created_at":        time.Now(),
+		"updated_at":        time.Now(),
 	}
 
 	query, args, err := sqlx.Named(queryCreateTransaction, argsKV)
@@ -63,12 +65,13 @@ func (r *budgetRepository) CreateTransaction(c context.Context, transaction enti
 	return nil
 }
 
-func (r *budgetRepository) GetTransactionByID(c context.Context, id string) (entity.BudgetTransaction, error) {
+func (r *budgetRepository) GetTransactionByID(c context.Context, id string, userID string) (entity.BudgetTransaction, error) {
 	requestID := contextPkg.GetRequestID(c)
 	var transaction BudgetTransactionDB
 
 	argsKV := map[string]interface{}{
-		"id": id,
+		"id":      id,
+		"user_id": userID,
 	}
 
 	query, args, err := sqlx.Named(queryGetTransactionById, argsKV)
@@ -141,14 +144,16 @@ func (r *budgetRepository) GetTransactionsByUserID(c context.Context, userID str
 func (r *budgetRepository) UpdateTransaction(c context.Context, transaction entity.BudgetTransaction) error {
 	requestID := contextPkg.GetRequestID(c)
 	argsKV := map[string]interface{}{
-		"id":          transaction.ID,
-		"title":       transaction.Title,
-		"description": transaction.Description,
-		"nominal":     transaction.Nominal,
-		"type":        transaction.Type,
-		"category":    transaction.Category,
-		"audio_link":  transaction.AudioLink,
-		"updated_at":  time.Now(),
+		"id":                transaction.ID,
+		"user_id":           transaction.UserID,
+		"title":             transaction.Title,
+		"description":       transaction.Description,
+		"nominal":           transaction.Nominal,
+		"type":              transaction.Type,
+		"category":          transaction.Category,
+		"audio_link":        transaction.AudioLink,
+		"audio_duration_ms": audioDurationMs(transaction),
+		"updated_at":        time.Now(),
 	}
 
 	query, args, err := sqlx.Named(queryUpdateTransaction, argsKV)
@@ -194,10 +199,11 @@ func (r *budgetRepository) UpdateTransaction(c context.Context, transaction enti
 	return nil
 }
 
-func (r *budgetRepository) DeleteTransaction(ctx context.Context, id string) error {
+func (r *budgetRepository) DeleteTransaction(ctx context.Context, id string, userID string) error {
 	requestID := contextPkg.GetRequestID(ctx)
 	argsKV := map[string]interface{}{
-		"id": id,
+		"id":      id,
+		"user_id": userID,
 	}
 
 	query, args, err := sqlx.Named(queryDeleteTransaction, argsKV)
@@ -333,17 +339,27 @@ func (r *budgetRepository) GetTransactionsByPeriod(ctx context.Context, userID s
 	return result, nil
 }
 
+// audioDurationMs leaves the duration empty for transactions without audio
+// and for notes recorded before durations were kept.
+func audioDurationMs(transaction entity.BudgetTransaction) sql.NullInt64 {
+	return sql.NullInt64{
+		Int64: transaction.AudioDurationMs,
+		Valid: transaction.AudioDurationMs > 0,
+	}
+}
+
 func (r *budgetRepository) makeBudgetTransaction(transaction BudgetTransactionDB) entity.BudgetTransaction {
 	return entity.BudgetTransaction{
-		ID:          transaction.ID.String,
-		UserID:      transaction.UserID.String,
-		Title:       transaction.Title.String,
-		Description: transaction.Description.String,
-		Nominal:     transaction.Nominal.Float64,
-		Type:        transaction.Type.String,
-		Category:    transaction.Category.String,
-		AudioLink:   transaction.AudioLink.String,
-		CreatedAt:   transaction.CreatedAt,
-		UpdatedAt:   transaction.UpdatedAt,
+		ID:              transaction.ID.String,
+		UserID:          transaction.UserID.String,
+		Title:           transaction.Title.String,
+		Description:     transaction.Description.String,
+		Nominal:         transaction.Nominal.Float64,
+		Type:            transaction.Type.String,
+		Category:        transaction.Category.String,
+		AudioLink:       transaction.AudioLink.String,
+		AudioDurationMs: transaction.AudioDurationMs.Int64,
+		CreatedAt:       transaction.CreatedAt,
+		UpdatedAt:       transaction.UpdatedAt,
 	}
 }
@@ -11,6 +11,7 @@ const (
 			type,
 			category,
 			audio_link,
+			audio_duration_ms,
 			created_at,
 			updated_at
 		) VALUES (
@@ -22,6 +23,7 @@ const (
 			:type,
 			:category,
 			:audio_link,
+			:audio_duration_ms,
 			:created_at,
 			:updated_at
 		)
@@ -37,6 +39,7 @@ const (
 			type,
 			category,
 			audio_link,
+			audio_duration_ms,
 			created_at,
 			updated_at
 		FROM budget_transactions
@@ -54,6 +57,7 @@ const (
 			type,
 			category,
 			audio_link,
+			audio_duration_ms,
 			created_at,
 			updated_at
 		FROM budget_transactions
@@ -74,6 +78,7 @@ const (
 			type,
 			category,
 			audio_link,
+			audio_duration_ms,
 			created_at,
 			updated_at
 		FROM budget_transactions
@@ -94,10 +99,11 @@ const (
 			type,
 			category,
 			audio_link,
+			audio_duration_ms,
 			created_at,
 			updated_at
 		FROM budget_transactions
-		WHERE id = :id
+		WHERE id = :id AND user_id = :user_id
 	`
 
 	queryGetTransactionsByUserID = `
@@ -110,6 +116,7 @@ const (
 			type,
 			category,
 			audio_link,
+			audio_duration_ms,
 			created_at,
 			updated_at
 		FROM budget_transactions
@@ -126,13 +133,14 @@ const (
 			type = :type,
 			category = :category,
 			audio_link = :audio_link,
+			audio_duration_ms = :audio_duration_ms,
 			updated_at = :updated_at
-		WHERE id = :id
+		WHERE id = :id AND user_id = :user_id
 	`
 
 	queryDeleteTransaction = `
 		DELETE FROM budget_transactions
-		WHERE id = :id
+		WHERE id = :id AND user_id = :user_id
 	`
 
 	queryGetTransactionsByTypeAndCategory = `
@@ -145,6 +153,7 @@ const (
 			type,
 			category,
 			audio_link,
+			audio_duration_ms,
 			created_at,
 			updated_at
 		FROM budget_transactions
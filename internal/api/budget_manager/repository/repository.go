@@ -58,14 +58,18 @@ func (r *repository) NewClient(tx bool) (Client, error) {
 	}, nil
 }
 
+// Client exposes the budget queries. Every lookup or mutation of a single
+// transaction is scoped to its owner, so a transaction belonging to another
+// user is reported as budget_manager.ErrTransactionNotFound rather than
+// leaking its existence.
 type Client struct {
 	Budget interface {
 		CreateTransaction(c context.Context, transaction entity.BudgetTransaction) error
-		GetTransactionByID(c context.Context, id string) (entity.BudgetTransaction, error)
+		GetTransactionByID(c context.Context, id string, userID string) (entity.BudgetTransaction, error)
 		GetTransactionsByUserID(c context.Context, userID string) ([]entity.BudgetTransaction, error)
 		GetTransactionsByPeriod(ctx context.Context, userID string, period string) ([]entity.BudgetTransaction, error)
 		UpdateTransaction(c context.Context, transaction entity.BudgetTransaction) error
-		DeleteTransaction(ctx context.Context, id string) error
+		DeleteTransaction(ctx context.Context, id string, userID string) error
 		GetTransactionsByTypeAndCategory(ctx context.Context, userID string, transactionType string, category string) ([]entity.BudgetTransaction, error)
 	}
 
@@ -0,0 +1,282 @@
+package budgetService
+
+import (
+	"ProjectGolang/internal/api/budget_manager"
+	"ProjectGolang/internal/jobs"
+	"ProjectGolang/pkg/audio"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/s3"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxAudioDuration is the longest voice note accepted.
+const maxAudioDuration = 5 * time.Minute
+
+// storeAudio checks that an uploaded voice note really is audio in a format
+// the app can play and no longer than maxAudioDuration, then stores it. It
+// returns the key of the note and how long it lasts.
+func (s *budgetService) storeAudio(ctx context.Context, userID string, file *multipart.FileHeader) (string, time.Duration, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	maxSize := s3.MaxSize(s3.PurposeAudio)
+	if file.Size > maxSize {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"size":       file.Size,
+		}).Warn("Audio file too large")
+		return "", 0, budget_manager.ErrAudioFileTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to open uploaded audio file")
+		return "", 0, budget_manager.ErrFailedToUploadAudio
+	}
+	data, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	src.Close()
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to read uploaded audio file")
+		return "", 0, budget_manager.ErrFailedToUploadAudio
+	}
+	if int64(len(data)) > maxSize {
+		return "", 0, budget_manager.ErrAudioFileTooLarge
+	}
+
+	info, err := audio.Probe(data)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"filename":   file.Filename,
+			"error":      err.Error(),
+		}).Warn("Audio file rejected")
+		if rejected := audioError(err); rejected != nil {
+			return "", 0, rejected
+		}
+		return "", 0, budget_manager.ErrInvalidAudioFile
+	}
+
+	if info.Duration > maxAudioDuration {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"duration":   info.Duration.String(),
+		}).Warn("Audio recording too long")
+		return "", 0, budget_manager.ErrAudioTooLong
+	}
+
+	audioKey, err := s.s3.UploadFile(userID, s3.PurposeAudio, file)
+	if err != nil {
+		if rejected := audioError(err); rejected != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Warn("Audio file rejected by storage")
+			return "", 0, rejected
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to upload audio file")
+		return "", 0, budget_manager.ErrFailedToUploadAudio
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"user_id":    userID,
+		"container":  info.Container,
+		"codec":      info.Codec,
+		"duration":   info.Duration.String(),
+	}).Debug("Stored audio file")
+
+	return audioKey, info.Duration, nil
+}
+
+// StreamAudio opens the voice note of a transaction for playback, limited to
+// the byte range asked for so players can seek without downloading the whole
+// note. For an unsatisfiable range it returns ErrRangeNotSatisfiable along
+// with the size of the note.
+func (s *budgetService) StreamAudio(ctx context.Context, id string, userID string, rangeHeader string) (budget_manager.AudioStream, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.budgetRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create new client")
+		return budget_manager.AudioStream{}, err
+	}
+
+	transaction, err := repo.Budget.GetTransactionByID(ctx, id, userID)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"id":         id,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Warn("Failed to get transaction by ID")
+		return budget_manager.AudioStream{}, err
+	}
+	if transaction.AudioLink == "" {
+		return budget_manager.AudioStream{}, budget_manager.ErrAudioNotFound
+	}
+
+	file, err := s.s3.Stat(userID, transaction.AudioLink)
+	if err != nil {
+		if errors.Is(err, s3.ErrNotFound) || errors.Is(err, s3.ErrNotOwner) {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"id":         id,
+				"error":      err.Error(),
+			}).Warn("Audio file missing from storage")
+			return budget_manager.AudioStream{}, budget_manager.ErrAudioNotFound
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"id":         id,
+			"error":      err.Error(),
+		}).Error("Failed to stat audio file")
+		return budget_manager.AudioStream{}, budget_manager.ErrStreamAudio
+	}
+
+	stream := budget_manager.AudioStream{
+		ContentType: file.ContentType,
+		Size:        file.Size,
+	}
+
+	start, end, partial, ok := parseRange(rangeHeader, file.Size)
+	if !ok {
+		return stream, budget_manager.ErrRangeNotSatisfiable
+	}
+	stream.Start, stream.End, stream.Partial = start, end, partial
+
+	stream.Body, err = s.s3.Open(userID, transaction.AudioLink, start, end-start+1)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"id":         id,
+			"error":      err.Error(),
+		}).Error("Failed to open audio file")
+		return budget_manager.AudioStream{}, budget_manager.ErrStreamAudio
+	}
+
+	return stream, nil
+}
+
+// parseRange resolves a Range header against a file of size bytes, returning
+// the inclusive range to send and whether it is less than the whole file.
+// Only a single byte range is honoured; headers asking for anything else get
+// the whole file, as the standard allows. ok is false when the range lies
+// entirely past the end of the file.
+func parseRange(header string, size int64) (start int64, end int64, partial bool, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, size - 1, false, true
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, size - 1, false, true
+	}
+
+	// A suffix range asks for the last bytes of the file.
+	if first == "" {
+		length, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || length < 0 {
+			return 0, size - 1, false, true
+		}
+		if length == 0 || size == 0 {
+			return 0, 0, false, false
+		}
+		return max(size-length, 0), size - 1, true, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, size - 1, false, true
+	}
+	if start >= size {
+		return 0, 0, false, false
+	}
+
+	end = size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, size - 1, false, true
+		}
+		end = min(end, size-1)
+	}
+
+	return start, end, true, true
+}
+
+// deleteAudio queues the removal of an audio file that no transaction uses
+// any more. The change it follows has already been made, so a failure to
+// queue only leaves the file behind and is logged.
+func (s *budgetService) deleteAudio(ctx context.Context, audioLink string) {
+	if audioLink == "" {
+		return
+	}
+
+	if err := s.jobs.Enqueue(ctx, jobs.DeleteFile{FileName: audioLink}); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"fileName":   audioLink,
+			"error":      err.Error(),
+		}).Error("Failed to queue audio file deletion")
+	}
+}
+
+// presignAudio turns the stored key of a voice note into a short-lived link
+// for its owner. A note that cannot be linked is left out rather than failing
+// the transactions around it.
+func (s *budgetService) presignAudio(ctx context.Context, userID string, audioKey string) string {
+	if audioKey == "" {
+		return ""
+	}
+
+	audioLink, err := s.s3.PresignUrl(userID, audioKey)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Warn("Failed to presign audio link")
+		return ""
+	}
+
+	return audioLink
+}
+
+// audioError returns the error for an audio file the probe or storage
+// refused, or nil if they failed for another reason.
+func audioError(err error) error {
+	switch {
+	case errors.Is(err, s3.ErrFileTooLarge):
+		return budget_manager.ErrAudioFileTooLarge
+	case errors.Is(err, s3.ErrUnsupportedFileType), errors.Is(err, audio.ErrUnsupportedAudio):
+		return budget_manager.ErrInvalidAudioFile
+	case errors.Is(err, audio.ErrInvalidAudio):
+		return budget_manager.ErrUnreadableAudioFile
+	}
+	return nil
+}
@@ -2,15 +2,12 @@ package budgetService
 
 import (
 	"ProjectGolang/internal/api/budget_manager"
+	"ProjectGolang/internal/api/notification"
 	"ProjectGolang/internal/entity"
 	contextPkg "ProjectGolang/pkg/context"
-	"errors"
-	"fmt"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 	"mime/multipart"
-	"path/filepath"
-	"strings"
 	"time"
 )
 
@@ -26,6 +23,7 @@ func (s *budgetService) CreateTransaction(ctx context.Context, req budget_manage
 		return err
 	}
 	var audioLink string
+	var audioDurationMs int64
 
 	if !entity.IsValidCategory(req.Type, req.Category) {
 		s.log.WithFields(logrus.Fields{
@@ -36,25 +34,13 @@ func (s *budgetService) CreateTransaction(ctx context.Context, req budget_manage
 		return budget_manager.ErrInvalidCategory
 	}
 
-	var fileName string
 	if audioFile != nil {
-		if !isAudioFile(audioFile.Filename) {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"filename":   audioFile.Filename,
-			}).Warn("Invalid audio file type")
-			return errors.New("invalid audio file type")
-		}
-
-		uploadedFileURL, err := s.s3.UploadFile(audioFile)
+		audioKey, duration, err := s.storeAudio(ctx, req.UserID, audioFile)
 		if err != nil {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"error":      err.Error(),
-			}).Error("Failed to upload audio file")
 			return err
 		}
-		audioLink = uploadedFileURL
+		audioLink = audioKey
+		audioDurationMs = duration.Milliseconds()
 	}
 
 	ULID, err := s.utils.NewULIDFromTimestamp(time.Now())
@@ -67,16 +53,17 @@ func (s *budgetService) CreateTransaction(ctx context.Context, req budget_manage
 	}
 
 	transaction := entity.BudgetTransaction{
-		ID:          ULID,
-		UserID:      req.UserID,
-		Title:       req.Title,
-		Description: req.Description,
-		Nominal:     req.Nominal,
-		Type:        req.Type,
-		Category:    req.Category,
-		AudioLink:   audioLink,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:              ULID,
+		UserID:          req.UserID,
+		Title:           req.Title,
+		Description:     req.Description,
+		Nominal:         req.Nominal,
+		Type:            req.Type,
+		Category:        req.Category,
+		AudioLink:       audioLink,
+		AudioDurationMs: audioDurationMs,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
 	if err := transaction.Validate(); err != nil {
@@ -93,22 +80,39 @@ func (s *budgetService) CreateTransaction(ctx context.Context, req budget_manage
 			"error":      err.Error(),
 		}).Error("Failed to create transaction")
 
-		if audioLink != "" {
-			if deleteErr := s.s3.DeleteFile(fileName); deleteErr != nil {
-				s.log.WithFields(logrus.Fields{
-					"request_id": requestID,
-					"error":      deleteErr.Error(),
-				}).Error("Failed to delete audio file after transaction creation failure")
-			}
-		}
+		s.deleteAudio(ctx, audioLink)
 
 		return budget_manager.ErrCreateTransaction
 	}
 
+	s.notifyTransactionRecorded(ctx, transaction)
+
 	return nil
 }
 
-func (s *budgetService) GetTransactionByID(ctx context.Context, id string) (entity.BudgetTransaction, error) {
+// notifyTransactionRecorded confirms a new transaction to the user. The
+// transaction is already stored, so a failed notification is only logged.
+func (s *budgetService) notifyTransactionRecorded(ctx context.Context, transaction entity.BudgetTransaction) {
+	err := s.notifier.Notify(ctx, notification.Message{
+		Template: notification.TemplateBudgetTransactionRecorded,
+		UserID:   transaction.UserID,
+		Data: map[string]interface{}{
+			"title":    transaction.Title,
+			"nominal":  transaction.Nominal,
+			"type":     transaction.Type,
+			"category": transaction.Category,
+		},
+	})
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"user_id":    transaction.UserID,
+			"error":      err.Error(),
+		}).Warn("Failed to send budget notification")
+	}
+}
+
+func (s *budgetService) GetTransactionByID(ctx context.Context, id string, userID string) (entity.BudgetTransaction, error) {
 	requestID := contextPkg.GetRequestID(ctx)
 
 	repo, err := s.budgetRepository.NewClient(false)
@@ -120,26 +124,19 @@ func (s *budgetService) GetTransactionByID(ctx context.Context, id string) (enti
 		return entity.BudgetTransaction{}, err
 	}
 
-	transaction, err := repo.Budget.GetTransactionByID(ctx, id)
+	transaction, err := repo.Budget.GetTransactionByID(ctx, id, userID)
 	if err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"id":         id,
+			"user_id":    userID,
 			"error":      err.Error(),
 		}).Error("Failed to get transaction by ID")
 		return entity.BudgetTransaction{}, err
 	}
 
-	audiolink, err := s.s3.PresignUrl(transaction.AudioLink)
-	if err != nil {
-		s.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-			"error":      err.Error(),
-		}).Error("Failed to presign audio link")
-		return entity.BudgetTransaction{}, err
-	}
+	transaction.AudioLink = s.presignAudio(ctx, userID, transaction.AudioLink)
 
-	transaction.AudioLink = audiolink
 	return transaction, nil
 }
 
@@ -165,19 +162,8 @@ func (s *budgetService) GetTransactionsByUserID(ctx context.Context, userID stri
 		return nil, err
 	}
 
-	for i, transaction := range transactions {
-		if transaction.AudioLink != "" {
-			audiolink, err := s.s3.PresignUrl(transaction.AudioLink)
-			if err != nil {
-				s.log.WithFields(logrus.Fields{
-					"request_id": requestID,
-					"error":      err.Error(),
-				}).Error("Failed to presign audio link")
-				return nil, err
-			}
-			transactions[i].AudioLink = audiolink
-			fmt.Println("Audio link presigned:", transactions[i].AudioLink)
-		}
+	for i := range transactions {
+		transactions[i].AudioLink = s.presignAudio(ctx, userID, transactions[i].AudioLink)
 	}
 
 	return transactions, nil
@@ -213,6 +199,10 @@ func (s *budgetService) GetTransactionsByPeriod(ctx context.Context, userID stri
 		return nil, err
 	}
 
+	for i := range transactions {
+		transactions[i].AudioLink = s.presignAudio(ctx, userID, transactions[i].AudioLink)
+	}
+
 	return transactions, nil
 }
 
@@ -237,85 +227,45 @@ func (s *budgetService) UpdateTransaction(ctx context.Context, req budget_manage
 		return budget_manager.ErrInvalidCategory
 	}
 
-	existingTransaction, err := repo.Budget.GetTransactionByID(ctx, req.ID)
+	existingTransaction, err := repo.Budget.GetTransactionByID(ctx, req.ID, req.UserID)
 	if err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"id":         req.ID,
+			"user_id":    req.UserID,
 			"error":      err.Error(),
-		}).Error("Failed to get existing transaction")
+		}).Warn("Failed to get existing transaction")
 		return err
 	}
 
-	if existingTransaction.UserID != req.UserID {
-		s.log.WithFields(logrus.Fields{
-			"request_id":          requestID,
-			"transaction_user_id": existingTransaction.UserID,
-			"request_user_id":     req.UserID,
-		}).Warn("Transaction does not belong to user")
-		return errors.New("transaction does not belong to user")
-	}
-
 	audioLink := existingTransaction.AudioLink
+	audioDurationMs := existingTransaction.AudioDurationMs
 
-	if req.DeleteAudio && audioLink != "" {
-		parts := strings.Split(audioLink, "/")
-		fileName := parts[len(parts)-1]
-
-		if err := s.s3.DeleteFile(fileName); err != nil {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"fileName":   fileName,
-				"error":      err.Error(),
-			}).Error("Failed to delete audio file")
-		}
-
+	if req.DeleteAudio {
 		audioLink = ""
+		audioDurationMs = 0
 	}
 
 	if audioFile != nil {
-		if !isAudioFile(audioFile.Filename) {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"filename":   audioFile.Filename,
-			}).Warn("Invalid audio file type")
-			return errors.New("invalid audio file type")
-		}
-
-		if audioLink != "" {
-			parts := strings.Split(audioLink, "/")
-			fileName := parts[len(parts)-1]
-
-			if err := s.s3.DeleteFile(fileName); err != nil {
-				s.log.WithFields(logrus.Fields{
-					"request_id": requestID,
-					"fileName":   fileName,
-					"error":      err.Error(),
-				}).Error("Failed to delete existing audio file")
-			}
-		}
-
-		uploadedFileURL, err := s.s3.UploadFile(audioFile)
+		audioKey, duration, err := s.storeAudio(ctx, req.UserID, audioFile)
 		if err != nil {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"error":      err.Error(),
-			}).Error("Failed to upload audio file")
 			return err
 		}
-		audioLink = uploadedFileURL
+		audioLink = audioKey
+		audioDurationMs = duration.Milliseconds()
 	}
 
 	transaction := entity.BudgetTransaction{
-		ID:          req.ID,
-		UserID:      req.UserID,
-		Title:       req.Title,
-		Description: req.Description,
-		Nominal:     req.Nominal,
-		Type:        req.Type,
-		Category:    req.Category,
-		AudioLink:   audioLink,
-		UpdatedAt:   time.Now(),
+		ID:              req.ID,
+		UserID:          req.UserID,
+		Title:           req.Title,
+		Description:     req.Description,
+		Nominal:         req.Nominal,
+		Type:            req.Type,
+		Category:        req.Category,
+		AudioLink:       audioLink,
+		AudioDurationMs: audioDurationMs,
+		UpdatedAt:       time.Now(),
 	}
 
 	if err := transaction.Validate(); err != nil {
@@ -331,9 +281,17 @@ func (s *budgetService) UpdateTransaction(ctx context.Context, req budget_manage
 			"request_id": requestID,
 			"error":      err.Error(),
 		}).Error("Failed to update transaction")
+		if audioLink != existingTransaction.AudioLink {
+			s.deleteAudio(ctx, audioLink)
+		}
 		return budget_manager.ErrUpdateTransaction
 	}
 
+	// The old recording is removed only once nothing points at it any more.
+	if existingTransaction.AudioLink != audioLink {
+		s.deleteAudio(ctx, existingTransaction.AudioLink)
+	}
+
 	return nil
 }
 
@@ -349,40 +307,18 @@ func (s *budgetService) DeleteTransaction(ctx context.Context, id string, userID
 		return err
 	}
 
-	existingTransaction, err := repo.Budget.GetTransactionByID(ctx, id)
+	existingTransaction, err := repo.Budget.GetTransactionByID(ctx, id, userID)
 	if err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"id":         id,
+			"user_id":    userID,
 			"error":      err.Error(),
-		}).Error("Failed to get existing transaction")
+		}).Warn("Failed to get existing transaction")
 		return err
 	}
 
-	if existingTransaction.UserID != userID {
-		s.log.WithFields(logrus.Fields{
-			"request_id":          requestID,
-			"transaction_user_id": existingTransaction.UserID,
-			"request_user_id":     userID,
-		}).Warn("Transaction does not belong to user")
-		return errors.New("transaction does not belong to user")
-	}
-
-	if existingTransaction.AudioLink != "" {
-		parts := strings.Split(existingTransaction.AudioLink, "/")
-		fileName := parts[len(parts)-1]
-
-		fmt.Println("Deleting file:", fileName)
-		if err := s.s3.DeleteFile(fileName); err != nil {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"fileName":   fileName,
-				"error":      err.Error(),
-			}).Error("Failed to delete audio file")
-		}
-	}
-
-	if err := repo.Budget.DeleteTransaction(ctx, id); err != nil {
+	if err := repo.Budget.DeleteTransaction(ctx, id, userID); err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"error":      err.Error(),
@@ -390,6 +326,8 @@ func (s *budgetService) DeleteTransaction(ctx context.Context, id string, userID
 		return budget_manager.ErrDeleteTransaction
 	}
 
+	s.deleteAudio(ctx, existingTransaction.AudioLink)
+
 	return nil
 }
 
@@ -434,18 +372,9 @@ func (s *budgetService) GetTransactionsByTypeAndCategory(ctx context.Context, us
 		return nil, err
 	}
 
-	return transactions, nil
-}
-
-func isAudioFile(filename string) bool {
-	ext := strings.ToLower(filepath.Ext(filename))
-	validExtensions := map[string]bool{
-		".mp3":  true,
-		".wav":  true,
-		".ogg":  true,
-		".m4a":  true,
-		".flac": true,
+	for i := range transactions {
+		transactions[i].AudioLink = s.presignAudio(ctx, userID, transactions[i].AudioLink)
 	}
 
-	return validExtensions[ext]
+	return transactions, nil
 }
@@ -3,7 +3,9 @@ package budgetService
 import (
 	"ProjectGolang/internal/api/budget_manager"
 	budgetRepository "ProjectGolang/internal/api/budget_manager/repository"
+	"ProjectGolang/internal/api/notification"
 	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/jobs"
 	"ProjectGolang/pkg/s3"
 	"ProjectGolang/pkg/utils"
 	"github.com/sirupsen/logrus"
@@ -13,26 +15,31 @@ import (
 
 type IBudgetService interface {
 	CreateTransaction(ctx context.Context, req budget_manager.CreateTransactionRequest, audioFile *multipart.FileHeader) error
-	GetTransactionByID(ctx context.Context, id string) (entity.BudgetTransaction, error)
+	GetTransactionByID(ctx context.Context, id string, userID string) (entity.BudgetTransaction, error)
 	GetTransactionsByUserID(ctx context.Context, userID string) ([]entity.BudgetTransaction, error)
 	GetTransactionsByPeriod(ctx context.Context, userID string, period string) ([]entity.BudgetTransaction, error)
 	UpdateTransaction(ctx context.Context, req budget_manager.UpdateTransactionRequest, audioFile *multipart.FileHeader) error
 	DeleteTransaction(ctx context.Context, id string, userID string) error
 	GetTransactionsByTypeAndCategory(ctx context.Context, userID string, transactionType string, category string) ([]entity.BudgetTransaction, error)
+	StreamAudio(ctx context.Context, id string, userID string, rangeHeader string) (budget_manager.AudioStream, error)
 }
 
 type budgetService struct {
 	log              *logrus.Logger
 	budgetRepository budgetRepository.Repository
 	s3               s3.ItfS3
+	jobs             jobs.Enqueuer
+	notifier         notification.Notifier
 	utils            utils.IUtils
 }
 
-func NewBudgetService(log *logrus.Logger, br budgetRepository.Repository, s3 s3.ItfS3, utils utils.IUtils) IBudgetService {
+func NewBudgetService(log *logrus.Logger, br budgetRepository.Repository, s3 s3.ItfS3, jobs jobs.Enqueuer, notifier notification.Notifier, utils utils.IUtils) IBudgetService {
 	return &budgetService{
 		log:              log,
 		budgetRepository: br,
 		s3:               s3,
+		jobs:             jobs,
+		notifier:         notifier,
 		utils:            utils,
 	}
 }
@@ -1,8 +1,16 @@
 package detection
 
+import (
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/pkg/liveness"
+	"time"
+)
+
 type KTP struct {
-	NIK              string `json:"nik"`
-	Nama             string `json:"nama"`
+	Provinsi         string `json:"provinsi"`
+	KabupatenKota    string `json:"kabupaten_kota"`
+	NIK              string `json:"nik" validate:"required,len=16,numeric"`
+	Nama             string `json:"nama" validate:"required"`
 	TempatLahir      string `json:"tempat_lahir"`
 	TanggalLahir     string `json:"tanggal_lahir"`
 	JenisKelamin     string `json:"jenis_kelamin"`
@@ -19,6 +27,208 @@ type KTP struct {
 	BerlakuHingga    string `json:"berlaku_hingga"`
 }
 
+// KTPProfile is a KTP mapped onto the user profile fields, with dates
+// normalized to YYYY-MM-DD. Province and Regency come from the card header and
+// are only checked against the NIK, not saved.
+type KTPProfile struct {
+	Province                  string `json:"province"`
+	Regency                   string `json:"regency"`
+	Name                      string `json:"name"`
+	NationalIdentityNumber    string `json:"national_identity_number"`
+	BirthPlace                string `json:"birth_place"`
+	BirthDate                 string `json:"birth_date"`
+	Gender                    string `json:"gender"`
+	Address                   string `json:"address"`
+	NeighborhoodCommunityUnit string `json:"neighborhood_community_unit"`
+	Village                   string `json:"village"`
+	District                  string `json:"district"`
+	Religion                  string `json:"religion"`
+	MaritalStatus             string `json:"marital_status"`
+	Profession                string `json:"profession"`
+	Citizenship               string `json:"citizenship"`
+	CardValidUntil            string `json:"card_valid_until"`
+}
+
+// KTPDraft is an extracted KTP awaiting the user's confirmation before it is
+// written to their profile.
+type KTPDraft struct {
+	KTP       KTP        `json:"ktp"`
+	Profile   KTPProfile `json:"profile"`
+	Issues    []string   `json:"issues,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	Quota     *Quota     `json:"quota,omitempty"`
+}
+
+type ConfirmKTPRequest struct {
+	Corrections KTPProfile `json:"corrections"`
+}
+
+// FaceFrameResponse is the framing guidance for one face frame together with
+// the progress of the session's liveness check.
+type FaceFrameResponse struct {
+	*entity.DetectionResult
+	Liveness       liveness.Progress `json:"liveness"`
+	LivenessResult *LivenessResult   `json:"liveness_result,omitempty"`
+	Quota          *Quota            `json:"quota,omitempty"`
+}
+
+type KTPFrameResponse struct {
+	*entity.KTPDetectionResult
+	Quota *Quota `json:"quota,omitempty"`
+}
+
+type QRISFrameResponse struct {
+	*entity.QRISDetectionResult
+	Quota *Quota `json:"quota,omitempty"`
+}
+
+// Mode is the detector a WebSocket session sends its frames to.
+type Mode string
+
+const (
+	ModeFace  Mode = "face"
+	ModeKTP   Mode = "ktp"
+	ModeQRIS  Mode = "qris"
+	ModeMoney Mode = "money"
+	ModeAuto  Mode = "auto"
+)
+
+func (m Mode) IsValid() bool {
+	switch m {
+	case ModeFace, ModeKTP, ModeQRIS, ModeMoney, ModeAuto:
+		return true
+	}
+	return false
+}
+
+// IsCamera reports whether m is served by the AI camera detectors, the only
+// modes of the face, KTP and QRIS sockets.
+func (m Mode) IsCamera() bool {
+	return m == ModeFace || m == ModeKTP || m == ModeQRIS
+}
+
+type ControlAction string
+
+const (
+	ActionMode   ControlAction = "mode"
+	ActionPause  ControlAction = "pause"
+	ActionResume ControlAction = "resume"
+	ActionStop   ControlAction = "stop"
+	ActionFrame  ControlAction = "frame"
+	ActionSpeech ControlAction = "speech"
+)
+
+// SpeechMode is how spoken instructions reach the client: as text for the
+// device's own screen reader, as a pre-synthesized audio clip, or both.
+type SpeechMode string
+
+const (
+	SpeechText  SpeechMode = "text"
+	SpeechAudio SpeechMode = "audio"
+	SpeechBoth  SpeechMode = "both"
+)
+
+func (m SpeechMode) IsValid() bool {
+	return m == SpeechText || m == SpeechAudio || m == SpeechBoth
+}
+
+func (m SpeechMode) WantsText() bool {
+	return m != SpeechAudio
+}
+
+func (m SpeechMode) WantsAudio() bool {
+	return m == SpeechAudio || m == SpeechBoth
+}
+
+// Speech references the audio clip of a spoken instruction. The same sentence
+// always has the same ID, so clients can cache clips they have played before.
+type Speech struct {
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+}
+
+// ControlMessage is a JSON text message a client sends on a detection
+// WebSocket to switch modes, pause or end the session without reconnecting.
+// A frame action carries a base64 image tagged with the mode to run it
+// through, leaving the session mode as it is. A speech action switches the
+// speech mode of the session.
+type ControlMessage struct {
+	Action      ControlAction `json:"action"`
+	Mode        Mode          `json:"mode,omitempty"`
+	Speech      SpeechMode    `json:"speech,omitempty"`
+	ImageBase64 string        `json:"image_base64,omitempty"`
+}
+
+const (
+	MessageSession  = "session"
+	MessageThrottle = "throttle"
+	MessageStats    = "stats"
+)
+
+// SessionMessage describes the state of a detection session. It is sent when
+// the session opens, after every control message and, as a throttle
+// message, whenever the frame rate the server can keep up with changes.
+// Frames sent faster than TargetFPS are dropped in favour of the latest one.
+type SessionMessage struct {
+	Type      string     `json:"type"`
+	Mode      Mode       `json:"mode"`
+	Speech    SpeechMode `json:"speech,omitempty"`
+	Paused    bool       `json:"paused"`
+	TargetFPS int        `json:"target_fps"`
+}
+
+// SessionStats is sent in reply to a stop action, just before the server
+// closes the connection.
+type SessionStats struct {
+	Type         string  `json:"type"`
+	Processed    int64   `json:"processed"`
+	Dropped      int64   `json:"dropped"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+const MessageResult = "result"
+
+// AssistResult is the common envelope for every result on the assist
+// WebSocket. Mode is the detector that produced Result, which differs from
+// RequestedMode in auto mode, and SpokenInstruction is a sentence meant to be
+// read out to the user. Depending on the speech mode the sentence comes as
+// text, as an audio clip in Speech, or both.
+type AssistResult struct {
+	Type              string      `json:"type"`
+	Mode              Mode        `json:"mode"`
+	RequestedMode     Mode        `json:"requested_mode"`
+	Detected          bool        `json:"detected"`
+	SpokenInstruction string      `json:"spoken_instruction,omitempty"`
+	Speech            *Speech     `json:"speech,omitempty"`
+	Result            interface{} `json:"result,omitempty"`
+	Quota             *Quota      `json:"quota,omitempty"`
+	Error             string      `json:"error,omitempty"`
+}
+
+// FrameError is sent over a detection WebSocket instead of a result when a
+// frame could not be processed.
+type FrameError struct {
+	Error string `json:"error"`
+	Quota *Quota `json:"quota,omitempty"`
+}
+
+// Quota is a user's usage of a limited resource within its current window.
+type Quota struct {
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// LivenessResult is sent once every challenge has passed. The token proves
+// liveness for the frame hashed in FrameSHA256, which the client keeps and
+// uploads as the face photo or KYC selfie together with the token.
+type LivenessResult struct {
+	Token       string    `json:"token"`
+	FrameSHA256 string    `json:"frame_sha256"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
 type OCRRequest struct {
 	ImageBase64 string `json:"image_base64"`
 }
@@ -74,9 +284,12 @@ type MoneyDetectionMultiple struct {
 }
 
 type MoneyDetectionResponse struct {
-	Multiple bool          `json:"multiple"`
-	Details  []MoneyDetail `json:"details"`
-	Total    int           `json:"total"`
+	Multiple          bool          `json:"multiple"`
+	Details           []MoneyDetail `json:"details"`
+	Total             int           `json:"total"`
+	SpokenInstruction string        `json:"spoken_instruction,omitempty"`
+	Speech            *Speech       `json:"speech,omitempty"`
+	Quota             *Quota        `json:"quota,omitempty"`
 }
 
 type MoneyResponse struct {
@@ -95,3 +308,81 @@ const (
 	FaceDetection DetectionType = "FACE"
 	QRISDetection DetectionType = "QRIS"
 )
+
+// ImageRequest is the JSON body of the Gemini assistive endpoints, which also
+// accept the image as a multipart "image" file.
+type ImageRequest struct {
+	ImageBase64 string `json:"image_base64" validate:"required"`
+}
+
+// TextBlock is one block of text read from an image, such as a heading, a
+// paragraph or a line of a sign.
+type TextBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ReadTextResponse is all text visible in an image. Blocks are in reading
+// order and Text joins them with newlines.
+type ReadTextResponse struct {
+	Found             bool        `json:"found"`
+	Language          string      `json:"language,omitempty"`
+	Blocks            []TextBlock `json:"blocks"`
+	Text              string      `json:"text"`
+	PromptVersion     string      `json:"prompt_version"`
+	SpokenInstruction string      `json:"spoken_instruction,omitempty"`
+	Speech            *Speech     `json:"speech,omitempty"`
+	Quota             *Quota      `json:"quota,omitempty"`
+}
+
+type SceneObject struct {
+	Name     string `json:"name"`
+	Position string `json:"position"`
+}
+
+// SceneDescriptionResponse describes what the camera sees. Hazards lists
+// anything a blind user could walk into or trip over.
+type SceneDescriptionResponse struct {
+	Summary           string        `json:"summary"`
+	Setting           string        `json:"setting,omitempty"`
+	Objects           []SceneObject `json:"objects"`
+	People            int           `json:"people"`
+	Hazards           []string      `json:"hazards"`
+	PromptVersion     string        `json:"prompt_version"`
+	SpokenInstruction string        `json:"spoken_instruction,omitempty"`
+	Speech            *Speech       `json:"speech,omitempty"`
+	Quota             *Quota        `json:"quota,omitempty"`
+}
+
+// ProductLabelResponse is what a product or package label says. ExpiryDate
+// is formatted as YYYY-MM-DD, and Expired is only set when it is known.
+type ProductLabelResponse struct {
+	Found             bool     `json:"found"`
+	ProductName       string   `json:"product_name,omitempty"`
+	Brand             string   `json:"brand,omitempty"`
+	Category          string   `json:"category,omitempty"`
+	NetContent        string   `json:"net_content,omitempty"`
+	ExpiryDate        string   `json:"expiry_date,omitempty"`
+	Expired           *bool    `json:"expired,omitempty"`
+	Warnings          []string `json:"warnings"`
+	PromptVersion     string   `json:"prompt_version"`
+	SpokenInstruction string   `json:"spoken_instruction,omitempty"`
+	Speech            *Speech  `json:"speech,omitempty"`
+	Quota             *Quota   `json:"quota,omitempty"`
+}
+
+type ClothingItem struct {
+	Item    string   `json:"item"`
+	Colors  []string `json:"colors"`
+	Pattern string   `json:"pattern,omitempty"`
+}
+
+// ClothingColorsResponse lists each piece of clothing in an image with its
+// colors, main color first.
+type ClothingColorsResponse struct {
+	Items             []ClothingItem `json:"items"`
+	PromptVersion     string         `json:"prompt_version"`
+	SpokenInstruction string         `json:"spoken_instruction,omitempty"`
+	Speech            *Speech        `json:"speech,omitempty"`
+	Quota             *Quota         `json:"quota,omitempty"`
+}
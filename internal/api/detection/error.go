@@ -8,4 +8,12 @@ import (
 var (
 	ErrInternalServerError = response.NewError(http.StatusInternalServerError, "internal server error")
 	ErrBadRequest          = response.NewError(http.StatusBadRequest, "bad request")
+	ErrKTPUnreadable       = response.NewError(http.StatusUnprocessableEntity, "could not read the KTP, retake the photo")
+	ErrKTPDraftNotFound    = response.NewError(http.StatusNotFound, "no KTP draft to confirm, extract the KTP again")
+	ErrKTPInconsistent     = response.NewError(http.StatusUnprocessableEntity, "KTP data is inconsistent")
+	ErrFrameQuotaExceeded  = response.NewError(http.StatusTooManyRequests, "frame quota exceeded, slow down")
+	ErrGeminiQuotaExceeded = response.NewError(http.StatusTooManyRequests, "daily AI analysis quota exceeded")
+	ErrImageUnreadable     = response.NewError(http.StatusUnprocessableEntity, "could not analyze the image, retake the photo")
+	ErrInvalidSpeechMode   = response.NewError(http.StatusBadRequest, "speech must be text, audio or both")
+	ErrSpeechClipNotFound  = response.NewError(http.StatusNotFound, "audio clip not found")
 )
@@ -2,8 +2,11 @@ package detectionHandler
 
 import (
 	"ProjectGolang/internal/api/detection"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/middleware"
 	contextPkg "ProjectGolang/pkg/context"
 	"ProjectGolang/pkg/handlerUtil"
+	jwtPkg "ProjectGolang/pkg/jwt"
 	"ProjectGolang/pkg/log"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
@@ -11,152 +14,45 @@ import (
 	"time"
 )
 
+// handleWebSocket streams face framing guidance and runs a liveness check over
+// the frames of the connection. A failed check starts over with a new set of
+// challenges on the next frame.
 func (h *DetectionHandler) handleWebSocket(c *websocket.Conn) {
-	for {
-		messageType, message, err := c.ReadMessage()
-		if err != nil {
-			break
-		}
-
-		if messageType == websocket.BinaryMessage {
-			result, err := h.detectionService.ProcessFrame(message)
-			if err != nil {
-				continue
-			}
-
-			if err := c.WriteJSON(result); err != nil {
-				break
-			}
-		}
-	}
+	h.runFrameSession(c, detection.ModeFace, false)
 }
 
 func (h *DetectionHandler) handleKTPWebSocket(c *websocket.Conn) {
-	h.log.Info("KTP detection WebSocket client connected")
-	defer h.log.Info("KTP detection WebSocket client disconnected")
-
-	c.SetPingHandler(func(data string) error {
-		h.log.Debug("Received ping, sending pong")
-		if err := c.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(5*time.Second)); err != nil {
-			h.log.Errorf("Error sending pong: %v", err)
-		}
-		return nil
-	})
-
-	maxReadTimeout := 60 * time.Second
-
-	for {
-		if err := c.SetReadDeadline(time.Now().Add(maxReadTimeout)); err != nil {
-			h.log.Errorf("Error setting read deadline: %v", err)
-			break
-		}
-
-		messageType, message, err := c.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				h.log.Errorf("KTP WebSocket error: %v", err)
-			} else {
-				h.log.Info("KTP WebSocket connection closed")
-			}
-			break
-		}
-
-		if messageType == websocket.BinaryMessage {
-			h.log.Info("Received binary message for KTP detection")
-
-			result, err := h.detectionService.ProcessKTPFrame(message)
-
-			if err != nil {
-				h.log.Errorf("Error processing KTP frame: %v", err)
-				if writeErr := c.WriteJSON(map[string]string{"error": err.Error()}); writeErr != nil {
-					h.log.Errorf("Error sending error response: %v", writeErr)
-					break
-				}
-				continue
-			}
-
-			if err := c.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
-				h.log.Errorf("Error setting write deadline: %v", err)
-				break
-			}
-
-			if err := c.WriteJSON(result); err != nil {
-				h.log.Errorf("Error writing JSON response: %v", err)
-				break
-			}
-
-			if err := c.SetWriteDeadline(time.Time{}); err != nil {
-				h.log.Errorf("Error resetting write deadline: %v", err)
-				break
-			}
-		} else {
-			h.log.Warnf("Received unexpected message type: %d", messageType)
-		}
-	}
+	h.runFrameSession(c, detection.ModeKTP, false)
 }
 
 func (h *DetectionHandler) handleQRISWebSocket(c *websocket.Conn) {
-	h.log.Info("QRIS detection WebSocket client connected")
-	defer h.log.Info("QRIS detection WebSocket client disconnected")
-
-	c.SetPingHandler(func(data string) error {
-		h.log.Debug("Received ping, sending pong")
-		if err := c.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(5*time.Second)); err != nil {
-			h.log.Errorf("Error sending pong: %v", err)
-		}
-		return nil
-	})
-
-	maxReadTimeout := 60 * time.Second
+	h.runFrameSession(c, detection.ModeQRIS, false)
+}
 
-	for {
-		if err := c.SetReadDeadline(time.Now().Add(maxReadTimeout)); err != nil {
-			h.log.Errorf("Error setting read deadline: %v", err)
-			break
-		}
+// handleAssistWebSocket serves the assistive camera: one session for every
+// detector, with frames routed by the session mode, by the mode they are
+// tagged with, or automatically, and every result in the assist envelope.
+func (h *DetectionHandler) handleAssistWebSocket(c *websocket.Conn) {
+	h.runFrameSession(c, detection.ModeAuto, true)
+}
 
-		messageType, message, err := c.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				h.log.Errorf("QRIS WebSocket error: %v", err)
-			} else {
-				h.log.Info("QRIS WebSocket connection closed")
-			}
-			break
-		}
+// webSocketContext builds the context for a detection connection from the
+// locals of its upgrade request, closing the connection if it carries no
+// authenticated user.
+func (h *DetectionHandler) webSocketContext(c *websocket.Conn) (context.Context, entity.UserLoginData, bool) {
+	requestID, _ := c.Locals(middleware.RequestIDKey).(string)
+	ctx := contextPkg.WithRequestID(context.Background(), requestID)
 
-		if messageType == websocket.BinaryMessage {
-			h.log.Info("Received binary message for QRIS detection")
-
-			result, err := h.detectionService.ProcessQRISFrame(message)
-
-			if err != nil {
-				h.log.Errorf("Error processing QRIS frame: %v", err)
-				if writeErr := c.WriteJSON(map[string]string{"error": err.Error()}); writeErr != nil {
-					h.log.Errorf("Error sending error response: %v", writeErr)
-					break
-				}
-				continue
-			}
-
-			if err := c.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
-				h.log.Errorf("Error setting write deadline: %v", err)
-				break
-			}
-
-			if err := c.WriteJSON(result); err != nil {
-				h.log.Errorf("Error writing JSON response: %v", err)
-				break
-			}
-
-			if err := c.SetWriteDeadline(time.Time{}); err != nil {
-				h.log.Errorf("Error resetting write deadline: %v", err)
-				break
-			}
-		} else {
-			h.log.Warnf("Received unexpected message type: %d", messageType)
-		}
+	user, ok := c.Locals("user").(entity.UserLoginData)
+	if !ok {
+		h.log.WithFields(log.Fields{
+			"request_id": requestID,
+		}).Warn("Detection WebSocket opened without an authenticated user")
+		_ = c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "unauthorized"), time.Now().Add(5*time.Second))
+		return ctx, entity.UserLoginData{}, false
 	}
+
+	return ctx, user, true
 }
 
 func (h *DetectionHandler) ExtractKTP(ctx *fiber.Ctx) error {
@@ -215,7 +111,12 @@ func (h *DetectionHandler) ExtractKTP(ctx *fiber.Ctx) error {
 		base64Image = req.ImageBase64
 	}
 
-	ktp, err := h.detectionService.ExtractAndSaveKTP(c, base64Image)
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	draft, err := h.detectionService.ExtractKTPDraft(c, userData.ID, base64Image)
 	if err != nil {
 		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "extract_ktp")
 	}
@@ -227,11 +128,41 @@ func (h *DetectionHandler) ExtractKTP(ctx *fiber.Ctx) error {
 		h.log.WithFields(log.Fields{
 			"request_id": requestID,
 			"path":       ctx.Path(),
-			"nik":        ktp.NIK,
+			"issues":     len(draft.Issues),
 		}).Info("KTP extraction successful")
-		return errHandler.HandleSuccess(ctx, fiber.StatusOK, detection.OCRResponse{
-			Data: *ktp,
-		})
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, draft)
+	}
+}
+
+func (h *DetectionHandler) ConfirmKTP(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var req detection.ConfirmKTPRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+		}
+	}
+
+	profile, err := h.detectionService.ConfirmKTPDraft(c, userData, req)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "confirm_ktp")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, profile)
 	}
 }
 
@@ -291,7 +222,13 @@ func (h *DetectionHandler) DetectMoney(ctx *fiber.Ctx) error {
 		base64Image = req.ImageBase64
 	}
 
-	result, err := h.detectionService.DetectMoney(c, base64Image)
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	speech := detection.SpeechMode(ctx.Query("speech"))
+	result, err := h.detectionService.DetectMoney(c, userData.ID, base64Image, speech)
 	if err != nil {
 		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "detect_money")
 	}
@@ -311,3 +248,27 @@ func (h *DetectionHandler) DetectMoney(ctx *fiber.Ctx) error {
 		})
 	}
 }
+
+// GetSpeechClip serves the audio of a spoken instruction. Clip IDs are derived
+// from the sentence, so a clip never changes and can be cached indefinitely.
+func (h *DetectionHandler) GetSpeechClip(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	clip, err := h.detectionService.SpeechClip(c, ctx.Params("id"))
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_speech_clip")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		ctx.Set(fiber.HeaderContentType, clip.MimeType)
+		ctx.Set(fiber.HeaderCacheControl, "private, max-age=31536000, immutable")
+		return ctx.Status(fiber.StatusOK).Send(clip.Data)
+	}
+}
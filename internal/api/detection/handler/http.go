@@ -42,19 +42,33 @@ func (h *DetectionHandler) Start(srv fiber.Router) {
 		return fiber.ErrUpgradeRequired
 	}
 
+	wsConfig := websocket.Config{
+		Subprotocols: []string{middleware.WebSocketTokenProtocol},
+	}
+
 	face := srv.Group("/face")
-	face.Use("/ws", wsMiddleware)
-	face.Get("/ws", websocket.New(h.handleWebSocket))
+	face.Use("/ws", wsMiddleware, h.middleware.NewWebSocketTokenMiddleware)
+	face.Get("/ws", websocket.New(h.handleWebSocket, wsConfig))
 
 	ktp := srv.Group("/ktp")
-	ktp.Use("/ws", wsMiddleware)
-	ktp.Get("/ws", websocket.New(h.handleKTPWebSocket))
-	ktp.Post("/extract", h.ExtractKTP)
+	ktp.Use("/ws", wsMiddleware, h.middleware.NewWebSocketTokenMiddleware)
+	ktp.Get("/ws", websocket.New(h.handleKTPWebSocket, wsConfig))
+	ktp.Post("/extract", h.middleware.NewTokenMiddleware, h.ExtractKTP)
+	ktp.Post("/confirm", h.middleware.NewTokenMiddleware, h.ConfirmKTP)
 
 	qris := srv.Group("/qris")
-	qris.Use("/ws", wsMiddleware)
-	qris.Get("/ws", websocket.New(h.handleQRISWebSocket))
+	qris.Use("/ws", wsMiddleware, h.middleware.NewWebSocketTokenMiddleware)
+	qris.Get("/ws", websocket.New(h.handleQRISWebSocket, wsConfig))
+
+	assist := srv.Group("/assist")
+	assist.Use("/ws", wsMiddleware, h.middleware.NewWebSocketTokenMiddleware)
+	assist.Get("/ws", websocket.New(h.handleAssistWebSocket, wsConfig))
+	assist.Post("/read-text", h.middleware.NewTokenMiddleware, h.ReadText)
+	assist.Post("/describe", h.middleware.NewTokenMiddleware, h.DescribeScene)
+	assist.Post("/product", h.middleware.NewTokenMiddleware, h.IdentifyProduct)
+	assist.Post("/clothing", h.middleware.NewTokenMiddleware, h.IdentifyClothingColors)
 
-	srv.Post("/money", h.DetectMoney)
+	srv.Post("/money", h.middleware.NewTokenMiddleware, h.DetectMoney)
 
+	srv.Get("/tts/clips/:id", h.middleware.NewTokenMiddleware, h.GetSpeechClip)
 }
@@ -0,0 +1,465 @@
+package detectionHandler
+
+import (
+	"ProjectGolang/internal/api/detection"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/pkg/liveness"
+	"ProjectGolang/pkg/log"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/websocket/v2"
+	"golang.org/x/net/context"
+	"sync"
+	"time"
+)
+
+const (
+	initialTargetFPS = 5
+	maxTargetFPS     = 10
+	sessionIdleLimit = 60 * time.Second
+	sessionWriteWait = 10 * time.Second
+
+	// moneyInterval spaces out money readings, each of which is a Gemini call
+	// against the user's daily quota.
+	moneyInterval = 3 * time.Second
+)
+
+var (
+	errInvalidControl = errors.New("invalid control message")
+	errInvalidMode    = errors.New("mode is not available on this socket")
+	errInvalidImage   = errors.New("image_base64 is not valid base64")
+	errInvalidSpeech  = errors.New("speech must be text, audio or both, and is only available on the assist socket")
+)
+
+// frameSession runs one detection WebSocket. The connection is read
+// continuously and only the latest frame is kept, so a client sending faster
+// than the AI backend answers has its older frames dropped instead of queued.
+// A single processor works through the frames one at a time and adapts the
+// advertised target FPS to the latency it sees. Assist sessions answer in the
+// common assist envelope, also accept the money and auto modes, and can have
+// their spoken instructions sent as audio clips.
+type frameSession struct {
+	h      *DetectionHandler
+	conn   *websocket.Conn
+	ctx    context.Context
+	user   entity.UserLoginData
+	assist bool
+
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	mode          detection.Mode
+	speech        detection.SpeechMode
+	paused        bool
+	pending       *pendingFrame
+	resetLiveness bool
+	targetFPS     int
+	processed     int64
+	dropped       int64
+	latencyTotal  time.Duration
+	latencyAvgMs  float64
+
+	frameReady chan struct{}
+	done       chan struct{}
+	finished   chan struct{}
+
+	liveness  *liveness.Session
+	lastMoney time.Time
+}
+
+type pendingFrame struct {
+	data []byte
+	mode detection.Mode
+}
+
+func (h *DetectionHandler) runFrameSession(c *websocket.Conn, mode detection.Mode, assist bool) {
+	ctx, user, ok := h.webSocketContext(c)
+	if !ok {
+		return
+	}
+
+	s := &frameSession{
+		h:          h,
+		conn:       c,
+		ctx:        ctx,
+		user:       user,
+		assist:     assist,
+		mode:       mode,
+		targetFPS:  initialTargetFPS,
+		frameReady: make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		finished:   make(chan struct{}),
+		liveness:   liveness.NewSession(),
+	}
+
+	var speechErr error
+	if assist {
+		s.speech = detection.SpeechText
+		if speech := detection.SpeechMode(c.Query("speech")); speech != "" {
+			if speech.IsValid() {
+				s.speech = speech
+			} else {
+				speechErr = errInvalidSpeech
+			}
+		}
+	}
+
+	c.SetPingHandler(func(data string) error {
+		s.writeMu.Lock()
+		defer s.writeMu.Unlock()
+		if err := c.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(5*time.Second)); err != nil {
+			h.log.Errorf("Error sending pong: %v", err)
+		}
+		return nil
+	})
+
+	h.log.WithFields(log.Fields{
+		"user_id": user.ID,
+		"mode":    mode,
+	}).Info("Detection WebSocket client connected")
+
+	if err := s.write(s.state(detection.MessageSession)); err != nil {
+		return
+	}
+	if speechErr != nil {
+		if err := s.write(detection.FrameError{Error: speechErr.Error()}); err != nil {
+			return
+		}
+	}
+
+	go s.processFrames()
+
+	stopped := s.readFrames()
+
+	close(s.done)
+	<-s.finished
+
+	s.mu.Lock()
+	s.dropPendingLocked()
+	s.mu.Unlock()
+
+	stats := s.stats()
+	if stopped {
+		if err := s.write(stats); err == nil {
+			s.writeMu.Lock()
+			_ = c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(5*time.Second))
+			s.writeMu.Unlock()
+		}
+	}
+
+	h.log.WithFields(log.Fields{
+		"user_id":        user.ID,
+		"processed":      stats.Processed,
+		"dropped":        stats.Dropped,
+		"avg_latency_ms": stats.AvgLatencyMs,
+	}).Info("Detection WebSocket client disconnected")
+}
+
+// readFrames reads until the connection fails or the client stops the
+// session, which it reports.
+func (s *frameSession) readFrames() bool {
+	for {
+		if err := s.conn.SetReadDeadline(time.Now().Add(sessionIdleLimit)); err != nil {
+			return false
+		}
+
+		messageType, message, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure, websocket.CloseNormalClosure) {
+				s.h.log.Errorf("Detection WebSocket error: %v", err)
+			}
+			return false
+		}
+
+		switch messageType {
+		case websocket.BinaryMessage:
+			s.offer(message, "")
+		case websocket.TextMessage:
+			action, err := s.control(message)
+			if err != nil {
+				if err := s.write(detection.FrameError{Error: err.Error()}); err != nil {
+					return false
+				}
+				continue
+			}
+
+			switch action {
+			case detection.ActionStop:
+				return true
+			case detection.ActionFrame:
+				continue
+			}
+
+			if err := s.write(s.state(detection.MessageSession)); err != nil {
+				return false
+			}
+		}
+	}
+}
+
+// offer replaces the pending frame, counting the one it replaces as dropped.
+// Frames without a mode of their own use the session mode.
+func (s *frameSession) offer(frame []byte, mode detection.Mode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.paused {
+		s.dropped++
+		return
+	}
+
+	if mode == "" {
+		mode = s.mode
+	}
+
+	if s.pending != nil {
+		s.dropped++
+	}
+	s.pending = &pendingFrame{data: frame, mode: mode}
+
+	select {
+	case s.frameReady <- struct{}{}:
+	default:
+	}
+}
+
+func (s *frameSession) control(message []byte) (detection.ControlAction, error) {
+	var msg detection.ControlMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return "", errInvalidControl
+	}
+
+	if msg.Action == detection.ActionFrame {
+		if msg.Mode != "" && !s.modeAllowed(msg.Mode) {
+			return "", errInvalidMode
+		}
+
+		frame, err := base64.StdEncoding.DecodeString(msg.ImageBase64)
+		if err != nil || len(frame) == 0 {
+			return "", errInvalidImage
+		}
+
+		s.offer(frame, msg.Mode)
+		return msg.Action, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Action {
+	case detection.ActionMode:
+		if !s.modeAllowed(msg.Mode) {
+			return "", errInvalidMode
+		}
+		if msg.Mode != s.mode {
+			s.mode = msg.Mode
+			s.resetLiveness = true
+			s.dropPendingLocked()
+		}
+	case detection.ActionPause:
+		s.paused = true
+		s.dropPendingLocked()
+	case detection.ActionResume:
+		s.paused = false
+	case detection.ActionSpeech:
+		if !s.assist || !msg.Speech.IsValid() {
+			return "", errInvalidSpeech
+		}
+		s.speech = msg.Speech
+	case detection.ActionStop:
+	default:
+		return "", errInvalidControl
+	}
+
+	return msg.Action, nil
+}
+
+func (s *frameSession) modeAllowed(mode detection.Mode) bool {
+	return mode.IsValid() && (s.assist || mode.IsCamera())
+}
+
+func (s *frameSession) dropPendingLocked() {
+	if s.pending != nil {
+		s.dropped++
+		s.pending = nil
+	}
+}
+
+func (s *frameSession) processFrames() {
+	defer close(s.finished)
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.frameReady:
+		}
+
+		s.mu.Lock()
+		frame, resetLiveness := s.pending, s.resetLiveness
+		s.pending = nil
+		s.resetLiveness = false
+		speech := s.speech
+		if frame != nil && frame.mode == detection.ModeMoney && time.Since(s.lastMoney) < moneyInterval {
+			s.dropped++
+			frame = nil
+		}
+		s.mu.Unlock()
+
+		if frame == nil {
+			continue
+		}
+		if resetLiveness {
+			s.liveness = liveness.NewSession()
+		}
+		if frame.mode == detection.ModeMoney {
+			s.lastMoney = time.Now()
+		}
+
+		start := time.Now()
+		var res interface{}
+		if s.assist {
+			res = s.processAssist(frame.mode, speech, frame.data)
+		} else {
+			res = s.process(frame.mode, frame.data)
+		}
+		throttle := s.observe(time.Since(start))
+
+		if err := s.write(res); err != nil {
+			s.conn.Close()
+			return
+		}
+
+		if throttle {
+			if err := s.write(s.state(detection.MessageThrottle)); err != nil {
+				s.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// process runs frame through the detector of mode and returns the message to
+// send back.
+func (s *frameSession) process(mode detection.Mode, frame []byte) interface{} {
+	quota, err := s.h.detectionService.UseFrameQuota(s.ctx, s.user.ID)
+	if err != nil {
+		return detection.FrameError{Error: err.Error(), Quota: quota}
+	}
+
+	switch mode {
+	case detection.ModeKTP:
+		result, err := s.h.detectionService.ProcessKTPFrame(s.ctx, frame)
+		if err != nil {
+			s.h.log.Errorf("Error processing KTP frame: %v", err)
+			return detection.FrameError{Error: err.Error(), Quota: quota}
+		}
+		return detection.KTPFrameResponse{KTPDetectionResult: result, Quota: quota}
+	case detection.ModeQRIS:
+		result, err := s.h.detectionService.ProcessQRISFrame(s.ctx, frame)
+		if err != nil {
+			s.h.log.Errorf("Error processing QRIS frame: %v", err)
+			return detection.FrameError{Error: err.Error(), Quota: quota}
+		}
+		return detection.QRISFrameResponse{QRISDetectionResult: result, Quota: quota}
+	default:
+		result, err := s.h.detectionService.ProcessFrame(s.ctx, s.user.ID, s.liveness, frame)
+		if err != nil {
+			s.h.log.Errorf("Error processing face frame: %v", err)
+			return detection.FrameError{Error: err.Error(), Quota: quota}
+		}
+		result.Quota = quota
+
+		// A failed liveness check starts over with new challenges.
+		if result.Liveness.Status == liveness.StatusFailed {
+			s.liveness = liveness.NewSession()
+		}
+		return result
+	}
+}
+
+func (s *frameSession) processAssist(mode detection.Mode, speech detection.SpeechMode, frame []byte) interface{} {
+	res, err := s.h.detectionService.Assist(s.ctx, s.user.ID, s.liveness, mode, speech, frame)
+	if err != nil {
+		return detection.FrameError{Error: err.Error()}
+	}
+
+	// A failed liveness check starts over with new challenges.
+	if face, ok := res.Result.(*detection.FaceFrameResponse); ok && face.Liveness.Status == liveness.StatusFailed {
+		s.liveness = liveness.NewSession()
+	}
+
+	return res
+}
+
+// observe records the latency of a processed frame and reports whether the
+// target FPS changed enough to advertise it again.
+func (s *frameSession) observe(latency time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.processed++
+	s.latencyTotal += latency
+
+	ms := float64(latency) / float64(time.Millisecond)
+	if s.processed == 1 {
+		s.latencyAvgMs = ms
+	} else {
+		s.latencyAvgMs = 0.8*s.latencyAvgMs + 0.2*ms
+	}
+
+	target := maxTargetFPS
+	if s.latencyAvgMs > 0 {
+		target = int(1000 / s.latencyAvgMs)
+	}
+	target = max(1, min(target, maxTargetFPS))
+
+	// Small swings are not worth a message; reaching a bound always is.
+	diff := target - s.targetFPS
+	if diff == 0 || (diff > -2 && diff < 2 && target != 1 && target != maxTargetFPS) {
+		return false
+	}
+
+	s.targetFPS = target
+	return true
+}
+
+func (s *frameSession) state(messageType string) detection.SessionMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return detection.SessionMessage{
+		Type:      messageType,
+		Mode:      s.mode,
+		Speech:    s.speech,
+		Paused:    s.paused,
+		TargetFPS: s.targetFPS,
+	}
+}
+
+func (s *frameSession) stats() detection.SessionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := detection.SessionStats{
+		Type:      detection.MessageStats,
+		Processed: s.processed,
+		Dropped:   s.dropped,
+	}
+	if s.processed > 0 {
+		stats.AvgLatencyMs = float64(s.latencyTotal) / float64(s.processed) / float64(time.Millisecond)
+	}
+
+	return stats
+}
+
+func (s *frameSession) write(v interface{}) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(sessionWriteWait)); err != nil {
+		return err
+	}
+	return s.conn.WriteJSON(v)
+}
@@ -0,0 +1,102 @@
+package detectionHandler
+
+import (
+	"ProjectGolang/internal/api/detection"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/handlerUtil"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"ProjectGolang/pkg/log"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+	"time"
+)
+
+// imageAnalysis is a Gemini assistive analysis of an uploaded image.
+type imageAnalysis func(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (interface{}, error)
+
+func (h *DetectionHandler) ReadText(ctx *fiber.Ctx) error {
+	return h.analyzeImage(ctx, "read_text", func(c context.Context, userID string, base64Image string, speech detection.SpeechMode) (interface{}, error) {
+		return h.detectionService.ReadText(c, userID, base64Image, speech)
+	})
+}
+
+func (h *DetectionHandler) DescribeScene(ctx *fiber.Ctx) error {
+	return h.analyzeImage(ctx, "describe_scene", func(c context.Context, userID string, base64Image string, speech detection.SpeechMode) (interface{}, error) {
+		return h.detectionService.DescribeScene(c, userID, base64Image, speech)
+	})
+}
+
+func (h *DetectionHandler) IdentifyProduct(ctx *fiber.Ctx) error {
+	return h.analyzeImage(ctx, "identify_product", func(c context.Context, userID string, base64Image string, speech detection.SpeechMode) (interface{}, error) {
+		return h.detectionService.IdentifyProduct(c, userID, base64Image, speech)
+	})
+}
+
+func (h *DetectionHandler) IdentifyClothingColors(ctx *fiber.Ctx) error {
+	return h.analyzeImage(ctx, "identify_clothing_colors", func(c context.Context, userID string, base64Image string, speech detection.SpeechMode) (interface{}, error) {
+		return h.detectionService.IdentifyClothingColors(c, userID, base64Image, speech)
+	})
+}
+
+// analyzeImage reads the image of the request, as a multipart "image" file or
+// as JSON, and runs analysis over it for the logged-in user.
+func (h *DetectionHandler) analyzeImage(ctx *fiber.Ctx, operation string, analysis imageAnalysis) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 20*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var base64Image string
+
+	file, err := ctx.FormFile("image")
+	if err == nil {
+		if err := h.utils.ValidateImageFile(file); err != nil {
+			return errHandler.Handle(ctx, requestID, err, ctx.Path(), "validate_image_file")
+		}
+
+		fileContent, err := file.Open()
+		if err != nil {
+			return errHandler.Handle(ctx, requestID, err, ctx.Path(), "open_file")
+		}
+		defer fileContent.Close()
+
+		base64Image, err = h.utils.ConvertFileToBase64(fileContent)
+		if err != nil {
+			return errHandler.Handle(ctx, requestID, err, ctx.Path(), "convert_to_base64")
+		}
+	} else {
+		var req detection.ImageRequest
+		if err := ctx.BodyParser(&req); err != nil {
+			return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+		}
+
+		if err := h.validator.Struct(req); err != nil {
+			return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+		}
+
+		base64Image = req.ImageBase64
+	}
+
+	result, err := analysis(c, userData.ID, base64Image, detection.SpeechMode(ctx.Query("speech")))
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), operation)
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		h.log.WithFields(log.Fields{
+			"request_id": requestID,
+			"path":       ctx.Path(),
+			"operation":  operation,
+		}).Info("Image analysis successful")
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, result)
+	}
+}
@@ -0,0 +1,279 @@
+package detectionService
+
+import (
+	"ProjectGolang/internal/api/detection"
+	"ProjectGolang/internal/entity"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/liveness"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"sync"
+)
+
+// autoMinConfidence is the confidence a KTP or QRIS detection needs before
+// auto mode settles on it.
+const autoMinConfidence = 0.5
+
+var livenessInstructions = map[liveness.Challenge]string{
+	liveness.ChallengeBlink:     "Kedipkan mata",
+	liveness.ChallengeTurnLeft:  "Tolehkan kepala ke kiri",
+	liveness.ChallengeTurnRight: "Tolehkan kepala ke kanan",
+	liveness.ChallengeSmile:     "Tersenyumlah",
+}
+
+// Assist runs frame through the detector of mode and wraps the outcome in the
+// assist envelope, failures included, so the client always has something to
+// read out. Auto mode looks for a KTP or QRIS code first and falls back to
+// the face detector; it never calls Gemini, so money has to be asked for
+// explicitly. speech picks whether the spoken instruction comes as text, as
+// an audio clip or both.
+func (s *detectionService) Assist(ctx context.Context, userID string, session *liveness.Session, mode detection.Mode, speech detection.SpeechMode, frame []byte) (*detection.AssistResult, error) {
+	if !mode.IsValid() {
+		return nil, detection.ErrBadRequest
+	}
+	if speech == "" {
+		speech = detection.SpeechText
+	}
+	if !speech.IsValid() {
+		return nil, detection.ErrInvalidSpeechMode
+	}
+
+	quota, err := s.UseFrameQuota(ctx, userID)
+	if err != nil {
+		res := assistError(mode, err, quota)
+		res.SpokenInstruction, res.Speech = s.speak(ctx, speech, res.SpokenInstruction)
+		return res, nil
+	}
+
+	var res *detection.AssistResult
+	switch mode {
+	case detection.ModeFace:
+		res, err = s.assistFace(ctx, userID, session, frame)
+	case detection.ModeKTP:
+		res, err = s.assistKTP(ctx, frame)
+	case detection.ModeQRIS:
+		res, err = s.assistQRIS(ctx, frame)
+	case detection.ModeMoney:
+		res, err = s.assistMoney(ctx, userID, frame)
+	default:
+		res, err = s.assistAuto(ctx, userID, session, frame)
+	}
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"user_id":    userID,
+			"mode":       mode,
+			"error":      err.Error(),
+		}).Warn("Assist frame failed")
+		res = assistError(mode, err, quota)
+		res.SpokenInstruction, res.Speech = s.speak(ctx, speech, res.SpokenInstruction)
+		return res, nil
+	}
+
+	res.Type = detection.MessageResult
+	res.RequestedMode = mode
+	res.Quota = quota
+	res.SpokenInstruction, res.Speech = s.speak(ctx, speech, res.SpokenInstruction)
+	return res, nil
+}
+
+func assistError(mode detection.Mode, err error, quota *detection.Quota) *detection.AssistResult {
+	res := &detection.AssistResult{
+		Type:              detection.MessageResult,
+		Mode:              mode,
+		RequestedMode:     mode,
+		SpokenInstruction: "Terjadi kesalahan, coba lagi",
+		Quota:             quota,
+		Error:             err.Error(),
+	}
+
+	switch {
+	case errors.Is(err, detection.ErrFrameQuotaExceeded):
+		res.SpokenInstruction = "Terlalu banyak gambar, tunggu sebentar"
+	case errors.Is(err, detection.ErrGeminiQuotaExceeded):
+		res.SpokenInstruction = "Batas deteksi uang hari ini sudah habis"
+	}
+
+	return res
+}
+
+func (s *detectionService) assistFace(ctx context.Context, userID string, session *liveness.Session, frame []byte) (*detection.AssistResult, error) {
+	result, err := s.ProcessFrame(ctx, userID, session, frame)
+	if err != nil {
+		return nil, err
+	}
+
+	return &detection.AssistResult{
+		Mode:              detection.ModeFace,
+		Detected:          result.Status != string(entity.NoFaceDetected),
+		SpokenInstruction: spokenFaceInstruction(result),
+		Result:            result,
+	}, nil
+}
+
+func (s *detectionService) assistKTP(ctx context.Context, frame []byte) (*detection.AssistResult, error) {
+	result, err := s.ProcessKTPFrame(ctx, frame)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &detection.AssistResult{
+		Mode:              detection.ModeKTP,
+		Detected:          result.KTPPosition != nil && result.Error == "",
+		SpokenInstruction: "KTP tidak terlihat, arahkan kamera ke KTP",
+		Result:            result,
+	}
+	if res.Detected {
+		res.SpokenInstruction = "KTP terdeteksi, tahan kamera"
+	}
+
+	return res, nil
+}
+
+func (s *detectionService) assistQRIS(ctx context.Context, frame []byte) (*detection.AssistResult, error) {
+	result, err := s.ProcessQRISFrame(ctx, frame)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &detection.AssistResult{
+		Mode:              detection.ModeQRIS,
+		Detected:          result.QRISPosition != nil && result.Error == "",
+		SpokenInstruction: "Kode QRIS tidak terlihat, arahkan kamera ke kode QRIS",
+		Result:            result,
+	}
+	if res.Detected {
+		res.SpokenInstruction = "Kode QRIS terdeteksi, tahan kamera"
+	}
+
+	return res, nil
+}
+
+func (s *detectionService) assistMoney(ctx context.Context, userID string, frame []byte) (*detection.AssistResult, error) {
+	result, err := s.DetectMoney(ctx, userID, base64.StdEncoding.EncodeToString(frame), detection.SpeechText)
+	if errors.Is(err, detection.ErrImageUnreadable) || errors.Is(err, detection.ErrBadRequest) {
+		// An image without a readable banknote is an answer, not a failure.
+		return &detection.AssistResult{
+			Mode:              detection.ModeMoney,
+			SpokenInstruction: "Uang tidak terbaca, arahkan kamera ke uang",
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &detection.AssistResult{
+		Mode:              detection.ModeMoney,
+		Detected:          result.Total > 0,
+		SpokenInstruction: result.SpokenInstruction,
+		Result:            result,
+	}, nil
+}
+
+func (s *detectionService) assistAuto(ctx context.Context, userID string, session *liveness.Session, frame []byte) (*detection.AssistResult, error) {
+	var (
+		wg      sync.WaitGroup
+		ktp     *detection.AssistResult
+		qris    *detection.AssistResult
+		ktpErr  error
+		qrisErr error
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		ktp, ktpErr = s.assistKTP(ctx, frame)
+	}()
+	go func() {
+		defer wg.Done()
+		qris, qrisErr = s.assistQRIS(ctx, frame)
+	}()
+	wg.Wait()
+
+	var best *detection.AssistResult
+	bestConfidence := autoMinConfidence
+	if ktpErr == nil && ktp.Detected {
+		if confidence := ktp.Result.(*entity.KTPDetectionResult).Confidence; confidence >= bestConfidence {
+			best, bestConfidence = ktp, confidence
+		}
+	}
+	if qrisErr == nil && qris.Detected {
+		if confidence := qris.Result.(*entity.QRISDetectionResult).Confidence; confidence >= bestConfidence {
+			best = qris
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+
+	face, err := s.assistFace(ctx, userID, session, frame)
+	if err != nil {
+		return nil, err
+	}
+	if face.Detected {
+		return face, nil
+	}
+
+	return &detection.AssistResult{
+		Mode:              detection.ModeAuto,
+		SpokenInstruction: "Tidak ada yang terdeteksi, arahkan kamera ke wajah, KTP atau kode QRIS",
+	}, nil
+}
+
+func spokenFaceInstruction(result *detection.FaceFrameResponse) string {
+	switch {
+	case result.LivenessResult != nil:
+		return "Verifikasi wajah berhasil"
+	case result.Liveness.Status == liveness.StatusFailed:
+		return "Verifikasi wajah gagal, kita ulangi dari awal"
+	case result.Liveness.Status == liveness.StatusInProgress:
+		if instruction, ok := livenessInstructions[result.Liveness.Challenge]; ok {
+			return instruction
+		}
+	}
+
+	switch entity.PositionStatus(result.Status) {
+	case entity.NoFaceDetected:
+		return "Wajah tidak terlihat, arahkan kamera ke wajah"
+	case entity.PerfectPosition:
+		return "Posisi wajah sudah tepat, tahan"
+	}
+
+	if spoken, ok := translateFaceInstruction(result.Instructions); ok {
+		return spoken
+	}
+	return "Posisikan wajah di tengah layar"
+}
+
+func spokenMoney(result *detection.MoneyDetectionResponse) string {
+	if result.Total <= 0 {
+		return "Uang tidak terbaca, arahkan kamera ke uang"
+	}
+
+	if len(result.Details) > 1 {
+		return fmt.Sprintf("%d lembar uang, total %s", len(result.Details), spokenRupiah(result.Total))
+	}
+
+	return spokenRupiah(result.Total)
+}
+
+// spokenRupiah writes amount the way it is read out, e.g. 1250000 becomes
+// "1 juta 250 ribu rupiah".
+func spokenRupiah(amount int) string {
+	var spoken string
+
+	if millions := amount / 1000000; millions > 0 {
+		spoken += fmt.Sprintf("%d juta ", millions)
+	}
+	if thousands := amount % 1000000 / 1000; thousands > 0 {
+		spoken += fmt.Sprintf("%d ribu ", thousands)
+	}
+	if rest := amount % 1000; rest > 0 {
+		spoken += fmt.Sprintf("%d ", rest)
+	}
+
+	return spoken + "rupiah"
+}
@@ -0,0 +1,62 @@
+package detectionService
+
+import (
+	"ProjectGolang/internal/api/detection"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+var testFrame = []byte("image")
+
+func TestAssistMoney(t *testing.T) {
+	d := newDetectionTest(t)
+
+	res, err := d.service.Assist(context.Background(), testUserID, nil, detection.ModeMoney, detection.SpeechText, testFrame)
+
+	require.NoError(t, err)
+	assert.True(t, res.Detected)
+	assert.Equal(t, spokenMoneyFixture, res.SpokenInstruction)
+	assert.Empty(t, res.Error)
+}
+
+func TestAssistMoneyUnreadable(t *testing.T) {
+	d := newDetectionTest(t)
+	d.gemini.Add(moneyPrompt.ID(), `{"detail": [], "total": -1}`)
+
+	res, err := d.service.Assist(context.Background(), testUserID, nil, detection.ModeMoney, detection.SpeechText, testFrame)
+
+	require.NoError(t, err)
+	assert.False(t, res.Detected)
+	assert.Equal(t, "Uang tidak terbaca, arahkan kamera ke uang", res.SpokenInstruction)
+	assert.Empty(t, res.Error, "an unreadable banknote is an answer, not a failure")
+}
+
+func TestAssistMoneyReportsFailures(t *testing.T) {
+	t.Run("Gemini unavailable", func(t *testing.T) {
+		d := newDetectionTest(t)
+		// Without a recorded answer the fake fails like an unreachable Gemini.
+		d.gemini.Add(moneyPrompt.ID())
+
+		res, err := d.service.Assist(context.Background(), testUserID, nil, detection.ModeMoney, detection.SpeechText, testFrame)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Terjadi kesalahan, coba lagi", res.SpokenInstruction, "a failure must not be told as an unreadable banknote")
+		assert.NotEmpty(t, res.Error)
+	})
+
+	t.Run("Gemini quota exceeded", func(t *testing.T) {
+		d := newDetectionTest(t)
+		for i := int64(0); i < d.service.geminiQuota; i++ {
+			_, err := d.service.DetectMoney(context.Background(), testUserID, testImage, detection.SpeechText)
+			require.NoError(t, err)
+		}
+
+		res, err := d.service.Assist(context.Background(), testUserID, nil, detection.ModeMoney, detection.SpeechText, testFrame)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Batas deteksi uang hari ini sudah habis", res.SpokenInstruction)
+		assert.Contains(t, res.Error, detection.ErrGeminiQuotaExceeded.Error())
+	})
+}
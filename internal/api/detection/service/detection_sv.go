@@ -3,173 +3,123 @@ package detectionService
 import (
 	"ProjectGolang/internal/api/detection"
 	"ProjectGolang/internal/entity"
-	"encoding/json"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/gemini"
+	"ProjectGolang/pkg/liveness"
 	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
-	"strings"
+	"time"
 )
 
-func (s *detectionService) ProcessFrame(frame []byte) (*entity.DetectionResult, error) {
-	result, err := s.websocketPkg.ProcessFaceFrame(frame)
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
-}
+// ProcessFrame returns the framing guidance for a face frame and advances the
+// connection's liveness session. The frame that completes the last challenge
+// is the one the signed liveness result vouches for, and the result is bound
+// to userID.
+func (s *detectionService) ProcessFrame(ctx context.Context, userID string, session *liveness.Session, frame []byte) (*detection.FaceFrameResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
 
-func (s *detectionService) ProcessKTPFrame(frame []byte) (*entity.KTPDetectionResult, error) {
-	result, err := s.websocketPkg.ProcessKTPFrame(frame)
+	result, err := s.websocketPkg.ProcessFaceFrame(ctx, frame)
 	if err != nil {
 		return nil, err
 	}
-	return result, nil
-}
 
-func (s *detectionService) ProcessQRISFrame(frame []byte) (*entity.QRISDetectionResult, error) {
-	result, err := s.websocketPkg.ProcessQRISFrame(frame)
-	if err != nil {
-		return nil, err
+	wasPassed := session.Status() == liveness.StatusPassed
+	res := &detection.FaceFrameResponse{
+		DetectionResult: result,
+		Liveness:        session.Observe(*result, time.Now()),
 	}
-	return result, nil
-}
 
-func (s *detectionService) ExtractAndSaveKTP(ctx context.Context, base64Image string) (*detection.KTP, error) {
-	prompt := `
-	Ekstrak semua informasi dari KTP Indonesia ini dan berikan hasilnya dalam format JSON.
-	Format output yang diinginkan:
-	{
-		"nik": "1234567890123456",
-		"nama": "NAMA LENGKAP",
-		"tempat_lahir": "KOTA",
-		"tanggal_lahir": "01-01-1990",
-		"jenis_kelamin": "LAKI-LAKI/PEREMPUAN",
-		"golongan_darah": "A/B/AB/O",
-		"alamat": "ALAMAT LENGKAP",
-		"rt": "001",
-		"rw": "002",
-		"kelurahan": "NAMA KELURAHAN",
-		"kecamatan": "NAMA KECAMATAN",
-		"agama": "ISLAM/KRISTEN/KATOLIK/dll",
-		"status_perkawinan": "BELUM KAWIN/KAWIN/CERAI HIDUP/CERAI MATI",
-		"pekerjaan": "JENIS PEKERJAAN",
-		"kewarganegaraan": "WNI/WNA",
-		"berlaku_hingga": "SEUMUR HIDUP/31-12-2025"
+	if wasPassed || res.Liveness.Status != liveness.StatusPassed {
+		return res, nil
 	}
-	Berikan HANYA respons JSON, tanpa teks tambahan apapun.
-	`
 
-	result, err := s.gemini.AnalyzeImage(ctx, base64Image, prompt)
+	token, expiresAt, err := s.liveness.Issue(userID, frame, session.Challenges())
 	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to issue liveness token")
 		return nil, err
 	}
 
-	ktp, err := parseGeminiResponse(result)
+	res.LivenessResult = &detection.LivenessResult{
+		Token:       token,
+		FrameSHA256: liveness.FrameHash(frame),
+		ExpiresAt:   expiresAt,
+	}
 
-	return ktp, nil
+	return res, nil
 }
 
-func parseGeminiResponse(response string) (*detection.KTP, error) {
-	jsonStart := strings.Index(response, "{")
-	jsonEnd := strings.LastIndex(response, "}")
-
-	if jsonStart == -1 || jsonEnd == -1 || jsonEnd <= jsonStart {
-		return nil, errors.New("cannot find valid JSON in response")
+func (s *detectionService) ProcessKTPFrame(ctx context.Context, frame []byte) (*entity.KTPDetectionResult, error) {
+	result, err := s.websocketPkg.ProcessKTPFrame(ctx, frame)
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	jsonStr := response[jsonStart : jsonEnd+1]
-
-	var ktp detection.KTP
-	err := json.Unmarshal([]byte(jsonStr), &ktp)
+func (s *detectionService) ProcessQRISFrame(ctx context.Context, frame []byte) (*entity.QRISDetectionResult, error) {
+	result, err := s.websocketPkg.ProcessQRISFrame(ctx, frame)
 	if err != nil {
 		return nil, err
 	}
+	return result, nil
+}
 
-	if ktp.NIK == "" || ktp.Nama == "" {
-		return nil, errors.New("failed to extract essential KTP information")
+func (s *detectionService) extractKTP(ctx context.Context, userID string, base64Image string) (*detection.KTP, *detection.Quota, error) {
+	var ktp detection.KTP
+	quota, err := s.extract(ctx, userID, ktpPrompt, base64Image, &ktp)
+	if err != nil {
+		return nil, nil, geminiError(err, detection.ErrKTPUnreadable)
 	}
 
-	return &ktp, nil
+	return &ktp, quota, nil
 }
 
-func (s *detectionService) DetectMoney(ctx context.Context, base64Image string) (*detection.MoneyDetectionResponse, error) {
-	prompt := `
-	Identifikasi uang Rupiah pada gambar ini dan berikan hasilnya dalam format JSON.
-	
-	Hal-hal yang perlu diidentifikasi:
-	1. Nominal/nilai uang dalam angka (contoh: 50000, 100000)
-	2. Jenis uang (kertas/logam)
-	3. Gambar/tokoh yang ada di uang tersebut
-	
-	Format output yang diinginkan:
-	{
-		"nominal": 50000,
-		"jenis": "kertas",
-		"tokoh": "I Gusti Ngurah Rai",
-		"warna_dominan": "Biru"
+func (s *detectionService) DetectMoney(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (*detection.MoneyDetectionResponse, error) {
+	if speech == "" {
+		speech = detection.SpeechText
 	}
-	
-	Jika terdapat lebih dari satu uang dalam gambar, identifikasi semua uang tersebut dan jumlahkan nilai totalnya. 
-	Contoh output jika terdapat lebih dari satu uang:
-	{
-		"detail": [
-			{
-				"nominal": 50000,
-				"jenis": "kertas",
-				"tokoh": "I Gusti Ngurah Rai",
-				"warna_dominan": "Biru"
-			},
-			{
-				"nominal": 100000,
-				"jenis": "kertas",
-			}
-		],
-		"total": 150000
+	if !speech.IsValid() {
+		return nil, detection.ErrInvalidSpeechMode
 	}
-	
-	Berikan HANYA respons JSON, tanpa teks tambahan apapun.
-	`
 
-	result, err := s.gemini.AnalyzeImage(ctx, base64Image, prompt)
+	var output moneyOutput
+	quota, err := s.extract(ctx, userID, moneyPrompt, base64Image, &output)
 	if err != nil {
-		return nil, err
+		return nil, geminiError(err, detection.ErrImageUnreadable)
 	}
-
-	return parseMoneyResponse(result)
-}
-
-func parseMoneyResponse(response string) (*detection.MoneyDetectionResponse, error) {
-	jsonStart := strings.Index(response, "{")
-	jsonEnd := strings.LastIndex(response, "}")
-
-	if jsonStart == -1 || jsonEnd == -1 || jsonEnd <= jsonStart {
-		return nil, errors.New("cannot find valid JSON in response")
+	if output.Total == 0 {
+		return nil, fmt.Errorf("%w: no money found", detection.ErrImageUnreadable)
 	}
 
-	jsonStr := response[jsonStart : jsonEnd+1]
-
-	var multipleResponse detection.MoneyDetectionMultiple
-	err := json.Unmarshal([]byte(jsonStr), &multipleResponse)
-	if err == nil && len(multipleResponse.Detail) > 0 {
-		return &detection.MoneyDetectionResponse{
-			Multiple: true,
-			Details:  multipleResponse.Detail,
-			Total:    multipleResponse.Total,
-		}, nil
+	res := &detection.MoneyDetectionResponse{
+		Multiple: len(output.Detail) > 1,
+		Details:  make([]detection.MoneyDetail, 0, len(output.Detail)),
+		Total:    output.Total,
+		Quota:    quota,
 	}
-
-	var singleResponse detection.MoneyDetail
-	err = json.Unmarshal([]byte(jsonStr), &singleResponse)
-	if err != nil {
-		return nil, errors.New("failed to parse Gemini response as valid JSON")
+	for _, note := range output.Detail {
+		res.Details = append(res.Details, detection.MoneyDetail{Nominal: note.Nominal, Jenis: note.Jenis})
 	}
 
-	if singleResponse.Nominal == 0 {
-		return nil, errors.New("failed to extract money value information")
-	}
+	res.SpokenInstruction, res.Speech = s.speak(ctx, speech, spokenMoney(res))
+	return res, nil
+}
 
-	return &detection.MoneyDetectionResponse{
-		Multiple: false,
-		Details:  []detection.MoneyDetail{singleResponse},
-		Total:    singleResponse.Nominal,
-	}, nil
+// geminiError maps an extraction failure onto the domain: output that never
+// validated means the image could not be read, a bad image is the client's
+// fault, and anything else is passed on.
+func geminiError(err error, unreadable error) error {
+	switch {
+	case errors.Is(err, gemini.ErrInvalidOutput):
+		return fmt.Errorf("%w: %v", unreadable, err)
+	case errors.Is(err, gemini.ErrInvalidImage):
+		return fmt.Errorf("%w: %v", detection.ErrBadRequest, err)
+	default:
+		return err
+	}
 }
@@ -0,0 +1,144 @@
+package detectionService
+
+import (
+	"ProjectGolang/internal/api/detection"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+const validMoney = `{"detail": [{"nominal": 50000, "jenis": "kertas"}], "total": 50000}`
+
+// geminiUses returns how many Gemini calls are counted against the test user
+// today.
+func (d *detectionTest) geminiUses(t *testing.T) int64 {
+	t.Helper()
+
+	windowStart := time.Now().UTC().Truncate(geminiQuotaWindow)
+	used, err := d.redis.Count(context.Background(), quotaKey("gemini", testUserID, windowStart))
+	require.NoError(t, err)
+	return used
+}
+
+func TestDetectMoneyRepromptsInvalidOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		invalid string
+		problem string
+	}{
+		{
+			name:    "not JSON",
+			invalid: `Ada satu lembar uang 50 ribu.`,
+			problem: "answer is not JSON matching the schema",
+		},
+		{
+			name:    "unknown field",
+			invalid: `{"detail": [], "total": 0, "currency": "IDR"}`,
+			problem: `unknown field "currency"`,
+		},
+		{
+			name:    "nominal that does not exist",
+			invalid: `{"detail": [{"nominal": 30000, "jenis": "kertas"}], "total": 30000}`,
+			problem: `detail[0].nominal fails "oneof"`,
+		},
+		{
+			name:    "total that does not add up",
+			invalid: `{"detail": [{"nominal": 50000, "jenis": "kertas"}], "total": 70000}`,
+			problem: "total is 70000 but the nominals add up to 50000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDetectionTest(t)
+			d.gemini.Add(moneyPrompt.ID(), tt.invalid, validMoney)
+
+			res, err := d.service.DetectMoney(context.Background(), testUserID, testImage, detection.SpeechText)
+
+			require.NoError(t, err)
+			assert.Equal(t, 50000, res.Total)
+			assert.False(t, res.Multiple)
+
+			requests := d.gemini.Requests()
+			require.Len(t, requests, 2)
+			assert.Equal(t, moneyPrompt.Text, requests[0].Text)
+			assert.True(t, strings.HasPrefix(requests[1].Text, moneyPrompt.Text))
+			assert.Contains(t, requests[1].Text, tt.problem, "the re-prompt must tell Gemini what was wrong")
+
+			assert.Equal(t, int64(1), d.geminiUses(t), "a re-prompt is part of the same analysis")
+			assert.Equal(t, int64(4), res.Quota.Remaining)
+		})
+	}
+}
+
+func TestDetectMoneyGivesUpAfterReprompts(t *testing.T) {
+	d := newDetectionTest(t)
+	d.gemini.Add(moneyPrompt.ID(), `{"detail": [], "total": -1}`)
+
+	_, err := d.service.DetectMoney(context.Background(), testUserID, testImage, detection.SpeechText)
+
+	assert.ErrorIs(t, err, detection.ErrImageUnreadable)
+	assert.Len(t, d.gemini.Requests(), 2, "one answer and one re-prompt")
+	assert.Equal(t, int64(1), d.geminiUses(t), "an unreadable image still counts against the quota")
+}
+
+func TestDetectMoneyRefundsFailedGeminiCall(t *testing.T) {
+	d := newDetectionTest(t)
+	// Without a recorded answer the fake fails like an unreachable Gemini.
+	d.gemini.Add(moneyPrompt.ID())
+
+	_, err := d.service.DetectMoney(context.Background(), testUserID, testImage, detection.SpeechText)
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, detection.ErrImageUnreadable)
+	assert.Equal(t, int64(0), d.geminiUses(t), "a Gemini failure must not use up the quota")
+}
+
+func TestDetectMoneyQuotaExceeded(t *testing.T) {
+	d := newDetectionTest(t)
+
+	for i := int64(0); i < d.service.geminiQuota; i++ {
+		_, err := d.service.DetectMoney(context.Background(), testUserID, testImage, detection.SpeechText)
+		require.NoError(t, err)
+	}
+
+	_, err := d.service.DetectMoney(context.Background(), testUserID, testImage, detection.SpeechText)
+
+	assert.ErrorIs(t, err, detection.ErrGeminiQuotaExceeded)
+	assert.Len(t, d.gemini.Requests(), int(d.service.geminiQuota), "Gemini must not be called past the quota")
+}
+
+func TestExtractKTPDraft(t *testing.T) {
+	d := newDetectionTest(t)
+
+	draft, err := d.service.ExtractKTPDraft(context.Background(), testUserID, testImage)
+
+	require.NoError(t, err)
+	assert.Equal(t, "3171012501900001", draft.Profile.NationalIdentityNumber)
+	assert.Equal(t, "BUDI SANTOSO", draft.Profile.Name)
+	assert.Equal(t, "DKI JAKARTA", draft.Profile.Province)
+	assert.Equal(t, "JAKARTA PUSAT", draft.Profile.Regency)
+	assert.Empty(t, draft.Issues, "the recorded KTP is consistent with its NIK")
+
+	stored, err := d.redis.GetOTP(context.Background(), ktpDraftKey(testUserID))
+	require.NoError(t, err)
+	assert.Contains(t, stored, "3171012501900001", "the draft must be kept for confirmation")
+}
+
+func TestExtractKTPDraftRepromptsMissingNIK(t *testing.T) {
+	d := newDetectionTest(t)
+	d.gemini.Add(ktpPrompt.ID(), `{"nama": "BUDI SANTOSO", "nik": "31710125"}`)
+
+	_, err := d.service.ExtractKTPDraft(context.Background(), testUserID, testImage)
+
+	assert.ErrorIs(t, err, detection.ErrKTPUnreadable)
+	requests := d.gemini.Requests()
+	require.Len(t, requests, 2)
+	assert.Contains(t, requests[1].Text, `nik fails "len" (16)`)
+
+	_, err = d.redis.GetOTP(context.Background(), ktpDraftKey(testUserID))
+	assert.Error(t, err, "no draft may be kept for an unreadable KTP")
+}
@@ -0,0 +1,269 @@
+package detectionService
+
+import (
+	"ProjectGolang/internal/api/auth"
+	"ProjectGolang/internal/api/detection"
+	"ProjectGolang/internal/entity"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/nik"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"strings"
+	"time"
+)
+
+const (
+	ktpDraftTTL = 30 * time.Minute
+	ktpLifetime = "SEUMUR HIDUP"
+	dateLayout  = "2006-01-02"
+)
+
+// ktpDateLayouts are the date formats seen on KTPs and in OCR output.
+var ktpDateLayouts = []string{
+	"02-01-2006",
+	"2-1-2006",
+	"02/01/2006",
+	"2/1/2006",
+	"02.01.2006",
+	"02 01 2006",
+	dateLayout,
+}
+
+func ktpDraftKey(userID string) string {
+	return "ktp:draft:" + userID
+}
+
+// ExtractKTPDraft reads the KTP in base64Image and keeps the result as a draft
+// for the user to review. Nothing is written to the profile until the draft
+// is confirmed with ConfirmKTPDraft.
+func (s *detectionService) ExtractKTPDraft(ctx context.Context, userID string, base64Image string) (*detection.KTPDraft, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	ktp, quota, err := s.extractKTP(ctx, userID, base64Image)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Warn("Failed to extract KTP")
+		return nil, err
+	}
+
+	profile := buildKTPProfile(*ktp)
+	draft := detection.KTPDraft{
+		KTP:       *ktp,
+		Profile:   profile,
+		Issues:    checkKTPProfile(profile),
+		ExpiresAt: time.Now().Add(ktpDraftTTL),
+	}
+
+	payload, err := json.Marshal(draft)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.redisServer.SetOTP(ctx, ktpDraftKey(userID), string(payload), ktpDraftTTL); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to store KTP draft in Redis")
+		return nil, err
+	}
+
+	draft.Quota = quota
+	return &draft, nil
+}
+
+// ConfirmKTPDraft applies the user's corrections to the pending draft and, if
+// the result is consistent with its NIK, saves it to the user's profile.
+func (s *detectionService) ConfirmKTPDraft(ctx context.Context, user entity.UserLoginData, req detection.ConfirmKTPRequest) (*detection.KTPProfile, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	payload, err := s.redisServer.GetOTP(ctx, ktpDraftKey(user.ID))
+	if err != nil {
+		return nil, detection.ErrKTPDraftNotFound
+	}
+
+	var draft detection.KTPDraft
+	if err := json.Unmarshal([]byte(payload), &draft); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to decode KTP draft")
+		return nil, detection.ErrKTPDraftNotFound
+	}
+
+	profile := applyKTPCorrections(draft.Profile, req.Corrections)
+	if issues := checkKTPProfile(profile); len(issues) > 0 {
+		return nil, fmt.Errorf("%w: %s", detection.ErrKTPInconsistent, strings.Join(issues, "; "))
+	}
+
+	if err := s.authService.User().UpdateUser(ctx, user, auth.UpdateUserRequest{
+		Name:                      profile.Name,
+		NationalIdentityNumber:    profile.NationalIdentityNumber,
+		BirthPlace:                profile.BirthPlace,
+		BirthDate:                 profile.BirthDate,
+		Gender:                    profile.Gender,
+		Address:                   profile.Address,
+		NeighborhoodCommunityUnit: profile.NeighborhoodCommunityUnit,
+		Village:                   profile.Village,
+		District:                  profile.District,
+		Religion:                  profile.Religion,
+		MaritalStatus:             profile.MaritalStatus,
+		Profession:                profile.Profession,
+		Citizenship:               profile.Citizenship,
+		CardValidUntil:            profile.CardValidUntil,
+	}); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    user.ID,
+			"error":      err.Error(),
+		}).Error("Failed to save KTP to user profile")
+		return nil, err
+	}
+
+	if err := s.redisServer.DeleteOTP(ctx, ktpDraftKey(user.ID)); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to delete KTP draft from Redis")
+	}
+
+	return &profile, nil
+}
+
+func buildKTPProfile(ktp detection.KTP) detection.KTPProfile {
+	neighborhood := strings.TrimSpace(ktp.RT)
+	if rw := strings.TrimSpace(ktp.RW); rw != "" {
+		neighborhood += "/" + rw
+	}
+
+	return detection.KTPProfile{
+		Province:                  normalizeRegionName(ktp.Provinsi),
+		Regency:                   normalizeRegionName(ktp.KabupatenKota),
+		Name:                      strings.TrimSpace(ktp.Nama),
+		NationalIdentityNumber:    strings.Join(strings.Fields(ktp.NIK), ""),
+		BirthPlace:                strings.TrimSpace(ktp.TempatLahir),
+		BirthDate:                 normalizeKTPDate(ktp.TanggalLahir),
+		Gender:                    normalizeGender(ktp.JenisKelamin),
+		Address:                   strings.TrimSpace(ktp.Alamat),
+		NeighborhoodCommunityUnit: neighborhood,
+		Village:                   strings.TrimSpace(ktp.Kelurahan),
+		District:                  strings.TrimSpace(ktp.Kecamatan),
+		Religion:                  strings.TrimSpace(ktp.Agama),
+		MaritalStatus:             strings.TrimSpace(ktp.StatusPerkawinan),
+		Profession:                strings.TrimSpace(ktp.Pekerjaan),
+		Citizenship:               strings.TrimSpace(ktp.Kewarganegaraan),
+		CardValidUntil:            normalizeKTPDate(ktp.BerlakuHingga),
+	}
+}
+
+func applyKTPCorrections(profile detection.KTPProfile, corrections detection.KTPProfile) detection.KTPProfile {
+	override := func(field *string, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			*field = value
+		}
+	}
+
+	override(&profile.Province, normalizeRegionName(corrections.Province))
+	override(&profile.Regency, normalizeRegionName(corrections.Regency))
+	override(&profile.Name, corrections.Name)
+	override(&profile.NationalIdentityNumber, strings.Join(strings.Fields(corrections.NationalIdentityNumber), ""))
+	override(&profile.BirthPlace, corrections.BirthPlace)
+	override(&profile.BirthDate, normalizeKTPDate(corrections.BirthDate))
+	override(&profile.Gender, normalizeGender(corrections.Gender))
+	override(&profile.Address, corrections.Address)
+	override(&profile.NeighborhoodCommunityUnit, corrections.NeighborhoodCommunityUnit)
+	override(&profile.Village, corrections.Village)
+	override(&profile.District, corrections.District)
+	override(&profile.Religion, corrections.Religion)
+	override(&profile.MaritalStatus, corrections.MaritalStatus)
+	override(&profile.Profession, corrections.Profession)
+	override(&profile.Citizenship, corrections.Citizenship)
+	override(&profile.CardValidUntil, normalizeKTPDate(corrections.CardValidUntil))
+
+	return profile
+}
+
+// checkKTPProfile lists everything that keeps the profile from being saved,
+// including a NIK whose region, birth date or gender digits disagree with the
+// rest of the card.
+func checkKTPProfile(profile detection.KTPProfile) []string {
+	var issues []string
+
+	if profile.Name == "" {
+		issues = append(issues, "name is missing")
+	}
+
+	if profile.Gender != "" && profile.Gender != nik.GenderMale && profile.Gender != nik.GenderFemale {
+		issues = append(issues, fmt.Sprintf("gender %q is not %s or %s", profile.Gender, nik.GenderMale, nik.GenderFemale))
+	}
+
+	var birthDate time.Time
+	if profile.BirthDate == "" {
+		issues = append(issues, "birth date is missing")
+	} else if parsed, err := time.Parse(dateLayout, profile.BirthDate); err != nil {
+		issues = append(issues, fmt.Sprintf("birth date %q is not a valid date", profile.BirthDate))
+	} else if parsed.After(time.Now()) {
+		issues = append(issues, "birth date is in the future")
+	} else {
+		birthDate = parsed
+	}
+
+	if profile.CardValidUntil != "" {
+		if _, err := time.Parse(dateLayout, profile.CardValidUntil); err != nil {
+			issues = append(issues, fmt.Sprintf("card validity %q is not a valid date", profile.CardValidUntil))
+		}
+	}
+
+	parsedNIK, err := nik.Parse(profile.NationalIdentityNumber)
+	if err != nil {
+		return append(issues, err.Error())
+	}
+
+	issues = append(issues, parsedNIK.Mismatches(birthDate, profile.Gender)...)
+	return append(issues, parsedNIK.RegionMismatches(profile.Province, profile.Regency)...)
+}
+
+// normalizeKTPDate converts the dates printed on a KTP to YYYY-MM-DD.
+// "SEUMUR HIDUP" becomes entity.CardValidForLife. Values that cannot be parsed
+// are returned trimmed so checkKTPProfile can report them.
+func normalizeKTPDate(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return ""
+	}
+
+	if strings.Join(strings.Fields(value), " ") == ktpLifetime {
+		return entity.CardValidForLife.Format(dateLayout)
+	}
+
+	for _, layout := range ktpDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.Format(dateLayout)
+		}
+	}
+
+	return value
+}
+
+// normalizeRegionName upper-cases a province or regency and drops the
+// "PROVINSI" prefix printed in the card header.
+func normalizeRegionName(value string) string {
+	value = strings.Join(strings.Fields(strings.ToUpper(value)), " ")
+	return strings.TrimSpace(strings.TrimPrefix(value, "PROVINSI"))
+}
+
+func normalizeGender(value string) string {
+	switch strings.Join(strings.Fields(strings.ToUpper(value)), " ") {
+	case "":
+		return ""
+	case "LAKI-LAKI", "LAKI LAKI", "LAKI", "PRIA", "L", "MALE":
+		return nik.GenderMale
+	case "PEREMPUAN", "WANITA", "P", "FEMALE":
+		return nik.GenderFemale
+	default:
+		return strings.TrimSpace(value)
+	}
+}
@@ -0,0 +1,257 @@
+package detectionService
+
+import (
+	"ProjectGolang/pkg/gemini"
+	"errors"
+	"fmt"
+)
+
+// The Gemini prompts of the detection domain, each followed by the output it
+// is decoded and validated into. Gemini's answer is constrained to the JSON
+// schema of the output type, so the examples in the prompts only illustrate.
+
+var ktpPrompt = gemini.Prompt{
+	Name:    "ktp",
+	Version: 3,
+	Text: `
+	Ekstrak semua informasi dari KTP Indonesia ini dan berikan hasilnya dalam format JSON.
+	Tulis teks persis seperti tercetak, dalam huruf kapital. Kosongkan kolom yang tidak terbaca.
+	"provinsi" dan "kabupaten_kota" diambil dari judul kartu, tanpa kata "PROVINSI".
+	Format output yang diinginkan:
+	{
+		"provinsi": "JAWA BARAT",
+		"kabupaten_kota": "KABUPATEN BANDUNG/KOTA BANDUNG",
+		"nik": "1234567890123456",
+		"nama": "NAMA LENGKAP",
+		"tempat_lahir": "KOTA",
+		"tanggal_lahir": "01-01-1990",
+		"jenis_kelamin": "LAKI-LAKI/PEREMPUAN",
+		"golongan_darah": "A/B/AB/O",
+		"alamat": "ALAMAT LENGKAP",
+		"rt": "001",
+		"rw": "002",
+		"kelurahan": "NAMA KELURAHAN",
+		"kecamatan": "NAMA KECAMATAN",
+		"agama": "ISLAM/KRISTEN/KATOLIK/dll",
+		"status_perkawinan": "BELUM KAWIN/KAWIN/CERAI HIDUP/CERAI MATI",
+		"pekerjaan": "JENIS PEKERJAAN",
+		"kewarganegaraan": "WNI/WNA",
+		"berlaku_hingga": "SEUMUR HIDUP/31-12-2025"
+	}
+	Berikan HANYA respons JSON, tanpa teks tambahan apapun.
+	`,
+}
+
+var moneyPrompt = gemini.Prompt{
+	Name:    "money",
+	Version: 2,
+	Text: `
+	Identifikasi semua uang Rupiah pada gambar ini dan berikan hasilnya dalam format JSON.
+
+	Untuk setiap uang sebutkan:
+	1. Nominal/nilai uang dalam angka (contoh: 50000, 100000)
+	2. Jenis uang: "kertas" atau "logam"
+
+	Jumlahkan nilai semua uang sebagai total.
+
+	Format output yang diinginkan:
+	{
+		"detail": [
+			{"nominal": 50000, "jenis": "kertas"},
+			{"nominal": 100000, "jenis": "kertas"}
+		],
+		"total": 150000
+	}
+
+	Jika tidak ada uang pada gambar, kembalikan {"detail": [], "total": 0}.
+	Berikan HANYA respons JSON, tanpa teks tambahan apapun.
+	`,
+}
+
+type moneyOutput struct {
+	Detail []moneyNoteOutput `json:"detail" validate:"dive"`
+	Total  int               `json:"total" validate:"min=0"`
+}
+
+type moneyNoteOutput struct {
+	Nominal int    `json:"nominal" validate:"oneof=100 200 500 1000 2000 5000 10000 20000 50000 75000 100000"`
+	Jenis   string `json:"jenis" validate:"oneof=kertas logam"`
+}
+
+func (o *moneyOutput) Validate() error {
+	sum := 0
+	for _, note := range o.Detail {
+		sum += note.Nominal
+	}
+	if sum != o.Total {
+		return fmt.Errorf("total is %d but the nominals add up to %d", o.Total, sum)
+	}
+	return nil
+}
+
+var readTextPrompt = gemini.Prompt{
+	Name:    "read_text",
+	Version: 1,
+	Text: `
+	Baca semua teks yang terlihat pada gambar ini, misalnya dokumen, surat, papan nama atau rambu.
+	Gambar ini diambil oleh pengguna tunanetra, jadi jangan menebak teks yang tidak terbaca.
+
+	Urutkan teks sesuai urutan membaca yang wajar: dari atas ke bawah, kiri ke kanan, per kolom jika ada kolom.
+	Pisahkan teks menjadi blok. Jenis blok adalah salah satu dari: "judul", "paragraf", "baris", "tabel", "lainnya".
+
+	Format output:
+	{
+		"found": true,
+		"language": "id",
+		"blocks": [
+			{"type": "judul", "text": "PENGUMUMAN"},
+			{"type": "paragraf", "text": "Kantor tutup pada hari Senin."}
+		]
+	}
+
+	Jika tidak ada teks yang terbaca, kembalikan {"found": false, "blocks": []}.
+	Berikan HANYA respons JSON, tanpa teks tambahan apapun.
+	`,
+}
+
+type readTextOutput struct {
+	Found    bool              `json:"found"`
+	Language string            `json:"language,omitempty"`
+	Blocks   []textBlockOutput `json:"blocks" validate:"dive"`
+}
+
+type textBlockOutput struct {
+	Type string `json:"type" validate:"oneof=judul paragraf baris tabel lainnya"`
+	Text string `json:"text" validate:"required"`
+}
+
+func (o *readTextOutput) Validate() error {
+	if o.Found != (len(o.Blocks) > 0) {
+		return errors.New("found must be true exactly when blocks are returned")
+	}
+	return nil
+}
+
+var describeScenePrompt = gemini.Prompt{
+	Name:    "describe_scene",
+	Version: 1,
+	Text: `
+	Jelaskan apa yang terlihat pada gambar ini untuk pengguna tunanetra, dalam bahasa Indonesia.
+
+	Hal-hal yang perlu dijelaskan:
+	1. Ringkasan singkat, paling banyak dua kalimat
+	2. Jenis tempat (contoh: dapur, jalan raya, kantor)
+	3. Benda-benda penting beserta posisinya dari sudut pandang kamera: "kiri", "tengah", "kanan", "dekat" atau "jauh"
+	4. Jumlah orang yang terlihat
+	5. Bahaya yang bisa menabrak atau membuat pengguna tersandung, misalnya tangga, lubang atau kendaraan
+
+	Format output:
+	{
+		"summary": "Dapur dengan meja di depan Anda.",
+		"setting": "dapur",
+		"objects": [
+			{"name": "meja", "position": "tengah"},
+			{"name": "kompor", "position": "kiri"}
+		],
+		"people": 0,
+		"hazards": ["panci panas di atas kompor"]
+	}
+
+	Gunakan daftar kosong jika tidak ada benda atau bahaya.
+	Berikan HANYA respons JSON, tanpa teks tambahan apapun.
+	`,
+}
+
+type sceneOutput struct {
+	Summary string              `json:"summary" validate:"required"`
+	Setting string              `json:"setting,omitempty"`
+	Objects []sceneObjectOutput `json:"objects" validate:"dive"`
+	People  int                 `json:"people" validate:"min=0"`
+	Hazards []string            `json:"hazards" validate:"dive,required"`
+}
+
+type sceneObjectOutput struct {
+	Name     string `json:"name" validate:"required"`
+	Position string `json:"position" validate:"oneof=kiri tengah kanan dekat jauh"`
+}
+
+var productLabelPrompt = gemini.Prompt{
+	Name:    "product_label",
+	Version: 1,
+	Text: `
+	Identifikasi produk atau kemasan pada gambar ini dari labelnya.
+
+	Hal-hal yang perlu diidentifikasi:
+	1. Nama produk
+	2. Merek
+	3. Kategori (contoh: makanan, minuman, obat, kosmetik, pembersih)
+	4. Isi bersih (contoh: 250 ml, 1 kg)
+	5. Tanggal kedaluwarsa dalam format YYYY-MM-DD; jika hanya bulan dan tahun yang tertulis, gunakan tanggal terakhir bulan itu
+	6. Peringatan pada label (contoh: alergen, jauhkan dari jangkauan anak)
+
+	Format output:
+	{
+		"found": true,
+		"product_name": "Susu UHT Cokelat",
+		"brand": "Contoh",
+		"category": "minuman",
+		"net_content": "250 ml",
+		"expiry_date": "2025-08-31",
+		"warnings": ["mengandung susu"]
+	}
+
+	Kosongkan kolom yang tidak terbaca, jangan menebak tanggal kedaluwarsa.
+	Jika tidak ada produk pada gambar, kembalikan {"found": false, "warnings": []}.
+	Berikan HANYA respons JSON, tanpa teks tambahan apapun.
+	`,
+}
+
+type productLabelOutput struct {
+	Found       bool     `json:"found"`
+	ProductName string   `json:"product_name,omitempty"`
+	Brand       string   `json:"brand,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	NetContent  string   `json:"net_content,omitempty"`
+	ExpiryDate  string   `json:"expiry_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Warnings    []string `json:"warnings" validate:"dive,required"`
+}
+
+func (o *productLabelOutput) Validate() error {
+	if o.Found && o.ProductName == "" && o.Brand == "" {
+		return errors.New("a found product needs product_name or brand")
+	}
+	return nil
+}
+
+var clothingColorsPrompt = gemini.Prompt{
+	Name:    "clothing_colors",
+	Version: 1,
+	Text: `
+	Identifikasi setiap pakaian yang terlihat pada gambar ini beserta warnanya, dalam bahasa Indonesia.
+
+	Untuk setiap pakaian sebutkan:
+	1. Jenis pakaian (contoh: kemeja, celana panjang, rok, jaket)
+	2. Warna, dimulai dari warna yang paling dominan, dengan nama warna sehari-hari (contoh: biru tua, merah muda)
+	3. Motif jika ada (contoh: polos, garis-garis, kotak-kotak, bunga)
+
+	Format output:
+	{
+		"items": [
+			{"item": "kemeja", "colors": ["biru muda", "putih"], "pattern": "garis-garis"}
+		]
+	}
+
+	Jika tidak ada pakaian pada gambar, kembalikan {"items": []}.
+	Berikan HANYA respons JSON, tanpa teks tambahan apapun.
+	`,
+}
+
+type clothingColorsOutput struct {
+	Items []clothingItemOutput `json:"items" validate:"dive"`
+}
+
+type clothingItemOutput struct {
+	Item    string   `json:"item" validate:"required"`
+	Colors  []string `json:"colors" validate:"min=1,dive,required"`
+	Pattern string   `json:"pattern,omitempty"`
+}
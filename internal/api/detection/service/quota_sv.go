@@ -0,0 +1,115 @@
+package detectionService
+
+import (
+	"ProjectGolang/internal/api/detection"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/gemini"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+const geminiQuotaWindow = 24 * time.Hour
+
+// UseFrameQuota counts one frame sent by userID to the AI detection backends.
+// Frames are limited per minute across all detection WebSockets.
+func (s *detectionService) UseFrameQuota(ctx context.Context, userID string) (*detection.Quota, error) {
+	quota, err := s.useQuota(ctx, "frames", userID, s.frameQuota, time.Now().Truncate(time.Minute), time.Minute)
+	if err != nil {
+		return quota, err
+	}
+
+	if quota.Remaining < 0 {
+		quota.Remaining = 0
+		return quota, detection.ErrFrameQuotaExceeded
+	}
+
+	return quota, nil
+}
+
+// extract runs prompt over the image and decodes the answer into output,
+// counting the Gemini call against userID's quota. The call is given back when
+// Gemini itself fails, so an outage does not use up the quota; images that
+// cannot be read still count.
+func (s *detectionService) extract(ctx context.Context, userID string, prompt gemini.Prompt, base64Image string, output interface{}) (*detection.Quota, error) {
+	quota, err := s.useGeminiQuota(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.gemini.Extract(ctx, prompt, base64Image, output); err != nil {
+		if !errors.Is(err, gemini.ErrInvalidOutput) && !errors.Is(err, gemini.ErrInvalidImage) {
+			s.refundQuota(ctx, "gemini", userID, quota.ResetAt.Add(-geminiQuotaWindow))
+		}
+		return nil, err
+	}
+
+	return quota, nil
+}
+
+// useGeminiQuota counts one Gemini call made for userID. Calls are limited
+// per UTC day.
+func (s *detectionService) useGeminiQuota(ctx context.Context, userID string) (*detection.Quota, error) {
+	quota, err := s.useQuota(ctx, "gemini", userID, s.geminiQuota, time.Now().UTC().Truncate(geminiQuotaWindow), geminiQuotaWindow)
+	if err != nil {
+		return quota, err
+	}
+
+	if quota.Remaining < 0 {
+		quota.Remaining = 0
+		return quota, fmt.Errorf("%w, resets at %s", detection.ErrGeminiQuotaExceeded, quota.ResetAt.Format(time.RFC3339))
+	}
+
+	return quota, nil
+}
+
+// useQuota increments the fixed-window counter for resource and returns the
+// usage left in the window. Remaining goes negative once the limit is passed.
+func (s *detectionService) useQuota(ctx context.Context, resource string, userID string, limit int64, windowStart time.Time, window time.Duration) (*detection.Quota, error) {
+	resetAt := windowStart.Add(window)
+
+	used, err := s.redisServer.Incr(ctx, quotaKey(resource, userID, windowStart), time.Until(resetAt)+time.Minute)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"user_id":    userID,
+			"resource":   resource,
+			"error":      err.Error(),
+		}).Error("Failed to count quota usage")
+		return nil, detection.ErrInternalServerError
+	}
+
+	if used > limit {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"user_id":    userID,
+			"resource":   resource,
+			"limit":      limit,
+		}).Warn("Quota exceeded")
+	}
+
+	return &detection.Quota{
+		Limit:     limit,
+		Remaining: limit - used,
+		ResetAt:   resetAt,
+	}, nil
+}
+
+// refundQuota gives back one use counted by useQuota in the window starting
+// at windowStart.
+func (s *detectionService) refundQuota(ctx context.Context, resource string, userID string, windowStart time.Time) {
+	if _, err := s.redisServer.Decr(ctx, quotaKey(resource, userID, windowStart)); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"user_id":    userID,
+			"resource":   resource,
+			"error":      err.Error(),
+		}).Error("Failed to refund quota usage")
+	}
+}
+
+func quotaKey(resource string, userID string, windowStart time.Time) string {
+	return fmt.Sprintf("quota:%s:%s:%d", resource, userID, windowStart.Unix())
+}
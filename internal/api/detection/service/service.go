@@ -1,32 +1,71 @@
 package detectionService
 
 import (
+	authService "ProjectGolang/internal/api/auth/service"
 	"ProjectGolang/internal/api/detection"
 	"ProjectGolang/internal/entity"
 	"ProjectGolang/pkg/gemini"
+	"ProjectGolang/pkg/liveness"
+	"ProjectGolang/pkg/redis"
+	"ProjectGolang/pkg/tts"
 	websocketPkg "ProjectGolang/pkg/websocket"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 )
 
 type IDetectionService interface {
-	ProcessFrame(frame []byte) (*entity.DetectionResult, error)
-	ProcessKTPFrame(frame []byte) (*entity.KTPDetectionResult, error)
-	ProcessQRISFrame(frame []byte) (*entity.QRISDetectionResult, error)
-	ExtractAndSaveKTP(ctx context.Context, base64Image string) (*detection.KTP, error)
-	DetectMoney(ctx context.Context, base64Image string) (*detection.MoneyDetectionResponse, error)
+	UseFrameQuota(ctx context.Context, userID string) (*detection.Quota, error)
+	ProcessFrame(ctx context.Context, userID string, session *liveness.Session, frame []byte) (*detection.FaceFrameResponse, error)
+	ProcessKTPFrame(ctx context.Context, frame []byte) (*entity.KTPDetectionResult, error)
+	ProcessQRISFrame(ctx context.Context, frame []byte) (*entity.QRISDetectionResult, error)
+	Assist(ctx context.Context, userID string, session *liveness.Session, mode detection.Mode, speech detection.SpeechMode, frame []byte) (*detection.AssistResult, error)
+	ExtractKTPDraft(ctx context.Context, userID string, base64Image string) (*detection.KTPDraft, error)
+	ConfirmKTPDraft(ctx context.Context, user entity.UserLoginData, req detection.ConfirmKTPRequest) (*detection.KTPProfile, error)
+	DetectMoney(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (*detection.MoneyDetectionResponse, error)
+	ReadText(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (*detection.ReadTextResponse, error)
+	DescribeScene(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (*detection.SceneDescriptionResponse, error)
+	IdentifyProduct(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (*detection.ProductLabelResponse, error)
+	IdentifyClothingColors(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (*detection.ClothingColorsResponse, error)
+	SpeechClip(ctx context.Context, id string) (*tts.Clip, error)
 }
 
 type detectionService struct {
+	log          *logrus.Logger
 	websocketPkg websocketPkg.IWebsocket
-	gemini       gemini.IGemini
+	gemini       *gemini.Extractor
+	redisServer  redis.IRedis
+	authService  authService.AuthService
+	liveness     liveness.ITokens
+	speaker      tts.ISpeaker
+	frameQuota   int64
+	geminiQuota  int64
+}
+
+// Config holds the per-user limits on calls to the AI backends.
+type Config struct {
+	FrameQuotaPerMinute int64 `env:"FRAME_QUOTA_PER_MINUTE" default:"300"`
+	GeminiQuotaPerDay   int64 `env:"GEMINI_QUOTA_PER_DAY" default:"50"`
 }
 
 func NewDetectionService(
+	log *logrus.Logger,
+	cfg Config,
 	websocket websocketPkg.IWebsocket,
-	gemini gemini.IGemini,
+	extractor *gemini.Extractor,
+	redisServer redis.IRedis,
+	authService authService.AuthService,
+	livenessTokens liveness.ITokens,
+	speaker tts.ISpeaker,
 ) IDetectionService {
 	return &detectionService{
+		log:          log,
 		websocketPkg: websocket,
-		gemini:       gemini,
+		gemini:       extractor,
+		redisServer:  redisServer,
+		authService:  authService,
+		liveness:     livenessTokens,
+		speaker:      speaker,
+		frameQuota:   cfg.FrameQuotaPerMinute,
+		geminiQuota:  cfg.GeminiQuotaPerDay,
 	}
 }
@@ -0,0 +1,53 @@
+package detectionService
+
+import (
+	"ProjectGolang/pkg/gemini"
+	"ProjectGolang/pkg/redis"
+	"ProjectGolang/pkg/tts"
+	"github.com/sirupsen/logrus"
+	"io"
+	"testing"
+)
+
+const (
+	testUserID = "user-1"
+	// testImage stands in for a photo; the fake Gemini does not look at it.
+	testImage = "aW1hZ2U="
+)
+
+type detectionTest struct {
+	service     *detectionService
+	gemini      *gemini.FakeGemini
+	synthesizer *tts.FakeSynthesizer
+	redis       *redis.FakeRedis
+}
+
+// newDetectionTest builds the service on the recorded Gemini answers, the
+// offline synthesizer and an in-memory Redis.
+func newDetectionTest(t *testing.T) *detectionTest {
+	t.Helper()
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	fakeGemini := gemini.NewFake()
+	synthesizer := tts.NewFake()
+	redisServer := redis.NewFake()
+
+	extractor := gemini.NewExtractor(fakeGemini, log, gemini.Config{
+		TimeoutSeconds: 1,
+		Reprompts:      1,
+	})
+
+	service := NewDetectionService(log, Config{
+		FrameQuotaPerMinute: 10,
+		GeminiQuotaPerDay:   5,
+	}, nil, extractor, redisServer, nil, nil, tts.NewSpeaker(synthesizer, redisServer))
+
+	return &detectionTest{
+		service:     service.(*detectionService),
+		gemini:      fakeGemini,
+		synthesizer: synthesizer,
+		redis:       redisServer,
+	}
+}
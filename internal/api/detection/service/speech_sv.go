@@ -0,0 +1,112 @@
+package detectionService
+
+import (
+	"ProjectGolang/internal/api/detection"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/tts"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"strings"
+)
+
+// speechClipPath is where clients fetch the audio of a clip ID.
+const speechClipPath = "/api/v1/tts/clips/"
+
+// faceInstructions translates the framing instructions of the face detector
+// into the short Indonesian sentences read out to the user.
+var faceInstructions = map[string]string{
+	"move left":        "Geser ke kiri",
+	"move right":       "Geser ke kanan",
+	"move up":          "Geser ke atas",
+	"move down":        "Geser ke bawah",
+	"move closer":      "Dekatkan wajah",
+	"move back":        "Jauhkan wajah",
+	"move further":     "Jauhkan wajah",
+	"move away":        "Jauhkan wajah",
+	"hold still":       "Tahan posisi",
+	"center your face": "Posisikan wajah di tengah layar",
+	"open your eyes":   "Buka mata",
+	"face the camera":  "Hadapkan wajah ke kamera",
+}
+
+// speak fills in the spoken sentence the way speech asks for it and returns
+// the text to send along with the audio clip, if any. Speech only ever adds
+// to a result: when the clip cannot be made the sentence falls back to text
+// rather than failing the detection.
+func (s *detectionService) speak(ctx context.Context, speech detection.SpeechMode, text string) (string, *detection.Speech) {
+	if !speech.WantsAudio() || text == "" {
+		return text, nil
+	}
+
+	if s.speaker == nil {
+		return text, nil
+	}
+
+	clip, err := s.speaker.Speak(ctx, text)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"text":       text,
+			"error":      err.Error(),
+		}).Warn("Failed to synthesize spoken instruction")
+		return text, nil
+	}
+
+	res := &detection.Speech{
+		ID:       clip.ID,
+		URL:      speechClipPath + clip.ID,
+		MimeType: clip.MimeType,
+	}
+	if !speech.WantsText() {
+		return "", res
+	}
+	return text, res
+}
+
+func (s *detectionService) SpeechClip(ctx context.Context, id string) (*tts.Clip, error) {
+	if s.speaker == nil {
+		return nil, detection.ErrSpeechClipNotFound
+	}
+
+	clip, err := s.speaker.Clip(ctx, id)
+	if errors.Is(err, tts.ErrClipNotFound) {
+		return nil, detection.ErrSpeechClipNotFound
+	} else if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"clip_id":    id,
+			"error":      err.Error(),
+		}).Error("Failed to load speech clip")
+		return nil, detection.ErrInternalServerError
+	}
+
+	return clip, nil
+}
+
+// translateFaceInstruction returns the Indonesian sentence for the first
+// framing instruction of the face detector it knows.
+func translateFaceInstruction(instructions []string) (string, bool) {
+	for _, instruction := range instructions {
+		key := strings.ToLower(strings.Trim(strings.TrimSpace(instruction), ".!"))
+		if spoken, ok := faceInstructions[key]; ok {
+			return spoken, true
+		}
+	}
+	return "", false
+}
+
+// truncateSpoken shortens text to what can be synthesized, cutting at a word
+// boundary. The full text stays in the response for the screen reader.
+func truncateSpoken(text string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= tts.MaxTextLength {
+		return string(runes)
+	}
+
+	cut := string(runes[:tts.MaxTextLength-3])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "..."
+}
@@ -0,0 +1,112 @@
+package detectionService
+
+import (
+	"ProjectGolang/internal/api/detection"
+	"ProjectGolang/pkg/tts"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+// The recorded money answer is a 50 000 and a 100 000 note.
+const spokenMoneyFixture = "2 lembar uang, total 150 ribu rupiah"
+
+func TestDetectMoneySpeechModes(t *testing.T) {
+	tests := []struct {
+		speech detection.SpeechMode
+		text   string
+		audio  bool
+	}{
+		{speech: "", text: spokenMoneyFixture},
+		{speech: detection.SpeechText, text: spokenMoneyFixture},
+		{speech: detection.SpeechAudio, audio: true},
+		{speech: detection.SpeechBoth, text: spokenMoneyFixture, audio: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.speech), func(t *testing.T) {
+			d := newDetectionTest(t)
+
+			res, err := d.service.DetectMoney(context.Background(), testUserID, testImage, tt.speech)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.text, res.SpokenInstruction)
+			if !tt.audio {
+				assert.Nil(t, res.Speech)
+				assert.Equal(t, int64(0), d.synthesizer.Calls(), "no audio may be made for text only")
+				return
+			}
+			require.NotNil(t, res.Speech)
+			assert.Equal(t, speechClipPath+res.Speech.ID, res.Speech.URL)
+			assert.Equal(t, "audio/wav", res.Speech.MimeType)
+		})
+	}
+}
+
+func TestDetectMoneyRejectsUnknownSpeechMode(t *testing.T) {
+	d := newDetectionTest(t)
+
+	_, err := d.service.DetectMoney(context.Background(), testUserID, testImage, "braille")
+
+	assert.ErrorIs(t, err, detection.ErrInvalidSpeechMode)
+	assert.Empty(t, d.gemini.Requests(), "an invalid request must not use the Gemini quota")
+}
+
+func TestSpeechIsSynthesizedOnce(t *testing.T) {
+	d := newDetectionTest(t)
+
+	first, err := d.service.DetectMoney(context.Background(), testUserID, testImage, detection.SpeechAudio)
+	require.NoError(t, err)
+	second, err := d.service.DetectMoney(context.Background(), testUserID, testImage, detection.SpeechAudio)
+	require.NoError(t, err)
+
+	assert.Equal(t, first.Speech.ID, second.Speech.ID, "the same sentence must keep its clip ID")
+	assert.Equal(t, int64(1), d.synthesizer.Calls())
+
+	clip, err := d.service.SpeechClip(context.Background(), first.Speech.ID)
+	require.NoError(t, err)
+	assert.Equal(t, spokenMoneyFixture, clip.Text)
+	assert.NotEmpty(t, clip.Data)
+	assert.Equal(t, int64(1), d.synthesizer.Calls(), "serving a clip must not synthesize it again")
+}
+
+func TestSpeechClipsAreSharedThroughRedis(t *testing.T) {
+	d := newDetectionTest(t)
+
+	res, err := d.service.DetectMoney(context.Background(), testUserID, testImage, detection.SpeechAudio)
+	require.NoError(t, err)
+
+	// Another instance, or this one after a restart, has an empty memory
+	// cache but the same Redis.
+	synthesizer := tts.NewFake()
+	d.service.speaker = tts.NewSpeaker(synthesizer, d.redis)
+
+	clip, err := d.service.SpeechClip(context.Background(), res.Speech.ID)
+	require.NoError(t, err)
+	assert.Equal(t, spokenMoneyFixture, clip.Text)
+
+	again, err := d.service.DetectMoney(context.Background(), testUserID, testImage, detection.SpeechAudio)
+	require.NoError(t, err)
+	assert.Equal(t, res.Speech.ID, again.Speech.ID)
+	assert.Equal(t, int64(0), synthesizer.Calls())
+}
+
+func TestSpeechClipNotFound(t *testing.T) {
+	d := newDetectionTest(t)
+
+	_, err := d.service.SpeechClip(context.Background(), "0123456789abcdef0123456789abcdef")
+
+	assert.ErrorIs(t, err, detection.ErrSpeechClipNotFound)
+}
+
+func TestSpeakFallsBackToText(t *testing.T) {
+	d := newDetectionTest(t)
+	text := strings.Repeat("kata ", tts.MaxTextLength)
+
+	spoken, speech := d.service.speak(context.Background(), detection.SpeechAudio, text)
+
+	assert.Equal(t, text, spoken, "a sentence that cannot be synthesized is still sent as text")
+	assert.Nil(t, speech)
+}
@@ -0,0 +1,203 @@
+package detectionService
+
+import (
+	"ProjectGolang/internal/api/detection"
+	"ProjectGolang/pkg/gemini"
+	"fmt"
+	"golang.org/x/net/context"
+	"strings"
+	"time"
+)
+
+var (
+	indonesianMonths = []string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+	// wib is the timezone expiry dates are compared in.
+	wib = time.FixedZone("WIB", 7*60*60)
+)
+
+// ReadText reads all visible text in a document or sign, in reading order.
+func (s *detectionService) ReadText(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (*detection.ReadTextResponse, error) {
+	var output readTextOutput
+	quota, err := s.analyzeImage(ctx, userID, base64Image, speech, readTextPrompt, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &detection.ReadTextResponse{
+		Found:         output.Found,
+		Language:      output.Language,
+		Blocks:        make([]detection.TextBlock, 0, len(output.Blocks)),
+		PromptVersion: readTextPrompt.ID(),
+		Quota:         quota,
+	}
+	lines := make([]string, 0, len(output.Blocks))
+	for _, block := range output.Blocks {
+		res.Blocks = append(res.Blocks, detection.TextBlock{Type: block.Type, Text: block.Text})
+		lines = append(lines, block.Text)
+	}
+	res.Text = strings.Join(lines, "\n")
+
+	spoken := "Tidak ada teks yang terbaca"
+	if res.Found {
+		spoken = res.Text
+	}
+	res.SpokenInstruction, res.Speech = s.speak(ctx, speech, truncateSpoken(spoken))
+	return res, nil
+}
+
+// DescribeScene describes what the camera sees, hazards included.
+func (s *detectionService) DescribeScene(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (*detection.SceneDescriptionResponse, error) {
+	var output sceneOutput
+	quota, err := s.analyzeImage(ctx, userID, base64Image, speech, describeScenePrompt, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &detection.SceneDescriptionResponse{
+		Summary:       output.Summary,
+		Setting:       output.Setting,
+		Objects:       make([]detection.SceneObject, 0, len(output.Objects)),
+		People:        output.People,
+		Hazards:       append([]string{}, output.Hazards...),
+		PromptVersion: describeScenePrompt.ID(),
+		Quota:         quota,
+	}
+	for _, object := range output.Objects {
+		res.Objects = append(res.Objects, detection.SceneObject{Name: object.Name, Position: object.Position})
+	}
+
+	spoken := res.Summary
+	if len(res.Hazards) > 0 {
+		spoken += " Hati-hati, " + strings.Join(res.Hazards, ", ")
+	}
+	res.SpokenInstruction, res.Speech = s.speak(ctx, speech, truncateSpoken(spoken))
+	return res, nil
+}
+
+// IdentifyProduct reads a product or package label, including its expiry
+// date.
+func (s *detectionService) IdentifyProduct(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (*detection.ProductLabelResponse, error) {
+	var output productLabelOutput
+	quota, err := s.analyzeImage(ctx, userID, base64Image, speech, productLabelPrompt, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &detection.ProductLabelResponse{
+		Found:         output.Found,
+		Warnings:      append([]string{}, output.Warnings...),
+		PromptVersion: productLabelPrompt.ID(),
+		Quota:         quota,
+	}
+	if output.Found {
+		res.ProductName = output.ProductName
+		res.Brand = output.Brand
+		res.Category = output.Category
+		res.NetContent = output.NetContent
+		res.ExpiryDate = output.ExpiryDate
+		res.Expired = expired(output.ExpiryDate, time.Now())
+	}
+
+	res.SpokenInstruction, res.Speech = s.speak(ctx, speech, truncateSpoken(spokenProduct(res)))
+	return res, nil
+}
+
+// IdentifyClothingColors names the colors of each piece of clothing in the
+// image.
+func (s *detectionService) IdentifyClothingColors(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode) (*detection.ClothingColorsResponse, error) {
+	var output clothingColorsOutput
+	quota, err := s.analyzeImage(ctx, userID, base64Image, speech, clothingColorsPrompt, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &detection.ClothingColorsResponse{
+		Items:         make([]detection.ClothingItem, 0, len(output.Items)),
+		PromptVersion: clothingColorsPrompt.ID(),
+		Quota:         quota,
+	}
+	for _, item := range output.Items {
+		res.Items = append(res.Items, detection.ClothingItem{Item: item.Item, Colors: item.Colors, Pattern: item.Pattern})
+	}
+
+	res.SpokenInstruction, res.Speech = s.speak(ctx, speech, truncateSpoken(spokenClothing(res)))
+	return res, nil
+}
+
+// analyzeImage checks speech and the user's Gemini quota, then runs prompt
+// over the image and decodes the answer into output.
+func (s *detectionService) analyzeImage(ctx context.Context, userID string, base64Image string, speech detection.SpeechMode, prompt gemini.Prompt, output interface{}) (*detection.Quota, error) {
+	if speech != "" && !speech.IsValid() {
+		return nil, detection.ErrInvalidSpeechMode
+	}
+
+	quota, err := s.extract(ctx, userID, prompt, base64Image, output)
+	if err != nil {
+		return nil, geminiError(err, detection.ErrImageUnreadable)
+	}
+
+	return quota, nil
+}
+
+// expired reports whether a YYYY-MM-DD expiry date has passed in Indonesia
+// on now, or nil if there is no date.
+func expired(expiryDate string, now time.Time) *bool {
+	date, err := time.ParseInLocation(dateLayout, expiryDate, wib)
+	if err != nil {
+		return nil
+	}
+
+	today := time.Date(now.In(wib).Year(), now.In(wib).Month(), now.In(wib).Day(), 0, 0, 0, 0, wib)
+	passed := date.Before(today)
+	return &passed
+}
+
+func spokenProduct(res *detection.ProductLabelResponse) string {
+	if !res.Found {
+		return "Produk tidak terbaca, arahkan kamera ke label kemasan"
+	}
+
+	spoken := strings.TrimSpace(res.ProductName + " " + res.Brand)
+	if res.NetContent != "" {
+		spoken += ", " + res.NetContent
+	}
+
+	switch {
+	case res.Expired == nil:
+		spoken += ", tanggal kedaluwarsa tidak terbaca"
+	case *res.Expired:
+		spoken += ", sudah kedaluwarsa sejak " + spokenDate(res.ExpiryDate)
+	default:
+		spoken += ", kedaluwarsa " + spokenDate(res.ExpiryDate)
+	}
+
+	return spoken
+}
+
+// spokenDate writes a YYYY-MM-DD date the way it is read out, e.g.
+// "31 Agustus 2025".
+func spokenDate(value string) string {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return value
+	}
+	return fmt.Sprintf("%d %s %d", date.Day(), indonesianMonths[date.Month()-1], date.Year())
+}
+
+func spokenClothing(res *detection.ClothingColorsResponse) string {
+	if len(res.Items) == 0 {
+		return "Pakaian tidak terlihat, arahkan kamera ke pakaian"
+	}
+
+	parts := make([]string, 0, len(res.Items))
+	for _, item := range res.Items {
+		part := item.Item + " " + strings.Join(item.Colors, " dan ")
+		if item.Pattern != "" && item.Pattern != "polos" {
+			part += " bermotif " + item.Pattern
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, ", ")
+}
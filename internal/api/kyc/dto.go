@@ -0,0 +1,123 @@
+package kyc
+
+import (
+	"ProjectGolang/internal/entity"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusNotStarted         Status = "not_started"
+	StatusDocumentsSubmitted Status = "documents_submitted"
+	StatusFaceMatched        Status = "face_matched"
+	StatusApproved           Status = "approved"
+	StatusRejected           Status = "rejected"
+)
+
+// transitions is the KYC state machine. Documents can be resubmitted until a
+// reviewer approves them; approval is final.
+var transitions = map[Status][]Status{
+	StatusNotStarted:         {StatusDocumentsSubmitted},
+	StatusDocumentsSubmitted: {StatusDocumentsSubmitted, StatusFaceMatched, StatusRejected},
+	StatusFaceMatched:        {StatusApproved, StatusRejected},
+	StatusRejected:           {StatusDocumentsSubmitted},
+}
+
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusNotStarted, StatusDocumentsSubmitted, StatusFaceMatched, StatusApproved, StatusRejected:
+		return true
+	}
+	return false
+}
+
+const (
+	RejectionFaceMismatch = "selfie does not match the KTP photo"
+	RejectionNoFace       = "no face could be found on the KTP photo or the selfie"
+)
+
+type Verification struct {
+	UserID          string
+	Status          Status
+	Level           entity.KYCLevel
+	KTPImageURL     string
+	SelfieURL       string
+	FaceSimilarity  float64
+	RejectionReason string
+	Attempts        int
+	SubmittedAt     time.Time
+	FaceMatchedAt   time.Time
+	ReviewedAt      time.Time
+	ReviewedBy      string
+	UpdatedAt       time.Time
+}
+
+// FaceMatchResult moves a submission out of documents_submitted once the
+// selfie has been compared with the KTP photo.
+type FaceMatchResult struct {
+	UserID          string
+	Status          Status
+	FaceSimilarity  float64
+	RejectionReason string
+	MatchedAt       time.Time
+}
+
+// Review records a reviewer's decision on a face-matched submission.
+type Review struct {
+	UserID          string
+	From            Status
+	Status          Status
+	Level           entity.KYCLevel
+	RejectionReason string
+	ReviewedBy      string
+	ReviewedAt      time.Time
+}
+
+type StatusResponse struct {
+	Status          Status              `json:"status"`
+	Level           entity.KYCLevel     `json:"level"`
+	WalletLimits    entity.WalletLimits `json:"wallet_limits"`
+	FaceSimilarity  *float64            `json:"face_similarity,omitempty"`
+	RejectionReason string              `json:"rejection_reason,omitempty"`
+	Attempts        int                 `json:"attempts"`
+	SubmittedAt     *time.Time          `json:"submitted_at,omitempty"`
+	ReviewedAt      *time.Time          `json:"reviewed_at,omitempty"`
+}
+
+// VerificationDetail is what a reviewer sees, with short-lived links to the
+// submitted documents.
+type VerificationDetail struct {
+	UserID          string          `json:"user_id"`
+	Status          Status          `json:"status"`
+	Level           entity.KYCLevel `json:"level"`
+	KTPImageURL     string          `json:"ktp_image_url,omitempty"`
+	SelfieURL       string          `json:"selfie_url,omitempty"`
+	FaceSimilarity  *float64        `json:"face_similarity,omitempty"`
+	RejectionReason string          `json:"rejection_reason,omitempty"`
+	Attempts        int             `json:"attempts"`
+	SubmittedAt     *time.Time      `json:"submitted_at,omitempty"`
+	FaceMatchedAt   *time.Time      `json:"face_matched_at,omitempty"`
+	ReviewedAt      *time.Time      `json:"reviewed_at,omitempty"`
+	ReviewedBy      string          `json:"reviewed_by,omitempty"`
+}
+
+type VerificationListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=documents_submitted face_matched approved rejected"`
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+}
+
+type VerificationListResponse struct {
+	Verifications []VerificationDetail `json:"verifications"`
+	Total         int                  `json:"total"`
+}
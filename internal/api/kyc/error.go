@@ -0,0 +1,19 @@
+package kyc
+
+import (
+	"ProjectGolang/pkg/response"
+	"net/http"
+)
+
+var (
+	ErrVerificationNotFound = response.NewError(http.StatusNotFound, "KYC verification not found")
+	ErrInvalidTransition    = response.NewError(http.StatusConflict, "KYC verification cannot move to the requested status")
+	ErrKTPNotConfirmed      = response.NewError(http.StatusConflict, "confirm your KTP data before submitting KYC documents")
+	ErrMissingDocument      = response.NewError(http.StatusBadRequest, "both a KTP photo and a selfie are required")
+	ErrInvalidFileType      = response.NewError(http.StatusBadRequest, "KYC documents must be JPEG or PNG images")
+	ErrFileTooLarge         = response.NewError(http.StatusBadRequest, "KYC documents must not exceed 5MB")
+	ErrFailedToUploadFile   = response.NewError(http.StatusInternalServerError, "failed to upload KYC documents")
+	ErrLivenessRequired     = response.NewError(http.StatusPreconditionRequired, "a liveness check is required, take the selfie through the face scanner")
+	ErrInvalidLivenessProof = response.NewError(http.StatusForbidden, "liveness proof is invalid, expired, already used or does not match the selfie")
+	ErrCannotReviewSelf     = response.NewError(http.StatusForbidden, "reviewers cannot decide on their own KYC verification")
+)
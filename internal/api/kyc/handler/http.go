@@ -0,0 +1,37 @@
+package kycHandler
+
+import (
+	kycService "ProjectGolang/internal/api/kyc/service"
+	"ProjectGolang/internal/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type KYCHandler struct {
+	log        *logrus.Logger
+	validator  *validator.Validate
+	middleware middleware.Middleware
+	kycService kycService.IKYCService
+}
+
+func New(
+	log *logrus.Logger,
+	validate *validator.Validate,
+	middleware middleware.Middleware,
+	kycService kycService.IKYCService,
+) *KYCHandler {
+	return &KYCHandler{
+		log:        log,
+		validator:  validate,
+		middleware: middleware,
+		kycService: kycService,
+	}
+}
+
+func (h *KYCHandler) Start(srv fiber.Router) {
+	kycGroup := srv.Group("/kyc", h.middleware.NewTokenMiddleware)
+
+	kycGroup.Get("/", h.GetStatus)
+	kycGroup.Post("/documents", h.SubmitDocuments)
+}
@@ -0,0 +1,71 @@
+package kycHandler
+
+import (
+	"ProjectGolang/internal/api/kyc"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/handlerUtil"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+	"time"
+)
+
+func (h *KYCHandler) GetStatus(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	res, err := h.kycService.GetStatus(c, userData.ID)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_kyc_status")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *KYCHandler) SubmitDocuments(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	ktpPhoto, err := ctx.FormFile("ktp_photo")
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, kyc.ErrMissingDocument, ctx.Path(), "get_form_file")
+	}
+
+	selfie, err := ctx.FormFile("selfie")
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, kyc.ErrMissingDocument, ctx.Path(), "get_form_file")
+	}
+
+	res, err := h.kycService.SubmitDocuments(c, userData.ID, ktpPhoto, selfie, ctx.FormValue("liveness_token"))
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "submit_kyc_documents")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
@@ -0,0 +1,108 @@
+package kycRepository
+
+const (
+	queryGetVerification = `
+		SELECT
+			user_id,
+			status,
+			level,
+			ktp_image_url,
+			selfie_url,
+			face_similarity,
+			rejection_reason,
+			attempts,
+			submitted_at,
+			face_matched_at,
+			reviewed_at,
+			reviewed_by,
+			updated_at
+		FROM kyc_verifications
+		WHERE user_id = :user_id
+	`
+
+	querySaveSubmission = `
+		INSERT INTO kyc_verifications (
+			user_id,
+			status,
+			level,
+			ktp_image_url,
+			selfie_url,
+			attempts,
+			submitted_at,
+			created_at,
+			updated_at
+		) VALUES (
+			:user_id,
+			'documents_submitted',
+			0,
+			:ktp_image_url,
+			:selfie_url,
+			1,
+			:submitted_at,
+			:submitted_at,
+			:submitted_at
+		)
+		ON CONFLICT (user_id) DO UPDATE SET
+			status = 'documents_submitted',
+			level = 0,
+			ktp_image_url = EXCLUDED.ktp_image_url,
+			selfie_url = EXCLUDED.selfie_url,
+			face_similarity = NULL,
+			rejection_reason = NULL,
+			attempts = kyc_verifications.attempts + 1,
+			submitted_at = EXCLUDED.submitted_at,
+			face_matched_at = NULL,
+			reviewed_at = NULL,
+			reviewed_by = NULL,
+			updated_at = EXCLUDED.updated_at
+		WHERE kyc_verifications.status IN ('not_started', 'documents_submitted', 'rejected')
+	`
+
+	queryRecordFaceMatch = `
+		UPDATE kyc_verifications
+		SET status = :status,
+			face_similarity = :face_similarity,
+			rejection_reason = :rejection_reason,
+			face_matched_at = :matched_at,
+			updated_at = :matched_at
+		WHERE user_id = :user_id AND status = 'documents_submitted'
+	`
+
+	queryRecordReview = `
+		UPDATE kyc_verifications
+		SET status = :status,
+			level = :level,
+			rejection_reason = :rejection_reason,
+			reviewed_at = :reviewed_at,
+			reviewed_by = :reviewed_by,
+			updated_at = :reviewed_at
+		WHERE user_id = :user_id AND status = :from_status
+	`
+
+	queryListVerifications = `
+		SELECT
+			user_id,
+			status,
+			level,
+			ktp_image_url,
+			selfie_url,
+			face_similarity,
+			rejection_reason,
+			attempts,
+			submitted_at,
+			face_matched_at,
+			reviewed_at,
+			reviewed_by,
+			updated_at
+		FROM kyc_verifications
+		WHERE (:status = '' OR status = :status)
+		ORDER BY submitted_at ASC NULLS LAST
+		LIMIT :limit OFFSET :offset
+	`
+
+	queryCountVerifications = `
+		SELECT COUNT(*)
+		FROM kyc_verifications
+		WHERE (:status = '' OR status = :status)
+	`
+)
@@ -0,0 +1,86 @@
+package kycRepository
+
+import (
+	"ProjectGolang/internal/api/kyc"
+	"ProjectGolang/internal/events"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+type SQLExecutor interface {
+	sqlx.ExtContext
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row
+	Rebind(query string) string
+}
+
+func New(db *sqlx.DB, log *logrus.Logger) Repository {
+	return &repository{
+		DB:  db,
+		log: log,
+	}
+}
+
+type repository struct {
+	DB  *sqlx.DB
+	log *logrus.Logger
+}
+
+type Repository interface {
+	NewClient(tx bool) (Client, error)
+}
+
+func (r *repository) NewClient(tx bool) (Client, error) {
+	var sqlExecutor SQLExecutor
+	var commitFunc, rollbackFunc func() error
+
+	sqlExecutor = r.DB
+
+	if tx {
+		var err error
+		txx, err := r.DB.Beginx()
+		if err != nil {
+			return Client{}, err
+		}
+
+		sqlExecutor = txx
+		commitFunc = txx.Commit
+		rollbackFunc = txx.Rollback
+	} else {
+		commitFunc = func() error { return nil }
+		rollbackFunc = func() error { return nil }
+	}
+
+	return Client{
+		Verifications: &verificationRepository{q: sqlExecutor, log: r.log},
+		Outbox:        events.NewOutbox(sqlExecutor, r.log),
+		Commit:        commitFunc,
+		Rollback:      rollbackFunc,
+	}, nil
+}
+
+type Client struct {
+	// Verifications keeps one row per user. Status changes are conditional on
+	// the status the caller last saw, so concurrent submissions or reviews
+	// fail with kyc.ErrInvalidTransition instead of overwriting each other.
+	Verifications interface {
+		GetVerification(ctx context.Context, userID string) (kyc.Verification, error)
+		SaveSubmission(ctx context.Context, verification kyc.Verification) error
+		RecordFaceMatch(ctx context.Context, result kyc.FaceMatchResult) error
+		RecordReview(ctx context.Context, review kyc.Review) error
+		ListVerifications(ctx context.Context, status string, limit, offset int) ([]kyc.Verification, int, error)
+	}
+
+	// Outbox records review outcomes for other domains, committed together
+	// with the review.
+	Outbox events.Outbox
+
+	Commit   func() error
+	Rollback func() error
+}
+
+type verificationRepository struct {
+	q   SQLExecutor
+	log *logrus.Logger
+}
@@ -0,0 +1,263 @@
+package kycRepository
+
+import (
+	"ProjectGolang/internal/api/kyc"
+	"ProjectGolang/internal/entity"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+type VerificationDB struct {
+	UserID          sql.NullString  `db:"user_id"`
+	Status          sql.NullString  `db:"status"`
+	Level           sql.NullInt64   `db:"level"`
+	KTPImageURL     sql.NullString  `db:"ktp_image_url"`
+	SelfieURL       sql.NullString  `db:"selfie_url"`
+	FaceSimilarity  sql.NullFloat64 `db:"face_similarity"`
+	RejectionReason sql.NullString  `db:"rejection_reason"`
+	Attempts        sql.NullInt64   `db:"attempts"`
+	SubmittedAt     sql.NullTime    `db:"submitted_at"`
+	FaceMatchedAt   sql.NullTime    `db:"face_matched_at"`
+	ReviewedAt      sql.NullTime    `db:"reviewed_at"`
+	ReviewedBy      sql.NullString  `db:"reviewed_by"`
+	UpdatedAt       sql.NullTime    `db:"updated_at"`
+}
+
+func (r *verificationRepository) GetVerification(ctx context.Context, userID string) (kyc.Verification, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var verification VerificationDB
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryGetVerification, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetVerification named query preparation err")
+		return kyc.Verification{}, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).StructScan(&verification); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return kyc.Verification{}, kyc.ErrVerificationNotFound
+		}
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetVerification execution err")
+		return kyc.Verification{}, err
+	}
+
+	return r.makeVerification(verification), nil
+}
+
+func (r *verificationRepository) SaveSubmission(ctx context.Context, verification kyc.Verification) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"user_id":       verification.UserID,
+		"ktp_image_url": verification.KTPImageURL,
+		"selfie_url":    verification.SelfieURL,
+		"submitted_at":  verification.SubmittedAt,
+	}
+
+	query, args, err := sqlx.Named(querySaveSubmission, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("SaveSubmission named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	result, err := r.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("SaveSubmission execution err")
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    verification.UserID,
+		}).Warn("SaveSubmission rejected by current status")
+		return kyc.ErrInvalidTransition
+	}
+
+	return nil
+}
+
+func (r *verificationRepository) RecordFaceMatch(ctx context.Context, result kyc.FaceMatchResult) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"user_id":          result.UserID,
+		"status":           result.Status,
+		"face_similarity":  result.FaceSimilarity,
+		"rejection_reason": sql.NullString{String: result.RejectionReason, Valid: result.RejectionReason != ""},
+		"matched_at":       result.MatchedAt,
+	}
+
+	query, args, err := sqlx.Named(queryRecordFaceMatch, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("RecordFaceMatch named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	res, err := r.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("RecordFaceMatch execution err")
+		return err
+	}
+
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    result.UserID,
+		}).Warn("RecordFaceMatch rejected by current status")
+		return kyc.ErrInvalidTransition
+	}
+
+	return nil
+}
+
+func (r *verificationRepository) RecordReview(ctx context.Context, review kyc.Review) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"user_id":          review.UserID,
+		"from_status":      review.From,
+		"status":           review.Status,
+		"level":            review.Level,
+		"rejection_reason": sql.NullString{String: review.RejectionReason, Valid: review.RejectionReason != ""},
+		"reviewed_at":      review.ReviewedAt,
+		"reviewed_by":      review.ReviewedBy,
+	}
+
+	query, args, err := sqlx.Named(queryRecordReview, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("RecordReview named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	result, err := r.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("RecordReview execution err")
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    review.UserID,
+		}).Warn("RecordReview rejected by current status")
+		return kyc.ErrInvalidTransition
+	}
+
+	return nil
+}
+
+func (r *verificationRepository) ListVerifications(ctx context.Context, status string, limit, offset int) ([]kyc.Verification, int, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var verifications []VerificationDB
+	var total int
+
+	argsKV := map[string]interface{}{
+		"status": status,
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	countQuery, countArgs, err := sqlx.Named(queryCountVerifications, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CountVerifications named query preparation err")
+		return nil, 0, err
+	}
+
+	countQuery = r.q.Rebind(countQuery)
+
+	if err := r.q.QueryRowxContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CountVerifications execution err")
+		return nil, 0, err
+	}
+
+	query, args, err := sqlx.Named(queryListVerifications, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("ListVerifications named query preparation err")
+		return nil, 0, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.SelectContext(ctx, &verifications, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("ListVerifications execution err")
+		return nil, 0, err
+	}
+
+	result := make([]kyc.Verification, 0, len(verifications))
+	for _, verification := range verifications {
+		result = append(result, r.makeVerification(verification))
+	}
+
+	return result, total, nil
+}
+
+func (r *verificationRepository) makeVerification(verification VerificationDB) kyc.Verification {
+	return kyc.Verification{
+		UserID:          verification.UserID.String,
+		Status:          kyc.Status(verification.Status.String),
+		Level:           entity.KYCLevel(verification.Level.Int64),
+		KTPImageURL:     verification.KTPImageURL.String,
+		SelfieURL:       verification.SelfieURL.String,
+		FaceSimilarity:  verification.FaceSimilarity.Float64,
+		RejectionReason: verification.RejectionReason.String,
+		Attempts:        int(verification.Attempts.Int64),
+		SubmittedAt:     verification.SubmittedAt.Time,
+		FaceMatchedAt:   verification.FaceMatchedAt.Time,
+		ReviewedAt:      verification.ReviewedAt.Time,
+		ReviewedBy:      verification.ReviewedBy.String,
+		UpdatedAt:       verification.UpdatedAt.Time,
+	}
+}
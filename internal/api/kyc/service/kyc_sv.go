@@ -0,0 +1,347 @@
+package kycService
+
+import (
+	"ProjectGolang/internal/api/auth"
+	"ProjectGolang/internal/api/kyc"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/jobs"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/facematch"
+	"ProjectGolang/pkg/liveness"
+	"ProjectGolang/pkg/s3"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+)
+
+const maxDocumentSize = 5 * 1024 * 1024
+
+func (s *kycService) GetStatus(ctx context.Context, userID string) (*kyc.StatusResponse, error) {
+	verification, err := s.getVerification(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeStatusResponse(verification), nil
+}
+
+func (s *kycService) GetLevel(ctx context.Context, userID string) (entity.KYCLevel, error) {
+	verification, err := s.getVerification(ctx, userID)
+	if err != nil {
+		return entity.KYCLevelUnverified, err
+	}
+
+	if verification.Status != kyc.StatusApproved {
+		return entity.KYCLevelUnverified, nil
+	}
+
+	return verification.Level, nil
+}
+
+// SubmitDocuments stores the KTP photo and a selfie that passed the face
+// scanner's liveness check, moving the verification to documents_submitted,
+// and immediately compares the two faces. A match moves it on to
+// face_matched to await review; a mismatch rejects it so the user can try
+// again. When the matcher itself fails the submission is left in
+// documents_submitted and can simply be resubmitted.
+func (s *kycService) SubmitDocuments(ctx context.Context, userID string, ktpPhoto *multipart.FileHeader, selfie *multipart.FileHeader, livenessToken string) (*kyc.StatusResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	if ktpPhoto == nil || selfie == nil {
+		return nil, kyc.ErrMissingDocument
+	}
+
+	ktpImage, err := readDocument(ktpPhoto)
+	if err != nil {
+		return nil, err
+	}
+
+	selfieImage, err := readDocument(selfie)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.getVerification(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !current.Status.CanTransitionTo(kyc.StatusDocumentsSubmitted) {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"status":     current.Status,
+		}).Warn("KYC documents submitted in a final state")
+		return nil, kyc.ErrInvalidTransition
+	}
+
+	if err := s.ensureKTPConfirmed(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.redeemLiveness(ctx, userID, selfieImage, livenessToken); err != nil {
+		return nil, err
+	}
+
+	ktpImageKey, err := s.s3Client.UploadFile(userID, s3.PurposeKTP, ktpPhoto)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to upload KTP photo to S3")
+		return nil, kyc.ErrFailedToUploadFile
+	}
+
+	selfieKey, err := s.s3Client.UploadFile(userID, s3.PurposeSelfie, selfie)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to upload selfie to S3")
+		s.deleteFiles(ctx, userID, ktpImageKey)
+		return nil, kyc.ErrFailedToUploadFile
+	}
+
+	repo, err := s.kycRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		s.deleteFiles(ctx, userID, ktpImageKey, selfieKey)
+		return nil, err
+	}
+
+	if err := repo.Verifications.SaveSubmission(ctx, kyc.Verification{
+		UserID:      userID,
+		KTPImageURL: ktpImageKey,
+		SelfieURL:   selfieKey,
+		SubmittedAt: time.Now(),
+	}); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to save KYC submission")
+		s.deleteFiles(ctx, userID, ktpImageKey, selfieKey)
+		return nil, err
+	}
+
+	s.deleteFiles(ctx, userID, current.KTPImageURL, current.SelfieURL)
+
+	result := kyc.FaceMatchResult{
+		UserID:    userID,
+		MatchedAt: time.Now(),
+	}
+
+	match, err := s.faceMatcher.Compare(ctx, ktpImage, selfieImage)
+	switch {
+	case errors.Is(err, facematch.ErrNoFace), errors.Is(err, facematch.ErrInvalidData):
+		result.Status = kyc.StatusRejected
+		result.RejectionReason = kyc.RejectionNoFace
+	case err != nil:
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Face matching failed, submission left for retry")
+		return s.GetStatus(ctx, userID)
+	case match.Matched:
+		result.Status = kyc.StatusFaceMatched
+		result.FaceSimilarity = match.Similarity
+	default:
+		result.Status = kyc.StatusRejected
+		result.FaceSimilarity = match.Similarity
+		result.RejectionReason = kyc.RejectionFaceMismatch
+	}
+
+	if err := repo.Verifications.RecordFaceMatch(ctx, result); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to record face match result")
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"user_id":    userID,
+		"status":     result.Status,
+		"similarity": result.FaceSimilarity,
+	}).Info("KYC face match completed")
+
+	return s.GetStatus(ctx, userID)
+}
+
+func (s *kycService) getVerification(ctx context.Context, userID string) (kyc.Verification, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.kycRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return kyc.Verification{}, err
+	}
+
+	verification, err := repo.Verifications.GetVerification(ctx, userID)
+	if err != nil {
+		if errors.Is(err, kyc.ErrVerificationNotFound) {
+			return kyc.Verification{UserID: userID, Status: kyc.StatusNotStarted}, nil
+		}
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to get KYC verification")
+		return kyc.Verification{}, err
+	}
+
+	return verification, nil
+}
+
+// ensureKTPConfirmed requires the KTP data to have been confirmed into the
+// profile first, so reviewers compare the documents against known details.
+func (s *kycService) ensureKTPConfirmed(ctx context.Context, userID string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.authService.GetRepository().NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return err
+	}
+
+	user, err := repo.Users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return auth.ErrUserNotFound
+		}
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to get user by ID")
+		return err
+	}
+
+	if user.NationalIdentityNumber == "" {
+		return kyc.ErrKTPNotConfirmed
+	}
+
+	return nil
+}
+
+func (s *kycService) redeemLiveness(ctx context.Context, userID string, selfie []byte, livenessToken string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	if livenessToken == "" {
+		return kyc.ErrLivenessRequired
+	}
+
+	if _, err := s.liveness.Redeem(ctx, livenessToken, userID, selfie); err != nil {
+		if errors.Is(err, liveness.ErrSecretNotConfigured) {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("Liveness tokens are not configured")
+			return err
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Warn("Liveness proof rejected")
+		return kyc.ErrInvalidLivenessProof
+	}
+
+	return nil
+}
+
+func (s *kycService) deleteFiles(ctx context.Context, userID string, fileKeys ...string) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	for _, fileKey := range fileKeys {
+		if fileKey == "" {
+			continue
+		}
+
+		if err := s.jobs.Enqueue(ctx, jobs.DeleteFile{FileName: fileKey}); err != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"user_id":    userID,
+				"file_name":  fileKey,
+				"error":      err.Error(),
+			}).Warn("Failed to queue KYC document deletion")
+		}
+	}
+}
+
+// readDocument loads an uploaded image after checking its size and sniffing
+// its content, since the client supplied Content-Type cannot be trusted.
+func readDocument(file *multipart.FileHeader) ([]byte, error) {
+	if file.Size > maxDocumentSize {
+		return nil, kyc.ErrFileTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, maxDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxDocumentSize {
+		return nil, kyc.ErrFileTooLarge
+	}
+
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png":
+		return data, nil
+	}
+
+	return nil, kyc.ErrInvalidFileType
+}
+
+func makeStatusResponse(verification kyc.Verification) *kyc.StatusResponse {
+	level := entity.KYCLevelUnverified
+	if verification.Status == kyc.StatusApproved {
+		level = verification.Level
+	}
+
+	res := &kyc.StatusResponse{
+		Status:          verification.Status,
+		Level:           level,
+		WalletLimits:    level.WalletLimits(),
+		RejectionReason: verification.RejectionReason,
+		Attempts:        verification.Attempts,
+	}
+
+	if !verification.FaceMatchedAt.IsZero() {
+		similarity := verification.FaceSimilarity
+		res.FaceSimilarity = &similarity
+	}
+	if !verification.SubmittedAt.IsZero() {
+		res.SubmittedAt = &verification.SubmittedAt
+	}
+	if !verification.ReviewedAt.IsZero() {
+		res.ReviewedAt = &verification.ReviewedAt
+	}
+
+	return res
+}
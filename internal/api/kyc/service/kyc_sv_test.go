@@ -0,0 +1,338 @@
+package kycService
+
+import (
+	authRepository "ProjectGolang/internal/api/auth/repository"
+	authService "ProjectGolang/internal/api/auth/service"
+	"ProjectGolang/internal/api/kyc"
+	kycRepository "ProjectGolang/internal/api/kyc/repository"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/jobs"
+	awsPkg "ProjectGolang/pkg/aws"
+	"ProjectGolang/pkg/facematch"
+	"ProjectGolang/pkg/liveness"
+	"ProjectGolang/pkg/redis"
+	"ProjectGolang/pkg/s3"
+	"bytes"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"mime/multipart"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	userID     = "user-1"
+	reviewerID = "reviewer-1"
+)
+
+var (
+	pngHeader = []byte("\x89PNG\r\n\x1a\n")
+	ktpImage  = append(append([]byte{}, pngHeader...), []byte("ktp photo")...)
+	selfie    = append(append([]byte{}, pngHeader...), []byte("selfie")...)
+)
+
+// recordedJobs keeps the jobs enqueued instead of running them.
+type recordedJobs struct {
+	mutex sync.Mutex
+	jobs  []jobs.Job
+}
+
+func (r *recordedJobs) Enqueue(_ context.Context, job jobs.Job, _ ...jobs.Option) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.jobs = append(r.jobs, job)
+	return nil
+}
+
+type kycTest struct {
+	service  IKYCService
+	matcher  *facematch.FakeMatcher
+	liveness liveness.ITokens
+	mock     sqlmock.Sqlmock
+}
+
+func newKYCTest(t *testing.T) *kycTest {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	storage, err := s3.New(s3.Config{
+		Driver:      s3.DriverLocal,
+		LocalDir:    t.TempDir(),
+		LocalURL:    "http://localhost/files",
+		LocalSecret: "kyc-test-secret",
+	}, awsPkg.Config{})
+	require.NoError(t, err)
+
+	matcher := facematch.NewFake(50)
+	tokens := liveness.NewTokens(liveness.Config{TokenSecret: "kyc-test-secret"}, redis.NewFake())
+	auth := authService.New(log, authRepository.New(sqlxDB, log), nil, nil, nil, nil, nil, nil, nil, nil, nil, tokens)
+
+	return &kycTest{
+		service:  NewKYCService(log, kycRepository.New(sqlxDB, log), auth, matcher, tokens, storage, &recordedJobs{}),
+		matcher:  matcher,
+		liveness: tokens,
+		mock:     mock,
+	}
+}
+
+// submit sends the KTP photo and the selfie with a liveness token for the
+// selfie.
+func (k *kycTest) submit(t *testing.T, token string) (*kyc.StatusResponse, error) {
+	t.Helper()
+
+	return k.service.SubmitDocuments(context.Background(), userID,
+		fileHeader(t, "ktp.png", ktpImage), fileHeader(t, "selfie.png", selfie), token)
+}
+
+func (k *kycTest) livenessToken(t *testing.T) string {
+	t.Helper()
+
+	token, _, err := k.liveness.Issue(userID, selfie, nil)
+	require.NoError(t, err)
+	return token
+}
+
+// expectVerification expects the verification to be read, finding it in
+// status, or finding none when status is empty.
+func (k *kycTest) expectVerification(status kyc.Status) {
+	rows := sqlmock.NewRows([]string{"user_id", "status", "level", "attempts", "submitted_at"})
+	if status != "" {
+		level := entity.KYCLevelUnverified
+		if status == kyc.StatusApproved {
+			level = entity.KYCLevelVerified
+		}
+		rows.AddRow(userID, string(status), int64(level), 1, time.Now())
+	}
+
+	k.mock.ExpectQuery(`FROM kyc_verifications\s+WHERE user_id = \$1`).
+		WithArgs(userID).
+		WillReturnRows(rows)
+}
+
+func (k *kycTest) expectKTPConfirmed() {
+	k.mock.ExpectQuery(`FROM Users\s+WHERE id = \$1`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "national_identity_number"}).
+			AddRow(userID, "3171012345678901"))
+}
+
+func (k *kycTest) expectSubmission() {
+	k.mock.ExpectExec(`INSERT INTO kyc_verifications`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// expectFaceMatch expects the face match to be recorded with status,
+// similarity and rejection reason, which is NULL when empty.
+func (k *kycTest) expectFaceMatch(status kyc.Status, similarity float64, reason string) {
+	var rejectionReason interface{}
+	if reason != "" {
+		rejectionReason = reason
+	}
+
+	k.mock.ExpectExec(`UPDATE kyc_verifications\s+SET status = \$1`).
+		WithArgs(string(status), similarity, rejectionReason, sqlmock.AnyArg(), sqlmock.AnyArg(), userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func fileHeader(t *testing.T, name string, data []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", name)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { form.RemoveAll() })
+
+	return form.File["file"][0]
+}
+
+func TestSubmitDocuments(t *testing.T) {
+	tests := []struct {
+		name       string
+		prepare    func(matcher *facematch.FakeMatcher)
+		status     kyc.Status
+		similarity float64
+		reason     string
+	}{
+		{
+			name: "matching faces await review",
+			prepare: func(matcher *facematch.FakeMatcher) {
+				matcher.Enroll("owner", ktpImage, selfie)
+			},
+			status:     kyc.StatusFaceMatched,
+			similarity: 99,
+		},
+		{
+			name: "different faces are rejected",
+			prepare: func(matcher *facematch.FakeMatcher) {
+				matcher.Enroll("owner", ktpImage)
+				matcher.Enroll("someone else", selfie)
+			},
+			status:     kyc.StatusRejected,
+			similarity: 1,
+			reason:     kyc.RejectionFaceMismatch,
+		},
+		{
+			name:       "faces below the threshold are rejected",
+			prepare:    func(*facematch.FakeMatcher) {},
+			status:     kyc.StatusRejected,
+			similarity: 50,
+			reason:     kyc.RejectionFaceMismatch,
+		},
+		{
+			name: "a KTP photo without a face is rejected",
+			prepare: func(matcher *facematch.FakeMatcher) {
+				matcher.SetNoFace(ktpImage)
+			},
+			status: kyc.StatusRejected,
+			reason: kyc.RejectionNoFace,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := newKYCTest(t)
+			tt.prepare(k.matcher)
+
+			k.expectVerification("")
+			k.expectKTPConfirmed()
+			k.expectSubmission()
+			k.expectFaceMatch(tt.status, tt.similarity, tt.reason)
+			k.expectVerification(tt.status)
+
+			res, err := k.submit(t, k.livenessToken(t))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, res.Status)
+			assert.Equal(t, entity.KYCLevelUnverified, res.Level)
+			assert.NoError(t, k.mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSubmitDocumentsAfterRejection(t *testing.T) {
+	k := newKYCTest(t)
+	k.matcher.Enroll("owner", ktpImage, selfie)
+
+	k.expectVerification(kyc.StatusRejected)
+	k.expectKTPConfirmed()
+	k.expectSubmission()
+	k.expectFaceMatch(kyc.StatusFaceMatched, 99, "")
+	k.expectVerification(kyc.StatusFaceMatched)
+
+	res, err := k.submit(t, k.livenessToken(t))
+
+	require.NoError(t, err)
+	assert.Equal(t, kyc.StatusFaceMatched, res.Status)
+	assert.NoError(t, k.mock.ExpectationsWereMet())
+}
+
+func TestSubmitDocumentsInFinalState(t *testing.T) {
+	for _, status := range []kyc.Status{kyc.StatusFaceMatched, kyc.StatusApproved} {
+		t.Run(string(status), func(t *testing.T) {
+			k := newKYCTest(t)
+			k.expectVerification(status)
+
+			_, err := k.submit(t, k.livenessToken(t))
+
+			assert.ErrorIs(t, err, kyc.ErrInvalidTransition)
+			assert.NoError(t, k.mock.ExpectationsWereMet(), "nothing may be saved once documents await review")
+		})
+	}
+}
+
+func TestSubmitDocumentsRejectsReplayedLivenessToken(t *testing.T) {
+	k := newKYCTest(t)
+	k.matcher.Enroll("owner", ktpImage, selfie)
+	token := k.livenessToken(t)
+
+	k.expectVerification("")
+	k.expectKTPConfirmed()
+	k.expectSubmission()
+	k.expectFaceMatch(kyc.StatusFaceMatched, 99, "")
+	k.expectVerification(kyc.StatusFaceMatched)
+
+	_, err := k.submit(t, token)
+	require.NoError(t, err)
+
+	// A reviewer rejected the submission, so documents may be sent again,
+	// but not with the same liveness proof.
+	k.expectVerification(kyc.StatusRejected)
+	k.expectKTPConfirmed()
+
+	_, err = k.submit(t, token)
+	assert.ErrorIs(t, err, kyc.ErrInvalidLivenessProof)
+	assert.NoError(t, k.mock.ExpectationsWereMet())
+}
+
+func TestReview(t *testing.T) {
+	t.Run("approves a face-matched submission", func(t *testing.T) {
+		k := newKYCTest(t)
+		k.expectVerification(kyc.StatusFaceMatched)
+		k.mock.ExpectBegin()
+		k.mock.ExpectExec(`UPDATE kyc_verifications\s+SET status = \$1`).
+			WithArgs(string(kyc.StatusApproved), int64(entity.KYCLevelVerified), nil, sqlmock.AnyArg(), reviewerID, sqlmock.AnyArg(), userID, string(kyc.StatusFaceMatched)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		k.mock.ExpectExec(`INSERT INTO outbox_events`).
+			WithArgs(sqlmock.AnyArg(), "kyc.approved", userID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		k.mock.ExpectCommit()
+		k.expectVerification(kyc.StatusApproved)
+
+		res, err := k.service.Approve(context.Background(), reviewerID, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, kyc.StatusApproved, res.Status)
+		assert.Equal(t, entity.KYCLevelVerified, res.Level)
+		assert.NoError(t, k.mock.ExpectationsWereMet())
+	})
+
+	t.Run("does not approve before the faces matched", func(t *testing.T) {
+		k := newKYCTest(t)
+		k.expectVerification(kyc.StatusDocumentsSubmitted)
+
+		_, err := k.service.Approve(context.Background(), reviewerID, userID)
+
+		assert.ErrorIs(t, err, kyc.ErrInvalidTransition)
+		assert.NoError(t, k.mock.ExpectationsWereMet())
+	})
+
+	t.Run("does not review an approved submission again", func(t *testing.T) {
+		k := newKYCTest(t)
+		k.expectVerification(kyc.StatusApproved)
+
+		_, err := k.service.Reject(context.Background(), reviewerID, userID, "blurry photo")
+
+		assert.ErrorIs(t, err, kyc.ErrInvalidTransition)
+		assert.NoError(t, k.mock.ExpectationsWereMet())
+	})
+
+	t.Run("does not let users review themselves", func(t *testing.T) {
+		k := newKYCTest(t)
+
+		_, err := k.service.Approve(context.Background(), userID, userID)
+
+		assert.ErrorIs(t, err, kyc.ErrCannotReviewSelf)
+		assert.NoError(t, k.mock.ExpectationsWereMet())
+	})
+}
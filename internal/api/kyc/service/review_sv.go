@@ -0,0 +1,214 @@
+package kycService
+
+import (
+	"ProjectGolang/internal/api/kyc"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/events"
+	contextPkg "ProjectGolang/pkg/context"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+func (s *kycService) ListVerifications(ctx context.Context, req kyc.VerificationListRequest) (*kyc.VerificationListResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	page, limit := req.Page, req.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	repo, err := s.kycRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+
+	verifications, total, err := repo.Verifications.ListVerifications(ctx, req.Status, limit, (page-1)*limit)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to list KYC verifications")
+		return nil, err
+	}
+
+	result := make([]kyc.VerificationDetail, 0, len(verifications))
+	for _, verification := range verifications {
+		result = append(result, makeVerificationDetail(verification, "", ""))
+	}
+
+	return &kyc.VerificationListResponse{Verifications: result, Total: total}, nil
+}
+
+// GetVerification returns a submission with presigned links to its documents
+// for a reviewer.
+func (s *kycService) GetVerification(ctx context.Context, userID string) (*kyc.VerificationDetail, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	verification, err := s.getVerification(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if verification.Status == kyc.StatusNotStarted {
+		return nil, kyc.ErrVerificationNotFound
+	}
+
+	detail := makeVerificationDetail(verification,
+		s.presign(requestID, verification.UserID, verification.KTPImageURL),
+		s.presign(requestID, verification.UserID, verification.SelfieURL),
+	)
+
+	return &detail, nil
+}
+
+func (s *kycService) Approve(ctx context.Context, reviewerID string, userID string) (*kyc.StatusResponse, error) {
+	return s.review(ctx, kyc.Review{
+		UserID:     userID,
+		Status:     kyc.StatusApproved,
+		Level:      entity.KYCLevelVerified,
+		ReviewedBy: reviewerID,
+	})
+}
+
+func (s *kycService) Reject(ctx context.Context, reviewerID string, userID string, reason string) (*kyc.StatusResponse, error) {
+	return s.review(ctx, kyc.Review{
+		UserID:          userID,
+		Status:          kyc.StatusRejected,
+		Level:           entity.KYCLevelUnverified,
+		RejectionReason: reason,
+		ReviewedBy:      reviewerID,
+	})
+}
+
+func (s *kycService) review(ctx context.Context, review kyc.Review) (*kyc.StatusResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	if review.ReviewedBy == review.UserID {
+		return nil, kyc.ErrCannotReviewSelf
+	}
+
+	current, err := s.getVerification(ctx, review.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Status == kyc.StatusNotStarted {
+		return nil, kyc.ErrVerificationNotFound
+	}
+
+	if !current.Status.CanTransitionTo(review.Status) {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    review.UserID,
+			"from":       current.Status,
+			"to":         review.Status,
+		}).Warn("Invalid KYC review transition")
+		return nil, kyc.ErrInvalidTransition
+	}
+
+	repo, err := s.kycRepository.NewClient(true)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+	defer repo.Rollback()
+
+	review.From = current.Status
+	review.ReviewedAt = time.Now()
+
+	if err := repo.Verifications.RecordReview(ctx, review); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    review.UserID,
+			"error":      err.Error(),
+		}).Error("Failed to record KYC review")
+		return nil, err
+	}
+
+	var event events.Event = events.KYCApproved{Level: int(review.Level), ReviewedBy: review.ReviewedBy}
+	if review.Status == kyc.StatusRejected {
+		event = events.KYCRejected{Reason: review.RejectionReason, ReviewedBy: review.ReviewedBy}
+	}
+	if err := repo.Outbox.Append(ctx, review.UserID, event); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    review.UserID,
+			"error":      err.Error(),
+		}).Error("Failed to record KYC review event")
+		return nil, err
+	}
+
+	if err := repo.Commit(); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to commit transaction")
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"request_id":  requestID,
+		"user_id":     review.UserID,
+		"reviewed_by": review.ReviewedBy,
+		"status":      review.Status,
+	}).Info("KYC verification reviewed")
+
+	return s.GetStatus(ctx, review.UserID)
+}
+
+// presign links a document for a reviewer, who is the one person other than
+// its owner allowed to see it.
+func (s *kycService) presign(requestID string, userID string, fileKey string) string {
+	if fileKey == "" {
+		return ""
+	}
+
+	presignedURL, err := s.s3Client.PresignUrl(userID, fileKey)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Warn("Failed to presign KYC document URL")
+		return ""
+	}
+
+	return presignedURL
+}
+
+func makeVerificationDetail(verification kyc.Verification, ktpImageURL string, selfieURL string) kyc.VerificationDetail {
+	detail := kyc.VerificationDetail{
+		UserID:          verification.UserID,
+		Status:          verification.Status,
+		Level:           verification.Level,
+		KTPImageURL:     ktpImageURL,
+		SelfieURL:       selfieURL,
+		RejectionReason: verification.RejectionReason,
+		Attempts:        verification.Attempts,
+		ReviewedBy:      verification.ReviewedBy,
+	}
+
+	if !verification.FaceMatchedAt.IsZero() {
+		similarity := verification.FaceSimilarity
+		detail.FaceSimilarity = &similarity
+		detail.FaceMatchedAt = &verification.FaceMatchedAt
+	}
+	if !verification.SubmittedAt.IsZero() {
+		detail.SubmittedAt = &verification.SubmittedAt
+	}
+	if !verification.ReviewedAt.IsZero() {
+		detail.ReviewedAt = &verification.ReviewedAt
+	}
+
+	return detail
+}
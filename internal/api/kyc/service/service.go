@@ -0,0 +1,55 @@
+package kycService
+
+import (
+	authService "ProjectGolang/internal/api/auth/service"
+	"ProjectGolang/internal/api/kyc"
+	kycRepository "ProjectGolang/internal/api/kyc/repository"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/jobs"
+	"ProjectGolang/pkg/facematch"
+	"ProjectGolang/pkg/liveness"
+	"ProjectGolang/pkg/s3"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"mime/multipart"
+)
+
+type IKYCService interface {
+	GetStatus(ctx context.Context, userID string) (*kyc.StatusResponse, error)
+	SubmitDocuments(ctx context.Context, userID string, ktpPhoto *multipart.FileHeader, selfie *multipart.FileHeader, livenessToken string) (*kyc.StatusResponse, error)
+	GetLevel(ctx context.Context, userID string) (entity.KYCLevel, error)
+	ListVerifications(ctx context.Context, req kyc.VerificationListRequest) (*kyc.VerificationListResponse, error)
+	GetVerification(ctx context.Context, userID string) (*kyc.VerificationDetail, error)
+	Approve(ctx context.Context, reviewerID string, userID string) (*kyc.StatusResponse, error)
+	Reject(ctx context.Context, reviewerID string, userID string, reason string) (*kyc.StatusResponse, error)
+}
+
+type kycService struct {
+	log           *logrus.Logger
+	kycRepository kycRepository.Repository
+	authService   authService.AuthService
+	faceMatcher   facematch.IFaceMatcher
+	liveness      liveness.ITokens
+	s3Client      s3.ItfS3
+	jobs          jobs.Enqueuer
+}
+
+func NewKYCService(
+	log *logrus.Logger,
+	kr kycRepository.Repository,
+	as authService.AuthService,
+	faceMatcher facematch.IFaceMatcher,
+	livenessTokens liveness.ITokens,
+	s3Client s3.ItfS3,
+	jobs jobs.Enqueuer,
+) IKYCService {
+	return &kycService{
+		log:           log,
+		kycRepository: kr,
+		authService:   as,
+		faceMatcher:   faceMatcher,
+		liveness:      livenessTokens,
+		s3Client:      s3Client,
+		jobs:          jobs,
+	}
+}
@@ -0,0 +1,125 @@
+package notification
+
+import (
+	"golang.org/x/net/context"
+	"time"
+)
+
+type Channel string
+
+const (
+	ChannelWhatsApp Channel = "whatsapp"
+	ChannelEmail    Channel = "email"
+	ChannelSMS      Channel = "sms"
+	ChannelInApp    Channel = "in_app"
+)
+
+type Language string
+
+const (
+	LanguageIndonesian Language = "id"
+	LanguageEnglish    Language = "en"
+
+	DefaultLanguage = LanguageIndonesian
+)
+
+// Category groups templates for channel preferences, so a user picks their
+// channels once for all wallet notifications rather than per template.
+type Category string
+
+const (
+	CategorySecurity Category = "security"
+	CategoryWallet   Category = "wallet"
+	CategoryBudget   Category = "budget"
+)
+
+type Template string
+
+const (
+	TemplatePhoneOTP                  Template = "phone_otp"
+	TemplateEmailOTP                  Template = "email_otp"
+	TemplateAccountUnlockOTP          Template = "account_unlock_otp"
+	TemplateAccountDeletionOTP        Template = "account_deletion_otp"
+	TemplateWalletCredited            Template = "wallet_credited"
+	TemplateWalletRefunded            Template = "wallet_refunded"
+	TemplateBudgetTransactionRecorded Template = "budget_transaction_recorded"
+)
+
+const (
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed"
+	DeliveryStatusSkipped = "skipped"
+)
+
+// Message is a notification to render from Template with Data and deliver to
+// a user. UserID picks the user's language, channels and contact details;
+// it is empty when the recipient has no account yet. Phone and Email
+// override the stored contact details, e.g. to verify a new number.
+// CodeKey is the Redis key of a one-time code; the code is read from there
+// into Data["code"] when the message is sent, so it is never queued.
+type Message struct {
+	Template Template               `json:"template"`
+	UserID   string                 `json:"user_id,omitempty"`
+	Phone    string                 `json:"phone,omitempty"`
+	Email    string                 `json:"email,omitempty"`
+	CodeKey  string                 `json:"code_key,omitempty"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+// Notifier delivers messages on the first channel that works, trying the
+// user's preferred channels in order. It fails only when every channel did.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+type Preferences struct {
+	UserID    string
+	Language  Language
+	Channels  map[Category][]Channel
+	UpdatedAt time.Time
+}
+
+type PreferencesResponse struct {
+	Language Language               `json:"language"`
+	Channels map[Category][]Channel `json:"channels"`
+}
+
+// UpdatePreferencesRequest replaces the channels of the categories it lists,
+// in order of preference. An empty list turns a category off, except for
+// security codes, which are always sent.
+type UpdatePreferencesRequest struct {
+	Language Language               `json:"language" validate:"omitempty,oneof=id en"`
+	Channels map[Category][]Channel `json:"channels" validate:"omitempty,dive,keys,oneof=security wallet budget,endkeys,unique,dive,oneof=whatsapp email sms in_app"`
+}
+
+type Delivery struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	Template  Template  `json:"template"`
+	Channel   Channel   `json:"channel"`
+	Recipient string    `json:"recipient"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InAppNotification struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"-"`
+	Template  Template   `json:"template"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type InboxResponse struct {
+	Notifications []InAppNotification `json:"notifications"`
+	Unread        int                 `json:"unread"`
+}
+
+// Contact holds the addresses stored for a user.
+type Contact struct {
+	Phone string
+	Email string
+}
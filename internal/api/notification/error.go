@@ -0,0 +1,14 @@
+package notification
+
+import (
+	"ProjectGolang/pkg/response"
+	"net/http"
+)
+
+var (
+	ErrNotificationNotFound = response.NewError(http.StatusNotFound, "notification not found")
+	ErrDeliveryFailed       = response.NewError(http.StatusBadGateway, "notification could not be delivered on any channel")
+	ErrUnknownTemplate      = response.NewError(http.StatusInternalServerError, "unknown notification template")
+	ErrTemplateData         = response.NewError(http.StatusInternalServerError, "notification template data is incomplete")
+	ErrCodeExpired          = response.NewError(http.StatusGone, "one-time code expired before it could be sent")
+)
@@ -0,0 +1,40 @@
+package notificationHandler
+
+import (
+	notificationService "ProjectGolang/internal/api/notification/service"
+	"ProjectGolang/internal/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type NotificationHandler struct {
+	log                 *logrus.Logger
+	validator           *validator.Validate
+	middleware          middleware.Middleware
+	notificationService notificationService.INotificationService
+}
+
+func New(
+	log *logrus.Logger,
+	validate *validator.Validate,
+	middleware middleware.Middleware,
+	notificationService notificationService.INotificationService,
+) *NotificationHandler {
+	return &NotificationHandler{
+		log:                 log,
+		validator:           validate,
+		middleware:          middleware,
+		notificationService: notificationService,
+	}
+}
+
+func (h *NotificationHandler) Start(srv fiber.Router) {
+	notificationGroup := srv.Group("/notifications", h.middleware.NewTokenMiddleware)
+
+	notificationGroup.Get("/", h.GetInbox)
+	notificationGroup.Get("/preferences", h.GetPreferences)
+	notificationGroup.Put("/preferences", h.UpdatePreferences)
+	notificationGroup.Get("/deliveries", h.GetDeliveries)
+	notificationGroup.Patch("/:id/read", h.MarkRead)
+}
@@ -0,0 +1,149 @@
+package notificationHandler
+
+import (
+	"ProjectGolang/internal/api/notification"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/handlerUtil"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+	"strconv"
+	"time"
+)
+
+func (h *NotificationHandler) GetInbox(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+
+	res, err := h.notificationService.GetInbox(c, userData.ID, limit)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_inbox")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *NotificationHandler) MarkRead(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	if err := h.notificationService.MarkRead(c, userData.ID, ctx.Params("id")); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "mark_notification_read")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, nil)
+	}
+}
+
+func (h *NotificationHandler) GetPreferences(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	res, err := h.notificationService.GetPreferences(c, userData.ID)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_notification_preferences")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *NotificationHandler) UpdatePreferences(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	var req notification.UpdatePreferencesRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "parse_request_body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(ctx, requestID, err, ctx.Path())
+	}
+
+	res, err := h.notificationService.UpdatePreferences(c, userData.ID, req)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "update_notification_preferences")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
+
+func (h *NotificationHandler) GetDeliveries(ctx *fiber.Ctx) error {
+	requestID := h.middleware.GetRequestID(ctx)
+	c, cancel := context.WithTimeout(contextPkg.FromFiberCtx(ctx), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerUtil.New(h.log)
+
+	userData, err := jwtPkg.GetUserLoginData(ctx)
+	if err != nil {
+		return errHandler.HandleUnauthorized(ctx, requestID, "Unauthorized")
+	}
+
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+
+	res, err := h.notificationService.GetDeliveries(c, userData.ID, limit)
+	if err != nil {
+		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "get_notification_deliveries")
+	}
+
+	select {
+	case <-c.Done():
+		return errHandler.HandleRequestTimeout(ctx)
+	default:
+		return errHandler.HandleSuccess(ctx, fiber.StatusOK, res)
+	}
+}
@@ -0,0 +1,102 @@
+package notificationRepository
+
+import (
+	"ProjectGolang/internal/api/notification"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+type DeliveryDB struct {
+	ID        sql.NullString `db:"id"`
+	UserID    sql.NullString `db:"user_id"`
+	Template  sql.NullString `db:"template"`
+	Channel   sql.NullString `db:"channel"`
+	Recipient sql.NullString `db:"recipient"`
+	Status    sql.NullString `db:"status"`
+	Error     sql.NullString `db:"error"`
+	CreatedAt sql.NullTime   `db:"created_at"`
+}
+
+func (r *deliveryRepository) CreateDelivery(ctx context.Context, delivery notification.Delivery) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"id":         delivery.ID,
+		"user_id":    sql.NullString{String: delivery.UserID, Valid: delivery.UserID != ""},
+		"template":   string(delivery.Template),
+		"channel":    string(delivery.Channel),
+		"recipient":  sql.NullString{String: delivery.Recipient, Valid: delivery.Recipient != ""},
+		"status":     delivery.Status,
+		"error":      sql.NullString{String: delivery.Error, Valid: delivery.Error != ""},
+		"created_at": delivery.CreatedAt,
+	}
+
+	query, args, err := sqlx.Named(queryCreateDelivery, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CreateDelivery named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CreateDelivery execution err")
+		return err
+	}
+
+	return nil
+}
+
+func (r *deliveryRepository) GetDeliveries(ctx context.Context, userID string, limit int) ([]notification.Delivery, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var rows []DeliveryDB
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+		"limit":   limit,
+	}
+
+	query, args, err := sqlx.Named(queryGetDeliveries, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetDeliveries named query preparation err")
+		return nil, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.SelectContext(ctx, &rows, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetDeliveries execution err")
+		return nil, err
+	}
+
+	deliveries := make([]notification.Delivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, notification.Delivery{
+			ID:        row.ID.String,
+			UserID:    row.UserID.String,
+			Template:  notification.Template(row.Template.String),
+			Channel:   notification.Channel(row.Channel.String),
+			Recipient: row.Recipient.String,
+			Status:    row.Status.String,
+			Error:     row.Error.String,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,169 @@
+package notificationRepository
+
+import (
+	"ProjectGolang/internal/api/notification"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+type NotificationDB struct {
+	ID        sql.NullString `db:"id"`
+	UserID    sql.NullString `db:"user_id"`
+	Template  sql.NullString `db:"template"`
+	Title     sql.NullString `db:"title"`
+	Body      sql.NullString `db:"body"`
+	ReadAt    sql.NullTime   `db:"read_at"`
+	CreatedAt sql.NullTime   `db:"created_at"`
+}
+
+func (r *inboxRepository) CreateNotification(ctx context.Context, n notification.InAppNotification) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"id":         n.ID,
+		"user_id":    n.UserID,
+		"template":   string(n.Template),
+		"title":      n.Title,
+		"body":       n.Body,
+		"created_at": n.CreatedAt,
+	}
+
+	query, args, err := sqlx.Named(queryCreateNotification, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CreateNotification named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CreateNotification execution err")
+		return err
+	}
+
+	return nil
+}
+
+func (r *inboxRepository) GetNotifications(ctx context.Context, userID string, limit int) ([]notification.InAppNotification, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var rows []NotificationDB
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+		"limit":   limit,
+	}
+
+	query, args, err := sqlx.Named(queryGetNotifications, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetNotifications named query preparation err")
+		return nil, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.SelectContext(ctx, &rows, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetNotifications execution err")
+		return nil, err
+	}
+
+	notifications := make([]notification.InAppNotification, 0, len(rows))
+	for _, row := range rows {
+		n := notification.InAppNotification{
+			ID:        row.ID.String,
+			UserID:    row.UserID.String,
+			Template:  notification.Template(row.Template.String),
+			Title:     row.Title.String,
+			Body:      row.Body.String,
+			CreatedAt: row.CreatedAt.Time,
+		}
+		if row.ReadAt.Valid {
+			readAt := row.ReadAt.Time
+			n.ReadAt = &readAt
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, nil
+}
+
+func (r *inboxRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var count int
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryCountUnread, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CountUnread named query preparation err")
+		return 0, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).Scan(&count); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("CountUnread execution err")
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *inboxRepository) MarkRead(ctx context.Context, id string, userID string, readAt time.Time) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"id":      id,
+		"user_id": userID,
+		"read_at": readAt,
+	}
+
+	query, args, err := sqlx.Named(queryMarkRead, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("MarkRead named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	result, err := r.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("MarkRead execution err")
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return notification.ErrNotificationNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package notificationRepository
+
+import (
+	"ProjectGolang/internal/api/notification"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+type PreferencesDB struct {
+	UserID    sql.NullString `db:"user_id"`
+	Language  sql.NullString `db:"language"`
+	Channels  []byte         `db:"channels"`
+	UpdatedAt sql.NullTime   `db:"updated_at"`
+}
+
+type ContactDB struct {
+	PhoneNumber sql.NullString `db:"phone_number"`
+	Email       sql.NullString `db:"email"`
+}
+
+// GetPreferences returns the stored preferences of the user, or empty ones
+// if they never changed them.
+func (r *preferenceRepository) GetPreferences(ctx context.Context, userID string) (notification.Preferences, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var prefs PreferencesDB
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryGetPreferences, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetPreferences named query preparation err")
+		return notification.Preferences{}, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).StructScan(&prefs); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notification.Preferences{UserID: userID}, nil
+		}
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetPreferences execution err")
+		return notification.Preferences{}, err
+	}
+
+	channels := make(map[notification.Category][]notification.Channel)
+	if len(prefs.Channels) > 0 {
+		if err := json.Unmarshal(prefs.Channels, &channels); err != nil {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("GetPreferences channels decoding err")
+			return notification.Preferences{}, err
+		}
+	}
+
+	return notification.Preferences{
+		UserID:    prefs.UserID.String,
+		Language:  notification.Language(prefs.Language.String),
+		Channels:  channels,
+		UpdatedAt: prefs.UpdatedAt.Time,
+	}, nil
+}
+
+func (r *preferenceRepository) UpsertPreferences(ctx context.Context, prefs notification.Preferences) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	channels, err := json.Marshal(prefs.Channels)
+	if err != nil {
+		return err
+	}
+
+	argsKV := map[string]interface{}{
+		"user_id":    prefs.UserID,
+		"language":   string(prefs.Language),
+		"channels":   string(channels),
+		"updated_at": prefs.UpdatedAt,
+	}
+
+	query, args, err := sqlx.Named(queryUpsertPreferences, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("UpsertPreferences named query preparation err")
+		return err
+	}
+
+	query = r.q.Rebind(query)
+
+	if _, err := r.q.ExecContext(ctx, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("UpsertPreferences execution err")
+		return err
+	}
+
+	return nil
+}
+
+// GetContact returns the phone number and email stored for the user, empty
+// if the user does not exist.
+func (r *preferenceRepository) GetContact(ctx context.Context, userID string) (notification.Contact, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var contact ContactDB
+
+	argsKV := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryGetContact, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetContact named query preparation err")
+		return notification.Contact{}, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).StructScan(&contact); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notification.Contact{}, nil
+		}
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetContact execution err")
+		return notification.Contact{}, err
+	}
+
+	return notification.Contact{
+		Phone: contact.PhoneNumber.String,
+		Email: contact.Email.String,
+	}, nil
+}
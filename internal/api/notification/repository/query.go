@@ -0,0 +1,122 @@
+package notificationRepository
+
+const (
+	queryGetPreferences = `
+		SELECT
+			user_id,
+			language,
+			channels,
+			updated_at
+		FROM notification_preferences
+		WHERE user_id = :user_id
+	`
+
+	queryUpsertPreferences = `
+		INSERT INTO notification_preferences (
+			user_id,
+			language,
+			channels,
+			updated_at
+		) VALUES (
+			:user_id,
+			:language,
+			:channels,
+			:updated_at
+		)
+		ON CONFLICT (user_id) DO UPDATE SET
+			language = EXCLUDED.language,
+			channels = EXCLUDED.channels,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	queryGetContact = `
+		SELECT
+			phone_number,
+			email
+		FROM users
+		WHERE id = :user_id
+	`
+
+	queryCreateDelivery = `
+		INSERT INTO notification_deliveries (
+			id,
+			user_id,
+			template,
+			channel,
+			recipient,
+			status,
+			error,
+			created_at
+		) VALUES (
+			:id,
+			:user_id,
+			:template,
+			:channel,
+			:recipient,
+			:status,
+			:error,
+			:created_at
+		)
+	`
+
+	queryGetDeliveries = `
+		SELECT
+			id,
+			user_id,
+			template,
+			channel,
+			recipient,
+			status,
+			error,
+			created_at
+		FROM notification_deliveries
+		WHERE user_id = :user_id
+		ORDER BY created_at DESC
+		LIMIT :limit
+	`
+
+	queryCreateNotification = `
+		INSERT INTO notifications (
+			id,
+			user_id,
+			template,
+			title,
+			body,
+			created_at
+		) VALUES (
+			:id,
+			:user_id,
+			:template,
+			:title,
+			:body,
+			:created_at
+		)
+	`
+
+	queryGetNotifications = `
+		SELECT
+			id,
+			user_id,
+			template,
+			title,
+			body,
+			read_at,
+			created_at
+		FROM notifications
+		WHERE user_id = :user_id
+		ORDER BY created_at DESC
+		LIMIT :limit
+	`
+
+	queryCountUnread = `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE user_id = :user_id AND read_at IS NULL
+	`
+
+	queryMarkRead = `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, :read_at)
+		WHERE id = :id AND user_id = :user_id
+	`
+)
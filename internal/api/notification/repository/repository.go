@@ -0,0 +1,102 @@
+package notificationRepository
+
+import (
+	"ProjectGolang/internal/api/notification"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+type SQLExecutor interface {
+	sqlx.ExtContext
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row
+	Rebind(query string) string
+}
+
+func New(db *sqlx.DB, log *logrus.Logger) Repository {
+	return &repository{
+		DB:  db,
+		log: log,
+	}
+}
+
+type repository struct {
+	DB  *sqlx.DB
+	log *logrus.Logger
+}
+
+type Repository interface {
+	NewClient(tx bool) (Client, error)
+}
+
+func (r *repository) NewClient(tx bool) (Client, error) {
+	var sqlExecutor SQLExecutor
+	var commitFunc, rollbackFunc func() error
+
+	sqlExecutor = r.DB
+
+	if tx {
+		var err error
+		txx, err := r.DB.Beginx()
+		if err != nil {
+			return Client{}, err
+		}
+
+		sqlExecutor = txx
+		commitFunc = txx.Commit
+		rollbackFunc = txx.Rollback
+	} else {
+		commitFunc = func() error { return nil }
+		rollbackFunc = func() error { return nil }
+	}
+
+	return Client{
+		Preferences: &preferenceRepository{q: sqlExecutor, log: r.log},
+		Deliveries:  &deliveryRepository{q: sqlExecutor, log: r.log},
+		Inbox:       &inboxRepository{q: sqlExecutor, log: r.log},
+		Commit:      commitFunc,
+		Rollback:    rollbackFunc,
+	}, nil
+}
+
+type Client struct {
+	// Preferences also reads the contact details of users, which live in the
+	// users table.
+	Preferences interface {
+		GetPreferences(ctx context.Context, userID string) (notification.Preferences, error)
+		UpsertPreferences(ctx context.Context, prefs notification.Preferences) error
+		GetContact(ctx context.Context, userID string) (notification.Contact, error)
+	}
+
+	Deliveries interface {
+		CreateDelivery(ctx context.Context, delivery notification.Delivery) error
+		GetDeliveries(ctx context.Context, userID string, limit int) ([]notification.Delivery, error)
+	}
+
+	Inbox interface {
+		CreateNotification(ctx context.Context, n notification.InAppNotification) error
+		GetNotifications(ctx context.Context, userID string, limit int) ([]notification.InAppNotification, error)
+		CountUnread(ctx context.Context, userID string) (int, error)
+		MarkRead(ctx context.Context, id string, userID string, readAt time.Time) error
+	}
+
+	Commit   func() error
+	Rollback func() error
+}
+
+type preferenceRepository struct {
+	q   SQLExecutor
+	log *logrus.Logger
+}
+
+type deliveryRepository struct {
+	q   SQLExecutor
+	log *logrus.Logger
+}
+
+type inboxRepository struct {
+	q   SQLExecutor
+	log *logrus.Logger
+}
@@ -0,0 +1,88 @@
+package notificationService
+
+import (
+	"ProjectGolang/internal/api/notification"
+	"ProjectGolang/pkg/sms"
+	"ProjectGolang/pkg/smtp"
+	"ProjectGolang/pkg/whatsapp"
+	"errors"
+	"golang.org/x/net/context"
+	"strings"
+)
+
+// Sender delivers a rendered notification on an external channel, to the
+// phone number or email the channel reaches.
+type Sender interface {
+	Channel() notification.Channel
+	Send(ctx context.Context, to string, subject string, body string) error
+}
+
+type whatsappSender struct {
+	client whatsapp.IWhatsappSender
+}
+
+func NewWhatsAppSender(client whatsapp.IWhatsappSender) Sender {
+	return &whatsappSender{client: client}
+}
+
+func (s *whatsappSender) Channel() notification.Channel {
+	return notification.ChannelWhatsApp
+}
+
+func (s *whatsappSender) Send(ctx context.Context, to string, _ string, body string) error {
+	if !s.client.IsConnected() {
+		return errors.New("whatsapp client is not connected")
+	}
+	return s.client.SendMessage(ctx, to, body)
+}
+
+type emailSender struct {
+	mailer smtp.ItfSmtp
+}
+
+func NewEmailSender(mailer smtp.ItfSmtp) Sender {
+	return &emailSender{mailer: mailer}
+}
+
+func (s *emailSender) Channel() notification.Channel {
+	return notification.ChannelEmail
+}
+
+func (s *emailSender) Send(_ context.Context, to string, subject string, body string) error {
+	return s.mailer.Send(to, subject, body)
+}
+
+type smsSender struct {
+	client sms.ISender
+}
+
+func NewSMSSender(client sms.ISender) Sender {
+	return &smsSender{client: client}
+}
+
+func (s *smsSender) Channel() notification.Channel {
+	return notification.ChannelSMS
+}
+
+func (s *smsSender) Send(ctx context.Context, to string, _ string, body string) error {
+	return s.client.Send(ctx, to, body)
+}
+
+// maskAddress hides most of a phone number or email in the delivery log.
+func maskAddress(channel notification.Channel, address string) string {
+	switch channel {
+	case notification.ChannelEmail:
+		name, domain, ok := strings.Cut(address, "@")
+		if !ok || name == "" {
+			return "***"
+		}
+		return name[:1] + "***@" + domain
+	case notification.ChannelWhatsApp, notification.ChannelSMS:
+		if len(address) <= 6 {
+			return "***"
+		}
+		return address[:3] + strings.Repeat("*", len(address)-6) + address[len(address)-3:]
+	default:
+		return address
+	}
+}
@@ -0,0 +1,22 @@
+package notificationService
+
+import (
+	"ProjectGolang/internal/api/notification"
+	"ProjectGolang/internal/events"
+	"golang.org/x/net/context"
+)
+
+// Subscribe tells users about the events that concern them.
+func (s *notificationService) Subscribe(dispatcher *events.Dispatcher) {
+	events.Subscribe(dispatcher, "notification.wallet_credited", func(ctx context.Context, userID string, event events.WalletCredited) error {
+		return s.notifyQueued(ctx, notification.Message{
+			Template: notification.TemplateWalletCredited,
+			UserID:   userID,
+			Data: map[string]interface{}{
+				"amount":       event.Amount,
+				"balance":      event.Balance,
+				"reference_no": event.ReferenceNo,
+			},
+		})
+	})
+}
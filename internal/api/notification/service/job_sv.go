@@ -0,0 +1,61 @@
+package notificationService
+
+import (
+	"ProjectGolang/internal/api/notification"
+	"ProjectGolang/internal/jobs"
+	"errors"
+	"golang.org/x/net/context"
+)
+
+// deliverAttempts keeps retries of a message within the lifetime of the
+// codes it may carry.
+const deliverAttempts = 3
+
+type deliverJob struct {
+	Message notification.Message `json:"message"`
+}
+
+func (deliverJob) JobType() string {
+	return "notification"
+}
+
+// Redacted keeps only the template and the user, so a dead letter holds no
+// contact details or template data.
+func (j deliverJob) Redacted() jobs.Job {
+	return deliverJob{Message: notification.Message{
+		Template: j.Message.Template,
+		UserID:   j.Message.UserID,
+	}}
+}
+
+type queuedNotifier struct {
+	queue jobs.Enqueuer
+}
+
+// NewQueuedNotifier queues messages instead of sending them, so a slow or
+// failing channel does not hold up the request. The notification service
+// delivers them once RegisterJobs has been called on the same queue.
+func NewQueuedNotifier(queue jobs.Enqueuer) notification.Notifier {
+	return &queuedNotifier{queue: queue}
+}
+
+func (n *queuedNotifier) Notify(ctx context.Context, msg notification.Message) error {
+	return n.queue.Enqueue(ctx, deliverJob{Message: msg}, jobs.MaxAttempts(deliverAttempts))
+}
+
+func (s *notificationService) RegisterJobs(queue *jobs.Queue) {
+	jobs.Handle(queue, func(ctx context.Context, job deliverJob) error {
+		return s.notifyQueued(ctx, job.Message)
+	})
+}
+
+// notifyQueued sends a message for a job, failing for good when the message
+// itself is broken or its code has expired so the job is not retried.
+func (s *notificationService) notifyQueued(ctx context.Context, msg notification.Message) error {
+	err := s.Notify(ctx, msg)
+	if errors.Is(err, notification.ErrUnknownTemplate) || errors.Is(err, notification.ErrTemplateData) ||
+		errors.Is(err, notification.ErrCodeExpired) {
+		return jobs.Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+package notificationService
+
+import (
+	"ProjectGolang/internal/api/notification"
+	notificationRepository "ProjectGolang/internal/api/notification/repository"
+	"ProjectGolang/internal/jobs"
+	"ProjectGolang/pkg/redis"
+	"ProjectGolang/pkg/utils"
+	"encoding/json"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testEmail = "budi@example.com"
+
+// recordedJobs keeps the jobs enqueued instead of running them.
+type recordedJobs struct {
+	mutex sync.Mutex
+	jobs  []jobs.Job
+}
+
+func (r *recordedJobs) Enqueue(_ context.Context, job jobs.Job, _ ...jobs.Option) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.jobs = append(r.jobs, job)
+	return nil
+}
+
+// recordedSender keeps the bodies it was asked to send.
+type recordedSender struct {
+	bodies []string
+}
+
+func (s *recordedSender) Channel() notification.Channel {
+	return notification.ChannelEmail
+}
+
+func (s *recordedSender) Send(_ context.Context, _ string, _ string, body string) error {
+	s.bodies = append(s.bodies, body)
+	return nil
+}
+
+func newNotifyTest(t *testing.T) (*notificationService, *recordedSender, *redis.FakeRedis, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	sender := &recordedSender{}
+	redisServer := redis.NewFake()
+	service := NewNotificationService(log, notificationRepository.New(sqlx.NewDb(db, "postgres"), log), redisServer, utils.New(), sender)
+	return service.(*notificationService), sender, redisServer, mock
+}
+
+func emailOTP() notification.Message {
+	return notification.Message{
+		Template: notification.TemplateEmailOTP,
+		Email:    testEmail,
+		CodeKey:  testEmail,
+		Data:     map[string]interface{}{"minutes": 5},
+	}
+}
+
+func TestQueuedNotifierDoesNotQueueCode(t *testing.T) {
+	queue := &recordedJobs{}
+	notifier := NewQueuedNotifier(queue)
+
+	require.NoError(t, notifier.Notify(context.Background(), emailOTP()))
+
+	require.Len(t, queue.jobs, 1)
+	payload, err := json.Marshal(queue.jobs[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), `"code_key":"budi@example.com"`)
+	assert.NotContains(t, string(payload), `"code"`)
+}
+
+func TestNotifySendsCodeStoredAtDelivery(t *testing.T) {
+	service, sender, redisServer, mock := newNotifyTest(t)
+	require.NoError(t, redisServer.SetOTP(context.Background(), testEmail, "48213", time.Minute))
+	mock.ExpectExec(`INSERT INTO notification_deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, service.notifyQueued(context.Background(), emailOTP()))
+
+	require.Len(t, sender.bodies, 1)
+	assert.Contains(t, sender.bodies[0], "48213")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotifyDropsExpiredCode(t *testing.T) {
+	service, sender, _, _ := newNotifyTest(t)
+
+	err := service.notifyQueued(context.Background(), emailOTP())
+
+	assert.ErrorIs(t, err, notification.ErrCodeExpired)
+	assert.Empty(t, sender.bodies)
+}
+
+func TestDeliverJobRedacted(t *testing.T) {
+	job := deliverJob{Message: notification.Message{
+		Template: notification.TemplateAccountUnlockOTP,
+		UserID:   "user-1",
+		Phone:    "+6281234567890",
+		Email:    testEmail,
+		CodeKey:  "unlock:user-1",
+		Data:     map[string]interface{}{"minutes": 5},
+	}}
+
+	redacted := job.Redacted().(deliverJob)
+
+	assert.Equal(t, notification.Message{
+		Template: notification.TemplateAccountUnlockOTP,
+		UserID:   "user-1",
+	}, redacted.Message)
+}
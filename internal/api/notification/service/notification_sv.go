@@ -0,0 +1,174 @@
+package notificationService
+
+import (
+	"ProjectGolang/internal/api/notification"
+	contextPkg "ProjectGolang/pkg/context"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+const (
+	defaultInboxLimit      = 20
+	defaultDeliveriesLimit = 50
+	maxListLimit           = 100
+)
+
+// defaultChannels are used for a category until the user picks their own.
+var defaultChannels = map[notification.Category][]notification.Channel{
+	notification.CategorySecurity: {notification.ChannelWhatsApp, notification.ChannelSMS, notification.ChannelEmail},
+	notification.CategoryWallet:   {notification.ChannelWhatsApp, notification.ChannelEmail, notification.ChannelInApp},
+	notification.CategoryBudget:   {notification.ChannelInApp},
+}
+
+func (s *notificationService) GetPreferences(ctx context.Context, userID string) (*notification.PreferencesResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.notificationRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+
+	prefs, err := repo.Preferences.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return makePreferencesResponse(prefs), nil
+}
+
+// UpdatePreferences changes the language if given and replaces the channels
+// of each category in the request, keeping the others.
+func (s *notificationService) UpdatePreferences(ctx context.Context, userID string, req notification.UpdatePreferencesRequest) (*notification.PreferencesResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.notificationRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+
+	prefs, err := repo.Preferences.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Language != "" {
+		prefs.Language = req.Language
+	}
+	if prefs.Language == "" {
+		prefs.Language = notification.DefaultLanguage
+	}
+	if prefs.Channels == nil {
+		prefs.Channels = make(map[notification.Category][]notification.Channel)
+	}
+	for category, channels := range req.Channels {
+		prefs.Channels[category] = append([]notification.Channel{}, channels...)
+	}
+	prefs.UserID = userID
+	prefs.UpdatedAt = time.Now()
+
+	if err := repo.Preferences.UpsertPreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"user_id":    userID,
+	}).Info("Notification preferences updated")
+
+	return makePreferencesResponse(prefs), nil
+}
+
+func (s *notificationService) GetInbox(ctx context.Context, userID string, limit int) (*notification.InboxResponse, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.notificationRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+
+	notifications, err := repo.Inbox.GetNotifications(ctx, userID, clampLimit(limit, defaultInboxLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := repo.Inbox.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification.InboxResponse{
+		Notifications: notifications,
+		Unread:        unread,
+	}, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID string, id string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.notificationRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return err
+	}
+
+	return repo.Inbox.MarkRead(ctx, id, userID, time.Now())
+}
+
+func (s *notificationService) GetDeliveries(ctx context.Context, userID string, limit int) ([]notification.Delivery, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.notificationRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return nil, err
+	}
+
+	return repo.Deliveries.GetDeliveries(ctx, userID, clampLimit(limit, defaultDeliveriesLimit))
+}
+
+// makePreferencesResponse fills in the defaults for whatever the user has not
+// chosen.
+func makePreferencesResponse(prefs notification.Preferences) *notification.PreferencesResponse {
+	res := &notification.PreferencesResponse{
+		Language: prefs.Language,
+		Channels: make(map[notification.Category][]notification.Channel, len(defaultChannels)),
+	}
+	if res.Language == "" {
+		res.Language = notification.DefaultLanguage
+	}
+
+	for category, channels := range defaultChannels {
+		if chosen, ok := prefs.Channels[category]; ok {
+			channels = chosen
+		}
+		res.Channels[category] = append([]notification.Channel{}, channels...)
+	}
+
+	return res
+}
+
+func clampLimit(limit int, fallback int) int {
+	if limit <= 0 {
+		return fallback
+	}
+	return min(limit, maxListLimit)
+}
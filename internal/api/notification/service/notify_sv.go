@@ -0,0 +1,240 @@
+package notificationService
+
+import (
+	"ProjectGolang/internal/api/notification"
+	notificationRepository "ProjectGolang/internal/api/notification/repository"
+	contextPkg "ProjectGolang/pkg/context"
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"slices"
+	"strings"
+	"time"
+)
+
+// attemptTimeout bounds a single channel, so a hanging provider leaves time
+// to fall back to the next one.
+const attemptTimeout = 5 * time.Second
+
+// Notify renders msg in the user's language and sends it on the first of the
+// user's channels that succeeds. Every attempt is written to the delivery
+// log.
+func (s *notificationService) Notify(ctx context.Context, msg notification.Message) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	tmpl, ok := templates[msg.Template]
+	if !ok {
+		return fmt.Errorf("%w: %s", notification.ErrUnknownTemplate, msg.Template)
+	}
+
+	if msg.CodeKey != "" {
+		data, err := s.withCode(ctx, msg)
+		if err != nil {
+			return err
+		}
+		msg.Data = data
+	}
+
+	repo, err := s.notificationRepository.NewClient(false)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create repository client")
+		return err
+	}
+
+	prefs := notification.Preferences{UserID: msg.UserID}
+	contact := notification.Contact{Phone: msg.Phone, Email: msg.Email}
+	if msg.UserID != "" {
+		// Without preferences or stored contacts the defaults still reach the
+		// addresses in the message, so neither failure holds back a code.
+		if stored, err := repo.Preferences.GetPreferences(ctx, msg.UserID); err == nil {
+			prefs = stored
+		}
+		if contact.Phone == "" || contact.Email == "" {
+			if stored, err := repo.Preferences.GetContact(ctx, msg.UserID); err == nil {
+				contact.Phone = cmp.Or(contact.Phone, stored.Phone)
+				contact.Email = cmp.Or(contact.Email, stored.Email)
+			}
+		}
+	}
+
+	subject, body, err := render(msg.Template, prefs.Language, msg.Data)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"template":   msg.Template,
+			"error":      err.Error(),
+		}).Error("Failed to render notification")
+		return err
+	}
+
+	preferred, set := prefs.Channels[tmpl.category]
+	channels := channelsFor(tmpl, preferred, set)
+	if len(channels) == 0 {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    msg.UserID,
+			"template":   msg.Template,
+		}).Debug("Notification category turned off by user")
+		return nil
+	}
+
+	failures := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		to := address(channel, msg.UserID, contact)
+		status, sendErr := s.send(ctx, repo, msg.Template, channel, to, subject, body)
+		s.recordDelivery(ctx, repo, msg, channel, to, status, sendErr)
+
+		if status == notification.DeliveryStatusSent {
+			s.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"user_id":    msg.UserID,
+				"template":   msg.Template,
+				"channel":    channel,
+			}).Info("Notification sent")
+			return nil
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    msg.UserID,
+			"template":   msg.Template,
+			"channel":    channel,
+			"status":     status,
+			"error":      sendErr.Error(),
+		}).Warn("Notification channel failed, falling back")
+		failures = append(failures, fmt.Sprintf("%s: %v", channel, sendErr))
+	}
+
+	return fmt.Errorf("%w: %s", notification.ErrDeliveryFailed, strings.Join(failures, "; "))
+}
+
+func (s *notificationService) send(ctx context.Context, repo notificationRepository.Client, key notification.Template, channel notification.Channel, to string, subject string, body string) (string, error) {
+	if to == "" {
+		return notification.DeliveryStatusSkipped, errors.New("no address for channel")
+	}
+
+	if channel == notification.ChannelInApp {
+		id, err := s.utils.NewULIDFromTimestamp(time.Now())
+		if err != nil {
+			return notification.DeliveryStatusFailed, err
+		}
+		err = repo.Inbox.CreateNotification(ctx, notification.InAppNotification{
+			ID:        id,
+			UserID:    to,
+			Template:  key,
+			Title:     subject,
+			Body:      body,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return notification.DeliveryStatusFailed, err
+		}
+		return notification.DeliveryStatusSent, nil
+	}
+
+	sender, ok := s.senders[channel]
+	if !ok {
+		return notification.DeliveryStatusSkipped, errors.New("channel not configured")
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	defer cancel()
+
+	if err := sender.Send(attemptCtx, to, subject, body); err != nil {
+		return notification.DeliveryStatusFailed, err
+	}
+	return notification.DeliveryStatusSent, nil
+}
+
+// recordDelivery writes an attempt to the delivery log. A log that cannot be
+// written does not fail the notification.
+func (s *notificationService) recordDelivery(ctx context.Context, repo notificationRepository.Client, msg notification.Message, channel notification.Channel, to string, status string, sendErr error) {
+	id, err := s.utils.NewULIDFromTimestamp(time.Now())
+	if err != nil {
+		return
+	}
+
+	delivery := notification.Delivery{
+		ID:        id,
+		UserID:    msg.UserID,
+		Template:  msg.Template,
+		Channel:   channel,
+		Recipient: maskAddress(channel, to),
+		Status:    status,
+		CreatedAt: time.Now(),
+	}
+	if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := repo.Deliveries.CreateDelivery(ctx, delivery); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"error":      err.Error(),
+		}).Error("Failed to record notification delivery")
+	}
+}
+
+// channelsFor orders the channels of a template by the user's preference for
+// its category. Critical templates keep all their channels, with the
+// preferred ones first.
+func channelsFor(tmpl template, preferred []notification.Channel, set bool) []notification.Channel {
+	if !set {
+		return tmpl.channels
+	}
+	if !tmpl.critical {
+		return preferred
+	}
+
+	channels := make([]notification.Channel, 0, len(tmpl.channels))
+	for _, channel := range preferred {
+		if slices.Contains(tmpl.channels, channel) {
+			channels = append(channels, channel)
+		}
+	}
+	for _, channel := range tmpl.channels {
+		if !slices.Contains(channels, channel) {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+func address(channel notification.Channel, userID string, contact notification.Contact) string {
+	switch channel {
+	case notification.ChannelWhatsApp, notification.ChannelSMS:
+		return contact.Phone
+	case notification.ChannelEmail:
+		return contact.Email
+	case notification.ChannelInApp:
+		return userID
+	default:
+		return ""
+	}
+}
+
+// withCode returns msg.Data with the one-time code stored at msg.CodeKey. A
+// code that has expired or was already used is not sent at all.
+func (s *notificationService) withCode(ctx context.Context, msg notification.Message) (map[string]interface{}, error) {
+	code, err := s.redisServer.GetOTP(ctx, msg.CodeKey)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"template":   msg.Template,
+			"error":      err.Error(),
+		}).Warn("One-time code gone before it was sent")
+		return nil, notification.ErrCodeExpired
+	}
+
+	data := make(map[string]interface{}, len(msg.Data)+1)
+	for k, v := range msg.Data {
+		data[k] = v
+	}
+	data["code"] = code
+	return data, nil
+}
@@ -0,0 +1,55 @@
+package notificationService
+
+import (
+	"ProjectGolang/internal/api/notification"
+	notificationRepository "ProjectGolang/internal/api/notification/repository"
+	"ProjectGolang/internal/events"
+	"ProjectGolang/internal/jobs"
+	"ProjectGolang/pkg/redis"
+	"ProjectGolang/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+type INotificationService interface {
+	notification.Notifier
+	GetPreferences(ctx context.Context, userID string) (*notification.PreferencesResponse, error)
+	UpdatePreferences(ctx context.Context, userID string, req notification.UpdatePreferencesRequest) (*notification.PreferencesResponse, error)
+	GetInbox(ctx context.Context, userID string, limit int) (*notification.InboxResponse, error)
+	MarkRead(ctx context.Context, userID string, id string) error
+	GetDeliveries(ctx context.Context, userID string, limit int) ([]notification.Delivery, error)
+	RegisterJobs(queue *jobs.Queue)
+	Subscribe(dispatcher *events.Dispatcher)
+}
+
+type notificationService struct {
+	log                    *logrus.Logger
+	notificationRepository notificationRepository.Repository
+	senders                map[notification.Channel]Sender
+	redisServer            redis.IRedis
+	utils                  utils.IUtils
+}
+
+// NewNotificationService delivers on the given senders and the in-app inbox.
+// Channels without a sender are skipped, so a message falls back to the
+// next channel. One-time codes are read from redisServer.
+func NewNotificationService(
+	log *logrus.Logger,
+	nr notificationRepository.Repository,
+	redisServer redis.IRedis,
+	utils utils.IUtils,
+	senders ...Sender,
+) INotificationService {
+	byChannel := make(map[notification.Channel]Sender, len(senders))
+	for _, sender := range senders {
+		byChannel[sender.Channel()] = sender
+	}
+
+	return &notificationService{
+		log:                    log,
+		notificationRepository: nr,
+		senders:                byChannel,
+		redisServer:            redisServer,
+		utils:                  utils,
+	}
+}
@@ -0,0 +1,215 @@
+package notificationService
+
+import (
+	"ProjectGolang/internal/api/notification"
+	"fmt"
+	"math"
+	"strings"
+	textTemplate "text/template"
+)
+
+// template describes how one kind of notification is worded and where it may
+// go.
+type template struct {
+	category notification.Category
+	// channels are tried in this order unless the user prefers otherwise.
+	// Templates that are not critical use the defaults of their category.
+	channels []notification.Channel
+	// critical templates carry a one-time code for the address in the message.
+	// They go out only on their own channels, which preferences can reorder
+	// but not turn off.
+	critical bool
+	text     map[notification.Language]templateText
+}
+
+type templateText struct {
+	subject string
+	body    string
+}
+
+type renderedTemplate struct {
+	subject *textTemplate.Template
+	body    *textTemplate.Template
+}
+
+var templates = map[notification.Template]template{
+	notification.TemplatePhoneOTP: {
+		category: notification.CategorySecurity,
+		channels: []notification.Channel{notification.ChannelWhatsApp, notification.ChannelSMS},
+		critical: true,
+		text: map[notification.Language]templateText{
+			notification.LanguageIndonesian: {
+				subject: "Kode verifikasi Sentra",
+				body:    "Kode verifikasi Sentra Anda adalah {{.code}}. Berlaku {{.minutes}} menit. Jangan berikan kode ini kepada siapa pun.",
+			},
+			notification.LanguageEnglish: {
+				subject: "Sentra verification code",
+				body:    "Your Sentra verification code is {{.code}}. It expires in {{.minutes}} minutes. Never share this code with anyone.",
+			},
+		},
+	},
+	notification.TemplateEmailOTP: {
+		category: notification.CategorySecurity,
+		channels: []notification.Channel{notification.ChannelEmail},
+		critical: true,
+		text: map[notification.Language]templateText{
+			notification.LanguageIndonesian: {
+				subject: "Kode verifikasi email Sentra",
+				body:    "Halo,\n\nKode verifikasi email Sentra Anda adalah {{.code}}. Kode ini berlaku {{.minutes}} menit.\n\nJika Anda tidak meminta kode ini, abaikan email ini.",
+			},
+			notification.LanguageEnglish: {
+				subject: "Sentra email verification code",
+				body:    "Hello,\n\nYour Sentra email verification code is {{.code}}. It expires in {{.minutes}} minutes.\n\nIf you did not request this code, you can ignore this email.",
+			},
+		},
+	},
+	notification.TemplateAccountUnlockOTP: {
+		category: notification.CategorySecurity,
+		channels: []notification.Channel{notification.ChannelWhatsApp, notification.ChannelSMS, notification.ChannelEmail},
+		critical: true,
+		text: map[notification.Language]templateText{
+			notification.LanguageIndonesian: {
+				subject: "Kode buka kunci akun Sentra",
+				body:    "Kode untuk membuka kunci akun Sentra Anda adalah {{.code}}. Berlaku {{.minutes}} menit. Jika Anda tidak memintanya, segera ganti kata sandi Anda.",
+			},
+			notification.LanguageEnglish: {
+				subject: "Sentra account unlock code",
+				body:    "Your Sentra account unlock code is {{.code}}. It expires in {{.minutes}} minutes. If you did not request it, change your password now.",
+			},
+		},
+	},
+	notification.TemplateAccountDeletionOTP: {
+		category: notification.CategorySecurity,
+		channels: []notification.Channel{notification.ChannelWhatsApp, notification.ChannelSMS},
+		critical: true,
+		text: map[notification.Language]templateText{
+			notification.LanguageIndonesian: {
+				subject: "Kode konfirmasi penghapusan akun Sentra",
+				body:    "Kode untuk mengonfirmasi penghapusan akun Sentra Anda adalah {{.code}}. Berlaku {{.minutes}} menit. Jika Anda tidak meminta penghapusan akun, abaikan pesan ini.",
+			},
+			notification.LanguageEnglish: {
+				subject: "Sentra account deletion code",
+				body:    "Your code to confirm deleting your Sentra account is {{.code}}. It expires in {{.minutes}} minutes. If you did not ask to delete your account, ignore this message.",
+			},
+		},
+	},
+	notification.TemplateWalletCredited: {
+		category: notification.CategoryWallet,
+		channels: defaultChannels[notification.CategoryWallet],
+		text: map[notification.Language]templateText{
+			notification.LanguageIndonesian: {
+				subject: "Top up berhasil",
+				body:    "Top up {{rupiah .amount}} berhasil. Saldo Sentra Anda sekarang {{rupiah .balance}}. No. referensi: {{.reference_no}}.",
+			},
+			notification.LanguageEnglish: {
+				subject: "Top up successful",
+				body:    "Your top up of {{rupiah .amount}} was successful. Your Sentra balance is now {{rupiah .balance}}. Reference: {{.reference_no}}.",
+			},
+		},
+	},
+	notification.TemplateWalletRefunded: {
+		category: notification.CategoryWallet,
+		channels: defaultChannels[notification.CategoryWallet],
+		text: map[notification.Language]templateText{
+			notification.LanguageIndonesian: {
+				subject: "Top up dibatalkan",
+				body:    "Top up {{rupiah .amount}} dengan no. referensi {{.reference_no}} dibatalkan dan dananya dikembalikan. Saldo Sentra Anda sekarang {{rupiah .balance}}.",
+			},
+			notification.LanguageEnglish: {
+				subject: "Top up reversed",
+				body:    "Your top up of {{rupiah .amount}} with reference {{.reference_no}} was reversed and refunded. Your Sentra balance is now {{rupiah .balance}}.",
+			},
+		},
+	},
+	notification.TemplateBudgetTransactionRecorded: {
+		category: notification.CategoryBudget,
+		channels: defaultChannels[notification.CategoryBudget],
+		text: map[notification.Language]templateText{
+			notification.LanguageIndonesian: {
+				subject: `{{if eq .type "income"}}Pemasukan{{else}}Pengeluaran{{end}} dicatat`,
+				body:    `{{if eq .type "income"}}Pemasukan{{else}}Pengeluaran{{end}} {{rupiah .nominal}} untuk "{{.title}}" ({{.category}}) telah dicatat.`,
+			},
+			notification.LanguageEnglish: {
+				subject: `{{if eq .type "income"}}Income{{else}}Expense{{end}} recorded`,
+				body:    `{{if eq .type "income"}}Income{{else}}Expense{{end}} of {{rupiah .nominal}} for "{{.title}}" ({{.category}}) was recorded.`,
+			},
+		},
+	},
+}
+
+var templateFuncs = textTemplate.FuncMap{
+	"rupiah": rupiah,
+}
+
+// rendered holds the parsed templates, parsed once so a typo fails at start up
+// rather than when the first notification is sent.
+var rendered = parseTemplates()
+
+func parseTemplates() map[notification.Template]map[notification.Language]renderedTemplate {
+	parsed := make(map[notification.Template]map[notification.Language]renderedTemplate, len(templates))
+	for key, tmpl := range templates {
+		parsed[key] = make(map[notification.Language]renderedTemplate, len(tmpl.text))
+		for language, text := range tmpl.text {
+			name := string(key) + "." + string(language)
+			parsed[key][language] = renderedTemplate{
+				subject: textTemplate.Must(textTemplate.New(name + ".subject").Funcs(templateFuncs).Option("missingkey=error").Parse(text.subject)),
+				body:    textTemplate.Must(textTemplate.New(name + ".body").Funcs(templateFuncs).Option("missingkey=error").Parse(text.body)),
+			}
+		}
+	}
+	return parsed
+}
+
+// render writes the subject and body of a template in language, falling back
+// to Indonesian.
+func render(key notification.Template, language notification.Language, data map[string]interface{}) (string, string, error) {
+	byLanguage, ok := rendered[key]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", notification.ErrUnknownTemplate, key)
+	}
+	tmpl, ok := byLanguage[language]
+	if !ok {
+		tmpl = byLanguage[notification.DefaultLanguage]
+	}
+
+	var subject, body strings.Builder
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return "", "", fmt.Errorf("%w: %v", notification.ErrTemplateData, err)
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("%w: %v", notification.ErrTemplateData, err)
+	}
+
+	return subject.String(), body.String(), nil
+}
+
+// rupiah formats an amount the Indonesian way, e.g. "Rp1.250.000".
+func rupiah(value interface{}) string {
+	var amount float64
+	switch v := value.(type) {
+	case float64:
+		amount = v
+	case float32:
+		amount = float64(v)
+	case int:
+		amount = float64(v)
+	case int64:
+		amount = float64(v)
+	default:
+		return fmt.Sprint(value)
+	}
+
+	digits := fmt.Sprintf("%d", int64(math.Abs(math.Round(amount))))
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if amount < 0 {
+		return "-Rp" + grouped.String()
+	}
+	return "Rp" + grouped.String()
+}
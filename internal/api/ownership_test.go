@@ -0,0 +1,206 @@
+package api_test
+
+import (
+	authHandler "ProjectGolang/internal/api/auth/handler"
+	budgetHandler "ProjectGolang/internal/api/budget_manager/handler"
+	budgetRepository "ProjectGolang/internal/api/budget_manager/repository"
+	budgetService "ProjectGolang/internal/api/budget_manager/service"
+	notificationHandler "ProjectGolang/internal/api/notification/handler"
+	notificationRepository "ProjectGolang/internal/api/notification/repository"
+	notificationService "ProjectGolang/internal/api/notification/service"
+	sentrapayHandler "ProjectGolang/internal/api/sentra_pay/handler"
+	sentrapayRepository "ProjectGolang/internal/api/sentra_pay/repository"
+	sentrapayService "ProjectGolang/internal/api/sentra_pay/service"
+	"ProjectGolang/internal/config"
+	"ProjectGolang/internal/middleware"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The resources below belong to owner. Every test signs in as intruder and
+// expects the same 404 the owner would get for a resource that does not exist,
+// with the lookup scoped to the intruder so nothing of the owner's is read.
+const (
+	owner          = "user-a"
+	intruder       = "user-b"
+	transactionID  = "budget-transaction-of-a"
+	referenceNo    = "TOPUP-OF-A"
+	notificationID = "notification-of-a"
+)
+
+type noRevocations struct{}
+
+func (noRevocations) RevokeAll(context.Context, string) error { return nil }
+
+func (noRevocations) IsRevoked(context.Context, string, time.Time) (bool, error) {
+	return false, nil
+}
+
+type testServer struct {
+	app   *fiber.App
+	mock  sqlmock.Sqlmock
+	token string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	jwt := jwtPkg.New(jwtPkg.Config{AccessTokenSecret: "ownership-test-secret"})
+	mw := middleware.New(log, jwt, noRevocations{})
+	validate := config.NewValidator()
+
+	app := fiber.New()
+	authHandler.New(log, nil, validate, mw, nil, nil, nil).Start(app)
+	budgetHandler.New(log, validate, mw,
+		budgetService.NewBudgetService(log, budgetRepository.New(sqlxDB, log), nil, nil, nil, nil),
+	).Start(app)
+	sentrapayHandler.New(log, validate, mw,
+		sentrapayService.NewSentraPayService(log, sentrapayRepository.New(sqlxDB, log), nil, nil, nil, nil, nil, nil),
+	).Start(app)
+	notificationHandler.New(log, validate, mw,
+		notificationService.NewNotificationService(log, notificationRepository.New(sqlxDB, log), nil, nil),
+	).Start(app)
+
+	token, _, err := jwt.Sign(map[string]interface{}{
+		"id":       intruder,
+		"email":    "b@example.com",
+		"username": "b",
+		"role":     "user",
+	}, time.Hour)
+	require.NoError(t, err)
+
+	return &testServer{app: app, mock: mock, token: token}
+}
+
+// request sends an authenticated request as intruder and returns the status
+// and body.
+func (s *testServer) request(t *testing.T, method string, path string, body string) (int, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+s.token)
+	if body != "" {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+
+	res, err := s.app.Test(req, -1)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	payload, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	return res.StatusCode, string(payload)
+}
+
+// expectBudgetTransactionLookup expects the transaction to be looked up for
+// intruder only, finding nothing.
+func (s *testServer) expectBudgetTransactionLookup() {
+	s.mock.ExpectQuery(`FROM budget_transactions\s+WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(transactionID, intruder).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+}
+
+func TestCrossUserAccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		expect  func(s *testServer)
+		message string
+	}{
+		{
+			name:    "user profile",
+			method:  http.MethodGet,
+			path:    "/users/" + owner,
+			message: "user not found",
+		},
+		{
+			name:    "budget transaction",
+			method:  http.MethodGet,
+			path:    "/budget/transactions/" + transactionID,
+			expect:  (*testServer).expectBudgetTransactionLookup,
+			message: "transaction not found",
+		},
+		{
+			name:    "budget transaction audio",
+			method:  http.MethodGet,
+			path:    "/budget/transactions/" + transactionID + "/audio",
+			expect:  (*testServer).expectBudgetTransactionLookup,
+			message: "transaction not found",
+		},
+		{
+			name:    "budget transaction update",
+			method:  http.MethodPut,
+			path:    "/budget/transactions",
+			body:    `{"id":"` + transactionID + `","title":"Makan","nominal":10000,"type":"expense","category":"makanan"}`,
+			expect:  (*testServer).expectBudgetTransactionLookup,
+			message: "transaction not found",
+		},
+		{
+			name:    "budget transaction delete",
+			method:  http.MethodDelete,
+			path:    "/budget/transactions/" + transactionID,
+			expect:  (*testServer).expectBudgetTransactionLookup,
+			message: "transaction not found",
+		},
+		{
+			name:   "wallet transaction status",
+			method: http.MethodGet,
+			path:   "/wallet/transactions/status/" + referenceNo,
+			expect: func(s *testServer) {
+				s.mock.ExpectQuery(`FROM wallet_transactions\s+WHERE reference_no = \$1 AND user_id = \$2`).
+					WithArgs(referenceNo, intruder).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			message: "transaction not found",
+		},
+		{
+			name:   "notification",
+			method: http.MethodPatch,
+			path:   "/notifications/" + notificationID + "/read",
+			expect: func(s *testServer) {
+				s.mock.ExpectExec(`UPDATE notifications\s+SET read_at = .*\s+WHERE id = \$2 AND user_id = \$3`).
+					WithArgs(sqlmock.AnyArg(), notificationID, intruder).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			message: "notification not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			if tt.expect != nil {
+				tt.expect(s)
+			}
+
+			status, body := s.request(t, tt.method, tt.path, tt.body)
+
+			assert.Equal(t, http.StatusNotFound, status, body)
+			assert.Contains(t, body, tt.message)
+			assert.NoError(t, s.mock.ExpectationsWereMet(), "the lookup must be scoped to the signed-in user")
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package realtime
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	MessageEvent = "event"
+	MessageReady = "ready"
+)
+
+// Event is a domain event as pushed to the devices of its user. ID is the
+// outbox event ID, which a reconnecting client sends back as last_event_id to
+// receive what it missed.
+type Event struct {
+	Message    string          `json:"message"`
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// Ready follows the replay of missed events. Reset tells the client that
+// events could not be replayed, because last_event_id is unknown or older
+// than the replay window, and that it should reload its state over REST.
+type Ready struct {
+	Message     string `json:"message"`
+	Replayed    int    `json:"replayed"`
+	Reset       bool   `json:"reset"`
+	LastEventID string `json:"last_event_id,omitempty"`
+}
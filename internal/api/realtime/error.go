@@ -0,0 +1,12 @@
+package realtime
+
+import (
+	"ProjectGolang/pkg/response"
+	"net/http"
+)
+
+var (
+	ErrTooManyConnections = response.NewError(http.StatusTooManyRequests, "too many event streams open for this user")
+	ErrReplayEvents       = response.NewError(http.StatusInternalServerError, "failed to replay missed events")
+	ErrEventNotFound      = response.NewError(http.StatusNotFound, "event not found")
+)
@@ -0,0 +1,44 @@
+package realtimeHandler
+
+import (
+	realtimeService "ProjectGolang/internal/api/realtime/service"
+	"ProjectGolang/internal/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type RealtimeHandler struct {
+	log             *logrus.Logger
+	middleware      middleware.Middleware
+	realtimeService realtimeService.IRealtimeService
+}
+
+func New(
+	log *logrus.Logger,
+	middleware middleware.Middleware,
+	realtimeService realtimeService.IRealtimeService,
+) *RealtimeHandler {
+	return &RealtimeHandler{
+		log:             log,
+		middleware:      middleware,
+		realtimeService: realtimeService,
+	}
+}
+
+func (h *RealtimeHandler) Start(srv fiber.Router) {
+	wsMiddleware := func(c *fiber.Ctx) error {
+		if websocket.IsWebSocketUpgrade(c) {
+			return c.Next()
+		}
+		return fiber.ErrUpgradeRequired
+	}
+
+	wsConfig := websocket.Config{
+		Subprotocols: []string{middleware.WebSocketTokenProtocol},
+	}
+
+	eventGroup := srv.Group("/events")
+	eventGroup.Use("/ws", wsMiddleware, h.middleware.NewWebSocketTokenMiddleware)
+	eventGroup.Get("/ws", websocket.New(h.handleWebSocket, wsConfig))
+}
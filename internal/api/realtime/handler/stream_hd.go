@@ -0,0 +1,166 @@
+package realtimeHandler
+
+import (
+	"ProjectGolang/internal/api/realtime"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/middleware"
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/log"
+	"errors"
+	"github.com/gofiber/websocket/v2"
+	"golang.org/x/net/context"
+	"sync"
+	"time"
+)
+
+const (
+	streamPingInterval = 30 * time.Second
+	streamIdleLimit    = 60 * time.Second
+	streamWriteWait    = 10 * time.Second
+)
+
+// handleWebSocket streams the events of the authenticated user. A client
+// passing last_event_id first receives the events it missed, then a ready
+// message, then live events as they happen. Nothing is read from the client
+// beyond pings and the close handshake.
+func (h *RealtimeHandler) handleWebSocket(c *websocket.Conn) {
+	requestID, _ := c.Locals(middleware.RequestIDKey).(string)
+	ctx := contextPkg.WithRequestID(context.Background(), requestID)
+
+	var writeMu sync.Mutex
+	write := func(v interface{}) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+
+		if err := c.SetWriteDeadline(time.Now().Add(streamWriteWait)); err != nil {
+			return err
+		}
+		return c.WriteJSON(v)
+	}
+	closeWith := func(code int, text string) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(5*time.Second))
+	}
+
+	user, ok := c.Locals("user").(entity.UserLoginData)
+	if !ok {
+		h.log.WithFields(log.Fields{
+			"request_id": requestID,
+		}).Warn("Event WebSocket opened without an authenticated user")
+		closeWith(websocket.ClosePolicyViolation, "unauthorized")
+		return
+	}
+
+	lastEventID := c.Query("last_event_id")
+
+	conn, err := h.realtimeService.Connect(ctx, user.ID, lastEventID)
+	if err != nil {
+		h.log.WithFields(log.Fields{
+			"request_id": requestID,
+			"user_id":    user.ID,
+			"error":      err.Error(),
+		}).Warn("Failed to open event stream")
+		if errors.Is(err, realtime.ErrTooManyConnections) {
+			closeWith(websocket.CloseTryAgainLater, realtime.ErrTooManyConnections.Error())
+		} else {
+			closeWith(websocket.CloseInternalServerErr, realtime.ErrReplayEvents.Error())
+		}
+		return
+	}
+	defer conn.Close()
+
+	c.SetPingHandler(func(data string) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := c.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(5*time.Second)); err != nil {
+			h.log.Errorf("Error sending pong: %v", err)
+		}
+		return nil
+	})
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(streamIdleLimit))
+	})
+
+	h.log.WithFields(log.Fields{
+		"request_id": requestID,
+		"user_id":    user.ID,
+		"replayed":   len(conn.Missed),
+		"reset":      conn.Reset,
+	}).Info("Event WebSocket client connected")
+
+	// Events replayed from the outbox may also arrive live.
+	replayed := make(map[string]struct{}, len(conn.Missed))
+	ready := realtime.Ready{
+		Message:     realtime.MessageReady,
+		Replayed:    len(conn.Missed),
+		Reset:       conn.Reset,
+		LastEventID: lastEventID,
+	}
+	for _, event := range conn.Missed {
+		if err := write(event); err != nil {
+			return
+		}
+		replayed[event.ID] = struct{}{}
+		ready.LastEventID = event.ID
+	}
+	if conn.Reset {
+		ready.LastEventID = ""
+	}
+	if err := write(ready); err != nil {
+		return
+	}
+
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		for {
+			if err := c.SetReadDeadline(time.Now().Add(streamIdleLimit)); err != nil {
+				return
+			}
+			if _, _, err := c.ReadMessage(); err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure, websocket.CloseNormalClosure) {
+					h.log.Errorf("Event WebSocket error: %v", err)
+				}
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-disconnected:
+			h.log.WithFields(log.Fields{
+				"request_id": requestID,
+				"user_id":    user.ID,
+			}).Info("Event WebSocket client disconnected")
+			return
+		case event, ok := <-conn.Events():
+			if !ok {
+				h.log.WithFields(log.Fields{
+					"request_id": requestID,
+					"user_id":    user.ID,
+				}).Warn("Event WebSocket client fell behind")
+				closeWith(websocket.CloseTryAgainLater, "stream fell behind, reconnect with last_event_id")
+				return
+			}
+			if _, ok := replayed[event.ID]; ok {
+				delete(replayed, event.ID)
+				continue
+			}
+			if err := write(event); err != nil {
+				return
+			}
+		case <-ping.C:
+			writeMu.Lock()
+			err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteWait))
+			writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
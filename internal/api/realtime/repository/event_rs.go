@@ -0,0 +1,86 @@
+package realtimeRepository
+
+import (
+	"ProjectGolang/internal/api/realtime"
+	"ProjectGolang/internal/events"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+// GetEventSequence returns the sequence of a dispatched event of the user.
+func (r *eventRepository) GetEventSequence(ctx context.Context, userID string, id string) (int64, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var sequence int64
+
+	argsKV := map[string]interface{}{
+		"id":      id,
+		"user_id": userID,
+	}
+
+	query, args, err := sqlx.Named(queryGetEventSequence, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetEventSequence named query preparation err")
+		return 0, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).Scan(&sequence); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, realtime.ErrEventNotFound
+		}
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetEventSequence execution err")
+		return 0, err
+	}
+
+	return sequence, nil
+}
+
+func (r *eventRepository) GetUserEventsAfter(ctx context.Context, userID string, afterSequence int64, since time.Time, limit int) ([]events.Record, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+	var rows []events.EventDB
+
+	argsKV := map[string]interface{}{
+		"user_id":        userID,
+		"after_sequence": afterSequence,
+		"since":          since,
+		"limit":          limit,
+	}
+
+	query, args, err := sqlx.Named(queryGetUserEventsAfter, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetUserEventsAfter named query preparation err")
+		return nil, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.SelectContext(ctx, &rows, query, args...); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetUserEventsAfter execution err")
+		return nil, err
+	}
+
+	records := make([]events.Record, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, row.Record())
+	}
+
+	return records, nil
+}
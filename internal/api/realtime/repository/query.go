@@ -0,0 +1,27 @@
+package realtimeRepository
+
+const (
+	queryGetEventSequence = `
+		SELECT sequence
+		FROM outbox_events
+		WHERE id = :id
+			AND user_id = :user_id
+			AND sequence IS NOT NULL
+	`
+
+	queryGetUserEventsAfter = `
+		SELECT
+			id,
+			sequence,
+			type,
+			user_id,
+			payload,
+			occurred_at
+		FROM outbox_events
+		WHERE user_id = :user_id
+			AND sequence > :after_sequence
+			AND occurred_at >= :since
+		ORDER BY sequence
+		LIMIT :limit
+	`
+)
@@ -0,0 +1,75 @@
+package realtimeRepository
+
+import (
+	"ProjectGolang/internal/events"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+type SQLExecutor interface {
+	sqlx.ExtContext
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	Rebind(query string) string
+}
+
+func New(db *sqlx.DB, log *logrus.Logger) Repository {
+	return &repository{
+		DB:  db,
+		log: log,
+	}
+}
+
+type repository struct {
+	DB  *sqlx.DB
+	log *logrus.Logger
+}
+
+type Repository interface {
+	NewClient(tx bool) (Client, error)
+}
+
+func (r *repository) NewClient(tx bool) (Client, error) {
+	var sqlExecutor SQLExecutor
+	var commitFunc, rollbackFunc func() error
+
+	sqlExecutor = r.DB
+
+	if tx {
+		var err error
+		txx, err := r.DB.Beginx()
+		if err != nil {
+			return Client{}, err
+		}
+
+		sqlExecutor = txx
+		commitFunc = txx.Commit
+		rollbackFunc = txx.Rollback
+	} else {
+		commitFunc = func() error { return nil }
+		rollbackFunc = func() error { return nil }
+	}
+
+	return Client{
+		Events:   &eventRepository{q: sqlExecutor, log: r.log},
+		Commit:   commitFunc,
+		Rollback: rollbackFunc,
+	}, nil
+}
+
+type Client struct {
+	// Events reads the outbox written by the other domains.
+	Events interface {
+		GetEventSequence(ctx context.Context, userID string, id string) (int64, error)
+		GetUserEventsAfter(ctx context.Context, userID string, afterSequence int64, since time.Time, limit int) ([]events.Record, error)
+	}
+
+	Commit   func() error
+	Rollback func() error
+}
+
+type eventRepository struct {
+	q   SQLExecutor
+	log *logrus.Logger
+}
@@ -0,0 +1,118 @@
+package realtimeService
+
+import (
+	"ProjectGolang/internal/api/realtime"
+	"sync"
+)
+
+const (
+	maxConnectionsPerUser = 10
+	// connectionBuffer is how far a stream may fall behind before it is
+	// dropped. The client resumes from its last event when it reconnects.
+	connectionBuffer = 64
+)
+
+// Connection is one open stream of a user. Missed holds the events replayed
+// on connect, which come before anything read from Events.
+type Connection struct {
+	UserID string
+	Missed []realtime.Event
+	Reset  bool
+
+	hub    *hub
+	events chan realtime.Event
+	mu     sync.Mutex
+	closed bool
+}
+
+// Events delivers the live events of the user. It is closed when the
+// connection is closed or falls too far behind.
+func (c *Connection) Events() <-chan realtime.Event {
+	return c.events
+}
+
+func (c *Connection) Close() {
+	c.hub.remove(c)
+	c.closeEvents()
+}
+
+func (c *Connection) send(event realtime.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	select {
+	case c.events <- event:
+	default:
+		c.closed = true
+		close(c.events)
+	}
+}
+
+func (c *Connection) closeEvents() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.events)
+	}
+}
+
+// hub tracks the connections open on this instance, by user.
+type hub struct {
+	mu          sync.Mutex
+	connections map[string]map[*Connection]struct{}
+}
+
+func newHub() *hub {
+	return &hub{
+		connections: make(map[string]map[*Connection]struct{}),
+	}
+}
+
+func (h *hub) add(userID string) (*Connection, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	conns := h.connections[userID]
+	if len(conns) >= maxConnectionsPerUser {
+		return nil, realtime.ErrTooManyConnections
+	}
+	if conns == nil {
+		conns = make(map[*Connection]struct{})
+		h.connections[userID] = conns
+	}
+
+	conn := &Connection{
+		UserID: userID,
+		hub:    h,
+		events: make(chan realtime.Event, connectionBuffer),
+	}
+	conns[conn] = struct{}{}
+
+	return conn, nil
+}
+
+func (h *hub) remove(conn *Connection) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	conns := h.connections[conn.UserID]
+	delete(conns, conn)
+	if len(conns) == 0 {
+		delete(h.connections, conn.UserID)
+	}
+}
+
+func (h *hub) broadcast(userID string, event realtime.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for conn := range h.connections[userID] {
+		conn.send(event)
+	}
+}
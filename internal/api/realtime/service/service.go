@@ -0,0 +1,38 @@
+package realtimeService
+
+import (
+	realtimeRepository "ProjectGolang/internal/api/realtime/repository"
+	"ProjectGolang/internal/events"
+	"ProjectGolang/pkg/redis"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+type IRealtimeService interface {
+	Connect(ctx context.Context, userID string, lastEventID string) (*Connection, error)
+	Subscribe(dispatcher *events.Dispatcher)
+	Run(ctx context.Context)
+}
+
+type realtimeService struct {
+	log                *logrus.Logger
+	realtimeRepository realtimeRepository.Repository
+	redis              redis.IRedis
+	hub                *hub
+}
+
+// NewRealtimeService pushes events to the streams open on this instance.
+// Events are fanned out between instances over Redis, so a user's devices
+// may be connected to different ones.
+func NewRealtimeService(
+	log *logrus.Logger,
+	rr realtimeRepository.Repository,
+	redis redis.IRedis,
+) IRealtimeService {
+	return &realtimeService{
+		log:                log,
+		realtimeRepository: rr,
+		redis:              redis,
+		hub:                newHub(),
+	}
+}
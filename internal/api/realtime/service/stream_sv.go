@@ -0,0 +1,183 @@
+package realtimeService
+
+import (
+	"ProjectGolang/internal/api/realtime"
+	"ProjectGolang/internal/events"
+	"ProjectGolang/internal/jobs"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/oklog/ulid/v2"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+const (
+	userEventsChannel = "user_events"
+
+	// replayWindow is how far back a reconnecting client can resume from.
+	replayWindow   = 7 * 24 * time.Hour
+	replayPageSize = 100
+	maxReplay      = 500
+)
+
+type publishedEvent struct {
+	UserID string         `json:"user_id"`
+	Event  realtime.Event `json:"event"`
+}
+
+// Subscribe publishes the events users are shown live to every instance.
+func (s *realtimeService) Subscribe(dispatcher *events.Dispatcher) {
+	dispatcher.SubscribeAll("realtime.user_events", func(ctx context.Context, record events.Record) error {
+		event, ok, err := project(record)
+		if err != nil {
+			return jobs.Permanent(err)
+		}
+		if !ok {
+			return nil
+		}
+
+		message, err := json.Marshal(publishedEvent{UserID: record.UserID, Event: event})
+		if err != nil {
+			return err
+		}
+
+		return s.redis.Publish(ctx, userEventsChannel, string(message))
+	})
+}
+
+// Run delivers the events published by any instance to the connections open
+// on this one, until ctx is cancelled.
+func (s *realtimeService) Run(ctx context.Context) {
+	for message := range s.redis.Subscribe(ctx, userEventsChannel) {
+		var published publishedEvent
+		if err := json.Unmarshal([]byte(message), &published); err != nil {
+			s.log.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Warn("Dropping malformed user event")
+			continue
+		}
+
+		s.hub.broadcast(published.UserID, published.Event)
+	}
+}
+
+// Connect opens a stream for the user. The connection is registered before
+// the replay, so no event falls between the two; events read both from the
+// outbox and live are the client's to skip by ID.
+func (s *realtimeService) Connect(ctx context.Context, userID string, lastEventID string) (*Connection, error) {
+	conn, err := s.hub.add(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastEventID == "" {
+		return conn, nil
+	}
+
+	conn.Missed, conn.Reset, err = s.replay(ctx, userID, lastEventID)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: %v", realtime.ErrReplayEvents, err)
+	}
+
+	return conn, nil
+}
+
+// replay reads the events dispatched after lastEventID, or reports a reset
+// when they cannot all be replayed. Events are replayed in the order they
+// were dispatched rather than by ID, so an event whose transaction committed
+// after a later ID's is not skipped.
+func (s *realtimeService) replay(ctx context.Context, userID string, lastEventID string) ([]realtime.Event, bool, error) {
+	since := time.Now().Add(-replayWindow)
+
+	id, err := ulid.ParseStrict(lastEventID)
+	if err != nil || ulid.Time(id.Time()).Before(since) {
+		return nil, true, nil
+	}
+
+	repo, err := s.realtimeRepository.NewClient(false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	after, err := repo.Events.GetEventSequence(ctx, userID, id.String())
+	if errors.Is(err, realtime.ErrEventNotFound) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var missed []realtime.Event
+	for {
+		records, err := repo.Events.GetUserEventsAfter(ctx, userID, after, since, replayPageSize)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, record := range records {
+			event, ok, err := project(record)
+			if err != nil {
+				s.log.WithFields(logrus.Fields{
+					"event_id": record.ID,
+					"error":    err.Error(),
+				}).Warn("Skipping undecodable event in replay")
+				continue
+			}
+			if ok {
+				missed = append(missed, event)
+			}
+		}
+
+		if len(missed) > maxReplay {
+			return nil, true, nil
+		}
+		if len(records) < replayPageSize {
+			return missed, false, nil
+		}
+		after = records[len(records)-1].Sequence
+	}
+}
+
+// project returns the event as the user is shown it, and whether they are
+// shown it at all. Who reviewed a KYC submission stays internal.
+func project(record events.Record) (realtime.Event, bool, error) {
+	event := realtime.Event{
+		Message:    realtime.MessageEvent,
+		ID:         record.ID,
+		Type:       record.Type,
+		Data:       record.Payload,
+		OccurredAt: record.OccurredAt,
+	}
+
+	var data interface{}
+	switch record.Type {
+	case events.WalletCredited{}.EventType(),
+		events.TopUpStatusChanged{}.EventType():
+		return event, true, nil
+	case events.KYCApproved{}.EventType():
+		var approved events.KYCApproved
+		if err := json.Unmarshal(record.Payload, &approved); err != nil {
+			return realtime.Event{}, false, err
+		}
+		data = map[string]interface{}{"level": approved.Level}
+	case events.KYCRejected{}.EventType():
+		var rejected events.KYCRejected
+		if err := json.Unmarshal(record.Payload, &rejected); err != nil {
+			return realtime.Event{}, false, err
+		}
+		data = map[string]interface{}{"reason": rejected.Reason}
+	default:
+		return realtime.Event{}, false, nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return realtime.Event{}, false, err
+	}
+	event.Data = payload
+
+	return event, true, nil
+}
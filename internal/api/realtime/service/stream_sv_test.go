@@ -0,0 +1,126 @@
+package realtimeService
+
+import (
+	realtimeRepository "ProjectGolang/internal/api/realtime/repository"
+	"ProjectGolang/pkg/redis"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/oklog/ulid/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+	"time"
+)
+
+const testUserID = "user-1"
+
+func newStreamTest(t *testing.T) (*realtimeService, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	service := NewRealtimeService(log, realtimeRepository.New(sqlx.NewDb(db, "postgres"), log), redis.NewFake())
+	return service.(*realtimeService), mock
+}
+
+func eventID(t *testing.T, at time.Time) string {
+	t.Helper()
+
+	id, err := ulid.New(ulid.Timestamp(at), ulid.DefaultEntropy())
+	require.NoError(t, err)
+	return id.String()
+}
+
+func eventRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "sequence", "type", "user_id", "payload", "occurred_at"})
+}
+
+func TestReplayFollowsDispatchOrder(t *testing.T) {
+	service, mock := newStreamTest(t)
+	now := time.Now()
+	lastSeen := eventID(t, now.Add(-time.Minute))
+	// Written before the last event the client saw, but committed and
+	// dispatched after it.
+	lateCommit := eventID(t, now.Add(-2*time.Minute))
+	next := eventID(t, now)
+
+	mock.ExpectQuery(`SELECT sequence\s+FROM outbox_events`).
+		WithArgs(lastSeen, testUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(41))
+	mock.ExpectQuery(`AND sequence > \$2`).
+		WithArgs(testUserID, int64(41), sqlmock.AnyArg(), replayPageSize).
+		WillReturnRows(eventRows().
+			AddRow(lateCommit, 42, "wallet.credited", testUserID, []byte(`{"amount": 10000}`), now.Add(-2*time.Minute)).
+			AddRow(next, 43, "kyc.approved", testUserID, []byte(`{"level": 2, "reviewed_by": "admin-1"}`), now))
+
+	conn, err := service.Connect(context.Background(), testUserID, lastSeen)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.False(t, conn.Reset)
+	require.Len(t, conn.Missed, 2)
+	assert.Equal(t, lateCommit, conn.Missed[0].ID, "an event committed late must still be replayed")
+	assert.Equal(t, next, conn.Missed[1].ID)
+	assert.JSONEq(t, `{"level": 2}`, string(conn.Missed[1].Data))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplayPagesBySequence(t *testing.T) {
+	service, mock := newStreamTest(t)
+	now := time.Now()
+	lastSeen := eventID(t, now.Add(-time.Hour))
+
+	mock.ExpectQuery(`SELECT sequence\s+FROM outbox_events`).
+		WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(1))
+	page := eventRows()
+	for i := 0; i < replayPageSize; i++ {
+		page.AddRow(eventID(t, now), 2+i, "wallet.credited", testUserID, []byte(`{}`), now)
+	}
+	mock.ExpectQuery(`AND sequence > \$2`).
+		WithArgs(testUserID, int64(1), sqlmock.AnyArg(), replayPageSize).
+		WillReturnRows(page)
+	mock.ExpectQuery(`AND sequence > \$2`).
+		WithArgs(testUserID, int64(1+replayPageSize), sqlmock.AnyArg(), replayPageSize).
+		WillReturnRows(eventRows())
+
+	conn, err := service.Connect(context.Background(), testUserID, lastSeen)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.Len(t, conn.Missed, replayPageSize)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplayResets(t *testing.T) {
+	t.Run("unknown event", func(t *testing.T) {
+		service, mock := newStreamTest(t)
+		mock.ExpectQuery(`SELECT sequence\s+FROM outbox_events`).
+			WillReturnRows(sqlmock.NewRows([]string{"sequence"}))
+
+		conn, err := service.Connect(context.Background(), testUserID, eventID(t, time.Now()))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		assert.True(t, conn.Reset)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("older than the replay window", func(t *testing.T) {
+		service, mock := newStreamTest(t)
+
+		conn, err := service.Connect(context.Background(), testUserID, eventID(t, time.Now().Add(-replayWindow-time.Hour)))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		assert.True(t, conn.Reset)
+		assert.NoError(t, mock.ExpectationsWereMet(), "the outbox must not be read")
+	})
+}
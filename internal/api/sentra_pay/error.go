@@ -42,4 +42,6 @@ var (
 	ErrWalletNotFound          = response.NewError(404, "wallet not found")
 	ErrInvalidCallback         = response.NewError(400, "invalid callback data")
 	ErrInvalidTransactionState = response.NewError(400, "invalid transaction state")
+	ErrTopUpLimitExceeded      = response.NewError(403, "top-up amount exceeds the limit for your verification level, complete KYC to raise it")
+	ErrBalanceLimitExceeded    = response.NewError(403, "top-up would exceed the maximum balance for your verification level, complete KYC to raise it")
 )
@@ -218,7 +218,7 @@ func (h *SentraPayHandler) CheckTransactionStatus(ctx *fiber.Ctx) error {
 		"reference_no": referenceNo,
 	}).Debug("Checking transaction status")
 
-	status, err := h.sentraPayService.CheckTransactionStatus(c, referenceNo)
+	status, err := h.sentraPayService.CheckTransactionStatus(c, referenceNo, userData.ID)
 	if err != nil {
 		return errHandler.Handle(ctx, requestID, err, ctx.Path(), "check_transaction_status")
 	}
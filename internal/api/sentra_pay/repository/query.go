@@ -28,12 +28,13 @@ const (
 		WHERE user_id = :user_id
 	`
 
-	queryUpdateWalletBalance = `
+	queryAdjustWalletBalance = `
 		UPDATE wallets
 		SET
-			balance = :balance,
+			balance = balance + :delta,
 			updated_at = :updated_at
-		WHERE user_id = :user_id
+		WHERE user_id = :user_id AND balance + :delta >= 0
+		RETURNING balance
 	`
 
 	queryCreateTransaction = `
@@ -102,12 +103,30 @@ const (
 		WHERE reference_no = :reference_no
 	`
 
+	queryGetUserTransactionByReferenceNo = `
+		SELECT
+			id,
+			user_id,
+			amount,
+			type,
+			reference_no,
+			payment_method,
+			status,
+			bank_account,
+			bank_name,
+			description,
+			created_at,
+			updated_at
+		FROM wallet_transactions
+		WHERE reference_no = :reference_no AND user_id = :user_id
+	`
+
 	queryUpdateTransactionStatus = `
 		UPDATE wallet_transactions
 		SET
 			status = :status,
 			updated_at = :updated_at
-		WHERE reference_no = :reference_no
+		WHERE reference_no = :reference_no AND status = ANY(:from)
 	`
 
 	queryGetTransactionsByUserID = `
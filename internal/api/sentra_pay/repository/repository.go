@@ -2,6 +2,7 @@ package sentrapayRepository
 
 import (
 	sentrapay "ProjectGolang/internal/api/sentra_pay"
+	"ProjectGolang/internal/events"
 	"github.com/jmoiron/sqlx"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
@@ -53,6 +54,7 @@ func (r *repository) NewClient(tx bool) (Client, error) {
 
 	return Client{
 		Wallet:   &walletRepository{q: sqlExecutor, log: r.log},
+		Outbox:   events.NewOutbox(sqlExecutor, r.log),
 		Commit:   commitFunc,
 		Rollback: rollbackFunc,
 	}, nil
@@ -62,14 +64,17 @@ type Client struct {
 	Wallet interface {
 		CreateWallet(ctx context.Context, userID string) error
 		GetWallet(ctx context.Context, userID string) (sentrapay.WalletBalance, error)
-		UpdateWalletBalance(ctx context.Context, userID string, amount float64) error
+		AdjustWalletBalance(ctx context.Context, userID string, delta float64) (float64, error)
 		CreateTransaction(ctx context.Context, transaction sentrapay.WalletTransaction) error
 		GetTransactionByID(ctx context.Context, id string) (sentrapay.WalletTransaction, error)
 		GetTransactionByReferenceNo(ctx context.Context, referenceNo string) (sentrapay.WalletTransaction, error)
-		UpdateTransactionStatus(ctx context.Context, referenceNo string, status string) error
+		GetUserTransactionByReferenceNo(ctx context.Context, referenceNo string, userID string) (sentrapay.WalletTransaction, error)
+		UpdateTransactionStatus(ctx context.Context, referenceNo string, from []string, status string) error
 		GetTransactionsByUserID(ctx context.Context, userID string, limit, offset int) ([]sentrapay.WalletTransaction, int, error)
 	}
 
+	Outbox events.Outbox
+
 	Commit   func() error
 	Rollback func() error
 }
@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 	"time"
 )
@@ -111,52 +112,53 @@ func (r *walletRepository) GetWallet(ctx context.Context, userID string) (sentra
 	}, nil
 }
 
-func (r *walletRepository) UpdateWalletBalance(ctx context.Context, userID string, amount float64) error {
+// AdjustWalletBalance changes the balance by delta in a single statement, so
+// concurrent changes add up instead of overwriting each other, and returns
+// the new balance. A change that would make the balance negative fails with
+// ErrInsufficientBalance.
+func (r *walletRepository) AdjustWalletBalance(ctx context.Context, userID string, delta float64) (float64, error) {
 	requestID := contextPkg.GetRequestID(ctx)
 
 	argsKV := map[string]interface{}{
 		"user_id":    userID,
-		"balance":    amount,
+		"delta":      delta,
 		"updated_at": time.Now(),
 	}
 
-	query, args, err := sqlx.Named(queryUpdateWalletBalance, argsKV)
+	query, args, err := sqlx.Named(queryAdjustWalletBalance, argsKV)
 	if err != nil {
 		r.log.WithFields(logrus.Fields{
 			"request_id": requestID,
 			"error":      err.Error(),
-		}).Error("UpdateWalletBalance named query preparation err")
-		return err
+		}).Error("AdjustWalletBalance named query preparation err")
+		return 0, err
 	}
 
 	query = r.q.Rebind(query)
 
-	result, err := r.q.ExecContext(ctx, query, args...)
-	if err != nil {
-		r.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-			"error":      err.Error(),
-		}).Error("UpdateWalletBalance execution err")
-		return err
-	}
+	var balance float64
+	if err := r.q.QueryRowxContext(ctx, query, args...).Scan(&balance); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Error("AdjustWalletBalance execution err")
+			return 0, err
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		r.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-			"error":      err.Error(),
-		}).Error("UpdateWalletBalance rows affected err")
-		return err
-	}
+		if _, err := r.GetWallet(ctx, userID); err != nil {
+			return 0, err
+		}
 
-	if rowsAffected == 0 {
 		r.log.WithFields(logrus.Fields{
 			"request_id": requestID,
-		}).Warn("UpdateWalletBalance no rows affected")
-		return sentrapay.ErrWalletNotFound
+			"user_id":    userID,
+			"delta":      delta,
+		}).Warn("AdjustWalletBalance would make balance negative")
+		return 0, sentrapay.ErrInsufficientBalance
 	}
 
-	return nil
+	return balance, nil
 }
 
 func (r *walletRepository) CreateTransaction(ctx context.Context, transaction sentrapay.WalletTransaction) error {
@@ -278,11 +280,58 @@ func (r *walletRepository) GetTransactionByReferenceNo(ctx context.Context, refe
 	return r.makeWalletTransaction(transaction), nil
 }
 
-func (r *walletRepository) UpdateTransactionStatus(ctx context.Context, referenceNo string, status string) error {
+// GetUserTransactionByReferenceNo looks up a transaction owned by userID.
+// Transactions of other users are reported as not found.
+func (r *walletRepository) GetUserTransactionByReferenceNo(ctx context.Context, referenceNo string, userID string) (sentrapay.WalletTransaction, error) {
 	requestID := contextPkg.GetRequestID(ctx)
+	var transaction WalletTransactionDB
 
 	argsKV := map[string]interface{}{
 		"reference_no": referenceNo,
+		"user_id":      userID,
+	}
+
+	query, args, err := sqlx.Named(queryGetUserTransactionByReferenceNo, argsKV)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetUserTransactionByReferenceNo named query preparation err")
+		return sentrapay.WalletTransaction{}, err
+	}
+
+	query = r.q.Rebind(query)
+
+	if err := r.q.QueryRowxContext(ctx, query, args...).StructScan(&transaction); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"error":      err.Error(),
+			}).Warn("GetUserTransactionByReferenceNo no rows found")
+			return sentrapay.WalletTransaction{}, sentrapay.ErrTransactionNotFound
+		}
+
+		r.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("GetUserTransactionByReferenceNo execution err")
+
+		return sentrapay.WalletTransaction{}, err
+	}
+
+	return r.makeWalletTransaction(transaction), nil
+}
+
+// UpdateTransactionStatus moves a transaction to status only while it is in
+// one of the statuses in from. When it is not, because another request moved
+// it first, it fails with ErrInvalidTransactionState, so a status change and
+// what follows from it happen once.
+func (r *walletRepository) UpdateTransactionStatus(ctx context.Context, referenceNo string, from []string, status string) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	argsKV := map[string]interface{}{
+		"reference_no": referenceNo,
+		"from":         pq.Array(from),
 		"status":       status,
 		"updated_at":   time.Now(),
 	}
@@ -318,9 +367,12 @@ func (r *walletRepository) UpdateTransactionStatus(ctx context.Context, referenc
 
 	if rowsAffected == 0 {
 		r.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-		}).Warn("UpdateTransactionStatus no rows affected")
-		return sentrapay.ErrTransactionNotFound
+			"request_id":   requestID,
+			"reference_no": referenceNo,
+			"from":         from,
+			"status":       status,
+		}).Warn("UpdateTransactionStatus transaction not in expected status")
+		return sentrapay.ErrInvalidTransactionState
 	}
 
 	return nil
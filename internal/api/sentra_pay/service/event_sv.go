@@ -0,0 +1,40 @@
+package sentrapayService
+
+import (
+	sentrapayRepository "ProjectGolang/internal/api/sentra_pay/repository"
+	"ProjectGolang/internal/events"
+	contextPkg "ProjectGolang/pkg/context"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// recordTopUp writes the events of a top-up moving to status into the
+// transaction that moves it, so the change and its events commit or roll
+// back together. A successful top-up has also credited the wallet.
+func (s *sentraPayService) recordTopUp(ctx context.Context, repo sentrapayRepository.Client, userID string, referenceNo string, status string, amount float64, balance float64) error {
+	topUpEvents := []events.Event{
+		events.TopUpStatusChanged{ReferenceNo: referenceNo, Status: status},
+	}
+	if status == "success" {
+		topUpEvents = append(topUpEvents, events.WalletCredited{
+			ReferenceNo: referenceNo,
+			Amount:      amount,
+			Balance:     balance,
+		})
+	}
+
+	for _, event := range topUpEvents {
+		if err := repo.Outbox.Append(ctx, userID, event); err != nil {
+			s.log.WithFields(logrus.Fields{
+				"request_id":   contextPkg.GetRequestID(ctx),
+				"user_id":      userID,
+				"reference_no": referenceNo,
+				"event_type":   event.EventType(),
+				"error":        err.Error(),
+			}).Error("Failed to record top up event")
+			return err
+		}
+	}
+
+	return nil
+}
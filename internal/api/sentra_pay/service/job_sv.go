@@ -0,0 +1,73 @@
+package sentrapayService
+
+import (
+	"ProjectGolang/internal/jobs"
+	contextPkg "ProjectGolang/pkg/context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+const (
+	// vaExpiry is how long a top-up virtual account accepts payment.
+	vaExpiry = 24 * time.Hour
+	// reconcileDelay gives the DOKU callback time to arrive before the status
+	// is checked.
+	reconcileDelay = 15 * time.Minute
+	// reconcileAttempts covers vaExpiry once the backoff reaches its cap.
+	reconcileAttempts = 64
+)
+
+// reconcileTopUpJob checks a pending top-up with DOKU in case its payment
+// callback never arrived.
+type reconcileTopUpJob struct {
+	ReferenceNo string    `json:"reference_no"`
+	UserID      string    `json:"user_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (reconcileTopUpJob) JobType() string {
+	return "reconcile_top_up"
+}
+
+func (s *sentraPayService) RegisterJobs(queue *jobs.Queue) {
+	jobs.Handle(queue, s.reconcileTopUp)
+}
+
+// scheduleReconciliation queues the status check of a new top-up. The
+// callback credits the wallet without it, so a failure is only logged.
+func (s *sentraPayService) scheduleReconciliation(ctx context.Context, job reconcileTopUpJob) {
+	err := s.jobs.Enqueue(ctx, job, jobs.Delay(reconcileDelay), jobs.MaxAttempts(reconcileAttempts))
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id":   contextPkg.GetRequestID(ctx),
+			"reference_no": job.ReferenceNo,
+			"error":        err.Error(),
+		}).Warn("Failed to schedule top up reconciliation")
+	}
+}
+
+// reconcileTopUp credits a top-up that DOKU reports as paid. While it is
+// still pending it fails, so the queue checks again later, until the
+// virtual account expires.
+func (s *sentraPayService) reconcileTopUp(ctx context.Context, job reconcileTopUpJob) error {
+	status, err := s.CheckTransactionStatus(ctx, job.ReferenceNo, job.UserID)
+	if err != nil {
+		return err
+	}
+	if status != "pending" {
+		return nil
+	}
+
+	if time.Now().After(job.ExpiresAt) {
+		s.log.WithFields(logrus.Fields{
+			"request_id":   contextPkg.GetRequestID(ctx),
+			"reference_no": job.ReferenceNo,
+			"user_id":      job.UserID,
+		}).Info("Top up expired without payment")
+		return nil
+	}
+
+	return fmt.Errorf("top up %s is still pending", job.ReferenceNo)
+}
@@ -0,0 +1,49 @@
+package sentrapayService
+
+import (
+	sentrapay "ProjectGolang/internal/api/sentra_pay"
+	contextPkg "ProjectGolang/pkg/context"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// checkWalletLimits rejects a top-up that is larger than the user's KYC level
+// allows, or that would push the balance over the level's maximum.
+func (s *sentraPayService) checkWalletLimits(ctx context.Context, userID string, balance float64, amount float64) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	level, err := s.kycLevels.GetLevel(ctx, userID)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to get KYC level")
+		return err
+	}
+
+	limits := level.WalletLimits()
+
+	if amount > limits.MaxTopUpAmount {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"kyc_level":  level,
+			"amount":     amount,
+		}).Warn("Top-up amount above KYC limit")
+		return sentrapay.ErrTopUpLimitExceeded
+	}
+
+	if balance+amount > limits.MaxBalance {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userID,
+			"kyc_level":  level,
+			"balance":    balance,
+			"amount":     amount,
+		}).Warn("Top-up would exceed KYC balance limit")
+		return sentrapay.ErrBalanceLimitExceeded
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package sentrapayService
+
+import (
+	"ProjectGolang/internal/api/notification"
+	contextPkg "ProjectGolang/pkg/context"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// notifyTopUp tells the user their top-up changed the wallet balance. The
+// change is already committed, so a notification that cannot be delivered is
+// only logged.
+func (s *sentraPayService) notifyTopUp(ctx context.Context, template notification.Template, userID string, referenceNo string, amount float64, balance float64) {
+	err := s.notifier.Notify(ctx, notification.Message{
+		Template: template,
+		UserID:   userID,
+		Data: map[string]interface{}{
+			"amount":       amount,
+			"balance":      balance,
+			"reference_no": referenceNo,
+		},
+	})
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id":   contextPkg.GetRequestID(ctx),
+			"user_id":      userID,
+			"reference_no": referenceNo,
+			"error":        err.Error(),
+		}).Warn("Failed to send wallet notification")
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -43,7 +42,7 @@ func (s *sentraPayService) CreateTopUpTransaction(ctx context.Context, userID st
 	}
 	defer repo.Rollback()
 
-	_, err = repo.Wallet.GetWallet(ctx, userID)
+	wallet, err := repo.Wallet.GetWallet(ctx, userID)
 	if err != nil {
 		if errors.Is(err, sentrapay.ErrWalletNotFound) {
 			if err = repo.Wallet.CreateWallet(ctx, userID); err != nil {
@@ -64,6 +63,10 @@ func (s *sentraPayService) CreateTopUpTransaction(ctx context.Context, userID st
 		}
 	}
 
+	if err := s.checkWalletLimits(ctx, userID, wallet.Balance, req.Amount); err != nil {
+		return nil, err
+	}
+
 	transactionID, err := s.utils.NewULIDFromTimestamp(time.Now())
 	if err != nil {
 		s.log.WithFields(logrus.Fields{
@@ -101,7 +104,7 @@ func (s *sentraPayService) CreateTopUpTransaction(ctx context.Context, userID st
 		Amount:          req.Amount,
 		TrxId:           refNo,
 		Bank:            req.Bank,
-		ExpiredDuration: 24 * time.Hour,
+		ExpiredDuration: vaExpiry,
 		ReusableStatus:  false,
 	}
 
@@ -145,6 +148,12 @@ func (s *sentraPayService) CreateTopUpTransaction(ctx context.Context, userID st
 		return nil, err
 	}
 
+	s.scheduleReconciliation(ctx, reconcileTopUpJob{
+		ReferenceNo: refNo,
+		UserID:      userID,
+		ExpiresAt:   transaction.CreatedAt.Add(vaExpiry),
+	})
+
 	response := &sentrapay.TopUpResponse{
 		TransactionID:   transactionID,
 		ReferenceNo:     refNo,
@@ -175,7 +184,7 @@ func (s *sentraPayService) ProcessPaymentCallback(ctx context.Context, req sentr
 		return sentrapay.ErrInvalidCallback
 	}
 
-	expectedPartnerID := os.Getenv("DOKU_CLIENT_ID")
+	expectedPartnerID := s.dokuService.ClientID()
 	if expectedPartnerID != "" && xPartnerID != expectedPartnerID {
 		s.log.WithFields(logrus.Fields{
 			"request_id": requestID,
@@ -268,34 +277,20 @@ func (s *sentraPayService) ProcessPaymentCallback(ctx context.Context, req sentr
 		return sentrapay.ErrInvalidAmount
 	}
 
-	if err := repo.Wallet.UpdateTransactionStatus(ctx, req.TrxId, "success"); err != nil {
+	newBalance, err := s.applyTopUpStatus(ctx, repo, transaction, unsettledStatuses, "success", paidAmount)
+	if errors.Is(err, sentrapay.ErrInvalidTransactionState) {
+		// Settled by an admin or the reconciliation job since it was read.
 		s.log.WithFields(logrus.Fields{
 			"request_id":   requestID,
 			"reference_no": req.TrxId,
-			"error":        err.Error(),
-		}).Error("Failed to update transaction status")
-		return err
+		}).Info("Transaction already processed")
+		return nil
 	}
-
-	wallet, err := repo.Wallet.GetWallet(ctx, transaction.UserID)
 	if err != nil {
-		s.log.WithFields(logrus.Fields{
-			"request_id": requestID,
-			"user_id":    transaction.UserID,
-			"error":      err.Error(),
-		}).Error("Failed to get wallet")
 		return err
 	}
 
-	newBalance := wallet.Balance + paidAmount
-	if err := repo.Wallet.UpdateWalletBalance(ctx, transaction.UserID, newBalance); err != nil {
-		s.log.WithFields(logrus.Fields{
-			"request_id":  requestID,
-			"user_id":     transaction.UserID,
-			"old_balance": wallet.Balance,
-			"new_balance": newBalance,
-			"error":       err.Error(),
-		}).Error("Failed to update wallet balance")
+	if err := s.recordTopUp(ctx, repo, transaction.UserID, req.TrxId, "success", paidAmount, newBalance); err != nil {
 		return err
 	}
 
@@ -311,7 +306,6 @@ func (s *sentraPayService) ProcessPaymentCallback(ctx context.Context, req sentr
 		"request_id":   requestID,
 		"reference_no": req.TrxId,
 		"user_id":      transaction.UserID,
-		"old_balance":  wallet.Balance,
 		"new_balance":  newBalance,
 		"amount":       paidAmount,
 	}).Info("Payment processed successfully")
@@ -394,7 +388,7 @@ func (s *sentraPayService) GetTransactionHistory(ctx context.Context, userID str
 	}, nil
 }
 
-func (s *sentraPayService) CheckTransactionStatus(ctx context.Context, referenceNo string) (string, error) {
+func (s *sentraPayService) CheckTransactionStatus(ctx context.Context, referenceNo string, userID string) (string, error) {
 	requestID := contextPkg.GetRequestID(ctx)
 
 	repo, err := s.walletRepository.NewClient(false)
@@ -406,11 +400,12 @@ func (s *sentraPayService) CheckTransactionStatus(ctx context.Context, reference
 		return "", err
 	}
 
-	transaction, err := repo.Wallet.GetTransactionByReferenceNo(ctx, referenceNo)
+	transaction, err := repo.Wallet.GetUserTransactionByReferenceNo(ctx, referenceNo, userID)
 	if err != nil {
 		s.log.WithFields(logrus.Fields{
 			"request_id":   requestID,
 			"reference_no": referenceNo,
+			"user_id":      userID,
 			"error":        err.Error(),
 		}).Error("Failed to get transaction")
 		return "", err
@@ -433,7 +428,7 @@ func (s *sentraPayService) CheckTransactionStatus(ctx context.Context, reference
 		return transaction.Status, nil
 	}
 
-	if isPaid && transaction.Status == "pending" {
+	if isPaid && (transaction.Status == "pending" || transaction.Status == "processing") {
 		repoTx, err := s.walletRepository.NewClient(true)
 		if err != nil {
 			s.log.WithFields(logrus.Fields{
@@ -444,32 +439,18 @@ func (s *sentraPayService) CheckTransactionStatus(ctx context.Context, reference
 		}
 		defer repoTx.Rollback()
 
-		if err := repoTx.Wallet.UpdateTransactionStatus(ctx, referenceNo, "success"); err != nil {
-			s.log.WithFields(logrus.Fields{
-				"request_id":   requestID,
-				"reference_no": referenceNo,
-				"error":        err.Error(),
-			}).Error("Failed to update transaction status")
-			return transaction.Status, nil
+		// The transaction was read outside this database transaction, so the
+		// callback may have credited it since; the conditional status change
+		// makes sure only one of them does.
+		newBalance, err := s.applyTopUpStatus(ctx, repoTx, transaction, unsettledStatuses, "success", transaction.Amount)
+		if errors.Is(err, sentrapay.ErrInvalidTransactionState) {
+			return s.currentStatus(ctx, referenceNo, transaction.Status), nil
 		}
-
-		wallet, err := repoTx.Wallet.GetWallet(ctx, transaction.UserID)
 		if err != nil {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"user_id":    transaction.UserID,
-				"error":      err.Error(),
-			}).Error("Failed to get wallet")
 			return transaction.Status, nil
 		}
 
-		newBalance := wallet.Balance + transaction.Amount
-		if err := repoTx.Wallet.UpdateWalletBalance(ctx, transaction.UserID, newBalance); err != nil {
-			s.log.WithFields(logrus.Fields{
-				"request_id": requestID,
-				"user_id":    transaction.UserID,
-				"error":      err.Error(),
-			}).Error("Failed to update wallet balance")
+		if err := s.recordTopUp(ctx, repoTx, transaction.UserID, referenceNo, "success", transaction.Amount, newBalance); err != nil {
 			return transaction.Status, nil
 		}
 
@@ -487,6 +468,22 @@ func (s *sentraPayService) CheckTransactionStatus(ctx context.Context, reference
 	return transaction.Status, nil
 }
 
+// currentStatus reads the status of a transaction again after another request
+// changed it, falling back to the status read before.
+func (s *sentraPayService) currentStatus(ctx context.Context, referenceNo string, fallback string) string {
+	repo, err := s.walletRepository.NewClient(false)
+	if err != nil {
+		return fallback
+	}
+
+	transaction, err := repo.Wallet.GetTransactionByReferenceNo(ctx, referenceNo)
+	if err != nil {
+		return fallback
+	}
+
+	return transaction.Status
+}
+
 func isValidBank(bank string) bool {
 	validBanks := map[string]bool{
 		doku.BankBCA:      true,
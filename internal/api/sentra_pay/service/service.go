@@ -2,8 +2,11 @@ package sentrapayService
 
 import (
 	authRepository "ProjectGolang/internal/api/auth/repository"
+	"ProjectGolang/internal/api/notification"
 	sentrapay "ProjectGolang/internal/api/sentra_pay"
 	sentrapayRepository "ProjectGolang/internal/api/sentra_pay/repository"
+	"ProjectGolang/internal/entity"
+	"ProjectGolang/internal/jobs"
 	"ProjectGolang/pkg/doku"
 	"ProjectGolang/pkg/utils"
 	"context"
@@ -15,7 +18,16 @@ type ISentraPayService interface {
 	ProcessPaymentCallback(ctx context.Context, req sentrapay.PaymentCallbackRequest, channelID, xExternalID, xTimestamp, xPartnerID string) error
 	GetWalletBalance(ctx context.Context, userID string) (*sentrapay.WalletBalance, error)
 	GetTransactionHistory(ctx context.Context, userID string, page, limit int) (*sentrapay.TransactionHistoryResponse, error)
-	CheckTransactionStatus(ctx context.Context, referenceNo string) (string, error)
+	CheckTransactionStatus(ctx context.Context, referenceNo string, userID string) (string, error)
+	SettleTopUp(ctx context.Context, referenceNo string) (*sentrapay.WalletTransaction, error)
+	RefundTopUp(ctx context.Context, referenceNo string) (*sentrapay.WalletTransaction, error)
+	RegisterJobs(queue *jobs.Queue)
+}
+
+// KYCLevelReader reports how far a user has been verified, which decides the
+// wallet limits that apply to them.
+type KYCLevelReader interface {
+	GetLevel(ctx context.Context, userID string) (entity.KYCLevel, error)
 }
 
 type sentraPayService struct {
@@ -23,6 +35,9 @@ type sentraPayService struct {
 	walletRepository sentrapayRepository.Repository
 	dokuService      doku.IDokuService
 	authRepo         authRepository.Repository
+	kycLevels        KYCLevelReader
+	notifier         notification.Notifier
+	jobs             jobs.Enqueuer
 	utils            utils.IUtils
 }
 
@@ -31,6 +46,9 @@ func NewSentraPayService(
 	wr sentrapayRepository.Repository,
 	ds doku.IDokuService,
 	ar authRepository.Repository,
+	kycLevels KYCLevelReader,
+	notifier notification.Notifier,
+	jobs jobs.Enqueuer,
 	utils utils.IUtils,
 ) ISentraPayService {
 	return &sentraPayService{
@@ -38,6 +56,9 @@ func NewSentraPayService(
 		walletRepository: wr,
 		dokuService:      ds,
 		authRepo:         ar,
+		kycLevels:        kycLevels,
+		notifier:         notifier,
+		jobs:             jobs,
 		utils:            utils,
 	}
 }
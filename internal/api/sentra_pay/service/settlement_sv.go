@@ -0,0 +1,168 @@
+package sentrapayService
+
+import (
+	"ProjectGolang/internal/api/notification"
+	sentrapay "ProjectGolang/internal/api/sentra_pay"
+	sentrapayRepository "ProjectGolang/internal/api/sentra_pay/repository"
+	contextPkg "ProjectGolang/pkg/context"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// SettleTopUp marks a pending top-up as paid and credits the wallet, for cases
+// where the payment gateway callback never arrived.
+func (s *sentraPayService) SettleTopUp(ctx context.Context, referenceNo string) (*sentrapay.WalletTransaction, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.walletRepository.NewClient(true)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create database client")
+		return nil, err
+	}
+	defer repo.Rollback()
+
+	transaction, err := repo.Wallet.GetTransactionByReferenceNo(ctx, referenceNo)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id":   requestID,
+			"reference_no": referenceNo,
+			"error":        err.Error(),
+		}).Error("Failed to get transaction")
+		return nil, err
+	}
+
+	if transaction.Type != "topup" || (transaction.Status != "pending" && transaction.Status != "processing") {
+		s.log.WithFields(logrus.Fields{
+			"request_id":   requestID,
+			"reference_no": referenceNo,
+			"type":         transaction.Type,
+			"status":       transaction.Status,
+		}).Warn("Transaction in invalid state for manual settlement")
+		return nil, sentrapay.ErrInvalidTransactionState
+	}
+
+	newBalance, err := s.applyTopUpStatus(ctx, repo, transaction, unsettledStatuses, "success", transaction.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordTopUp(ctx, repo, transaction.UserID, referenceNo, "success", transaction.Amount, newBalance); err != nil {
+		return nil, err
+	}
+
+	if err := repo.Commit(); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to commit transaction")
+		return nil, err
+	}
+
+	transaction.Status = "success"
+	return &transaction, nil
+}
+
+// RefundTopUp reverses a settled top-up by debiting the credited amount back
+// out of the wallet.
+func (s *sentraPayService) RefundTopUp(ctx context.Context, referenceNo string) (*sentrapay.WalletTransaction, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	repo, err := s.walletRepository.NewClient(true)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to create database client")
+		return nil, err
+	}
+	defer repo.Rollback()
+
+	transaction, err := repo.Wallet.GetTransactionByReferenceNo(ctx, referenceNo)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id":   requestID,
+			"reference_no": referenceNo,
+			"error":        err.Error(),
+		}).Error("Failed to get transaction")
+		return nil, err
+	}
+
+	if transaction.Type != "topup" || transaction.Status != "success" {
+		s.log.WithFields(logrus.Fields{
+			"request_id":   requestID,
+			"reference_no": referenceNo,
+			"type":         transaction.Type,
+			"status":       transaction.Status,
+		}).Warn("Transaction in invalid state for refund")
+		return nil, sentrapay.ErrInvalidTransactionState
+	}
+
+	newBalance, err := s.applyTopUpStatus(ctx, repo, transaction, []string{"success"}, "refunded", -transaction.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordTopUp(ctx, repo, transaction.UserID, referenceNo, "refunded", transaction.Amount, newBalance); err != nil {
+		return nil, err
+	}
+
+	if err := repo.Commit(); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("Failed to commit transaction")
+		return nil, err
+	}
+
+	s.notifyTopUp(ctx, notification.TemplateWalletRefunded, transaction.UserID, referenceNo, transaction.Amount, newBalance)
+
+	transaction.Status = "refunded"
+	return &transaction, nil
+}
+
+// unsettledStatuses are the statuses a top-up can still be paid from.
+var unsettledStatuses = []string{"pending", "processing"}
+
+// applyTopUpStatus moves a top-up from one of the statuses in from to status
+// and changes the wallet balance by delta, returning the new balance. The
+// status change is conditional, so when the payment callback, the
+// reconciliation job or an admin race on the same top-up only the first one
+// changes the balance; the others get ErrInvalidTransactionState.
+func (s *sentraPayService) applyTopUpStatus(ctx context.Context, repo sentrapayRepository.Client, transaction sentrapay.WalletTransaction, from []string, status string, delta float64) (float64, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	if err := repo.Wallet.UpdateTransactionStatus(ctx, transaction.ReferenceNo, from, status); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id":   requestID,
+			"reference_no": transaction.ReferenceNo,
+			"status":       status,
+			"error":        err.Error(),
+		}).Error("Failed to update transaction status")
+		return 0, err
+	}
+
+	newBalance, err := repo.Wallet.AdjustWalletBalance(ctx, transaction.UserID, delta)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    transaction.UserID,
+			"delta":      delta,
+			"error":      err.Error(),
+		}).Error("Failed to update wallet balance")
+		return 0, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"request_id":   requestID,
+		"reference_no": transaction.ReferenceNo,
+		"user_id":      transaction.UserID,
+		"status":       status,
+		"delta":        delta,
+		"new_balance":  newBalance,
+	}).Info("Top-up status changed")
+
+	return newBalance, nil
+}
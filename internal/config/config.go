@@ -0,0 +1,119 @@
+package config
+
+import (
+	"ProjectGolang/database/postgres"
+	detectionService "ProjectGolang/internal/api/detection/service"
+	"ProjectGolang/internal/events"
+	"ProjectGolang/internal/jobs"
+	awsPkg "ProjectGolang/pkg/aws"
+	"ProjectGolang/pkg/captcha"
+	"ProjectGolang/pkg/doku"
+	"ProjectGolang/pkg/facematch"
+	"ProjectGolang/pkg/gemini"
+	"ProjectGolang/pkg/google"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"ProjectGolang/pkg/liveness"
+	"ProjectGolang/pkg/log"
+	"ProjectGolang/pkg/redis"
+	"ProjectGolang/pkg/s3"
+	"ProjectGolang/pkg/sms"
+	"ProjectGolang/pkg/smtp"
+	"ProjectGolang/pkg/tts"
+	websocketPkg "ProjectGolang/pkg/websocket"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"reflect"
+)
+
+// Environment is the profile the server runs under. It picks the defaults
+// that suit the deployment and how strictly the configuration is checked.
+type Environment string
+
+const (
+	EnvLocal      Environment = "local"
+	EnvStaging    Environment = "staging"
+	EnvProduction Environment = "production"
+)
+
+// Config is everything the server reads from its environment. Each field is
+// set from the variable in its env tag; sections belong to the package that
+// uses them.
+type Config struct {
+	Env  Environment `env:"APP_ENV" default:"local"`
+	Port string      `env:"APP_PORT" default:"3000"`
+
+	Log       log.Config
+	Database  postgres.Config
+	Redis     redis.Config
+	JWT       jwtPkg.Config
+	Google    google.Config
+	Captcha   captcha.Config
+	SMTP      smtp.Config
+	SMS       sms.Config
+	AWS       awsPkg.Config
+	Storage   s3.Config
+	FaceMatch facematch.Config
+	Liveness  liveness.Config
+	Gemini    gemini.Config
+	TTS       tts.Config
+	AI        websocketPkg.Config
+	Detection detectionService.Config
+	Doku      doku.Config
+	Jobs      jobs.Config
+	Webhooks  events.WebhookConfig
+}
+
+// profiles override the defaults in the env tags for each environment.
+// Anything set in a file, the environment or a flag still wins.
+var profiles = map[Environment]map[string]string{
+	EnvLocal: {},
+	EnvStaging: {
+		"LOG_LEVEL":  "info",
+		"DB_SSLMODE": "require",
+	},
+	EnvProduction: {
+		"LOG_LEVEL":          "info",
+		"DB_SSLMODE":         "require",
+		"DOKU_IS_PRODUCTION": "true",
+	},
+}
+
+const redacted = "[REDACTED]"
+
+// Fields returns the configuration as log fields keyed by variable name,
+// with every secret that is set replaced by a placeholder.
+func (c *Config) Fields() logrus.Fields {
+	fields := logrus.Fields{}
+	walk(reflect.ValueOf(c).Elem(), func(field reflect.StructField, value reflect.Value) {
+		key := field.Tag.Get("env")
+		if isSecret(field) && !value.IsZero() {
+			fields[key] = redacted
+			return
+		}
+		fields[key] = fmt.Sprint(value.Interface())
+	})
+	return fields
+}
+
+// walk calls fn for every field with an env tag in v, descending into the
+// structs that hold them.
+func walk(v reflect.Value, fn func(field reflect.StructField, value reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if _, ok := field.Tag.Lookup("env"); ok {
+			fn(field, v.Field(i))
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			walk(v.Field(i), fn)
+		}
+	}
+}
+
+func isSecret(field reflect.StructField) bool {
+	return field.Tag.Get("secret") == "true"
+}
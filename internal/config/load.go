@@ -0,0 +1,198 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultFile = ".env"
+	// CommandMigrate is the command that only needs the database section.
+	CommandMigrate = "migrate"
+)
+
+// Load builds the configuration from, in increasing order of precedence, the
+// defaults in the env tags, the profile of the environment, the .env file
+// and the .env.<environment> file, the process environment, and the flags in
+// args. Both files are optional unless -config names one. Every flag is the
+// name of its variable in lower case with dashes, so DB_HOST is -db-host.
+//
+// It returns the arguments left after the flags. All the problems found are
+// reported together. When the arguments start with CommandMigrate only the
+// database section is validated. flag.ErrHelp is returned as is after -h.
+func Load(args []string) (*Config, []string, error) {
+	cfg := &Config{}
+
+	flags := flag.NewFlagSet("app", flag.ContinueOnError)
+	file := flags.String("config", "", "read variables from `file` instead of "+defaultFile)
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, _ reflect.Value) {
+		key := field.Tag.Get("env")
+		flags.String(flagName(key), "", "overrides "+key)
+	})
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	fromFlags := map[string]string{}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			fromFlags[envName(f.Name)] = f.Value.String()
+		}
+	})
+
+	fromFile, err := readFile(*file, defaultFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The environment decides which profile and which second file apply, so
+	// it is looked up on its own first.
+	env := Environment(lookup("APP_ENV", fromFlags, fromFile))
+	if env == "" {
+		env = EnvLocal
+	}
+	fromEnvFile, err := readFile("", defaultFile+"."+string(env))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs []error
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		key := field.Tag.Get("env")
+
+		raw, ok := field.Tag.Lookup("default")
+		if profile, found := profiles[env][key]; found {
+			raw, ok = profile, true
+		}
+		if found := lookup(key, fromFlags, fromEnvFile, fromFile); found != "" {
+			raw, ok = found, true
+		}
+		if !ok {
+			return
+		}
+
+		if err := setValue(value, raw); err != nil {
+			// Values of secrets are left out of the error, which gets logged.
+			if isSecret(field) {
+				errs = append(errs, fmt.Errorf("%s: invalid value", key))
+			} else {
+				errs = append(errs, fmt.Errorf("%s: invalid value %q: %w", key, raw, err))
+			}
+		}
+	})
+
+	cfg.resolve()
+	if rest := flags.Args(); len(rest) > 0 && rest[0] == CommandMigrate {
+		errs = append(errs, cfg.ValidateDatabase())
+	} else {
+		errs = append(errs, cfg.Validate())
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+// resolve fills in the values that depend on others.
+func (c *Config) resolve() {
+	if c.Storage.LocalURL == "" {
+		c.Storage.LocalURL = fmt.Sprintf("http://localhost:%s/files", c.Port)
+	}
+}
+
+// lookup returns the value of key from the flags, then the process
+// environment, then files in the order given. Empty values count as unset,
+// as in the blank entries of .env.example.
+func lookup(key string, fromFlags map[string]string, files ...map[string]string) string {
+	if value := fromFlags[key]; value != "" {
+		return value
+	}
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	for _, file := range files {
+		if value := file[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// readFile reads the variables in name, which must exist, or failing a name
+// in fallback if it exists.
+func readFile(name string, fallback string) (map[string]string, error) {
+	if name == "" {
+		if _, err := os.Stat(fallback); errors.Is(err, os.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		name = fallback
+	}
+
+	values, err := godotenv.Read(name)
+	if err != nil {
+		return nil, fmt.Errorf("read config file %s: %w", name, err)
+	}
+	return values, nil
+}
+
+func setValue(value reflect.Value, raw string) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+func envName(flag string) string {
+	return strings.ReplaceAll(strings.ToUpper(flag), "-", "_")
+}
@@ -2,6 +2,12 @@ package config
 
 import (
 	"ProjectGolang/database/postgres"
+	accountHandler "ProjectGolang/internal/api/account/handler"
+	accountRepository "ProjectGolang/internal/api/account/repository"
+	accountService "ProjectGolang/internal/api/account/service"
+	adminHandler "ProjectGolang/internal/api/admin/handler"
+	adminRepository "ProjectGolang/internal/api/admin/repository"
+	adminService "ProjectGolang/internal/api/admin/service"
 	authHandler "ProjectGolang/internal/api/auth/handler"
 	authRepository "ProjectGolang/internal/api/auth/repository"
 	authService "ProjectGolang/internal/api/auth/service"
@@ -10,52 +16,83 @@ import (
 	budgetService "ProjectGolang/internal/api/budget_manager/service"
 	detectionHandler "ProjectGolang/internal/api/detection/handler"
 	detectionService "ProjectGolang/internal/api/detection/service"
+	kycHandler "ProjectGolang/internal/api/kyc/handler"
+	kycRepository "ProjectGolang/internal/api/kyc/repository"
+	kycService "ProjectGolang/internal/api/kyc/service"
+	notificationHandler "ProjectGolang/internal/api/notification/handler"
+	notificationRepository "ProjectGolang/internal/api/notification/repository"
+	notificationService "ProjectGolang/internal/api/notification/service"
+	realtimeHandler "ProjectGolang/internal/api/realtime/handler"
+	realtimeRepository "ProjectGolang/internal/api/realtime/repository"
+	realtimeService "ProjectGolang/internal/api/realtime/service"
 	sentrapayHandler "ProjectGolang/internal/api/sentra_pay/handler"
 	sentrapayRepository "ProjectGolang/internal/api/sentra_pay/repository"
 	sentrapayService "ProjectGolang/internal/api/sentra_pay/service"
+	"ProjectGolang/internal/events"
+	"ProjectGolang/internal/jobs"
 	"ProjectGolang/internal/middleware"
+	awsPkg "ProjectGolang/pkg/aws"
 	"ProjectGolang/pkg/bcrypt"
+	"ProjectGolang/pkg/captcha"
 	"ProjectGolang/pkg/doku"
+	"ProjectGolang/pkg/facematch"
 	"ProjectGolang/pkg/gemini"
 	"ProjectGolang/pkg/google"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"ProjectGolang/pkg/liveness"
 	"ProjectGolang/pkg/redis"
 	"ProjectGolang/pkg/s3"
+	"ProjectGolang/pkg/sms"
 	"ProjectGolang/pkg/smtp"
+	"ProjectGolang/pkg/tts"
 	"ProjectGolang/pkg/utils"
 	websocketPkg "ProjectGolang/pkg/websocket"
 	"ProjectGolang/pkg/whatsapp"
+	"context"
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
 	"github.com/sirupsen/logrus"
-	"os"
 )
 
 type ServerOption func(*Server) error
 
 type Server struct {
+	config         *Config
 	engine         *fiber.App
 	db             *sqlx.DB
 	log            *logrus.Logger
 	middleware     middleware.Middleware
+	jwt            jwtPkg.IJWT
+	sessions       jwtPkg.ISessions
 	validator      *validator.Validate
 	utils          utils.IUtils
 	bcryptUtils    bcrypt.IBcrypt
 	handlers       []handler
+	workers        []worker
 	googleProvider google.ItfGoogle
 	redisServer    redis.IRedis
 	smtpMailer     smtp.ItfSmtp
+	smsSender      sms.ISender
 	faceWebsocket  websocketPkg.IWebsocket
 	whatsappClient whatsapp.IWhatsappSender
 	geminiClient   gemini.IGemini
 	s3Client       s3.ItfS3
+	captcha        captcha.ICaptcha
+	faceMatcher    facematch.IFaceMatcher
+	synthesizer    tts.ISynthesizer
 }
 
 type handler interface {
 	Start(srv fiber.Router)
 }
 
+// worker is a background loop that runs until its context is cancelled.
+type worker interface {
+	Run(ctx context.Context)
+}
+
 func NewServer(options ...ServerOption) (*Server, error) {
 	server := &Server{}
 
@@ -65,6 +102,9 @@ func NewServer(options ...ServerOption) (*Server, error) {
 		}
 	}
 
+	if server.config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
 	if server.engine == nil {
 		return nil, fmt.Errorf("fiber app is required")
 	}
@@ -75,6 +115,15 @@ func NewServer(options ...ServerOption) (*Server, error) {
 	return server, nil
 }
 
+// WithConfig sets the configuration the handlers and workers are built
+// with. The other options take the sections they need as arguments.
+func WithConfig(cfg *Config) ServerOption {
+	return func(s *Server) error {
+		s.config = cfg
+		return nil
+	}
+}
+
 func WithFiber(fiberApp *fiber.App) ServerOption {
 	return func(s *Server) error {
 		s.engine = fiberApp
@@ -96,9 +145,9 @@ func WithValidator(validator *validator.Validate) ServerOption {
 	}
 }
 
-func WithDatabase() ServerOption {
+func WithDatabase(cfg postgres.Config) ServerOption {
 	return func(s *Server) error {
-		db, err := postgres.New()
+		db, err := postgres.New(cfg)
 		if err != nil {
 			if s.log != nil {
 				s.log.Errorf("Failed to connect to database: %v", err)
@@ -131,6 +180,22 @@ func WithSMTPMailer(smtpMailer smtp.ItfSmtp) ServerOption {
 	}
 }
 
+// WithSMSSender sends SMS through the gateway in cfg. Without one the server
+// still runs and notifications fall back to other channels.
+func WithSMSSender(cfg sms.Config) ServerOption {
+	return func(s *Server) error {
+		sender, err := sms.New(cfg)
+		if err != nil {
+			if s.log != nil {
+				s.log.Warnf("SMS notifications disabled: %v", err)
+			}
+			return nil
+		}
+		s.smsSender = sender
+		return nil
+	}
+}
+
 func WithWebSocket(webSocket websocketPkg.IWebsocket) ServerOption {
 	return func(s *Server) error {
 		s.faceWebsocket = webSocket
@@ -138,19 +203,36 @@ func WithWebSocket(webSocket websocketPkg.IWebsocket) ServerOption {
 	}
 }
 
+func WithJWT(jwt jwtPkg.IJWT) ServerOption {
+	return func(s *Server) error {
+		s.jwt = jwt
+		return nil
+	}
+}
+
+func WithSessions(sessions jwtPkg.ISessions) ServerOption {
+	return func(s *Server) error {
+		s.sessions = sessions
+		return nil
+	}
+}
+
 func WithMiddleware() ServerOption {
 	return func(s *Server) error {
 		if s.log == nil {
 			return fmt.Errorf("logger must be initialized before middleware")
 		}
-		s.middleware = middleware.New(s.log)
+		if s.jwt == nil || s.sessions == nil {
+			return fmt.Errorf("JWT and sessions must be initialized before middleware")
+		}
+		s.middleware = middleware.New(s.log, s.jwt, s.sessions)
 		return nil
 	}
 }
 
-func WithS3Client() ServerOption {
+func WithS3Client(cfg s3.Config, credentials awsPkg.Config) ServerOption {
 	return func(s *Server) error {
-		client, err := s3.New()
+		client, err := s3.New(cfg, credentials)
 		if err != nil {
 			if s.log != nil {
 				s.log.Errorf("Failed to initialize S3 client: %v", err)
@@ -162,9 +244,10 @@ func WithS3Client() ServerOption {
 	}
 }
 
-func WithWhatsappClient() ServerOption {
+// WithWhatsappClient keeps the WhatsApp session in the database in cfg.
+func WithWhatsappClient(cfg postgres.Config) ServerOption {
 	return func(s *Server) error {
-		client, err := whatsapp.New()
+		client, err := whatsapp.New(cfg)
 		if err != nil {
 			if s.log != nil {
 				s.log.Errorf("Failed to initialize WhatsApp client: %v", err)
@@ -176,20 +259,93 @@ func WithWhatsappClient() ServerOption {
 	}
 }
 
-func WithGeminiClient() ServerOption {
+// WithGeminiClient uses Gemini, or replays recorded answers when the
+// provider is fake, adding any fixtures in cfg.FixturesDir. With
+// cfg.RecordDir set, the answers of the real client are recorded there as
+// fixtures.
+func WithGeminiClient(cfg gemini.Config) ServerOption {
 	return func(s *Server) error {
-		client, err := gemini.NewGeminiClient()
+		if cfg.Provider == gemini.ProviderFake {
+			fake := gemini.NewFake()
+			if cfg.FixturesDir != "" {
+				if err := fake.LoadFixtures(cfg.FixturesDir); err != nil {
+					return fmt.Errorf("failed to load Gemini fixtures: %w", err)
+				}
+			}
+			s.geminiClient = fake
+			return nil
+		}
+
+		client, err := gemini.NewGeminiClient(cfg)
 		if err != nil {
 			if s.log != nil {
 				s.log.Errorf("Failed to create Gemini client: %v", err)
 			}
 			return fmt.Errorf("failed to create Gemini client: %w", err)
 		}
+
+		if cfg.RecordDir != "" {
+			client, err = gemini.NewRecorder(client, cfg.RecordDir)
+			if err != nil {
+				return fmt.Errorf("failed to record Gemini fixtures: %w", err)
+			}
+		}
+
 		s.geminiClient = client
 		return nil
 	}
 }
 
+func WithCaptcha(captchaVerifier captcha.ICaptcha) ServerOption {
+	return func(s *Server) error {
+		s.captcha = captchaVerifier
+		return nil
+	}
+}
+
+// WithFaceMatcher uses AWS Rekognition to compare KYC selfies with KTP
+// photos, or the deterministic fake when the provider is fake.
+func WithFaceMatcher(cfg facematch.Config, credentials awsPkg.Config) ServerOption {
+	return func(s *Server) error {
+		if cfg.Provider == facematch.ProviderFake {
+			s.faceMatcher = facematch.NewFake(facematch.DefaultThreshold)
+			return nil
+		}
+
+		matcher, err := facematch.New(cfg, credentials)
+		if err != nil {
+			if s.log != nil {
+				s.log.Errorf("Failed to initialize face matcher: %v", err)
+			}
+			return fmt.Errorf("failed to create face matcher: %w", err)
+		}
+		s.faceMatcher = matcher
+		return nil
+	}
+}
+
+// WithSpeechSynthesizer uses Google Cloud Text-to-Speech for spoken
+// instructions, or the offline fake when the provider is fake. Without a
+// provider the server still runs and sends spoken instructions as text only.
+func WithSpeechSynthesizer(cfg tts.Config) ServerOption {
+	return func(s *Server) error {
+		if cfg.Provider == tts.ProviderFake {
+			s.synthesizer = tts.NewFake()
+			return nil
+		}
+
+		synthesizer, err := tts.NewGoogle(cfg)
+		if err != nil {
+			if s.log != nil {
+				s.log.Warnf("Text-to-speech disabled: %v", err)
+			}
+			return nil
+		}
+		s.synthesizer = synthesizer
+		return nil
+	}
+}
+
 func WithUtils() ServerOption {
 	return func(s *Server) error {
 		s.utils = utils.New()
@@ -205,29 +361,107 @@ func WithBcryptUtils() ServerOption {
 }
 
 func (s *Server) RegisterHandler() {
+	livenessTokens := liveness.NewTokens(s.config.Liveness, s.redisServer)
+
+	var speaker tts.ISpeaker
+	if s.synthesizer != nil {
+		speaker = tts.NewSpeaker(s.synthesizer, s.redisServer)
+	}
+
+	// Side effects that must not hold up or fail a request run from the job
+	// queue.
+	jobQueue := jobs.New(s.db, s.log, s.utils, s.config.Jobs)
+	jobs.Handle(jobQueue, func(ctx context.Context, job jobs.DeleteFile) error {
+		return s.s3Client.DeleteFile(job.FileName)
+	})
+
+	// Domain events written to the outbox reach subscribers through the queue.
+	dispatcher := events.NewDispatcher(s.db, s.log, jobQueue)
+	if webhooks := dispatcher.SubscribeWebhooks(s.config.Webhooks); webhooks > 0 {
+		s.log.Infof("Sending domain events to %d webhooks", webhooks)
+	}
+
+	// Notification Domain
+	notificationRepo := notificationRepository.New(s.db, s.log)
+	notificationServices := notificationService.NewNotificationService(s.log, notificationRepo, s.redisServer, s.utils, s.notificationSenders()...)
+	notificationServices.RegisterJobs(jobQueue)
+	notificationServices.Subscribe(dispatcher)
+	notifier := notificationService.NewQueuedNotifier(jobQueue)
+	notificationHandlers := notificationHandler.New(s.log, s.validator, s.middleware, notificationServices)
+
+	// Realtime Domain
+	realtimeRepo := realtimeRepository.New(s.db, s.log)
+	realtimeServices := realtimeService.NewRealtimeService(s.log, realtimeRepo, s.redisServer)
+	realtimeServices.Subscribe(dispatcher)
+	realtimeHandlers := realtimeHandler.New(s.log, s.middleware, realtimeServices)
+
 	// Auth Domain
 	authRepo := authRepository.New(s.db, s.log)
-	authServices := authService.New(s.log, authRepo, s.googleProvider, s.smtpMailer, s.redisServer, s.whatsappClient, s.s3Client, s.smtpMailer, s.bcryptUtils, s.utils)
+	authServices := authService.New(s.log, authRepo, s.googleProvider, s.redisServer, notifier, s.s3Client, jobQueue, s.jwt, s.bcryptUtils, s.utils, s.captcha, livenessTokens)
 	authHandlers := authHandler.New(s.log, authServices, s.validator, s.middleware, s.googleProvider, s.redisServer, s.s3Client)
 
 	// Detection
-	detectionServices := detectionService.NewDetectionService(s.faceWebsocket, s.geminiClient)
+	extractor := gemini.NewExtractor(s.geminiClient, s.log, s.config.Gemini)
+	detectionServices := detectionService.NewDetectionService(s.log, s.config.Detection, s.faceWebsocket, extractor, s.redisServer, authServices, livenessTokens, speaker)
 	detectionHandlers := detectionHandler.New(s.log, s.validator, s.middleware, detectionServices, s.utils)
 
 	// Budget Manager
 	budgetRepo := budgetRepository.New(s.db, s.log)
-	budgetServices := budgetService.NewBudgetService(s.log, budgetRepo, s.s3Client, s.utils)
+	budgetServices := budgetService.NewBudgetService(s.log, budgetRepo, s.s3Client, jobQueue, notifier, s.utils)
 	budgetHandlers := budgetHandler.New(s.log, s.validator, s.middleware, budgetServices)
 
+	// KYC Domain
+	kycRepo := kycRepository.New(s.db, s.log)
+	kycServices := kycService.NewKYCService(s.log, kycRepo, authServices, s.faceMatcher, livenessTokens, s.s3Client, jobQueue)
+	kycHandlers := kycHandler.New(s.log, s.validator, s.middleware, kycServices)
+
 	// Payment Domain
-	dokuClient := doku.NewDokuService(s.log)
+	dokuClient := doku.NewDokuService(s.log, s.config.Doku)
 	dokuClient.Init()
 	dokuRepo := sentrapayRepository.New(s.db, s.log)
-	dokuServices := sentrapayService.NewSentraPayService(s.log, dokuRepo, dokuClient, authRepo, s.utils)
+	dokuServices := sentrapayService.NewSentraPayService(s.log, dokuRepo, dokuClient, authRepo, kycServices, notifier, jobQueue, s.utils)
+	dokuServices.RegisterJobs(jobQueue)
 	dokuHandlers := sentrapayHandler.New(s.log, s.validator, s.middleware, dokuServices)
 
+	// Admin Domain
+	adminRepo := adminRepository.New(s.db, s.log)
+	adminServices := adminService.NewAdminService(s.log, adminRepo, authServices, dokuServices, kycServices, s.sessions, s.utils)
+	adminHandlers := adminHandler.New(s.log, s.validator, s.middleware, adminServices)
+
+	// Account Domain
+	accountRepo := accountRepository.New(s.db, s.log)
+	accountServices := accountService.NewAccountService(s.log, accountRepo, authServices, s.redisServer, notifier, s.s3Client, jobQueue, s.bcryptUtils, s.utils)
+	accountHandlers := accountHandler.New(s.log, s.validator, s.middleware, accountServices)
+
 	s.setupHealthCheck()
-	s.handlers = append(s.handlers, authHandlers, detectionHandlers, budgetHandlers, dokuHandlers)
+	s.setupFileServer()
+
+	s.handlers = append(s.handlers, authHandlers, detectionHandlers, budgetHandlers, kycHandlers, dokuHandlers, adminHandlers, accountHandlers, notificationHandlers, realtimeHandlers)
+	s.workers = append(s.workers, accountServices, jobQueue, dispatcher, realtimeServices)
+}
+
+// notificationSenders returns a sender for every external channel the server
+// has a client for.
+func (s *Server) notificationSenders() []notificationService.Sender {
+	var senders []notificationService.Sender
+	if s.whatsappClient != nil {
+		senders = append(senders, notificationService.NewWhatsAppSender(s.whatsappClient))
+	}
+	if s.smtpMailer != nil {
+		senders = append(senders, notificationService.NewEmailSender(s.smtpMailer))
+	}
+	if s.smsSender != nil {
+		senders = append(senders, notificationService.NewSMSSender(s.smsSender))
+	}
+	return senders
+}
+
+// StartWorkers launches the background workers registered by RegisterHandler.
+// They stop when ctx is cancelled.
+func (s *Server) StartWorkers(ctx context.Context) {
+	for _, w := range s.workers {
+		go w.Run(ctx)
+	}
 }
 
 func (s *Server) Run() error {
@@ -238,12 +472,7 @@ func (s *Server) Run() error {
 		h.Start(router)
 	}
 
-	port := os.Getenv("APP_PORT")
-	if port == "" {
-		port = "3000"
-	}
-
-	if err := s.engine.Listen(fmt.Sprintf(":%s", port)); err != nil {
+	if err := s.engine.Listen(fmt.Sprintf(":%s", s.config.Port)); err != nil {
 		if s.whatsappClient != nil {
 			s.whatsappClient.Disconnect()
 		}
@@ -253,10 +482,22 @@ func (s *Server) Run() error {
 	return nil
 }
 
+// setupFileServer serves the uploads of storage backends that keep files
+// themselves, like the local driver.
+func (s *Server) setupFileServer() {
+	if server, ok := s.s3Client.(s3.FileServer); ok {
+		s.engine.Get(s3.LocalRoute, server.Serve)
+	}
+}
+
 func (s *Server) setupHealthCheck() {
 	s.engine.Get("/", func(ctx *fiber.Ctx) error {
-		return ctx.JSON(fiber.Map{
+		res := fiber.Map{
 			"message": "Server is Healthy!",
-		})
+		}
+		if s.faceWebsocket != nil {
+			res["ai_backends"] = s.faceWebsocket.Stats()
+		}
+		return ctx.JSON(res)
 	})
 }
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"ProjectGolang/pkg/facematch"
+	"ProjectGolang/pkg/gemini"
+	"ProjectGolang/pkg/s3"
+	"ProjectGolang/pkg/tts"
+	websocketPkg "ProjectGolang/pkg/websocket"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/url"
+	"strconv"
+)
+
+// minSecretLength is the shortest signing secret accepted in production.
+const minSecretLength = 32
+
+// Validate checks the configuration as a whole and returns every problem it
+// finds, so they can all be fixed in one go.
+func (c *Config) Validate() error {
+	v := &validation{missing: map[string]bool{}}
+
+	c.validateDatabase(v)
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		v.addf("APP_PORT: invalid port %q", c.Port)
+	}
+	v.required("REDIS_ADDRESS", c.Redis.Address)
+	v.required("JWT_ACCESS_TOKEN_SECRET", c.JWT.AccessTokenSecret)
+
+	switch c.Storage.Driver {
+	case s3.DriverS3:
+		v.required("AWS_REGION", c.AWS.Region)
+		v.required("AWS_BUCKET_NAME", c.Storage.Bucket)
+	case s3.DriverS3Compatible:
+		v.required("S3_ENDPOINT", c.Storage.Endpoint)
+		v.required("AWS_BUCKET_NAME", c.Storage.Bucket)
+	case s3.DriverLocal:
+		v.required("LOCAL_STORAGE_SECRET", c.Storage.LocalSecret)
+	default:
+		v.addf("STORAGE_DRIVER: unknown driver %q", c.Storage.Driver)
+	}
+
+	switch c.FaceMatch.Provider {
+	case facematch.ProviderRekognition:
+		v.required("AWS_REGION", c.AWS.Region)
+	case facematch.ProviderFake:
+	default:
+		v.addf("FACE_MATCH_PROVIDER: unknown provider %q", c.FaceMatch.Provider)
+	}
+	if c.FaceMatch.Threshold <= 0 || c.FaceMatch.Threshold > 100 {
+		v.addf("FACE_MATCH_THRESHOLD: must be above 0 and at most 100")
+	}
+
+	switch c.Gemini.Provider {
+	case gemini.ProviderGemini:
+		v.required("GEMINI_API_KEY", c.Gemini.APIKey)
+	case gemini.ProviderFake:
+	default:
+		v.addf("GEMINI_PROVIDER: unknown provider %q", c.Gemini.Provider)
+	}
+	v.positive("GEMINI_TIMEOUT_SECONDS", int64(c.Gemini.TimeoutSeconds))
+	v.notNegative("GEMINI_RETRIES", int64(c.Gemini.Retries))
+	v.notNegative("GEMINI_REPROMPTS", int64(c.Gemini.Reprompts))
+
+	switch c.TTS.Provider {
+	case tts.ProviderGoogle, tts.ProviderFake:
+	default:
+		v.addf("TTS_PROVIDER: unknown provider %q", c.TTS.Provider)
+	}
+
+	switch c.AI.Provider {
+	case "", websocketPkg.ProviderFake:
+	default:
+		v.addf("AI_DETECTION_PROVIDER: unknown provider %q", c.AI.Provider)
+	}
+	v.positive("AI_FACE_DETECTION_POOL_SIZE", int64(c.AI.FacePoolSize))
+	v.positive("AI_KTP_DETECTION_POOL_SIZE", int64(c.AI.KTPPoolSize))
+	v.positive("AI_QRIS_DETECTION_POOL_SIZE", int64(c.AI.QRISPoolSize))
+
+	v.positive("FRAME_QUOTA_PER_MINUTE", c.Detection.FrameQuotaPerMinute)
+	v.positive("GEMINI_QUOTA_PER_DAY", c.Detection.GeminiQuotaPerDay)
+	v.positive("JOB_WORKERS", int64(c.Jobs.Workers))
+	v.positive("JOB_DEAD_LETTER_RETENTION_DAYS", int64(c.Jobs.DeadLetterRetentionDays))
+
+	for _, raw := range c.Webhooks.URLs {
+		if parsed, err := url.Parse(raw); err != nil || parsed.Host == "" ||
+			(parsed.Scheme != "http" && parsed.Scheme != "https") {
+			v.addf("EVENT_WEBHOOK_URLS: invalid URL %q", raw)
+		}
+	}
+	if len(c.Webhooks.URLs) > 0 {
+		v.required("EVENT_WEBHOOK_SECRET", c.Webhooks.Secret)
+	}
+
+	if c.Env == EnvProduction {
+		c.validateProduction(v)
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// ValidateDatabase checks only what `app migrate` needs: the environment, the
+// logger and the database.
+func (c *Config) ValidateDatabase() error {
+	v := &validation{missing: map[string]bool{}}
+	c.validateDatabase(v)
+	return errors.Join(v.errs...)
+}
+
+func (c *Config) validateDatabase(v *validation) {
+	switch c.Env {
+	case EnvLocal, EnvStaging, EnvProduction:
+	default:
+		v.addf("APP_ENV: unknown environment %q", c.Env)
+	}
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		v.addf("LOG_LEVEL: unknown level %q", c.Log.Level)
+	}
+
+	v.required("DB_HOST", c.Database.Host)
+	v.required("DB_PORT", c.Database.Port)
+	v.required("DB_USER", c.Database.User)
+	v.required("DB_NAME", c.Database.Name)
+	if c.Env == EnvProduction && c.Database.SSLMode == "disable" {
+		v.addf("DB_SSLMODE: disable is not allowed in production")
+	}
+}
+
+// validateProduction refuses the settings that are only fit for development.
+func (c *Config) validateProduction(v *validation) {
+	if c.FaceMatch.Provider == facematch.ProviderFake {
+		v.addf("FACE_MATCH_PROVIDER: fake is not allowed in production")
+	}
+	if c.Gemini.Provider == gemini.ProviderFake {
+		v.addf("GEMINI_PROVIDER: fake is not allowed in production")
+	}
+	if c.TTS.Provider == tts.ProviderFake {
+		v.addf("TTS_PROVIDER: fake is not allowed in production")
+	}
+	if c.AI.Provider == websocketPkg.ProviderFake {
+		v.addf("AI_DETECTION_PROVIDER: fake is not allowed in production")
+	}
+	if c.Storage.Driver == s3.DriverLocal {
+		v.addf("STORAGE_DRIVER: local is not allowed in production")
+	}
+	v.minLength("JWT_ACCESS_TOKEN_SECRET", c.JWT.AccessTokenSecret)
+	v.minLength("LIVENESS_TOKEN_SECRET", c.Liveness.TokenSecret)
+	v.required("DOKU_CLIENT_ID", c.Doku.ClientID)
+	v.required("DOKU_SECRET_KEY", c.Doku.SecretKey)
+}
+
+type validation struct {
+	errs    []error
+	missing map[string]bool
+}
+
+func (v *validation) addf(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+// required reports each missing key once, however many settings need it.
+func (v *validation) required(key string, value string) {
+	if value == "" && !v.missing[key] {
+		v.missing[key] = true
+		v.addf("%s: required", key)
+	}
+}
+
+// minLength only says how long a secret must be; its value never ends up in
+// the error.
+func (v *validation) minLength(key string, secret string) {
+	if len(secret) < minSecretLength && !v.missing[key] {
+		v.addf("%s: must be at least %d bytes", key, minSecretLength)
+	}
+}
+
+func (v *validation) positive(key string, value int64) {
+	if value <= 0 {
+		v.addf("%s: must be positive, got %d", key, value)
+	}
+}
+
+func (v *validation) notNegative(key string, value int64) {
+	if value < 0 {
+		v.addf("%s: must not be negative, got %d", key, value)
+	}
+}
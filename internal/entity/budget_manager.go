@@ -75,16 +75,17 @@ func IsValidCategory(transactionType, category string) bool {
 }
 
 type BudgetTransaction struct {
-	ID          string    `json:"id"`
-	UserID      string    `json:"user_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Nominal     float64   `json:"nominal"`
-	Type        string    `json:"type"`
-	Category    string    `json:"category"`
-	AudioLink   string    `json:"audio_link"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              string    `json:"id"`
+	UserID          string    `json:"user_id"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	Nominal         float64   `json:"nominal"`
+	Type            string    `json:"type"`
+	Category        string    `json:"category"`
+	AudioLink       string    `json:"audio_link"`
+	AudioDurationMs int64     `json:"audio_duration_ms"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 func (t *BudgetTransaction) Validate() error {
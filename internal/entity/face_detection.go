@@ -12,4 +12,7 @@ type DetectionResult struct {
 	FaceSize     *float64           `json:"face_size,omitempty"`
 	FrameCenter  Position           `json:"frame_center"`
 	Deviations   map[string]float64 `json:"deviations,omitempty"`
+	EyeOpenness  *float64           `json:"eye_openness,omitempty"`
+	HeadYaw      *float64           `json:"head_yaw,omitempty"`
+	SmileScore   *float64           `json:"smile_score,omitempty"`
 }
@@ -0,0 +1,29 @@
+package entity
+
+// KYCLevel is how far a user's identity has been verified. It decides the
+// wallet limits that apply to the account.
+type KYCLevel int
+
+const (
+	KYCLevelUnverified KYCLevel = 0
+	KYCLevelVerified   KYCLevel = 1
+)
+
+// WalletLimits follow Bank Indonesia's caps for unregistered and registered
+// electronic money accounts.
+type WalletLimits struct {
+	MaxBalance     float64 `json:"max_balance"`
+	MaxTopUpAmount float64 `json:"max_top_up_amount"`
+}
+
+var walletLimitsByLevel = map[KYCLevel]WalletLimits{
+	KYCLevelUnverified: {MaxBalance: 2_000_000, MaxTopUpAmount: 1_000_000},
+	KYCLevelVerified:   {MaxBalance: 20_000_000, MaxTopUpAmount: 10_000_000},
+}
+
+func (l KYCLevel) WalletLimits() WalletLimits {
+	if limits, ok := walletLimitsByLevel[l]; ok {
+		return limits
+	}
+	return walletLimitsByLevel[KYCLevelUnverified]
+}
@@ -26,15 +26,58 @@ type User struct {
 	HashTouchID                  string    `db:"hash_touch_id"`
 	ProfilePhotoURL              string    `db:"profile_photo_url"`
 	FacePhotoURL                 string    `db:"face_photo_url"`
+	GoogleSubject                string    `db:"google_subject"`
+	Role                         Role      `db:"role"`
+	LockedAt                     time.Time `db:"locked_at"`
+	LockedReason                 string    `db:"locked_reason"`
 	IsVerified                   bool      `db:"is_verified"`
 	CreatedAt                    time.Time `db:"created_at"`
 	UpdatedAt                    time.Time `db:"updated_at"`
 }
 
+// CardValidForLife is stored as card_valid_until for KTPs marked
+// "SEUMUR HIDUP", which never expire.
+var CardValidForLife = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
 type UserLoginData struct {
 	ID       string
 	Username string
 	Email    string
+	Role     Role
+}
+
+type Role string
+
+const (
+	RoleUser    Role = "user"
+	RoleSupport Role = "support"
+	RoleAdmin   Role = "admin"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleUser, RoleSupport, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// CanAccess reports whether the logged-in user may act on a resource owned by
+// ownerID. Owners always can; anyone else needs one of the given roles.
+func (u UserLoginData) CanAccess(ownerID string, roles ...Role) bool {
+	if u.ID != "" && u.ID == ownerID {
+		return true
+	}
+	for _, role := range roles {
+		if u.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (u User) IsLocked() bool {
+	return !u.LockedAt.IsZero()
 }
 
 type PositionStatus string
@@ -0,0 +1,206 @@
+package events
+
+import (
+	"ProjectGolang/internal/jobs"
+	contextPkg "ProjectGolang/pkg/context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+const (
+	relayInterval  = time.Second
+	relayBatchSize = 100
+	// deliverAttempts gives a subscriber, and webhooks in particular, a few
+	// hours of backoff to recover before the delivery is dead-lettered.
+	deliverAttempts = 12
+)
+
+// Dispatcher relays events from the outbox to subscribers. Every subscriber
+// gets its own job on the queue, queued in the transaction that marks the
+// event dispatched, so each one is retried on its own and receives every
+// event at least once.
+type Dispatcher struct {
+	db          *sqlx.DB
+	log         *logrus.Logger
+	queue       *jobs.Queue
+	subscribers map[string]subscriber
+}
+
+type subscriber struct {
+	// eventType is empty for subscribers to every event.
+	eventType string
+	handle    func(ctx context.Context, record Record) error
+}
+
+type EventDB struct {
+	ID         sql.NullString `db:"id"`
+	Sequence   sql.NullInt64  `db:"sequence"`
+	Type       sql.NullString `db:"type"`
+	UserID     sql.NullString `db:"user_id"`
+	Payload    []byte         `db:"payload"`
+	RequestID  sql.NullString `db:"request_id"`
+	OccurredAt sql.NullTime   `db:"occurred_at"`
+}
+
+type deliverEvent struct {
+	Subscriber string `json:"subscriber"`
+	Event      Record `json:"event"`
+}
+
+func (deliverEvent) JobType() string {
+	return "deliver_event"
+}
+
+func NewDispatcher(db *sqlx.DB, log *logrus.Logger, queue *jobs.Queue) *Dispatcher {
+	d := &Dispatcher{
+		db:          db,
+		log:         log,
+		queue:       queue,
+		subscribers: make(map[string]subscriber),
+	}
+	jobs.Handle(queue, d.deliver)
+	return d
+}
+
+// Subscribe calls handle for every event of type T. The name identifies the
+// subscriber in queued deliveries and must stay stable across deploys.
+// Delivery is at least once, so handle must tolerate seeing an event again.
+func Subscribe[T Event](d *Dispatcher, name string, handle func(ctx context.Context, userID string, event T) error) {
+	var zero T
+	d.subscribers[name] = subscriber{
+		eventType: zero.EventType(),
+		handle: func(ctx context.Context, record Record) error {
+			var event T
+			if err := json.Unmarshal(record.Payload, &event); err != nil {
+				return jobs.Permanent(fmt.Errorf("decoding %s event: %w", record.Type, err))
+			}
+			return handle(ctx, record.UserID, event)
+		},
+	}
+}
+
+// SubscribeAll calls handle for every event, whatever its type.
+func (d *Dispatcher) SubscribeAll(name string, handle func(ctx context.Context, record Record) error) {
+	d.subscribers[name] = subscriber{handle: handle}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, job deliverEvent) error {
+	sub, ok := d.subscribers[job.Subscriber]
+	if !ok {
+		return jobs.Permanent(fmt.Errorf("no subscriber named %s", job.Subscriber))
+	}
+	return sub.handle(ctx, job.Event)
+}
+
+// Run relays new events until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		relayed, err := d.relay(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.log.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("Failed to relay outbox events")
+		}
+		if err == nil && relayed == relayBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(relayInterval):
+		}
+	}
+}
+
+// relay numbers one batch of events and queues their deliveries, reporting
+// how many events it took. While another instance is relaying it takes none.
+func (d *Dispatcher) relay(ctx context.Context) (int, error) {
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.GetContext(ctx, &locked, queryLockRelay); err != nil {
+		d.log.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("LockRelay execution err")
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	query, args, err := sqlx.Named(queryClaimEvents, map[string]interface{}{"limit": relayBatchSize})
+	if err != nil {
+		d.log.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("ClaimEvents named query preparation err")
+		return 0, err
+	}
+
+	var rows []EventDB
+	if err := tx.SelectContext(ctx, &rows, tx.Rebind(query), args...); err != nil {
+		d.log.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("ClaimEvents execution err")
+		return 0, err
+	}
+
+	for _, row := range rows {
+		record := row.Record()
+		eventCtx := contextPkg.WithRequestID(ctx, row.RequestID.String)
+
+		query, args, err := sqlx.Named(queryMarkDispatched, map[string]interface{}{
+			"id":            record.ID,
+			"dispatched_at": time.Now(),
+		})
+		if err != nil {
+			d.log.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("MarkDispatched named query preparation err")
+			return 0, err
+		}
+		if err := tx.QueryRowxContext(ctx, tx.Rebind(query), args...).Scan(&record.Sequence); err != nil {
+			d.log.WithFields(logrus.Fields{
+				"event_id": record.ID,
+				"error":    err.Error(),
+			}).Error("MarkDispatched execution err")
+			return 0, err
+		}
+
+		for name, sub := range d.subscribers {
+			if sub.eventType != "" && sub.eventType != record.Type {
+				continue
+			}
+			job := deliverEvent{Subscriber: name, Event: record}
+			if err := d.queue.EnqueueTx(eventCtx, tx, job, jobs.MaxAttempts(deliverAttempts)); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(rows), nil
+}
+
+func (row EventDB) Record() Record {
+	return Record{
+		ID:         row.ID.String,
+		Sequence:   row.Sequence.Int64,
+		Type:       row.Type.String,
+		UserID:     row.UserID.String,
+		Payload:    row.Payload,
+		OccurredAt: row.OccurredAt.Time,
+	}
+}
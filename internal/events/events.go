@@ -0,0 +1,86 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event is a fact about a user that other parts of the system may react to.
+// EventType names it for subscribers and webhooks and must be unique across
+// the application; the event itself is stored as JSON.
+type Event interface {
+	EventType() string
+}
+
+// Record is an event as stored in the outbox and handed to subscribers.
+// Sequence is assigned when the event is dispatched and orders events by
+// when they were dispatched, which IDs, taken before the writing transaction
+// commits, do not.
+type Record struct {
+	ID         string          `json:"id"`
+	Sequence   int64           `json:"sequence,omitempty"`
+	Type       string          `json:"type"`
+	UserID     string          `json:"user_id"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// WalletCredited is written when a top-up adds money to a wallet, whether
+// from the payment callback, a status check or a manual settlement.
+type WalletCredited struct {
+	ReferenceNo string  `json:"reference_no"`
+	Amount      float64 `json:"amount"`
+	Balance     float64 `json:"balance"`
+}
+
+func (WalletCredited) EventType() string {
+	return "wallet.credited"
+}
+
+// TopUpStatusChanged is written whenever a top-up leaves pending, with the
+// status it moved to.
+type TopUpStatusChanged struct {
+	ReferenceNo string `json:"reference_no"`
+	Status      string `json:"status"`
+}
+
+func (TopUpStatusChanged) EventType() string {
+	return "wallet.top_up_status_changed"
+}
+
+// UserRegistered is written when an account is created, by phone number or
+// by signing in with Google for the first time.
+type UserRegistered struct {
+	Method string `json:"method"`
+}
+
+func (UserRegistered) EventType() string {
+	return "user.registered"
+}
+
+const (
+	RegistrationPhone  = "phone"
+	RegistrationGoogle = "google"
+)
+
+// KYCApproved is written when a reviewer approves a user's identity
+// documents.
+type KYCApproved struct {
+	Level      int    `json:"level"`
+	ReviewedBy string `json:"reviewed_by"`
+}
+
+func (KYCApproved) EventType() string {
+	return "kyc.approved"
+}
+
+// KYCRejected is written when a reviewer rejects a user's identity
+// documents.
+type KYCRejected struct {
+	Reason     string `json:"reason"`
+	ReviewedBy string `json:"reviewed_by"`
+}
+
+func (KYCRejected) EventType() string {
+	return "kyc.rejected"
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/utils"
+	"encoding/json"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"time"
+)
+
+// Outbox writes events next to the state change they describe. Repositories
+// hand it the executor of their client, so an event opened in a transaction
+// is only published if that transaction commits.
+type Outbox interface {
+	Append(ctx context.Context, userID string, event Event) error
+}
+
+type outbox struct {
+	q     sqlx.ExtContext
+	log   *logrus.Logger
+	utils utils.IUtils
+}
+
+func NewOutbox(q sqlx.ExtContext, log *logrus.Logger) Outbox {
+	return &outbox{
+		q:     q,
+		log:   log,
+		utils: utils.New(),
+	}
+}
+
+func (o *outbox) Append(ctx context.Context, userID string, event Event) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	id, err := o.utils.NewULIDFromTimestamp(now)
+	if err != nil {
+		return err
+	}
+
+	argsKV := map[string]interface{}{
+		"id":          id,
+		"type":        event.EventType(),
+		"user_id":     userID,
+		"payload":     string(payload),
+		"request_id":  requestID,
+		"occurred_at": now,
+	}
+
+	query, args, err := sqlx.Named(queryAppendEvent, argsKV)
+	if err != nil {
+		o.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("AppendEvent named query preparation err")
+		return err
+	}
+
+	query = o.q.Rebind(query)
+
+	if _, err := o.q.ExecContext(ctx, query, args...); err != nil {
+		o.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"event_type": event.EventType(),
+			"error":      err.Error(),
+		}).Error("AppendEvent execution err")
+		return err
+	}
+
+	return nil
+}
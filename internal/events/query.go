@@ -0,0 +1,53 @@
+package events
+
+const (
+	queryAppendEvent = `
+		INSERT INTO outbox_events (
+			id,
+			type,
+			user_id,
+			payload,
+			request_id,
+			occurred_at
+		) VALUES (
+			:id,
+			:type,
+			:user_id,
+			:payload,
+			:request_id,
+			:occurred_at
+		)
+	`
+
+	// queryClaimEvents locks the oldest undispatched events, skipping those
+	// another relay is already handing out.
+	queryClaimEvents = `
+		SELECT
+			id,
+			type,
+			user_id,
+			payload,
+			request_id,
+			occurred_at
+		FROM outbox_events
+		WHERE dispatched_at IS NULL
+		ORDER BY id
+		LIMIT :limit
+		FOR UPDATE SKIP LOCKED
+	`
+
+	// queryLockRelay lets one relay at a time number events, so sequences
+	// are committed in the order they are handed out.
+	queryLockRelay = `
+		SELECT pg_try_advisory_xact_lock(hashtext('outbox_relay'))
+	`
+
+	queryMarkDispatched = `
+		UPDATE outbox_events
+		SET
+			dispatched_at = :dispatched_at,
+			sequence = nextval('outbox_events_sequence')
+		WHERE id = :id
+		RETURNING sequence
+	`
+)
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/net/context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// webhook posts events to an outside endpoint. Each request carries the
+// event ID, so the receiver can drop the duplicates at-least-once delivery
+// brings, and an HMAC-SHA256 signature of "<timestamp>.<body>" made with
+// EVENT_WEBHOOK_SECRET.
+type webhook struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// WebhookConfig lists the endpoints events are posted to and the secret
+// their requests are signed with.
+type WebhookConfig struct {
+	URLs   []string `env:"EVENT_WEBHOOK_URLS"`
+	Secret string   `env:"EVENT_WEBHOOK_SECRET" secret:"true"`
+}
+
+// SubscribeWebhooks sends every event to each URL in cfg, returning how many
+// were subscribed.
+func (d *Dispatcher) SubscribeWebhooks(cfg WebhookConfig) int {
+	secret := []byte(cfg.Secret)
+
+	count := 0
+	for _, url := range cfg.URLs {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			continue
+		}
+
+		hook := &webhook{
+			url:    url,
+			secret: secret,
+			client: &http.Client{Timeout: 10 * time.Second},
+		}
+		d.SubscribeAll("webhook:"+url, hook.send)
+		count++
+	}
+
+	return count
+}
+
+func (w *webhook) send(ctx context.Context, record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, w.secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", record.ID)
+	req.Header.Set("X-Event-Type", record.Type)
+	req.Header.Set("X-Event-Timestamp", timestamp)
+	req.Header.Set("X-Event-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with status %d", w.url, resp.StatusCode)
+	}
+
+	return nil
+}
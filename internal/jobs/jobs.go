@@ -0,0 +1,123 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/net/context"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts = 5
+)
+
+// Job is the payload of a queued job. JobType names the handler that runs it
+// and must be unique across the application; the payload itself is stored
+// as JSON.
+type Job interface {
+	JobType() string
+}
+
+// Enqueuer is what services depend on to queue jobs.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, job Job, opts ...Option) error
+}
+
+type enqueueOptions struct {
+	delay       time.Duration
+	maxAttempts int
+}
+
+type Option func(*enqueueOptions)
+
+// Delay runs the job no earlier than d from now.
+func Delay(d time.Duration) Option {
+	return func(o *enqueueOptions) {
+		o.delay = d
+	}
+}
+
+// MaxAttempts overrides DefaultMaxAttempts for the job.
+func MaxAttempts(n int) Option {
+	return func(o *enqueueOptions) {
+		if n > 0 {
+			o.maxAttempts = n
+		}
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks a handler error that retrying cannot fix, sending the job
+// straight to the dead-letter table.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// Redacter is implemented by jobs whose payload carries personal data. A job
+// that fails for good is kept in the dead-letter table as Redacted returns
+// it.
+type Redacter interface {
+	Redacted() Job
+}
+
+type handlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+type redactFunc func(payload json.RawMessage) json.RawMessage
+
+// Handle registers the handler for jobs of type T. It must be called before
+// the queue runs.
+func Handle[T Job](q *Queue, handle func(ctx context.Context, job T) error) {
+	var zero T
+	q.handlers[zero.JobType()] = func(ctx context.Context, payload json.RawMessage) error {
+		var job T
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return Permanent(fmt.Errorf("decoding %s payload: %w", zero.JobType(), err))
+		}
+		return handle(ctx, job)
+	}
+
+	if _, ok := any(zero).(Redacter); ok {
+		q.redactors[zero.JobType()] = func(payload json.RawMessage) json.RawMessage {
+			var job T
+			if err := json.Unmarshal(payload, &job); err != nil {
+				return json.RawMessage("{}")
+			}
+			redacted, err := json.Marshal(any(job).(Redacter).Redacted())
+			if err != nil {
+				return json.RawMessage("{}")
+			}
+			return redacted
+		}
+	}
+}
+
+// DeleteFile removes a stored object, by the location storage returned for
+// it or by its key. Deletes that fail, or that must wait until a transaction commits,
+// are retried from the queue instead of leaving the object behind.
+type DeleteFile struct {
+	FileName string `json:"file_name"`
+}
+
+func (DeleteFile) JobType() string {
+	return "delete_file"
+}
@@ -0,0 +1,100 @@
+package jobs
+
+const (
+	queryEnqueueJob = `
+		INSERT INTO jobs (
+			id,
+			type,
+			payload,
+			status,
+			attempts,
+			max_attempts,
+			request_id,
+			run_at,
+			created_at,
+			updated_at
+		) VALUES (
+			:id,
+			:type,
+			:payload,
+			'pending',
+			0,
+			:max_attempts,
+			:request_id,
+			:run_at,
+			:created_at,
+			:created_at
+		)
+	`
+
+	// queryClaimJob takes the next due job, or one whose worker died holding
+	// it, without waiting on jobs other workers are taking.
+	queryClaimJob = `
+		UPDATE jobs
+		SET
+			status = 'running',
+			attempts = attempts + 1,
+			locked_until = :locked_until,
+			updated_at = :now
+		WHERE id = (
+			SELECT id
+			FROM jobs
+			WHERE run_at <= :now
+				AND (status = 'pending' OR (status = 'running' AND locked_until < :now))
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id,
+			type,
+			payload,
+			attempts,
+			max_attempts,
+			request_id,
+			created_at
+	`
+
+	queryDeleteJob = `
+		DELETE FROM jobs
+		WHERE id = :id
+	`
+
+	queryRetryJob = `
+		UPDATE jobs
+		SET
+			status = 'pending',
+			run_at = :run_at,
+			locked_until = NULL,
+			last_error = :last_error,
+			updated_at = :now
+		WHERE id = :id
+	`
+
+	queryCreateDeadLetter = `
+		INSERT INTO dead_letter_jobs (
+			id,
+			type,
+			payload,
+			attempts,
+			last_error,
+			request_id,
+			created_at,
+			failed_at
+		) VALUES (
+			:id,
+			:type,
+			:payload,
+			:attempts,
+			:last_error,
+			:request_id,
+			:created_at,
+			:failed_at
+		)
+	`
+
+	queryDeleteExpiredDeadLetters = `
+		DELETE FROM dead_letter_jobs
+		WHERE failed_at < :failed_before
+	`
+)
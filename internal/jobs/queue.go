@@ -0,0 +1,384 @@
+package jobs
+
+import (
+	contextPkg "ProjectGolang/pkg/context"
+	"ProjectGolang/pkg/utils"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWorkers       = 4
+	defaultRetentionDays = 30
+	pollInterval         = time.Second
+	jobTimeout           = time.Minute
+	// lockDuration is how long a claimed job stays with its worker before
+	// another one may take it over.
+	lockDuration = 2 * jobTimeout
+	retryBackoff = 10 * time.Second
+	maxBackoff   = 30 * time.Minute
+	// pruneInterval is how often dead letters past their retention are
+	// deleted.
+	pruneInterval = time.Hour
+)
+
+// Queue stores jobs in Postgres and runs them on a pool of workers. Each job
+// is retried with exponential backoff until it succeeds or runs out of
+// attempts, when it is moved to the dead-letter table and kept there for the
+// retention period.
+type Queue struct {
+	db        *sqlx.DB
+	log       *logrus.Logger
+	utils     utils.IUtils
+	handlers  map[string]handlerFunc
+	redactors map[string]redactFunc
+	workers   int
+	retention time.Duration
+}
+
+type JobDB struct {
+	ID          sql.NullString `db:"id"`
+	Type        sql.NullString `db:"type"`
+	Payload     []byte         `db:"payload"`
+	Attempts    sql.NullInt64  `db:"attempts"`
+	MaxAttempts sql.NullInt64  `db:"max_attempts"`
+	RequestID   sql.NullString `db:"request_id"`
+	CreatedAt   sql.NullTime   `db:"created_at"`
+}
+
+// Config sizes the worker pool and sets how long dead letters are kept.
+type Config struct {
+	Workers                 int `env:"JOB_WORKERS" default:"4"`
+	DeadLetterRetentionDays int `env:"JOB_DEAD_LETTER_RETENTION_DAYS" default:"30"`
+}
+
+// New runs cfg.Workers workers, 4 if it is not positive, and keeps dead
+// letters for cfg.DeadLetterRetentionDays, 30 if it is not positive.
+func New(db *sqlx.DB, log *logrus.Logger, utils utils.IUtils, cfg Config) *Queue {
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = defaultWorkers
+	}
+	retentionDays := cfg.DeadLetterRetentionDays
+	if retentionDays < 1 {
+		retentionDays = defaultRetentionDays
+	}
+
+	return &Queue{
+		db:        db,
+		log:       log,
+		utils:     utils,
+		handlers:  make(map[string]handlerFunc),
+		redactors: make(map[string]redactFunc),
+		workers:   workers,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+	}
+}
+
+func (q *Queue) Enqueue(ctx context.Context, job Job, opts ...Option) error {
+	return q.EnqueueTx(ctx, q.db, job, opts...)
+}
+
+// EnqueueTx queues job on tx, so it runs only if tx commits.
+func (q *Queue) EnqueueTx(ctx context.Context, tx sqlx.ExtContext, job Job, opts ...Option) error {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	options := enqueueOptions{maxAttempts: DefaultMaxAttempts}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	id, err := q.utils.NewULIDFromTimestamp(time.Now())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	argsKV := map[string]interface{}{
+		"id":           id,
+		"type":         job.JobType(),
+		"payload":      string(payload),
+		"max_attempts": options.maxAttempts,
+		"request_id":   requestID,
+		"run_at":       now.Add(options.delay),
+		"created_at":   now,
+	}
+
+	query, args, err := sqlx.Named(queryEnqueueJob, argsKV)
+	if err != nil {
+		q.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"error":      err.Error(),
+		}).Error("EnqueueJob named query preparation err")
+		return err
+	}
+
+	query = tx.Rebind(query)
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		q.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"job_type":   job.JobType(),
+			"error":      err.Error(),
+		}).Error("EnqueueJob execution err")
+		return err
+	}
+
+	q.log.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"job_id":     id,
+		"job_type":   job.JobType(),
+	}).Debug("Job enqueued")
+
+	return nil
+}
+
+// Run works through due jobs until ctx is cancelled. Jobs still running then
+// are abandoned and picked up again once their lock expires. Dead letters
+// past the retention period are deleted every pruneInterval.
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.pruneDeadLetters(ctx)
+	}()
+	for i := 0; i < q.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		claimed, err := q.runNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			q.log.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("Failed to claim job")
+		}
+		if claimed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// runNext claims and runs one due job, reporting whether there was one.
+func (q *Queue) runNext(ctx context.Context) (bool, error) {
+	job, err := q.claim(ctx)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	jobCtx := contextPkg.WithRequestID(ctx, job.RequestID.String)
+	fields := logrus.Fields{
+		"request_id": job.RequestID.String,
+		"job_id":     job.ID.String,
+		"job_type":   job.Type.String,
+		"attempt":    job.Attempts.Int64,
+	}
+
+	handler, ok := q.handlers[job.Type.String]
+	if !ok {
+		q.fail(ctx, job, Permanent(fmt.Errorf("no handler for job type %s", job.Type.String)), fields)
+		return true, nil
+	}
+
+	runCtx, cancel := context.WithTimeout(jobCtx, jobTimeout)
+	start := time.Now()
+	err = runHandler(runCtx, handler, job.Payload)
+	cancel()
+
+	if err != nil {
+		q.fail(ctx, job, err, fields)
+		return true, nil
+	}
+
+	if err := q.exec(ctx, queryDeleteJob, map[string]interface{}{"id": job.ID.String}, "DeleteJob"); err != nil {
+		return true, err
+	}
+
+	fields["duration_ms"] = time.Since(start).Milliseconds()
+	q.log.WithFields(fields).Debug("Job done")
+	return true, nil
+}
+
+// runHandler turns a handler panic into an error so one bad job cannot take
+// down a worker.
+func runHandler(ctx context.Context, handler handlerFunc, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, payload)
+}
+
+func (q *Queue) claim(ctx context.Context) (*JobDB, error) {
+	now := time.Now()
+	argsKV := map[string]interface{}{
+		"now":          now,
+		"locked_until": now.Add(lockDuration),
+	}
+
+	query, args, err := sqlx.Named(queryClaimJob, argsKV)
+	if err != nil {
+		q.log.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("ClaimJob named query preparation err")
+		return nil, err
+	}
+
+	query = q.db.Rebind(query)
+
+	var job JobDB
+	if err := q.db.QueryRowxContext(ctx, query, args...).StructScan(&job); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// fail schedules the job for another attempt, or moves it to the dead-letter
+// table when the error is permanent or the job is out of attempts.
+func (q *Queue) fail(ctx context.Context, job *JobDB, jobErr error, fields logrus.Fields) {
+	fields["error"] = jobErr.Error()
+
+	if isPermanent(jobErr) || job.Attempts.Int64 >= job.MaxAttempts.Int64 {
+		if err := q.deadLetter(ctx, job, jobErr); err != nil {
+			fields["dead_letter_error"] = err.Error()
+			q.log.WithFields(fields).Error("Failed to move job to dead letters")
+			return
+		}
+		q.log.WithFields(fields).Error("Job moved to dead letters")
+		return
+	}
+
+	runAt := time.Now().Add(backoff(int(job.Attempts.Int64)))
+	argsKV := map[string]interface{}{
+		"id":         job.ID.String,
+		"run_at":     runAt,
+		"last_error": jobErr.Error(),
+		"now":        time.Now(),
+	}
+	if err := q.exec(ctx, queryRetryJob, argsKV, "RetryJob"); err != nil {
+		return
+	}
+
+	fields["retry_at"] = runAt
+	q.log.WithFields(fields).Warn("Job failed, retrying")
+}
+
+// deadLetter moves the job to the dead-letter table, redacting its payload
+// when its type has personal data in it.
+func (q *Queue) deadLetter(ctx context.Context, job *JobDB, jobErr error) error {
+	payload := json.RawMessage(job.Payload)
+	if redact, ok := q.redactors[job.Type.String]; ok {
+		payload = redact(payload)
+	}
+
+	tx, err := q.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	argsKV := map[string]interface{}{
+		"id":         job.ID.String,
+		"type":       job.Type.String,
+		"payload":    string(payload),
+		"attempts":   job.Attempts.Int64,
+		"last_error": jobErr.Error(),
+		"request_id": job.RequestID.String,
+		"created_at": job.CreatedAt.Time,
+		"failed_at":  time.Now(),
+	}
+
+	for _, namedQuery := range []string{queryCreateDeadLetter, queryDeleteJob} {
+		query, args, err := sqlx.Named(namedQuery, argsKV)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (q *Queue) pruneDeadLetters(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		argsKV := map[string]interface{}{
+			"failed_before": time.Now().Add(-q.retention),
+		}
+		_ = q.exec(ctx, queryDeleteExpiredDeadLetters, argsKV, "DeleteExpiredDeadLetters")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Queue) exec(ctx context.Context, namedQuery string, argsKV map[string]interface{}, name string) error {
+	query, args, err := sqlx.Named(namedQuery, argsKV)
+	if err != nil {
+		q.log.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Errorf("%s named query preparation err", name)
+		return err
+	}
+
+	query = q.db.Rebind(query)
+
+	if _, err := q.db.ExecContext(ctx, query, args...); err != nil {
+		q.log.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Errorf("%s execution err", name)
+		return err
+	}
+
+	return nil
+}
+
+// backoff doubles the wait after every attempt, from retryBackoff up to
+// maxBackoff, with up to a fifth added as jitter so failed jobs do not retry
+// in lockstep.
+func backoff(attempt int) time.Duration {
+	wait := maxBackoff
+	if attempt <= 12 {
+		wait = min(retryBackoff<<max(attempt-1, 0), maxBackoff)
+	}
+	return wait + time.Duration(rand.Int63n(int64(wait)/5+1))
+}
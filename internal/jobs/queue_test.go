@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"ProjectGolang/pkg/utils"
+	"database/sql/driver"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"io"
+	"testing"
+	"time"
+)
+
+type contactJob struct {
+	Template string `json:"template"`
+	Phone    string `json:"phone,omitempty"`
+}
+
+func (contactJob) JobType() string {
+	return "contact"
+}
+
+func (j contactJob) Redacted() Job {
+	return contactJob{Template: j.Template}
+}
+
+// payloadArg matches a JSON payload argument exactly.
+type payloadArg string
+
+func (p payloadArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	return ok && s == string(p)
+}
+
+// cutoffArg matches a time about retention before now.
+type cutoffArg time.Duration
+
+func (c cutoffArg) Match(v driver.Value) bool {
+	cutoff, ok := v.(time.Time)
+	drift := time.Since(cutoff) - time.Duration(c)
+	return ok && drift > -time.Minute && drift < time.Minute
+}
+
+func newQueueTest(t *testing.T) (*Queue, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	return New(sqlx.NewDb(db, "postgres"), log, utils.New(), Config{}), mock
+}
+
+func expectClaim(mock sqlmock.Sqlmock, jobType string, payload string) {
+	mock.ExpectQuery(`UPDATE jobs`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "payload", "attempts", "max_attempts", "request_id", "created_at"}).
+			AddRow("job-1", jobType, []byte(payload), 1, 3, "request-1", time.Now()))
+}
+
+func TestDeadLetterRedactsPayload(t *testing.T) {
+	q, mock := newQueueTest(t)
+	Handle(q, func(ctx context.Context, job contactJob) error {
+		return Permanent(errors.New("no such template"))
+	})
+
+	expectClaim(mock, "contact", `{"template":"phone_otp","phone":"+6281234567890"}`)
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO dead_letter_jobs`).
+		WithArgs("job-1", "contact", payloadArg(`{"template":"phone_otp"}`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM jobs`).WithArgs("job-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	claimed, err := q.runNext(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet(), "the phone number must not reach the dead letters")
+}
+
+func TestDeadLetterKeepsPayloadWithoutPersonalData(t *testing.T) {
+	q, mock := newQueueTest(t)
+	Handle(q, func(ctx context.Context, job DeleteFile) error {
+		return Permanent(errors.New("bucket gone"))
+	})
+
+	payload := `{"file_name":"photos/1.jpg"}`
+	expectClaim(mock, "delete_file", payload)
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO dead_letter_jobs`).
+		WithArgs("job-1", "delete_file", payloadArg(payload), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM jobs`).WithArgs("job-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := q.runNext(context.Background())
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunPrunesExpiredDeadLetters(t *testing.T) {
+	q, mock := newQueueTest(t)
+	q.workers = 0
+
+	mock.ExpectExec(`DELETE FROM dead_letter_jobs\s+WHERE failed_at < \$1`).
+		WithArgs(cutoffArg(defaultRetentionDays * 24 * time.Hour)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
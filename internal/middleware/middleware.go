@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"ProjectGolang/internal/entity"
+	jwtPkg "ProjectGolang/pkg/jwt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
@@ -8,6 +10,8 @@ import (
 type Middleware interface {
 	NewRateLimiter(ctx *fiber.Ctx) error
 	NewTokenMiddleware(ctx *fiber.Ctx) error
+	NewWebSocketTokenMiddleware(ctx *fiber.Ctx) error
+	RequireRole(roles ...entity.Role) fiber.Handler
 	NewRequestIDMiddleware() fiber.Handler
 	GetRequestID(ctx *fiber.Ctx) string
 }
@@ -20,9 +24,9 @@ type middleware struct {
 	log                 *logrus.Logger
 }
 
-func New(logger *logrus.Logger) Middleware {
+func New(logger *logrus.Logger, jwt jwtPkg.IJWT, sessions jwtPkg.ISessions) Middleware {
 	rateLimit := newRateLimiter(50, 100)
-	token := newTokenMiddleware()
+	token := newTokenMiddleware(jwt, sessions)
 	logging := newLoggingMiddleware(logger)
 	requestID := NewRequestIDMiddleware()
 
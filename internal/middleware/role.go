@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"ProjectGolang/internal/entity"
+	jwtPkg "ProjectGolang/pkg/jwt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// RequireRole only lets through users whose token carries one of roles. It
+// must be mounted after NewTokenMiddleware.
+func (m *middleware) RequireRole(roles ...entity.Role) fiber.Handler {
+	allowed := make(map[entity.Role]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		user, err := jwtPkg.GetUserLoginData(ctx)
+		if err != nil {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized, access token invalid or expired",
+			})
+		}
+
+		if !allowed[user.Role] {
+			m.log.WithFields(logrus.Fields{
+				"request_id": m.GetRequestID(ctx),
+				"user_id":    user.ID,
+				"role":       user.Role,
+				"path":       ctx.Path(),
+			}).Warn("Role not permitted")
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Forbidden, insufficient role",
+			})
+		}
+
+		return ctx.Next()
+	}
+}
@@ -3,22 +3,22 @@ package middleware
 import (
 	"ProjectGolang/internal/entity"
 	jwtPkg "ProjectGolang/pkg/jwt"
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/sirupsen/logrus"
 	"reflect"
 	"strings"
-)
-
-const (
-	AccessTokenSecret = "JWT_ACCESS_TOKEN_SECRET"
+	"time"
 )
 
 type tokenMiddleware struct {
+	jwt      jwtPkg.IJWT
+	sessions jwtPkg.ISessions
 }
 
-func newTokenMiddleware() *tokenMiddleware {
-	return &tokenMiddleware{}
+func newTokenMiddleware(jwt jwtPkg.IJWT, sessions jwtPkg.ISessions) *tokenMiddleware {
+	return &tokenMiddleware{jwt: jwt, sessions: sessions}
 }
 
 func (m *middleware) NewTokenMiddleware(ctx *fiber.Ctx) error {
@@ -56,7 +56,7 @@ func (m *middleware) NewTokenMiddleware(ctx *fiber.Ctx) error {
 		})
 	}
 
-	userToken, err := jwtPkg.VerifyTokenHeader(ctx, AccessTokenSecret)
+	userToken, err := m.token.jwt.VerifyTokenHeader(ctx)
 	if err != nil {
 		m.log.WithFields(logrus.Fields{
 			"error": err.Error(),
@@ -66,14 +66,26 @@ func (m *middleware) NewTokenMiddleware(ctx *fiber.Ctx) error {
 		})
 	}
 
+	user, err := m.userLoginData(userToken)
+	if err != nil || m.isRevoked(ctx, userToken, user.ID) {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized, access token invalid or expired",
+		})
+	}
+	ctx.Locals("user", user)
+
+	m.log.Info("Authentication successful")
+	return ctx.Next()
+}
+
+// userLoginData reads the user a verified access token was issued to.
+func (m *middleware) userLoginData(userToken *jwt.Token) (entity.UserLoginData, error) {
 	claims, ok := userToken.Claims.(jwt.MapClaims)
 	if !ok {
 		m.log.WithFields(logrus.Fields{
 			"error": "Invalid token claims",
 		}).Warn("Token claims check")
-		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized, access token invalid or expired",
-		})
+		return entity.UserLoginData{}, errors.New("invalid token claims")
 	}
 
 	m.log.WithFields(logrus.Fields{
@@ -83,22 +95,55 @@ func (m *middleware) NewTokenMiddleware(ctx *fiber.Ctx) error {
 		"email_exists": claims["email"] != nil,
 	}).Debug("Token claims")
 
-	if claims["id"] == nil || claims["email"] == nil || claims["username"] == nil {
+	id, idOK := claims["id"].(string)
+	email, emailOK := claims["email"].(string)
+	username, usernameOK := claims["username"].(string)
+	if !idOK || !emailOK || !usernameOK {
 		m.log.WithFields(logrus.Fields{
 			"error": "Token claims are missing required fields",
 		}).Warn("Token claims check")
-		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized, access token invalid or expired",
-		})
+		return entity.UserLoginData{}, errors.New("token claims are missing required fields")
 	}
 
-	user := entity.UserLoginData{
-		ID:       claims["id"].(string),
-		Email:    claims["email"].(string),
-		Username: claims["username"].(string),
+	// Tokens issued before roles existed carry no role claim and act as users.
+	role := entity.RoleUser
+	if claimRole, ok := claims["role"].(string); ok && entity.Role(claimRole).IsValid() {
+		role = entity.Role(claimRole)
 	}
-	ctx.Locals("user", user)
 
-	m.log.Info("Authentication successful")
-	return ctx.Next()
+	return entity.UserLoginData{
+		ID:       id,
+		Email:    email,
+		Username: username,
+		Role:     role,
+	}, nil
+}
+
+// isRevoked reports whether the tokens of the user were revoked after
+// userToken was issued, because they were locked or their role changed.
+// Tokens without an iat claim predate revocation and are checked as if issued
+// at the epoch. When the check itself fails the token is refused.
+func (m *middleware) isRevoked(ctx *fiber.Ctx, userToken *jwt.Token, userID string) bool {
+	var issuedAt time.Time
+	if iat, err := userToken.Claims.GetIssuedAt(); err == nil && iat != nil {
+		issuedAt = iat.Time
+	}
+
+	revoked, err := m.token.sessions.IsRevoked(ctx.Context(), userID, issuedAt)
+	if err != nil {
+		m.log.WithFields(logrus.Fields{
+			"request_id": m.GetRequestID(ctx),
+			"user_id":    userID,
+			"error":      err.Error(),
+		}).Error("Failed to check token revocation")
+		return true
+	}
+	if revoked {
+		m.log.WithFields(logrus.Fields{
+			"request_id": m.GetRequestID(ctx),
+			"user_id":    userID,
+		}).Warn("Revoked token rejected")
+	}
+
+	return revoked
 }
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+const (
+	// WebSocketTokenProtocol is the subprotocol a client offers in front of
+	// its access token, as in Sec-WebSocket-Protocol: access_token, <token>.
+	// The server selects it so the handshake succeeds in browsers.
+	WebSocketTokenProtocol = "access_token"
+	webSocketTokenQuery    = "access_token"
+)
+
+// NewWebSocketTokenMiddleware authenticates a WebSocket upgrade. Browsers
+// cannot set an Authorization header on the handshake, so the access token is
+// also accepted from the Sec-WebSocket-Protocol header or, failing that, the
+// access_token query parameter.
+func (m *middleware) NewWebSocketTokenMiddleware(ctx *fiber.Ctx) error {
+	if ctx.Get("Authorization") != "" {
+		return m.NewTokenMiddleware(ctx)
+	}
+
+	accessToken := webSocketProtocolToken(ctx.Get(fiber.HeaderSecWebSocketProtocol))
+	if accessToken == "" {
+		accessToken = ctx.Query(webSocketTokenQuery)
+	}
+
+	userToken, err := m.token.jwt.VerifyToken(accessToken)
+	if err != nil {
+		m.log.WithFields(logrus.Fields{
+			"request_id": m.GetRequestID(ctx),
+			"path":       ctx.Path(),
+			"error":      err.Error(),
+		}).Warn("WebSocket token verification failed")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized, access token invalid or expired",
+		})
+	}
+
+	user, err := m.userLoginData(userToken)
+	if err != nil || m.isRevoked(ctx, userToken, user.ID) {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized, access token invalid or expired",
+		})
+	}
+	ctx.Locals("user", user)
+
+	return ctx.Next()
+}
+
+// webSocketProtocolToken returns the protocol offered right after
+// WebSocketTokenProtocol in a Sec-WebSocket-Protocol header.
+func webSocketProtocolToken(header string) string {
+	protocols := strings.Split(header, ",")
+	for i := 0; i < len(protocols)-1; i++ {
+		if strings.TrimSpace(protocols[i]) == WebSocketTokenProtocol {
+			return strings.TrimSpace(protocols[i+1])
+		}
+	}
+	return ""
+}
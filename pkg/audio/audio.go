@@ -0,0 +1,65 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnsupportedAudio = errors.New("audio container or codec is not supported")
+	ErrInvalidAudio     = errors.New("audio file is malformed")
+)
+
+// Info describes an audio file as read from its headers.
+type Info struct {
+	// Container is one of "wav", "mp3", "ogg", "mp4" or "flac".
+	Container  string
+	Codec      string
+	SampleRate int
+	Channels   int
+	Duration   time.Duration
+}
+
+// Probe reads the container and codec of an audio file and works out its
+// duration, without decoding any audio. The container is judged by the
+// content alone; files whose duration cannot be told from their headers or
+// frames are rejected as malformed.
+func Probe(data []byte) (Info, error) {
+	var info Info
+	var err error
+
+	switch {
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		info, err = probeWAV(data)
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		info, err = probeFLAC(data)
+	case bytes.HasPrefix(data, []byte("OggS")):
+		info, err = probeOgg(data)
+	case len(data) >= 8 && string(data[4:8]) == "ftyp":
+		info, err = probeMP4(data)
+	case bytes.HasPrefix(data, []byte("ID3")) || isFrameSync(data):
+		info, err = probeMP3(data)
+	default:
+		return Info{}, ErrUnsupportedAudio
+	}
+	if err != nil {
+		return Info{}, err
+	}
+
+	if info.Duration <= 0 || info.SampleRate <= 0 || info.Channels <= 0 {
+		return Info{}, ErrInvalidAudio
+	}
+
+	return info, nil
+}
+
+// samplesDuration is how long count samples last at rate samples a second.
+func samplesDuration(count uint64, rate int) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	seconds := count / uint64(rate)
+	rest := count % uint64(rate)
+	return time.Duration(seconds)*time.Second + time.Duration(rest)*time.Second/time.Duration(rate)
+}
@@ -0,0 +1,25 @@
+package audio
+
+import "encoding/binary"
+
+// probeFLAC reads the STREAMINFO block, which the format requires to come
+// first.
+func probeFLAC(data []byte) (Info, error) {
+	const streamInfoLength = 34
+	if len(data) < 8+streamInfoLength || data[4]&0x7F != 0 {
+		return Info{}, ErrInvalidAudio
+	}
+
+	block := data[8 : 8+streamInfoLength]
+	sampleRate := int(block[10])<<12 | int(block[11])<<4 | int(block[12])>>4
+	channels := int(block[12]>>1&0x07) + 1
+	totalSamples := uint64(block[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(block[14:18]))
+
+	return Info{
+		Container:  "flac",
+		Codec:      "flac",
+		SampleRate: sampleRate,
+		Channels:   channels,
+		Duration:   samplesDuration(totalSamples, sampleRate),
+	}, nil
+}
@@ -0,0 +1,174 @@
+package audio
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// MPEG versions as coded in the frame header.
+const (
+	mpeg25 = 0
+	mpeg2  = 2
+	mpeg1  = 3
+)
+
+var (
+	// Layer III bitrates in kbit/s by index, for MPEG-1 and for MPEG-2 and
+	// 2.5. Index 0 is the free format, which is not accepted.
+	mp3Bitrates = [2][15]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	mp3SampleRates = map[int][3]int{
+		mpeg1:  {44100, 48000, 32000},
+		mpeg2:  {22050, 24000, 16000},
+		mpeg25: {11025, 12000, 8000},
+	}
+)
+
+type mp3Frame struct {
+	version    int
+	sampleRate int
+	channels   int
+	samples    int
+	length     int
+}
+
+func isFrameSync(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0
+}
+
+// parseMP3Frame reads the Layer III frame header at the start of data.
+func parseMP3Frame(data []byte) (mp3Frame, error) {
+	if len(data) < 4 || !isFrameSync(data) {
+		return mp3Frame{}, ErrInvalidAudio
+	}
+
+	version := int(data[1]>>3) & 0x03
+	layer := int(data[1]>>1) & 0x03
+	bitrateIndex := int(data[2] >> 4)
+	rateIndex := int(data[2]>>2) & 0x03
+	padding := int(data[2]>>1) & 0x01
+
+	if version == 1 || rateIndex == 3 || bitrateIndex == 15 {
+		return mp3Frame{}, ErrInvalidAudio
+	}
+	if layer != 1 || bitrateIndex == 0 {
+		return mp3Frame{}, ErrUnsupportedAudio
+	}
+
+	frame := mp3Frame{
+		version:    version,
+		sampleRate: mp3SampleRates[version][rateIndex],
+		channels:   2,
+		samples:    1152,
+	}
+	if data[3]>>6 == 3 {
+		frame.channels = 1
+	}
+
+	bitrate := mp3Bitrates[0][bitrateIndex]
+	coefficient := 144
+	if version != mpeg1 {
+		bitrate = mp3Bitrates[1][bitrateIndex]
+		coefficient = 72
+		frame.samples = 576
+	}
+	frame.length = coefficient*bitrate*1000/frame.sampleRate + padding
+
+	return frame, nil
+}
+
+// probeMP3 reads MPEG Layer III audio, taking the frame count from a Xing,
+// Info or VBRI header where the encoder wrote one and counting the frames
+// otherwise.
+func probeMP3(data []byte) (Info, error) {
+	// Taggers may pad the tag with more zeros than its size covers.
+	offset := id3Length(data)
+	for offset < len(data) && data[offset] == 0 {
+		offset++
+	}
+	if offset >= len(data) {
+		return Info{}, ErrInvalidAudio
+	}
+
+	first, err := parseMP3Frame(data[offset:])
+	if err != nil {
+		return Info{}, err
+	}
+	info := Info{
+		Container:  "mp3",
+		Codec:      "mp3",
+		SampleRate: first.sampleRate,
+		Channels:   first.channels,
+	}
+
+	if frames, ok := mp3FrameCount(data[offset:], first); ok {
+		info.Duration = samplesDuration(uint64(frames)*uint64(first.samples), first.sampleRate)
+		return info, nil
+	}
+
+	// Frames may change rate between them, so each adds its own length of
+	// time. Counting stops at the first thing that is not a frame, such as
+	// an ID3v1 tag.
+	for offset < len(data) {
+		frame, err := parseMP3Frame(data[offset:])
+		if err != nil || offset+frame.length > len(data) {
+			break
+		}
+		info.Duration += time.Duration(frame.samples) * time.Second / time.Duration(frame.sampleRate)
+		offset += frame.length
+	}
+
+	return info, nil
+}
+
+// mp3FrameCount reads the frame count a VBR header in the first frame
+// gives. The header sits after the side information, whose length depends
+// on the version and the channels.
+func mp3FrameCount(data []byte, first mp3Frame) (uint32, bool) {
+	sideInfo := 32
+	switch {
+	case first.version == mpeg1 && first.channels == 1:
+		sideInfo = 17
+	case first.version != mpeg1 && first.channels == 2:
+		sideInfo = 17
+	case first.version != mpeg1:
+		sideInfo = 9
+	}
+
+	xing := 4 + sideInfo
+	if len(data) >= xing+12 {
+		tag := string(data[xing : xing+4])
+		flags := binary.BigEndian.Uint32(data[xing+4:])
+		if (tag == "Xing" || tag == "Info") && flags&0x01 != 0 {
+			frames := binary.BigEndian.Uint32(data[xing+8:])
+			return frames, frames > 0
+		}
+	}
+
+	// VBRI always follows 32 bytes of header and side information.
+	const vbri = 4 + 32
+	if len(data) >= vbri+18 && string(data[vbri:vbri+4]) == "VBRI" {
+		frames := binary.BigEndian.Uint32(data[vbri+14:])
+		return frames, frames > 0
+	}
+
+	return 0, false
+}
+
+// id3Length returns the length of the ID3v2 tag at the start of data, if
+// there is one.
+func id3Length(data []byte) int {
+	if len(data) < 10 || string(data[:3]) != "ID3" {
+		return 0
+	}
+
+	// The size is stored in four bytes of seven bits each.
+	size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+	length := 10 + size
+	if data[5]&0x10 != 0 {
+		length += 10
+	}
+	return length
+}
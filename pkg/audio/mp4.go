@@ -0,0 +1,148 @@
+package audio
+
+import "encoding/binary"
+
+// mp4Codecs names the sample entry formats accepted in audio tracks.
+var mp4Codecs = map[string]string{
+	"mp4a": "aac",
+	"alac": "alac",
+	"Opus": "opus",
+	"fLaC": "flac",
+}
+
+// mp4Boxes returns the bodies of the boxes of type name directly within
+// data, stopping at the first box that does not fit.
+func mp4Boxes(data []byte, name string) [][]byte {
+	var boxes [][]byte
+	for offset := 0; offset+8 <= len(data); {
+		size := uint64(binary.BigEndian.Uint32(data[offset:]))
+		kind := string(data[offset+4 : offset+8])
+		header := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(data) - offset)
+		case 1:
+			if offset+16 > len(data) {
+				return boxes
+			}
+			size = binary.BigEndian.Uint64(data[offset+8:])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)-offset) {
+			return boxes
+		}
+
+		if kind == name {
+			boxes = append(boxes, data[offset+int(header):offset+int(size)])
+		}
+		offset += int(size)
+	}
+
+	return boxes
+}
+
+// mp4Box returns the body of the first box of type name within data.
+func mp4Box(data []byte, name string) ([]byte, bool) {
+	boxes := mp4Boxes(data, name)
+	if len(boxes) == 0 {
+		return nil, false
+	}
+	return boxes[0], true
+}
+
+// mp4Path follows a path of nested boxes.
+func mp4Path(data []byte, names ...string) ([]byte, bool) {
+	for _, name := range names {
+		body, ok := mp4Box(data, name)
+		if !ok {
+			return nil, false
+		}
+		data = body
+	}
+	return data, true
+}
+
+// probeMP4 reads the one audio track an M4A file should hold. Files with a
+// video track are refused, as they are videos rather than voice notes.
+func probeMP4(data []byte) (Info, error) {
+	moov, ok := mp4Box(data, "moov")
+	if !ok {
+		return Info{}, ErrInvalidAudio
+	}
+
+	var info Info
+	found := false
+	for _, trak := range mp4Boxes(moov, "trak") {
+		hdlr, ok := mp4Path(trak, "mdia", "hdlr")
+		if !ok || len(hdlr) < 12 {
+			return Info{}, ErrInvalidAudio
+		}
+
+		switch string(hdlr[8:12]) {
+		case "vide":
+			return Info{}, ErrUnsupportedAudio
+		case "soun":
+		default:
+			continue
+		}
+		if found {
+			return Info{}, ErrUnsupportedAudio
+		}
+
+		track, err := probeMP4Track(trak)
+		if err != nil {
+			return Info{}, err
+		}
+		info = track
+		found = true
+	}
+	if !found {
+		return Info{}, ErrInvalidAudio
+	}
+
+	info.Container = "mp4"
+	return info, nil
+}
+
+func probeMP4Track(trak []byte) (Info, error) {
+	mdhd, ok := mp4Path(trak, "mdia", "mdhd")
+	if !ok || len(mdhd) < 4 {
+		return Info{}, ErrInvalidAudio
+	}
+
+	var timescale uint32
+	var duration uint64
+	if mdhd[0] == 1 {
+		if len(mdhd) < 32 {
+			return Info{}, ErrInvalidAudio
+		}
+		timescale = binary.BigEndian.Uint32(mdhd[20:])
+		duration = binary.BigEndian.Uint64(mdhd[24:])
+	} else {
+		if len(mdhd) < 20 {
+			return Info{}, ErrInvalidAudio
+		}
+		timescale = binary.BigEndian.Uint32(mdhd[12:])
+		duration = uint64(binary.BigEndian.Uint32(mdhd[16:]))
+	}
+
+	stsd, ok := mp4Path(trak, "mdia", "minf", "stbl", "stsd")
+	// The first sample entry follows the version, flags and entry count.
+	// Its channel count and rate sit 24 and 32 bytes from its start.
+	if !ok || len(stsd) < 8+36 {
+		return Info{}, ErrInvalidAudio
+	}
+	entry := stsd[8:]
+	codec, ok := mp4Codecs[string(entry[4:8])]
+	if !ok {
+		return Info{}, ErrUnsupportedAudio
+	}
+
+	return Info{
+		Codec:      codec,
+		Channels:   int(binary.BigEndian.Uint16(entry[24:])),
+		SampleRate: int(binary.BigEndian.Uint32(entry[32:]) >> 16),
+		Duration:   samplesDuration(duration, int(timescale)),
+	}, nil
+}
@@ -0,0 +1,104 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	oggHeaderLength = 27
+	opusRate        = 48000
+)
+
+type oggPage struct {
+	granule int64
+	serial  uint32
+	body    []byte
+}
+
+// readOggPage reads the page starting at offset, returning it and the
+// offset of the page after it.
+func readOggPage(data []byte, offset int) (oggPage, int, bool) {
+	if offset+oggHeaderLength > len(data) || string(data[offset:offset+4]) != "OggS" {
+		return oggPage{}, 0, false
+	}
+
+	count := int(data[offset+26])
+	segmentsEnd := offset + oggHeaderLength + count
+	if segmentsEnd > len(data) {
+		return oggPage{}, 0, false
+	}
+
+	length := 0
+	for _, segment := range data[offset+oggHeaderLength : segmentsEnd] {
+		length += int(segment)
+	}
+	if segmentsEnd+length > len(data) {
+		return oggPage{}, 0, false
+	}
+
+	return oggPage{
+		granule: int64(binary.LittleEndian.Uint64(data[offset+6:])),
+		serial:  binary.LittleEndian.Uint32(data[offset+14:]),
+		body:    data[segmentsEnd : segmentsEnd+length],
+	}, segmentsEnd + length, true
+}
+
+// probeOgg reads the codec from the identification header that opens the
+// first stream, and the duration from the granule position of its last page,
+// which counts the samples up to the end of the stream.
+func probeOgg(data []byte) (Info, error) {
+	first, next, ok := readOggPage(data, 0)
+	if !ok {
+		return Info{}, ErrInvalidAudio
+	}
+
+	info := Info{Container: "ogg"}
+	var preSkip int64
+	var granuleRate int
+
+	header := first.body
+	switch {
+	case bytes.HasPrefix(header, []byte("OpusHead")):
+		if len(header) < 19 {
+			return Info{}, ErrInvalidAudio
+		}
+		info.Codec = "opus"
+		info.Channels = int(header[9])
+		preSkip = int64(binary.LittleEndian.Uint16(header[10:]))
+		// Opus always runs at 48 kHz; the rate in the header is only that of
+		// the original input.
+		info.SampleRate = opusRate
+		granuleRate = opusRate
+	case bytes.HasPrefix(header, []byte("\x01vorbis")):
+		if len(header) < 16 {
+			return Info{}, ErrInvalidAudio
+		}
+		info.Codec = "vorbis"
+		info.Channels = int(header[11])
+		info.SampleRate = int(binary.LittleEndian.Uint32(header[12:]))
+		granuleRate = info.SampleRate
+	default:
+		return Info{}, ErrUnsupportedAudio
+	}
+
+	// Pages that finish no packet carry a granule position of -1 and are
+	// passed over.
+	last := int64(-1)
+	for offset := next; offset < len(data); {
+		page, after, ok := readOggPage(data, offset)
+		if !ok {
+			break
+		}
+		if page.serial == first.serial && page.granule >= 0 {
+			last = page.granule
+		}
+		offset = after
+	}
+	if last <= preSkip {
+		return Info{}, ErrInvalidAudio
+	}
+
+	info.Duration = samplesDuration(uint64(last-preSkip), granuleRate)
+	return info, nil
+}
@@ -0,0 +1,72 @@
+package audio
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// wavCodecs names the WAVE format tags accepted, all of them fixed-rate so
+// the duration follows from the size of the data.
+var wavCodecs = map[uint16]string{
+	0x0001: "pcm",
+	0x0003: "pcm_float",
+	0x0006: "alaw",
+	0x0007: "mulaw",
+}
+
+const wavFormatExtensible = 0xFFFE
+
+func probeWAV(data []byte) (Info, error) {
+	info := Info{Container: "wav"}
+
+	var byteRate uint32
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 || body+size > len(data) {
+				return Info{}, ErrInvalidAudio
+			}
+			format := binary.LittleEndian.Uint16(data[body:])
+			// Extensible files keep the real format tag at the start of
+			// their subformat GUID.
+			if format == wavFormatExtensible {
+				if size < 40 {
+					return Info{}, ErrInvalidAudio
+				}
+				format = binary.LittleEndian.Uint16(data[body+24:])
+			}
+			codec, ok := wavCodecs[format]
+			if !ok {
+				return Info{}, ErrUnsupportedAudio
+			}
+			info.Codec = codec
+			info.Channels = int(binary.LittleEndian.Uint16(data[body+2:]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(data[body+4:]))
+			byteRate = binary.LittleEndian.Uint32(data[body+8:])
+
+		case "data":
+			if info.Codec == "" || byteRate == 0 {
+				return Info{}, ErrInvalidAudio
+			}
+			// Recorders that could not seek back leave the size unset, so
+			// the data is taken to run to the end of the file.
+			if size < 0 || body+size > len(data) {
+				size = len(data) - body
+			}
+			info.Duration = time.Duration(uint64(size) * uint64(time.Second) / uint64(byteRate))
+			return info, nil
+		}
+
+		if size < 0 {
+			return Info{}, ErrInvalidAudio
+		}
+		// Chunks are padded to an even length.
+		offset = body + size + size%2
+	}
+
+	return Info{}, ErrInvalidAudio
+}
@@ -0,0 +1,23 @@
+package awsPkg
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// Config holds the AWS credentials shared by the S3 client and the face
+// matcher.
+type Config struct {
+	Region          string `env:"AWS_REGION"`
+	AccessKeyID     string `env:"AWS_ACCESS_KEY_ID"`
+	SecretAccessKey string `env:"AWS_SECRET_ACCESS_KEY" secret:"true"`
+}
+
+// SDKConfig returns the SDK configuration for a session with these
+// credentials.
+func (c Config) SDKConfig() *aws.Config {
+	return &aws.Config{
+		Region:      aws.String(c.Region),
+		Credentials: credentials.NewStaticCredentials(c.AccessKeyID, c.SecretAccessKey, ""),
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-const RequestIDKey = "request_id"
+const (
+	RequestIDKey = "request_id"
+	ClientIPKey  = "client_ip"
+)
 
 func WithRequestID(ctx context.Context, requestID string) context.Context {
 	return context.WithValue(ctx, RequestIDKey, requestID)
@@ -19,6 +22,18 @@ func GetRequestID(ctx context.Context) string {
 	return requestID
 }
 
+func WithClientIP(ctx context.Context, clientIP string) context.Context {
+	return context.WithValue(ctx, ClientIPKey, clientIP)
+}
+
+func GetClientIP(ctx context.Context) string {
+	clientIP, ok := ctx.Value(ClientIPKey).(string)
+	if !ok || clientIP == "" {
+		return "unknown"
+	}
+	return clientIP
+}
+
 func FromFiberCtx(c *fiber.Ctx) context.Context {
 	ctx := context.Background()
 
@@ -31,5 +46,5 @@ func FromFiberCtx(c *fiber.Ctx) context.Context {
 		}
 	}
 
-	return WithRequestID(ctx, requestID)
+	return WithClientIP(WithRequestID(ctx, requestID), c.IP())
 }
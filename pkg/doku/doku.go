@@ -9,7 +9,6 @@ import (
 	"github.com/PTNUSASATUINTIARTHA-DOKU/doku-golang-library/models/va/notification/payment"
 	"github.com/sirupsen/logrus"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -19,10 +18,22 @@ type IDokuService interface {
 	CreateVirtualAccount(req CreateVaRequest) (*CreateVaResponse, error)
 	ValidateCallback(token string, notification payment.PaymentNotificationRequestBodyDTO) (payment.PaymentNotificationResponseBodyDTO, error)
 	CheckVAStatus(vaNumber string, customerNo string, partnerServiceId string, trxId string) (bool, error)
+	ClientID() string
+}
+
+// Config holds the SNAP credentials. The private key is read from
+// PrivateKeyFile when the client is initialized.
+type Config struct {
+	ClientID       string `env:"DOKU_CLIENT_ID"`
+	SecretKey      string `env:"DOKU_SECRET_KEY" secret:"true"`
+	PublicKey      string `env:"DOKU_PUBLIC_KEY"`
+	PrivateKeyFile string `env:"DOKU_PRIVATE_KEY_FILE" default:"private.key"`
+	IsProduction   bool   `env:"DOKU_IS_PRODUCTION"`
 }
 
 type dokuService struct {
 	client *doku.Snap
+	config Config
 	log    *logrus.Logger
 }
 
@@ -62,21 +73,27 @@ const (
 	BankDOKU     = "VIRTUAL_ACCOUNT_DOKU"
 )
 
-func NewDokuService(log *logrus.Logger) IDokuService {
+func NewDokuService(log *logrus.Logger, cfg Config) IDokuService {
 	return &dokuService{
-		log: log,
+		config: cfg,
+		log:    log,
 	}
 }
 
+// ClientID is the partner ID DOKU sends back in its callbacks.
+func (d *dokuService) ClientID() string {
+	return d.config.ClientID
+}
+
 func (d *dokuService) Init() error {
 	d.log.WithFields(logrus.Fields{
-		"client_id":     os.Getenv("DOKU_CLIENT_ID"),
-		"is_production": os.Getenv("DOKU_IS_PRODUCTION"),
+		"client_id":     d.config.ClientID,
+		"is_production": d.config.IsProduction,
 	}).Info("Initializing Doku client")
 
 	var privateKey string
 
-	privateKeyPEM, err := os.ReadFile("private.key")
+	privateKeyPEM, err := os.ReadFile(d.config.PrivateKeyFile)
 	if err != nil {
 		return fmt.Errorf("failed to read private key file: %v", err)
 	}
@@ -87,14 +104,11 @@ func (d *dokuService) Init() error {
 	}
 
 	d.client = &doku.Snap{
-		PrivateKey: privateKey,
-		PublicKey:  os.Getenv("DOKU_PUBLIC_KEY"),
-		ClientId:   os.Getenv("DOKU_CLIENT_ID"),
-		SecretKey:  os.Getenv("DOKU_SECRET_KEY"),
-		IsProduction: func() bool {
-			isProd, _ := strconv.ParseBool(os.Getenv("DOKU_IS_PRODUCTION"))
-			return isProd
-		}(),
+		PrivateKey:   privateKey,
+		PublicKey:    d.config.PublicKey,
+		ClientId:     d.config.ClientID,
+		SecretKey:    d.config.SecretKey,
+		IsProduction: d.config.IsProduction,
 	}
 
 	doku.TokenController = &controllers.TokenController{}
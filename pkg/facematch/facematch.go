@@ -0,0 +1,38 @@
+package facematch
+
+import (
+	"context"
+	"errors"
+)
+
+// DefaultThreshold is the minimum similarity, in percent, for two faces to be
+// considered the same person.
+const DefaultThreshold = 90.0
+
+const (
+	ProviderRekognition = "rekognition"
+	ProviderFake        = "fake"
+)
+
+// Config picks the matcher and the similarity, in percent, two faces need
+// to be taken as the same person.
+type Config struct {
+	Provider  string  `env:"FACE_MATCH_PROVIDER" default:"rekognition"`
+	Threshold float64 `env:"FACE_MATCH_THRESHOLD" default:"90"`
+}
+
+var (
+	ErrNoFace      = errors.New("no face detected in image")
+	ErrInvalidData = errors.New("image is empty or not supported")
+)
+
+type Result struct {
+	Similarity float64
+	Matched    bool
+}
+
+// IFaceMatcher compares the face on a reference document, such as the KTP
+// photo, with the face on a probe image such as a selfie.
+type IFaceMatcher interface {
+	Compare(ctx context.Context, reference []byte, probe []byte) (Result, error)
+}
@@ -0,0 +1,85 @@
+package facematch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"sync"
+)
+
+// FakeMatcher is a deterministic in-memory matcher for local development and
+// tests. Images enrolled to the same identity match with a similarity of 99,
+// images of different identities score 1, byte-identical images score 100 and
+// anything else scores defaultSimilarity. Images marked with SetNoFace fail
+// with ErrNoFace.
+type FakeMatcher struct {
+	threshold         float64
+	defaultSimilarity float64
+
+	mutex      sync.Mutex
+	identities map[[sha256.Size]byte]string
+	noFace     map[[sha256.Size]byte]bool
+}
+
+var _ IFaceMatcher = (*FakeMatcher)(nil)
+
+func NewFake(defaultSimilarity float64) *FakeMatcher {
+	return &FakeMatcher{
+		threshold:         DefaultThreshold,
+		defaultSimilarity: defaultSimilarity,
+		identities:        make(map[[sha256.Size]byte]string),
+		noFace:            make(map[[sha256.Size]byte]bool),
+	}
+}
+
+// Enroll records images as showing the face of identity.
+func (f *FakeMatcher) Enroll(identity string, images ...[]byte) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, image := range images {
+		f.identities[sha256.Sum256(image)] = identity
+	}
+}
+
+// SetNoFace makes Compare report that no face was found in image.
+func (f *FakeMatcher) SetNoFace(image []byte) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.noFace[sha256.Sum256(image)] = true
+}
+
+func (f *FakeMatcher) Compare(_ context.Context, reference []byte, probe []byte) (Result, error) {
+	if len(reference) == 0 || len(probe) == 0 {
+		return Result{}, ErrInvalidData
+	}
+
+	referenceSum := sha256.Sum256(reference)
+	probeSum := sha256.Sum256(probe)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.noFace[referenceSum] || f.noFace[probeSum] {
+		return Result{}, ErrNoFace
+	}
+
+	similarity := f.defaultSimilarity
+	referenceIdentity, referenceKnown := f.identities[referenceSum]
+	probeIdentity, probeKnown := f.identities[probeSum]
+
+	switch {
+	case bytes.Equal(reference, probe):
+		similarity = 100
+	case referenceKnown && probeKnown && referenceIdentity == probeIdentity:
+		similarity = 99
+	case referenceKnown && probeKnown:
+		similarity = 1
+	}
+
+	return Result{
+		Similarity: similarity,
+		Matched:    similarity >= f.threshold,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package facematch
+
+import (
+	awsPkg "ProjectGolang/pkg/aws"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rekognition"
+)
+
+// maxImageBytes is the largest image Rekognition accepts as raw bytes.
+const maxImageBytes = 5 * 1024 * 1024
+
+type rekognitionMatcher struct {
+	client    *rekognition.Rekognition
+	threshold float64
+}
+
+// New builds a matcher on AWS Rekognition CompareFaces using the same AWS
+// credentials as the S3 client.
+func New(cfg Config, credentials awsPkg.Config) (IFaceMatcher, error) {
+	if cfg.Threshold <= 0 || cfg.Threshold > 100 {
+		return nil, fmt.Errorf("invalid FACE_MATCH_THRESHOLD %v", cfg.Threshold)
+	}
+
+	sess, err := session.NewSession(credentials.SDKConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &rekognitionMatcher{
+		client:    rekognition.New(sess),
+		threshold: cfg.Threshold,
+	}, nil
+}
+
+func (m *rekognitionMatcher) Compare(ctx context.Context, reference []byte, probe []byte) (Result, error) {
+	if len(reference) == 0 || len(probe) == 0 || len(reference) > maxImageBytes || len(probe) > maxImageBytes {
+		return Result{}, ErrInvalidData
+	}
+
+	output, err := m.client.CompareFacesWithContext(ctx, &rekognition.CompareFacesInput{
+		SourceImage:         &rekognition.Image{Bytes: reference},
+		TargetImage:         &rekognition.Image{Bytes: probe},
+		SimilarityThreshold: aws.Float64(0),
+		QualityFilter:       aws.String(rekognition.QualityFilterAuto),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) {
+			switch awsErr.Code() {
+			case rekognition.ErrCodeInvalidParameterException:
+				return Result{}, ErrNoFace
+			case rekognition.ErrCodeInvalidImageFormatException, rekognition.ErrCodeImageTooLargeException:
+				return Result{}, ErrInvalidData
+			}
+		}
+		return Result{}, fmt.Errorf("compare faces: %w", err)
+	}
+
+	var similarity float64
+	for _, match := range output.FaceMatches {
+		if match.Similarity != nil && *match.Similarity > similarity {
+			similarity = *match.Similarity
+		}
+	}
+
+	return Result{
+		Similarity: similarity,
+		Matched:    similarity >= m.threshold,
+	}, nil
+}
@@ -0,0 +1,228 @@
+package gemini
+
+import (
+	contextPkg "ProjectGolang/pkg/context"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	retryBackoff    = 500 * time.Millisecond
+	maxLoggedLength = 2000
+)
+
+// ErrInvalidOutput is returned when Gemini keeps answering with output that
+// does not decode into, or does not validate as, the requested type.
+var ErrInvalidOutput = errors.New("gemini returned invalid output")
+
+// Validator is implemented by outputs with checks that span fields, on top of
+// the `validate` tags checked on every field.
+type Validator interface {
+	Validate() error
+}
+
+// Extractor turns Gemini answers into typed, validated Go values. Each call is
+// bounded by a timeout and retried with backoff when it fails; an answer that
+// does not decode strictly or fails validation is sent back to Gemini with
+// the problem, up to a number of re-prompts.
+type Extractor struct {
+	client    IGemini
+	log       *logrus.Logger
+	validate  *validator.Validate
+	timeout   time.Duration
+	retries   int
+	reprompts int
+}
+
+// NewExtractor bounds calls by cfg.TimeoutSeconds and retries and re-prompts
+// them as cfg allows.
+func NewExtractor(client IGemini, log *logrus.Logger, cfg Config) *Extractor {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _ := jsonName(field)
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
+	return &Extractor{
+		client:    client,
+		log:       log,
+		validate:  validate,
+		timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+		retries:   cfg.Retries,
+		reprompts: cfg.Reprompts,
+	}
+}
+
+// Extract runs prompt over the image and decodes the answer into out, which
+// must be a pointer to a struct. The response schema is derived from out.
+func (e *Extractor) Extract(ctx context.Context, prompt Prompt, base64Image string, out interface{}) error {
+	if e.client == nil {
+		return errors.New("gemini client not configured")
+	}
+
+	req := Request{
+		Prompt:      prompt,
+		Text:        prompt.Text,
+		Base64Image: base64Image,
+		Schema:      SchemaFor(reflect.TypeOf(out)),
+	}
+
+	var invalid error
+	for attempt := 0; attempt <= e.reprompts; attempt++ {
+		if invalid != nil {
+			req.Text = reprompt(prompt.Text, invalid)
+		}
+
+		raw, err := e.generate(ctx, req, attempt)
+		if err != nil {
+			return err
+		}
+
+		if invalid = e.decode(raw, out); invalid == nil {
+			return nil
+		}
+
+		e.log.WithFields(logrus.Fields{
+			"request_id": contextPkg.GetRequestID(ctx),
+			"prompt":     prompt.ID(),
+			"attempt":    attempt,
+			"error":      invalid.Error(),
+		}).Warn("Invalid Gemini output")
+	}
+
+	return fmt.Errorf("%w: %v", ErrInvalidOutput, invalid)
+}
+
+// generate makes one Gemini call, retrying failed calls with exponential
+// backoff.
+func (e *Extractor) generate(ctx context.Context, req Request, attempt int) (string, error) {
+	requestID := contextPkg.GetRequestID(ctx)
+
+	var err error
+	for try := 0; try <= e.retries; try++ {
+		if try > 0 {
+			backoff := retryBackoff << (try - 1)
+			backoff += time.Duration(rand.Int63n(int64(backoff) / 2))
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		e.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"prompt":     req.Prompt.ID(),
+			"attempt":    attempt,
+			"try":        try,
+			"text":       truncate(req.Text),
+		}).Debug("Sending Gemini prompt")
+
+		callCtx, cancel := context.WithTimeout(ctx, e.timeout)
+		start := time.Now()
+		var raw string
+		raw, err = e.client.GenerateJSON(callCtx, req)
+		cancel()
+
+		if err == nil {
+			e.log.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"prompt":     req.Prompt.ID(),
+				"attempt":    attempt,
+				"latency_ms": time.Since(start).Milliseconds(),
+				"response":   truncate(raw),
+			}).Debug("Received Gemini response")
+			return raw, nil
+		}
+
+		e.log.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"prompt":     req.Prompt.ID(),
+			"try":        try,
+			"error":      err.Error(),
+		}).Warn("Gemini call failed")
+
+		if errors.Is(err, ErrInvalidImage) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return "", err
+}
+
+// decode decodes raw strictly into out: one JSON value, no unknown fields,
+// every field valid.
+func (e *Extractor) decode(raw string, out interface{}) error {
+	// A previous attempt may have filled out before failing validation.
+	reflect.ValueOf(out).Elem().SetZero()
+
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		return fmt.Errorf("answer is not JSON matching the schema: %v", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("answer has data after the JSON object")
+	}
+
+	if err := e.validate.Struct(out); err != nil {
+		var fieldErrors validator.ValidationErrors
+		if errors.As(err, &fieldErrors) {
+			return describeFieldErrors(fieldErrors)
+		}
+		return err
+	}
+
+	if v, ok := out.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func describeFieldErrors(fieldErrors validator.ValidationErrors) error {
+	problems := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		// The namespace starts with the type name, which means nothing to
+		// Gemini.
+		_, field, _ := strings.Cut(fieldError.Namespace(), ".")
+		problem := fmt.Sprintf("%s fails %q", field, fieldError.Tag())
+		if fieldError.Param() != "" {
+			problem = fmt.Sprintf("%s fails %q (%s)", field, fieldError.Tag(), fieldError.Param())
+		}
+		problems = append(problems, problem)
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
+
+func reprompt(text string, invalid error) string {
+	var b bytes.Buffer
+	b.WriteString(text)
+	b.WriteString("\n\nJawaban sebelumnya tidak valid: ")
+	b.WriteString(invalid.Error())
+	b.WriteString(".\nPeriksa kembali gambar dan berikan jawaban JSON yang sesuai dengan skema.")
+	return b.String()
+}
+
+func truncate(text string) string {
+	if len(text) <= maxLoggedLength {
+		return text
+	}
+	return text[:maxLoggedLength] + "..."
+}
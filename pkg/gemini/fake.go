@@ -0,0 +1,164 @@
+package gemini
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//go:embed fixtures/*.json
+var embeddedFixtures embed.FS
+
+var ErrNoFixture = errors.New("no recorded Gemini response for prompt")
+
+// Fixture holds the recorded answers to one prompt, keyed by prompt ID. The
+// answers are replayed in order, so a fixture can start with invalid output
+// to exercise re-prompting; the last one repeats.
+type Fixture struct {
+	Prompt    string   `json:"prompt"`
+	Responses []string `json:"responses"`
+}
+
+// FakeGemini replays recorded answers instead of calling Gemini.
+type FakeGemini struct {
+	mu       sync.Mutex
+	fixtures map[string][]string
+	calls    map[string]int
+	requests []Request
+}
+
+// NewFake replays the fixtures shipped with this package.
+func NewFake() *FakeGemini {
+	f := &FakeGemini{
+		fixtures: make(map[string][]string),
+		calls:    make(map[string]int),
+	}
+	// The embedded fixtures are checked at build time by the embed pattern,
+	// so only a malformed file can fail here.
+	if err := f.load(embeddedFixtures, "fixtures"); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// LoadFixtures adds the fixtures in dir, such as those written by a
+// recorder, replacing embedded ones for the same prompts.
+func (f *FakeGemini) LoadFixtures(dir string) error {
+	return f.load(os.DirFS(dir), ".")
+}
+
+func (f *FakeGemini) load(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		raw, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return err
+		}
+
+		var fixture Fixture
+		if err := json.Unmarshal(raw, &fixture); err != nil {
+			return fmt.Errorf("fixture %s: %w", entry.Name(), err)
+		}
+		f.Add(fixture.Prompt, fixture.Responses...)
+	}
+
+	return nil
+}
+
+// Add replaces the answers to the prompt with ID prompt and restarts them.
+func (f *FakeGemini) Add(prompt string, responses ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fixtures[prompt] = responses
+	f.calls[prompt] = 0
+}
+
+// Requests returns the requests received so far.
+func (f *FakeGemini) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Request(nil), f.requests...)
+}
+
+func (f *FakeGemini) GenerateJSON(ctx context.Context, req Request) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, req)
+
+	id := req.Prompt.ID()
+	responses := f.fixtures[id]
+	if len(responses) == 0 {
+		return "", fmt.Errorf("%w %s", ErrNoFixture, id)
+	}
+
+	call := f.calls[id]
+	f.calls[id]++
+	return responses[min(call, len(responses)-1)], nil
+}
+
+// recorder passes calls through to a real client and appends every answer to
+// the fixture of its prompt in dir.
+type recorder struct {
+	client IGemini
+	dir    string
+	mu     sync.Mutex
+}
+
+// NewRecorder records the answers of client as fixtures in dir, for
+// FakeGemini.LoadFixtures to replay.
+func NewRecorder(client IGemini, dir string) (IGemini, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &recorder{client: client, dir: dir}, nil
+}
+
+func (r *recorder) GenerateJSON(ctx context.Context, req Request) (string, error) {
+	raw, err := r.client.GenerateJSON(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := req.Prompt.ID()
+	path := filepath.Join(r.dir, strings.ReplaceAll(id, "/", "_")+".json")
+
+	fixture := Fixture{Prompt: id}
+	if existing, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(existing, &fixture)
+	}
+	fixture.Responses = append(fixture.Responses, raw)
+
+	payload, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return raw, nil
+	}
+	// A fixture that cannot be written must not fail the request.
+	_ = os.WriteFile(path, payload, 0o644)
+
+	return raw, nil
+}
@@ -4,14 +4,29 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
-	"os"
+	"net/http"
+	"strings"
 
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/option"
 )
 
+var ErrInvalidImage = errors.New("invalid base64 image data")
+
 type IGemini interface {
-	AnalyzeImage(ctx context.Context, base64Image string, prompt string) (string, error)
+	// GenerateJSON runs the prompt of req over its image. The answer is
+	// constrained to JSON matching req.Schema but not checked against it; use
+	// an Extractor for that.
+	GenerateJSON(ctx context.Context, req Request) (string, error)
+}
+
+// Request is a single call to Gemini. Text is the prompt as sent, which is
+// Prompt.Text plus any corrections when a previous answer was invalid.
+type Request struct {
+	Prompt      Prompt
+	Text        string
+	Base64Image string
+	Schema      *genai.Schema
 }
 
 type geminiClient struct {
@@ -20,60 +35,79 @@ type geminiClient struct {
 	client    *genai.Client
 }
 
-func NewGeminiClient() (IGemini, error) {
+const (
+	ProviderGemini = "gemini"
+	ProviderFake   = "fake"
+)
 
-	apiKey := os.Getenv("GEMINI_API_KEY")
+// Config picks the client and the model it asks. With the fake provider
+// recorded answers are replayed, adding any fixtures in FixturesDir; with
+// RecordDir set the answers of the real client are recorded there as
+// fixtures. TimeoutSeconds, Retries and Reprompts tune the Extractor.
+type Config struct {
+	Provider       string `env:"GEMINI_PROVIDER" default:"gemini"`
+	APIKey         string `env:"GEMINI_API_KEY" secret:"true"`
+	ModelName      string `env:"GEMINI_MODEL_NAME" default:"gemini-1.5-flash"`
+	FixturesDir    string `env:"GEMINI_FIXTURES_DIR"`
+	RecordDir      string `env:"GEMINI_RECORD_DIR"`
+	TimeoutSeconds int    `env:"GEMINI_TIMEOUT_SECONDS" default:"15"`
+	Retries        int    `env:"GEMINI_RETRIES" default:"2"`
+	Reprompts      int    `env:"GEMINI_REPROMPTS" default:"1"`
+}
 
-	modelName := os.Getenv("GEMINI_MODEL_NAME")
-	if apiKey == "" {
+func NewGeminiClient(cfg Config) (IGemini, error) {
+	if cfg.APIKey == "" {
 		return nil, errors.New("gemini API key is required")
 	}
 
-	if modelName == "" {
-		modelName = "gemini-pro-vision"
-	}
-
-	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(cfg.APIKey))
 	if err != nil {
 		return nil, err
 	}
 
 	return &geminiClient{
-		apiKey:    apiKey,
-		modelName: modelName,
+		apiKey:    cfg.APIKey,
+		modelName: cfg.ModelName,
 		client:    client,
 	}, nil
 }
 
-func (g *geminiClient) AnalyzeImage(ctx context.Context, base64Image string, prompt string) (string, error) {
-	imgData, err := base64.StdEncoding.DecodeString(base64Image)
+func (g *geminiClient) GenerateJSON(ctx context.Context, req Request) (string, error) {
+	imgData, err := base64.StdEncoding.DecodeString(req.Base64Image)
 	if err != nil {
-		return "", errors.New("invalid base64 image data")
+		return "", ErrInvalidImage
 	}
 
 	model := g.client.GenerativeModel(g.modelName)
-
-	if prompt == "" {
-		prompt = "Analyze this image and provide details in JSON format."
+	model.SetTemperature(0)
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = req.Schema
+
+	// ImageData takes the subtype only, e.g. "jpeg" for image/jpeg.
+	format := strings.TrimPrefix(http.DetectContentType(imgData), "image/")
+	if strings.Contains(format, "/") {
+		format = "jpeg"
 	}
 
-	img := genai.ImageData("image/jpeg", imgData)
-	res, err := model.GenerateContent(ctx, genai.Text(prompt), img)
+	res, err := model.GenerateContent(ctx, genai.Text(req.Text), genai.ImageData(format, imgData))
 	if err != nil {
 		return "", err
 	}
 
-	if len(res.Candidates) == 0 || len(res.Candidates[0].Content.Parts) == 0 {
+	if len(res.Candidates) == 0 || res.Candidates[0].Content == nil || len(res.Candidates[0].Content.Parts) == 0 {
 		return "", errors.New("no response from Gemini API")
 	}
 
-	response := res.Candidates[0].Content.Parts[0]
-	text, ok := response.(genai.Text)
-	if !ok {
-		return "", errors.New("unexpected response format from Gemini API")
+	var text strings.Builder
+	for _, part := range res.Candidates[0].Content.Parts {
+		t, ok := part.(genai.Text)
+		if !ok {
+			return "", errors.New("unexpected response format from Gemini API")
+		}
+		text.WriteString(string(t))
 	}
 
-	return string(text), nil
+	return text.String(), nil
 }
 
 func (g *geminiClient) Close() {
@@ -0,0 +1,16 @@
+package gemini
+
+import "fmt"
+
+// Prompt is a versioned prompt template. Change the version whenever the text
+// or the output it asks for changes, so logs and fixtures can be traced back
+// to the prompt that produced them.
+type Prompt struct {
+	Name    string
+	Version int
+	Text    string
+}
+
+func (p Prompt) ID() string {
+	return fmt.Sprintf("%s/v%d", p.Name, p.Version)
+}
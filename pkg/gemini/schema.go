@@ -0,0 +1,90 @@
+package gemini
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// SchemaFor derives the response schema Gemini is constrained to from the Go
+// type its answer is decoded into. Properties are named by their json tags;
+// fields without omitempty are required, pointers are nullable, a
+// `description` tag describes the field and a `validate:"oneof=..."` tag on a
+// string becomes an enum.
+func SchemaFor(t reflect.Type) *genai.Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &genai.Schema{
+			Type:       genai.TypeObject,
+			Properties: make(map[string]*genai.Schema),
+		}
+		addFields(schema, t)
+		return schema
+	case reflect.Slice, reflect.Array:
+		return &genai.Schema{Type: genai.TypeArray, Items: SchemaFor(t.Elem())}
+	case reflect.String:
+		return &genai.Schema{Type: genai.TypeString}
+	case reflect.Bool:
+		return &genai.Schema{Type: genai.TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &genai.Schema{Type: genai.TypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &genai.Schema{Type: genai.TypeNumber}
+	default:
+		return &genai.Schema{Type: genai.TypeString}
+	}
+}
+
+func addFields(schema *genai.Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitempty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addFields(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := SchemaFor(field.Type)
+		property.Nullable = field.Type.Kind() == reflect.Pointer
+		property.Description = field.Tag.Get("description")
+		if property.Type == genai.TypeString {
+			property.Enum = oneOf(field.Tag.Get("validate"))
+		}
+
+		schema.Properties[name] = property
+		if !omitempty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	name, options, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(options, "omitempty")
+}
+
+func oneOf(validate string) []string {
+	for _, rule := range strings.Split(validate, ",") {
+		if values, ok := strings.CutPrefix(rule, "oneof="); ok {
+			return strings.Fields(values)
+		}
+	}
+	return nil
+}
@@ -1,64 +1,87 @@
 package google
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"github.com/gofiber/fiber/v2"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
-	"io"
-	"net/http"
-	"net/url"
-	"os"
 )
 
+var (
+	ErrMissingIDToken = errors.New("token response has no id_token")
+	ErrInvalidIDToken = errors.New("invalid id_token")
+)
+
+// IDTokenClaims are the OpenID Connect claims the application relies on.
+type IDTokenClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+	Nonce         string
+}
+
 type ItfGoogle interface {
-	GetUserExchangeToken(c *fiber.Ctx, code string) ([]byte, error)
 	GetConfig() *oauth2.Config
+	AuthCodeURL(state string, codeVerifier string, nonce string) string
+	ExchangeIDToken(ctx context.Context, code string, codeVerifier string) (IDTokenClaims, error)
 }
 
 type googleProvider struct {
-	config *oauth2.Config
+	config   *oauth2.Config
+	verifier *idTokenVerifier
+}
+
+// Config is the OAuth client registered with Google.
+type Config struct {
+	ClientID     string `env:"GOOGLE_CLIENT_ID"`
+	ClientSecret string `env:"GOOGLE_CLIENT_SECRET" secret:"true"`
+	RedirectURL  string `env:"GOOGLE_REDIRECT_URL" default:"http://localhost:8080/api/v1/auth/callback-gl"`
 }
 
-func New() ItfGoogle {
+func New(cfg Config) ItfGoogle {
 	oauthConfgl := &oauth2.Config{
-		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-		RedirectURL:  "http://localhost:8080/api/v1/auth/callback-gl",
-		Scopes:       []string{"https://www.googleapis.com/auth/userinfo.email"},
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
 		Endpoint:     google.Endpoint,
 	}
 
-	return &googleProvider{config: oauthConfgl}
+	return &googleProvider{
+		config:   oauthConfgl,
+		verifier: newIDTokenVerifier(oauthConfgl.ClientID),
+	}
 }
 
-func (g *googleProvider) GetUserExchangeToken(c *fiber.Ctx, code string) ([]byte, error) {
-	token, err := g.config.Exchange(c.Context(), code)
-	if err != nil {
-		fmt.Printf("Error exchanging token: %v", err)
-		return nil, err
-	}
+func (g *googleProvider) GetConfig() *oauth2.Config {
+	return g.config
+}
 
-	resp, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + url.QueryEscape(token.AccessToken))
-	if err != nil {
-		fmt.Printf("Error getting user info: %v", err)
-		return nil, err
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Printf("Error closing body: %v", err)
-		}
-	}(resp.Body)
+// AuthCodeURL builds the consent URL for an authorization code flow protected
+// by state, PKCE (S256) and an OpenID nonce.
+func (g *googleProvider) AuthCodeURL(state string, codeVerifier string, nonce string) string {
+	return g.config.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(codeVerifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("prompt", "select_account"),
+	)
+}
 
-	response, err := io.ReadAll(resp.Body)
+// ExchangeIDToken redeems the authorization code with the PKCE verifier and
+// returns the claims of the verified ID token.
+func (g *googleProvider) ExchangeIDToken(ctx context.Context, code string, codeVerifier string) (IDTokenClaims, error) {
+	token, err := g.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
 	if err != nil {
-		return nil, err
+		return IDTokenClaims{}, fmt.Errorf("exchange authorization code: %w", err)
 	}
 
-	return response, nil
-}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return IDTokenClaims{}, ErrMissingIDToken
+	}
 
-func (g *googleProvider) GetConfig() *oauth2.Config {
-	return g.config
+	return g.verifier.verify(ctx, rawIDToken)
 }
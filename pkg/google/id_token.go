@@ -0,0 +1,173 @@
+package google
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	googleCertsURL = "https://www.googleapis.com/oauth2/v3/certs"
+	jwksCacheTTL   = time.Hour
+)
+
+var googleIssuers = map[string]bool{
+	"accounts.google.com":         true,
+	"https://accounts.google.com": true,
+}
+
+type idTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// idTokenVerifier checks Google ID tokens against the published JWKS, which is
+// cached for jwksCacheTTL and refreshed early when an unknown key ID shows up.
+type idTokenVerifier struct {
+	clientID   string
+	certsURL   string
+	httpClient *http.Client
+
+	mutex     sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func newIDTokenVerifier(clientID string) *idTokenVerifier {
+	return &idTokenVerifier{
+		clientID:   clientID,
+		certsURL:   googleCertsURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (v *idTokenVerifier) verify(ctx context.Context, rawIDToken string) (IDTokenClaims, error) {
+	var claims idTokenClaims
+
+	_, err := jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithAudience(v.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return IDTokenClaims{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if !googleIssuers[claims.Issuer] {
+		return IDTokenClaims{}, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	}
+
+	if claims.Subject == "" {
+		return IDTokenClaims{}, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	return IDTokenClaims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+		Nonce:         claims.Nonce,
+	}, nil
+}
+
+func (v *idTokenVerifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if key, ok := v.keys[kid]; ok && time.Since(v.fetchedAt) < jwksCacheTTL {
+		return key, nil
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v.keys = keys
+	v.fetchedAt = time.Now()
+
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	return key, nil
+}
+
+func (v *idTokenVerifier) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.certsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch google certs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch google certs: status %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("decode google certs: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+
+		key, err := parseRSAKey(k)
+		if err != nil {
+			return nil, err
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func parseRSAKey(k jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus of key %q: %w", k.Kid, err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent of key %q: %w", k.Kid, err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
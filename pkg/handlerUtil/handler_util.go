@@ -207,18 +207,6 @@ func (h *ErrorHandler) Handle(c *fiber.Ctx, requestID string, err error, path st
 		})
 	}
 
-	if errors.Is(err, budget_manager.ErrTransactionNotOwned) {
-		h.logger.WithFields(log.Fields{
-			"request_id": requestID,
-			"error":      err.Error(),
-			"path":       path,
-			"operation":  operation,
-		}).Warn("Transaction does not belong to user")
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Transaction does not belong to user",
-		})
-	}
-
 	if errors.Is(err, budget_manager.ErrInvalidTransactionType) {
 		h.logger.WithFields(log.Fields{
 			"request_id": requestID,
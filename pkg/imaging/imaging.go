@@ -0,0 +1,122 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+var (
+	ErrInvalidImage  = errors.New("file is not a valid JPEG or PNG image")
+	ErrImageTooLarge = errors.New("image dimensions exceed the limit")
+)
+
+const (
+	// maxPixels and maxSide bound what is decoded, judged from the header
+	// before any pixel is, so a small file cannot expand into gigabytes.
+	maxPixels = 40_000_000
+	maxSide   = 12_000
+
+	jpegQuality = 85
+)
+
+// Size is a standard size photos are stored in, fitting within MaxSide
+// pixels on the longer side.
+type Size struct {
+	Name    string
+	MaxSide int
+}
+
+var (
+	Full      = Size{Name: "full", MaxSide: 1600}
+	Thumbnail = Size{Name: "thumbnail", MaxSide: 256}
+
+	// PhotoSizes are the sizes user photos are kept in, largest first.
+	PhotoSizes = []Size{Full, Thumbnail}
+)
+
+// Variant is an image encoded at one size.
+type Variant struct {
+	Size   Size
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Process decodes an uploaded photo, turns it upright according to its EXIF
+// orientation and encodes it as JPEG at each size. Images are never scaled
+// up. Nothing of the original file but its pixels survives, so metadata
+// such as EXIF and GPS tags is dropped.
+func Process(r io.Reader, sizes ...Size) ([]Variant, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return nil, ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if config.Width > maxSide || config.Height > maxSide || config.Width*config.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	// JPEG has no alpha, so transparent areas are flattened onto white.
+	img := image.NewRGBA(image.Rect(0, 0, config.Width, config.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(img, img.Bounds(), decoded, decoded.Bounds().Min, draw.Over)
+
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+
+	variants := make([]Variant, 0, len(sizes))
+	for _, size := range sizes {
+		width, height := fit(img.Bounds().Dx(), img.Bounds().Dy(), size.MaxSide)
+
+		resized := img
+		if width != img.Bounds().Dx() || height != img.Bounds().Dy() {
+			resized = resize(img, width, height)
+		}
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, Variant{
+			Size:   size,
+			Data:   buf.Bytes(),
+			Width:  width,
+			Height: height,
+		})
+	}
+
+	return variants, nil
+}
+
+// fit scales width and height down to fit within maxSide, keeping the
+// aspect ratio.
+func fit(width int, height int, maxSide int) (int, int) {
+	if width <= maxSide && height <= maxSide {
+		return width, height
+	}
+
+	if width >= height {
+		return maxSide, max(1, height*maxSide/width)
+	}
+	return max(1, width*maxSide/height), maxSide
+}
@@ -0,0 +1,127 @@
+package imaging
+
+import (
+	"image"
+)
+
+// orient turns an image upright given its EXIF orientation. Orientations 5
+// to 8 swap the width and height.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			// sx, sy is the pixel of the stored image shown at x, y.
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+
+			si := src.PixOffset(sx+src.Rect.Min.X, sy+src.Rect.Min.Y)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
+
+// resize scales an image down to width by height, averaging the source
+// pixels each destination pixel covers. It is done one axis at a time.
+func resize(src *image.RGBA, width int, height int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+
+	// Rows are scaled first into floats, then columns into the result.
+	horizontal := make([]float32, width*sh*4)
+	xWeights := weights(sw, width)
+	for y := 0; y < sh; y++ {
+		row := src.Pix[src.PixOffset(src.Rect.Min.X, src.Rect.Min.Y+y):]
+		for x, ws := range xWeights {
+			var r, g, b, a float32
+			for _, w := range ws {
+				p := row[w.index*4:]
+				r += float32(p[0]) * w.weight
+				g += float32(p[1]) * w.weight
+				b += float32(p[2]) * w.weight
+				a += float32(p[3]) * w.weight
+			}
+			o := (y*width + x) * 4
+			horizontal[o], horizontal[o+1], horizontal[o+2], horizontal[o+3] = r, g, b, a
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	yWeights := weights(sh, height)
+	for y, ws := range yWeights {
+		for x := 0; x < width; x++ {
+			var r, g, b, a float32
+			for _, w := range ws {
+				p := horizontal[(w.index*width+x)*4:]
+				r += p[0] * w.weight
+				g += p[1] * w.weight
+				b += p[2] * w.weight
+				a += p[3] * w.weight
+			}
+			o := dst.PixOffset(x, y)
+			dst.Pix[o], dst.Pix[o+1], dst.Pix[o+2], dst.Pix[o+3] = clamp(r), clamp(g), clamp(b), clamp(a)
+		}
+	}
+
+	return dst
+}
+
+type weight struct {
+	index  int
+	weight float32
+}
+
+// weights lists, for each of the to destination pixels along an axis, the
+// source pixels it covers and how much of each.
+func weights(from int, to int) [][]weight {
+	scale := float64(from) / float64(to)
+	result := make([][]weight, to)
+
+	for i := range result {
+		start, end := float64(i)*scale, float64(i+1)*scale
+		for j := int(start); j < from && float64(j) < end; j++ {
+			covered := min(end, float64(j+1)) - max(start, float64(j))
+			if covered > 0 {
+				result[i] = append(result[i], weight{index: j, weight: float32(covered / scale)})
+			}
+		}
+	}
+
+	return result
+}
+
+func clamp(v float32) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
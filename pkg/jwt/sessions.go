@@ -0,0 +1,58 @@
+package jwtPkg
+
+import (
+	"ProjectGolang/pkg/redis"
+	"context"
+	"errors"
+	goredis "github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// revocationTTL keeps a revocation for longer than any access token lives.
+const revocationTTL = 24 * time.Hour
+
+// ISessions revokes access tokens before they expire, for when a user is
+// locked or changes role and the claims in their tokens no longer hold.
+type ISessions interface {
+	// RevokeAll revokes every token issued to userID so far.
+	RevokeAll(ctx context.Context, userID string) error
+	// IsRevoked reports whether a token issued to userID at issuedAt has been
+	// revoked.
+	IsRevoked(ctx context.Context, userID string, issuedAt time.Time) (bool, error)
+}
+
+type sessions struct {
+	redisServer redis.IRedis
+}
+
+func NewSessions(redisServer redis.IRedis) ISessions {
+	return &sessions{redisServer: redisServer}
+}
+
+func (s *sessions) RevokeAll(ctx context.Context, userID string) error {
+	revokedAt := strconv.FormatInt(time.Now().Unix(), 10)
+	return s.redisServer.SetOTP(ctx, revokedKey(userID), revokedAt, revocationTTL)
+}
+
+// IsRevoked compares whole seconds, as in the iat claim, so a token issued in
+// the same second as a revocation counts as revoked.
+func (s *sessions) IsRevoked(ctx context.Context, userID string, issuedAt time.Time) (bool, error) {
+	raw, err := s.redisServer.GetOTP(ctx, revokedKey(userID))
+	if errors.Is(err, goredis.Nil) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	revokedAt, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return false, err
+	}
+
+	return issuedAt.Unix() <= revokedAt, nil
+}
+
+func revokedKey(userID string) string {
+	return "session:revoked:" + userID
+}
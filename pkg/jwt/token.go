@@ -7,21 +7,40 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/sirupsen/logrus"
-	"os"
 	"strings"
 	"time"
 )
 
-func Sign(Data map[string]interface{}, ExpiredAt time.Duration) (string, int64, error) {
-	expiredAt := time.Now().Add(ExpiredAt).Unix()
+// Config holds the secret access tokens are signed with.
+type Config struct {
+	AccessTokenSecret string `env:"JWT_ACCESS_TOKEN_SECRET" secret:"true"`
+}
+
+type IJWT interface {
+	Sign(Data map[string]interface{}, ExpiredAt time.Duration) (string, int64, error)
+	VerifyTokenHeader(c *fiber.Ctx) (*jwt.Token, error)
+	VerifyToken(accessToken string) (*jwt.Token, error)
+}
+
+type jwtTokens struct {
+	secret string
+}
+
+func New(cfg Config) IJWT {
+	return &jwtTokens{secret: cfg.AccessTokenSecret}
+}
+
+func (j *jwtTokens) Sign(Data map[string]interface{}, ExpiredAt time.Duration) (string, int64, error) {
+	now := time.Now()
+	expiredAt := now.Add(ExpiredAt).Unix()
 
-	JWTSecretKey := os.Getenv("JWT_ACCESS_TOKEN_SECRET")
-	if JWTSecretKey == "" {
+	if j.secret == "" {
 		return "", 0, fmt.Errorf("JWT_ACCESS_TOKEN_SECRET not set")
 	}
 
 	claims := jwt.MapClaims{}
 	claims["exp"] = expiredAt
+	claims["iat"] = now.Unix()
 	claims["authorization"] = true
 
 	for i, v := range Data {
@@ -31,7 +50,7 @@ func Sign(Data map[string]interface{}, ExpiredAt time.Duration) (string, int64,
 	logrus.WithField("claims", claims).Debug("Creating token with claims")
 
 	to := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, err := to.SignedString([]byte(JWTSecretKey))
+	accessToken, err := to.SignedString([]byte(j.secret))
 	if err != nil {
 		logrus.WithError(err).Error("Failed to sign token")
 		return "", 0, err
@@ -40,7 +59,7 @@ func Sign(Data map[string]interface{}, ExpiredAt time.Duration) (string, int64,
 	return accessToken, expiredAt, nil
 }
 
-func VerifyTokenHeader(c *fiber.Ctx, secretEnvKey string) (*jwt.Token, error) {
+func (j *jwtTokens) VerifyTokenHeader(c *fiber.Ctx) (*jwt.Token, error) {
 	log := logrus.WithField("func", "VerifyTokenHeader")
 
 	header := c.Get("Authorization")
@@ -65,8 +84,20 @@ func VerifyTokenHeader(c *fiber.Ctx, secretEnvKey string) (*jwt.Token, error) {
 
 	log.Debug("Token format valid, attempting to parse")
 
-	JWTSecretKey := os.Getenv(secretEnvKey)
-	if JWTSecretKey == "" {
+	return j.VerifyToken(accessToken)
+}
+
+// VerifyToken parses accessToken and checks its signature, for tokens that do
+// not arrive in the Authorization header.
+func (j *jwtTokens) VerifyToken(accessToken string) (*jwt.Token, error) {
+	log := logrus.WithField("func", "VerifyToken")
+
+	if accessToken == "" {
+		log.Error("Empty token")
+		return nil, errors.New("empty token")
+	}
+
+	if j.secret == "" {
 		log.Error("JWT_ACCESS_TOKEN_SECRET environment variable not set")
 		return nil, errors.New("JWT secret not configured")
 	}
@@ -76,7 +107,7 @@ func VerifyTokenHeader(c *fiber.Ctx, secretEnvKey string) (*jwt.Token, error) {
 			log.WithField("method", token.Header["alg"]).Error("Unexpected signing method")
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(JWTSecretKey), nil
+		return []byte(j.secret), nil
 	})
 
 	if err != nil {
@@ -0,0 +1,229 @@
+package liveness
+
+import (
+	"ProjectGolang/internal/entity"
+	"crypto/rand"
+	"math/big"
+	"time"
+)
+
+type Challenge string
+
+const (
+	ChallengeBlink     Challenge = "blink"
+	ChallengeTurnLeft  Challenge = "turn_left"
+	ChallengeTurnRight Challenge = "turn_right"
+	ChallengeSmile     Challenge = "smile"
+)
+
+var allChallenges = []Challenge{ChallengeBlink, ChallengeTurnLeft, ChallengeTurnRight, ChallengeSmile}
+
+var challengeInstructions = map[Challenge]string{
+	ChallengeBlink:     "BLINK",
+	ChallengeTurnLeft:  "TURN_HEAD_LEFT",
+	ChallengeTurnRight: "TURN_HEAD_RIGHT",
+	ChallengeSmile:     "SMILE",
+}
+
+type Status string
+
+const (
+	StatusWaiting    Status = "waiting_for_position"
+	StatusInProgress Status = "in_progress"
+	StatusPassed     Status = "passed"
+	StatusFailed     Status = "failed"
+)
+
+const (
+	// ChallengeCount is how many challenges a session asks for.
+	ChallengeCount = 3
+
+	challengeTimeout = 10 * time.Second
+	sessionTimeout   = 60 * time.Second
+
+	// perfectPosition is the framing status the face service reports once the
+	// face is centred; challenges only start from a well-framed face.
+	perfectPosition = "PERFECT_POSITION"
+
+	eyesOpenThreshold   = 0.6
+	eyesClosedThreshold = 0.25
+	headCentredYaw      = 10.0
+	headTurnedYaw       = 25.0
+	neutralThreshold    = 0.3
+	smileThreshold      = 0.7
+	smileFrames         = 2
+)
+
+type Progress struct {
+	Status      Status    `json:"status"`
+	Challenge   Challenge `json:"challenge,omitempty"`
+	Instruction string    `json:"instruction,omitempty"`
+	Completed   int       `json:"completed"`
+	Total       int       `json:"total"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+// Session runs an active liveness check over the frames of one face capture.
+// Each challenge has to be observed as a change between consecutive frames,
+// e.g. open eyes, then closed, then open again, which a printed photo or a
+// still image held up to the camera cannot produce.
+//
+// The signals come from the face service: eye_openness and smile_score in
+// [0,1] and head_yaw in degrees, negative when the user turns to their left.
+// Frames without the signal a challenge needs never complete it.
+type Session struct {
+	challenges []Challenge
+	current    int
+	phase      int
+	streak     int
+
+	startedAt          time.Time
+	challengeStartedAt time.Time
+	status             Status
+	reason             string
+}
+
+// NewSession starts a session with ChallengeCount distinct challenges in a
+// random order so a recording of a previous session cannot be replayed.
+func NewSession() *Session {
+	pool := append([]Challenge(nil), allChallenges...)
+	for i := len(pool) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			continue
+		}
+		j := int(n.Int64())
+		pool[i], pool[j] = pool[j], pool[i]
+	}
+
+	return NewSessionWithChallenges(pool[:ChallengeCount]...)
+}
+
+// NewSessionWithChallenges starts a session with a fixed challenge sequence.
+func NewSessionWithChallenges(challenges ...Challenge) *Session {
+	return &Session{
+		challenges: challenges,
+		status:     StatusWaiting,
+	}
+}
+
+func (s *Session) Challenges() []Challenge {
+	return append([]Challenge(nil), s.challenges...)
+}
+
+func (s *Session) Status() Status {
+	return s.status
+}
+
+// Observe feeds the next frame's detection result into the session and
+// returns the progress after it.
+func (s *Session) Observe(result entity.DetectionResult, at time.Time) Progress {
+	switch s.status {
+	case StatusPassed, StatusFailed:
+		return s.progress()
+	case StatusWaiting:
+		if result.FacePosition == nil || result.Status != perfectPosition {
+			return s.progress()
+		}
+		s.status = StatusInProgress
+		s.startedAt = at
+		s.challengeStartedAt = at
+	}
+
+	if at.Sub(s.startedAt) > sessionTimeout {
+		return s.fail("liveness check timed out")
+	}
+	if at.Sub(s.challengeStartedAt) > challengeTimeout {
+		return s.fail("challenge " + string(s.challenges[s.current]) + " was not completed in time")
+	}
+
+	if result.FacePosition == nil {
+		s.phase, s.streak = 0, 0
+		return s.progress()
+	}
+
+	if s.advance(s.challenges[s.current], result) {
+		s.current++
+		s.phase, s.streak = 0, 0
+		s.challengeStartedAt = at
+		if s.current == len(s.challenges) {
+			s.status = StatusPassed
+		}
+	}
+
+	return s.progress()
+}
+
+// advance moves the current challenge through its phases and reports whether
+// it has been completed.
+func (s *Session) advance(challenge Challenge, result entity.DetectionResult) bool {
+	switch challenge {
+	case ChallengeBlink:
+		if result.EyeOpenness == nil {
+			return false
+		}
+		eyes := *result.EyeOpenness
+		switch {
+		case s.phase == 0 && eyes >= eyesOpenThreshold:
+			s.phase = 1
+		case s.phase == 1 && eyes <= eyesClosedThreshold:
+			s.phase = 2
+		case s.phase == 2 && eyes >= eyesOpenThreshold:
+			return true
+		}
+	case ChallengeTurnLeft, ChallengeTurnRight:
+		if result.HeadYaw == nil {
+			return false
+		}
+		yaw := *result.HeadYaw
+		if challenge == ChallengeTurnRight {
+			yaw = -yaw
+		}
+		switch {
+		case s.phase == 0 && yaw >= -headCentredYaw && yaw <= headCentredYaw:
+			s.phase = 1
+		case s.phase == 1 && yaw <= -headTurnedYaw:
+			return true
+		}
+	case ChallengeSmile:
+		if result.SmileScore == nil {
+			return false
+		}
+		smile := *result.SmileScore
+		switch {
+		case s.phase == 0 && smile <= neutralThreshold:
+			s.phase = 1
+		case s.phase == 1 && smile >= smileThreshold:
+			s.streak++
+			if s.streak >= smileFrames {
+				return true
+			}
+		case s.phase == 1:
+			s.streak = 0
+		}
+	}
+
+	return false
+}
+
+func (s *Session) fail(reason string) Progress {
+	s.status = StatusFailed
+	s.reason = reason
+	return s.progress()
+}
+
+func (s *Session) progress() Progress {
+	progress := Progress{
+		Status:    s.status,
+		Completed: s.current,
+		Total:     len(s.challenges),
+		Reason:    s.reason,
+	}
+
+	if s.status == StatusInProgress && s.current < len(s.challenges) {
+		progress.Challenge = s.challenges[s.current]
+		progress.Instruction = challengeInstructions[progress.Challenge]
+	}
+
+	return progress
+}
@@ -0,0 +1,158 @@
+package liveness
+
+import (
+	"ProjectGolang/pkg/redis"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+)
+
+const (
+	tokenTTL    = 5 * time.Minute
+	tokenIssuer = "liveness"
+)
+
+var (
+	ErrSecretNotConfigured = errors.New("LIVENESS_TOKEN_SECRET not set")
+	ErrInvalidToken        = errors.New("invalid liveness token")
+	ErrTokenUsed           = errors.New("liveness token already used")
+	ErrSubjectRequired     = errors.New("liveness token needs a subject")
+	ErrSubjectMismatch     = errors.New("liveness token belongs to another user")
+	ErrImageMismatch       = errors.New("image is not the frame that passed the liveness check")
+)
+
+// Claims is the signed liveness result. FrameSHA256 is the hash of the frame
+// that completed the last challenge; the photo submitted afterwards has to be
+// exactly that frame.
+type Claims struct {
+	FrameSHA256 string      `json:"frame_sha256"`
+	Challenges  []Challenge `json:"challenges"`
+	jwt.RegisteredClaims
+}
+
+type ITokens interface {
+	Issue(subject string, frame []byte, challenges []Challenge) (string, time.Time, error)
+	Verify(token string, subject string) (Claims, error)
+	Redeem(ctx context.Context, token string, subject string, image []byte) (Claims, error)
+}
+
+type tokens struct {
+	secret      []byte
+	redisServer redis.IRedis
+}
+
+// Config holds the secret liveness results are signed with.
+type Config struct {
+	TokenSecret string `env:"LIVENESS_TOKEN_SECRET" secret:"true"`
+}
+
+// NewTokens signs liveness results with cfg.TokenSecret and uses Redis to
+// make every token single-use.
+func NewTokens(cfg Config, redisServer redis.IRedis) ITokens {
+	return &tokens{
+		secret:      []byte(cfg.TokenSecret),
+		redisServer: redisServer,
+	}
+}
+
+func FrameHash(frame []byte) string {
+	sum := sha256.Sum256(frame)
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue signs the liveness result for subject, the user who completed the
+// challenges. A result that belongs to nobody is never issued.
+func (t *tokens) Issue(subject string, frame []byte, challenges []Challenge) (string, time.Time, error) {
+	if len(t.secret) == 0 {
+		return "", time.Time{}, ErrSecretNotConfigured
+	}
+	if subject == "" {
+		return "", time.Time{}, ErrSubjectRequired
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(tokenTTL)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		FrameSHA256: FrameHash(frame),
+		Challenges:  challenges,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(id),
+			Issuer:    tokenIssuer,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	signed, err := token.SignedString(t.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
+}
+
+// Verify checks the signature of token and that it was issued to subject.
+// Tokens without a subject are rejected.
+func (t *tokens) Verify(token string, subject string) (Claims, error) {
+	if len(t.secret) == 0 {
+		return Claims{}, ErrSecretNotConfigured
+	}
+	if subject == "" {
+		return Claims{}, ErrSubjectRequired
+	}
+
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return t.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.ID == "" || claims.FrameSHA256 == "" || claims.Subject == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	if claims.Subject != subject {
+		return Claims{}, ErrSubjectMismatch
+	}
+
+	return claims, nil
+}
+
+// Redeem verifies token for subject and image and marks it as used.
+func (t *tokens) Redeem(ctx context.Context, token string, subject string, image []byte) (Claims, error) {
+	claims, err := t.Verify(token, subject)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if FrameHash(image) != claims.FrameSHA256 {
+		return Claims{}, ErrImageMismatch
+	}
+
+	uses, err := t.redisServer.Incr(ctx, "liveness:used:"+claims.ID, time.Until(claims.ExpiresAt.Time)+time.Minute)
+	if err != nil {
+		return Claims{}, err
+	}
+	if uses > 1 {
+		return Claims{}, ErrTokenUsed
+	}
+
+	return claims, nil
+}
@@ -26,10 +26,23 @@ const RequestIDKey = "request_id"
 
 type Fields = logrus.Fields
 
-func NewLogger() *logrus.Logger {
+// Config sets how much is logged and where. Logs go to stderr and, unless
+// Dir is empty, to a daily file in Dir.
+type Config struct {
+	Level string `env:"LOG_LEVEL" default:"debug"`
+	Dir   string `env:"LOG_DIR" default:"./storage/logs"`
+}
+
+// NewLogger sets up the shared logger the first time it is called; later
+// calls return it as it is.
+func NewLogger(cfg Config) *logrus.Logger {
 	once.Do(func() {
 		logger = logrus.New()
-		logger.SetLevel(logrus.DebugLevel)
+		level, err := logrus.ParseLevel(cfg.Level)
+		if err != nil {
+			level = logrus.DebugLevel
+		}
+		logger.SetLevel(level)
 
 		logger.SetFormatter(&formatter.Formatter{
 			NoColors:        false,
@@ -45,10 +58,9 @@ func NewLogger() *logrus.Logger {
 
 		writers := []io.Writer{os.Stderr}
 
-		appEnv := os.Getenv("APP_ENV")
-		if appEnv != "test" {
+		if cfg.Dir != "" {
 			fileWriter := &lumberjack.Logger{
-				Filename:   fmt.Sprintf("./storage/logs/app-%s.log", time.Now().Format("2006-01-02")),
+				Filename:   path.Join(cfg.Dir, fmt.Sprintf("app-%s.log", time.Now().Format("2006-01-02"))),
 				LocalTime:  true,
 				Compress:   true,
 				MaxSize:    100,
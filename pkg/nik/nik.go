@@ -0,0 +1,181 @@
+package nik
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidLength    = errors.New("nik must be 16 digits")
+	ErrNotNumeric       = errors.New("nik must only contain digits")
+	ErrUnknownProvince  = errors.New("nik has an unknown province code")
+	ErrInvalidRegion    = errors.New("nik has an invalid regency or district code")
+	ErrInvalidBirthDate = errors.New("nik encodes an invalid birth date")
+	ErrInvalidSerial    = errors.New("nik has an invalid serial number")
+)
+
+const (
+	GenderMale   = "LAKI-LAKI"
+	GenderFemale = "PEREMPUAN"
+
+	// femaleDayOffset is added to the day of birth in the NIK of women.
+	femaleDayOffset = 40
+	// firstCityCode is the lowest regency code of a kota; the codes below it
+	// belong to kabupaten.
+	firstCityCode = "71"
+)
+
+// provinces lists the two-digit province codes issued by Dukcapil.
+var provinces = map[string]string{
+	"11": "ACEH", "12": "SUMATERA UTARA", "13": "SUMATERA BARAT", "14": "RIAU",
+	"15": "JAMBI", "16": "SUMATERA SELATAN", "17": "BENGKULU", "18": "LAMPUNG",
+	"19": "KEPULAUAN BANGKA BELITUNG", "21": "KEPULAUAN RIAU",
+	"31": "DKI JAKARTA", "32": "JAWA BARAT", "33": "JAWA TENGAH", "34": "DI YOGYAKARTA",
+	"35": "JAWA TIMUR", "36": "BANTEN",
+	"51": "BALI", "52": "NUSA TENGGARA BARAT", "53": "NUSA TENGGARA TIMUR",
+	"61": "KALIMANTAN BARAT", "62": "KALIMANTAN TENGAH", "63": "KALIMANTAN SELATAN",
+	"64": "KALIMANTAN TIMUR", "65": "KALIMANTAN UTARA",
+	"71": "SULAWESI UTARA", "72": "SULAWESI TENGAH", "73": "SULAWESI SELATAN",
+	"74": "SULAWESI TENGGARA", "75": "GORONTALO", "76": "SULAWESI BARAT",
+	"81": "MALUKU", "82": "MALUKU UTARA",
+	"91": "PAPUA", "92": "PAPUA BARAT", "93": "PAPUA SELATAN", "94": "PAPUA TENGAH",
+	"95": "PAPUA PEGUNUNGAN", "96": "PAPUA BARAT DAYA",
+}
+
+// provinceAliases maps other names printed on older cards to the names above.
+var provinceAliases = map[string]string{
+	"NANGGROE ACEH DARUSSALAM":      "ACEH",
+	"DAERAH KHUSUS IBUKOTA JAKARTA": "DKI JAKARTA",
+	"DAERAH ISTIMEWA YOGYAKARTA":    "DI YOGYAKARTA",
+	"D.I. YOGYAKARTA":               "DI YOGYAKARTA",
+	"BANGKA BELITUNG":               "KEPULAUAN BANGKA BELITUNG",
+}
+
+// NIK is the decoded structure of a Nomor Induk Kependudukan:
+// PPRRDD DDMMYY SSSS, where PP/RR/DD are the province, regency and district
+// codes, DDMMYY the birth date (day + 40 for women) and SSSS a serial number.
+type NIK struct {
+	Number       string
+	ProvinceCode string
+	Province     string
+	RegencyCode  string
+	DistrictCode string
+	BirthDay     int
+	BirthMonth   int
+	BirthYear    int // two digits, the century is not encoded
+	Gender       string
+	Serial       string
+}
+
+// Parse validates the structure of number and decodes its parts. It cannot
+// tell whether the NIK was actually issued, only whether it is well formed.
+func Parse(number string) (NIK, error) {
+	if len(number) != 16 {
+		return NIK{}, ErrInvalidLength
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return NIK{}, ErrNotNumeric
+		}
+	}
+
+	n := NIK{
+		Number:       number,
+		ProvinceCode: number[0:2],
+		RegencyCode:  number[2:4],
+		DistrictCode: number[4:6],
+		Serial:       number[12:16],
+	}
+
+	province, ok := provinces[n.ProvinceCode]
+	if !ok {
+		return NIK{}, ErrUnknownProvince
+	}
+	n.Province = province
+
+	if n.RegencyCode == "00" || n.DistrictCode == "00" {
+		return NIK{}, ErrInvalidRegion
+	}
+
+	if n.Serial == "0000" {
+		return NIK{}, ErrInvalidSerial
+	}
+
+	day, _ := strconv.Atoi(number[6:8])
+	n.BirthMonth, _ = strconv.Atoi(number[8:10])
+	n.BirthYear, _ = strconv.Atoi(number[10:12])
+
+	n.Gender = GenderMale
+	if day > femaleDayOffset {
+		n.Gender = GenderFemale
+		day -= femaleDayOffset
+	}
+	n.BirthDay = day
+
+	// 2000 is a leap year, so a 29 February is accepted for any two-digit year
+	// here; the full date is compared in Mismatches.
+	probe := time.Date(2000, time.Month(n.BirthMonth), n.BirthDay, 0, 0, 0, 0, time.UTC)
+	if n.BirthMonth < 1 || n.BirthMonth > 12 || n.BirthDay < 1 || probe.Day() != n.BirthDay {
+		return NIK{}, ErrInvalidBirthDate
+	}
+
+	return n, nil
+}
+
+// Mismatches compares the NIK with the birth date and gender printed on the
+// card and describes every inconsistency. A zero birthDate or empty gender is
+// not compared.
+func (n NIK) Mismatches(birthDate time.Time, gender string) []string {
+	var mismatches []string
+
+	if !birthDate.IsZero() {
+		if birthDate.Day() != n.BirthDay || int(birthDate.Month()) != n.BirthMonth || birthDate.Year()%100 != n.BirthYear {
+			mismatches = append(mismatches, fmt.Sprintf(
+				"birth date %s does not match the NIK (%02d-%02d-xx%02d)",
+				birthDate.Format("02-01-2006"), n.BirthDay, n.BirthMonth, n.BirthYear,
+			))
+		}
+	}
+
+	if gender != "" && gender != n.Gender {
+		mismatches = append(mismatches, fmt.Sprintf("gender %s does not match the NIK (%s)", gender, n.Gender))
+	}
+
+	return mismatches
+}
+
+// IsCity reports whether the regency code belongs to a kota rather than a
+// kabupaten.
+func (n NIK) IsCity() bool {
+	return n.RegencyCode >= firstCityCode
+}
+
+// RegionMismatches compares the NIK with the province and regency printed in
+// the card header, e.g. "JAWA BARAT" and "KOTA BANDUNG". Regency names are not
+// listed here, so only whether it is a kota or a kabupaten is compared. An
+// empty province or regency is not compared.
+func (n NIK) RegionMismatches(province string, regency string) []string {
+	var mismatches []string
+
+	if alias, ok := provinceAliases[province]; ok {
+		province = alias
+	}
+	if province != "" && province != n.Province {
+		mismatches = append(mismatches, fmt.Sprintf("province %s does not match the NIK (%s)", province, n.Province))
+	}
+
+	isCity := strings.HasPrefix(regency, "KOTA ")
+	isKabupaten := strings.HasPrefix(regency, "KABUPATEN ") || strings.HasPrefix(regency, "KAB. ")
+	if (isCity && !n.IsCity()) || (isKabupaten && n.IsCity()) {
+		kind := "a kabupaten"
+		if n.IsCity() {
+			kind = "a kota"
+		}
+		mismatches = append(mismatches, fmt.Sprintf("regency %s does not match the NIK (%s)", regency, kind))
+	}
+
+	return mismatches
+}
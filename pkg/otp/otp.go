@@ -0,0 +1,63 @@
+// Package otp checks one-time codes kept in Redis, limiting how many wrong
+// codes can be tried for the same key.
+package otp
+
+import (
+	"ProjectGolang/pkg/redis"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+)
+
+// MaxAttempts is how many wrong codes a key accepts within attemptWindow.
+const MaxAttempts = 5
+
+const attemptWindow = 15 * time.Minute
+
+var (
+	ErrExpired         = errors.New("code expired or was never sent")
+	ErrInvalid         = errors.New("code does not match")
+	ErrTooManyAttempts = errors.New("too many wrong codes")
+)
+
+// Verify compares code with the one stored at key in constant time and
+// deletes it once it matched. Wrong codes are counted per key rather than per
+// client; after MaxAttempts the stored code is deleted and every attempt
+// fails with ErrTooManyAttempts until the window has passed, even for a new
+// code, so a code cannot be guessed from many addresses at once.
+func Verify(ctx context.Context, redisServer redis.IRedis, key string, code string) error {
+	attemptsKey := key + ":attempts"
+
+	attempts, err := redisServer.Count(ctx, attemptsKey)
+	if err != nil {
+		return err
+	}
+	if attempts >= MaxAttempts {
+		return ErrTooManyAttempts
+	}
+
+	stored, err := redisServer.GetOTP(ctx, key)
+	if err != nil {
+		return ErrExpired
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(code)) != 1 {
+		failures, err := redisServer.Incr(ctx, attemptsKey, attemptWindow)
+		if err != nil {
+			return err
+		}
+		if failures >= MaxAttempts {
+			if err := redisServer.DeleteOTP(ctx, key); err != nil {
+				return err
+			}
+			return ErrTooManyAttempts
+		}
+		return ErrInvalid
+	}
+
+	if err := redisServer.DeleteOTP(ctx, key); err != nil {
+		return err
+	}
+	return redisServer.DeleteOTP(ctx, attemptsKey)
+}
@@ -0,0 +1,167 @@
+package redis
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type fakeEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// FakeRedis is an in-memory IRedis for tests. Keys expire like they do in
+// Redis, and missing keys report redis.Nil just as the real client does.
+type FakeRedis struct {
+	mutex       sync.Mutex
+	entries     map[string]fakeEntry
+	subscribers map[string][]chan string
+}
+
+var _ IRedis = (*FakeRedis)(nil)
+
+func NewFake() *FakeRedis {
+	return &FakeRedis{
+		entries:     make(map[string]fakeEntry),
+		subscribers: make(map[string][]chan string),
+	}
+}
+
+// get returns the live entry at key. The caller holds the mutex.
+func (f *FakeRedis) get(key string) (fakeEntry, bool) {
+	entry, ok := f.entries[key]
+	if ok && !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+		delete(f.entries, key)
+		return fakeEntry{}, false
+	}
+	return entry, ok
+}
+
+func (f *FakeRedis) SetOTP(_ context.Context, key string, code string, expiration time.Duration) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	entry := fakeEntry{value: code}
+	if expiration > 0 {
+		entry.expiresAt = time.Now().Add(expiration)
+	}
+	f.entries[key] = entry
+	return nil
+}
+
+func (f *FakeRedis) GetOTP(_ context.Context, key string) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	entry, ok := f.get(key)
+	if !ok {
+		return "", redis.Nil
+	}
+	return entry.value, nil
+}
+
+func (f *FakeRedis) DeleteOTP(_ context.Context, key string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.entries, key)
+	return nil
+}
+
+func (f *FakeRedis) Incr(_ context.Context, key string, expiration time.Duration) (int64, error) {
+	return f.add(key, 1, expiration)
+}
+
+func (f *FakeRedis) Decr(_ context.Context, key string) (int64, error) {
+	return f.add(key, -1, 0)
+}
+
+// add changes the counter at key by delta, starting the expiration window of
+// a new counter like Incr does.
+func (f *FakeRedis) add(key string, delta int64, expiration time.Duration) (int64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	entry, ok := f.get(key)
+	count, err := strconv.ParseInt(entry.value, 10, 64)
+	if ok && err != nil {
+		return 0, err
+	}
+	if !ok {
+		count = 0
+		if delta > 0 && expiration > 0 {
+			entry.expiresAt = time.Now().Add(expiration)
+		}
+	}
+
+	count += delta
+	entry.value = strconv.FormatInt(count, 10)
+	f.entries[key] = entry
+	return count, nil
+}
+
+func (f *FakeRedis) Count(_ context.Context, key string) (int64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	entry, ok := f.get(key)
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseInt(entry.value, 10, 64)
+}
+
+func (f *FakeRedis) TTL(_ context.Context, key string) (time.Duration, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	entry, ok := f.get(key)
+	if !ok || entry.expiresAt.IsZero() {
+		return 0, nil
+	}
+	return time.Until(entry.expiresAt), nil
+}
+
+// Publish delivers message to the current subscribers of channel, dropping it
+// for those that are not reading.
+func (f *FakeRedis) Publish(_ context.Context, channel string, message string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, subscriber := range f.subscribers[channel] {
+		select {
+		case subscriber <- message:
+		default:
+		}
+	}
+	return nil
+}
+
+func (f *FakeRedis) Subscribe(ctx context.Context, channel string) <-chan string {
+	messages := make(chan string, 16)
+
+	f.mutex.Lock()
+	f.subscribers[channel] = append(f.subscribers[channel], messages)
+	f.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+
+		subscribers := f.subscribers[channel]
+		for i, subscriber := range subscribers {
+			if subscriber == messages {
+				f.subscribers[channel] = append(subscribers[:i], subscribers[i+1:]...)
+				break
+			}
+		}
+		close(messages)
+	}()
+
+	return messages
+}
@@ -6,31 +6,39 @@ import (
 	"fmt"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
-	"os"
-	"strconv"
 	"time"
 )
 
 type IRedis interface {
 	SetOTP(ctx context.Context, key string, code string, expiration time.Duration) error
 	GetOTP(ctx context.Context, key string) (string, error)
+	DeleteOTP(ctx context.Context, key string) error
+	Incr(ctx context.Context, key string, expiration time.Duration) (int64, error)
+	Decr(ctx context.Context, key string) (int64, error)
+	Count(ctx context.Context, key string) (int64, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Publish(ctx context.Context, channel string, message string) error
+	Subscribe(ctx context.Context, channel string) <-chan string
 }
 
 type redisClient struct {
 	client *redis.Client
 }
 
-func New() IRedis {
-	db, _ := strconv.Atoi(os.Getenv("REDIS_DB"))
-	redisAddr := os.Getenv("REDIS_ADDRESS")
-	redisPassword := os.Getenv("REDIS_PASSWORD")
+// Config is the Redis server to use.
+type Config struct {
+	Address  string `env:"REDIS_ADDRESS" default:"localhost:6379"`
+	Password string `env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `env:"REDIS_DB" default:"0"`
+}
 
-	logrus.Info(fmt.Sprintf("Connecting to Redis at %s...", redisAddr))
+func New(cfg Config) IRedis {
+	logrus.Info(fmt.Sprintf("Connecting to Redis at %s...", cfg.Address))
 
 	client := redis.NewClient(&redis.Options{
-		Addr:     redisAddr,
-		Password: redisPassword,
-		DB:       db,
+		Addr:     cfg.Address,
+		Password: cfg.Password,
+		DB:       cfg.DB,
 	})
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -86,3 +94,103 @@ func (r *redisClient) DeleteOTP(ctx context.Context, key string) error {
 	logrus.Debug(fmt.Sprintf("Successfully deleted OTP for key %s", key))
 	return nil
 }
+
+// Incr increments the counter stored at key and starts its expiration window
+// on the first increment, so the counter resets once the window has passed.
+func (r *redisClient) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	logrus.Debug(fmt.Sprintf("Incrementing counter for key %s", key))
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		logrus.Error(fmt.Sprintf("Error incrementing counter for key %s: %v", key, err))
+		return 0, err
+	}
+
+	if count == 1 {
+		if err := r.client.Expire(ctx, key, expiration).Err(); err != nil {
+			logrus.Error(fmt.Sprintf("Error setting expiration for key %s: %v", key, err))
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+// Decr decrements the counter stored at key without touching its expiration.
+func (r *redisClient) Decr(ctx context.Context, key string) (int64, error) {
+	logrus.Debug(fmt.Sprintf("Decrementing counter for key %s", key))
+	count, err := r.client.Decr(ctx, key).Result()
+	if err != nil {
+		logrus.Error(fmt.Sprintf("Error decrementing counter for key %s: %v", key, err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// TTL returns the remaining time to live of key, or zero when the key does
+// not exist or has no expiration.
+func (r *redisClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.TTL(ctx, key).Result()
+	if err != nil {
+		logrus.Error(fmt.Sprintf("Error getting TTL for key %s: %v", key, err))
+		return 0, err
+	}
+
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// Count returns the counter stored at key, or zero when the key does not exist.
+func (r *redisClient) Count(ctx context.Context, key string) (int64, error) {
+	count, err := r.client.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	} else if err != nil {
+		logrus.Error(fmt.Sprintf("Error getting counter for key %s: %v", key, err))
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *redisClient) Publish(ctx context.Context, channel string, message string) error {
+	if err := r.client.Publish(ctx, channel, message).Err(); err != nil {
+		logrus.Error(fmt.Sprintf("Error publishing to channel %s: %v", channel, err))
+		return err
+	}
+	return nil
+}
+
+// Subscribe delivers the messages published to channel until ctx is
+// cancelled, when the returned channel is closed. The subscription survives
+// reconnects, but messages published while the connection is down are lost.
+func (r *redisClient) Subscribe(ctx context.Context, channel string) <-chan string {
+	pubsub := r.client.Subscribe(ctx, channel)
+	messages := make(chan string)
+
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+
+		incoming := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-incoming:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages
+}
@@ -0,0 +1,157 @@
+package s3
+
+import (
+	awsPkg "ProjectGolang/pkg/aws"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+type s3Client struct {
+	client     *s3.S3
+	session    *session.Session
+	bucketName string
+}
+
+// newS3 connects to AWS, or with compatible set to the S3-compatible service
+// at cfg.Endpoint. Such services usually want path-style addressing, which
+// cfg.ForcePathStyle turns off when false.
+func newS3(cfg Config, credentials awsPkg.Config, compatible bool) (ItfS3, error) {
+	config := credentials.SDKConfig()
+
+	if compatible {
+		if cfg.Endpoint == "" {
+			return nil, fmt.Errorf("S3_ENDPOINT is required for the s3-compatible storage driver")
+		}
+
+		config.Endpoint = aws.String(cfg.Endpoint)
+		config.S3ForcePathStyle = aws.Bool(cfg.ForcePathStyle)
+		if aws.StringValue(config.Region) == "" {
+			config.Region = aws.String("us-east-1")
+		}
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Client{
+		client:     s3.New(sess),
+		session:    sess,
+		bucketName: cfg.Bucket,
+	}, nil
+}
+
+func (s *s3Client) UploadFile(userID string, purpose Purpose, file *multipart.FileHeader) (string, error) {
+	return uploadFile(s, userID, purpose, file)
+}
+
+func (s *s3Client) UploadSizes(userID string, purpose Purpose, sizes []Size) (string, error) {
+	return uploadSizes(s, userID, purpose, sizes)
+}
+
+func (s *s3Client) put(key string, contentType string, body io.Reader) error {
+	uploader := s3manager.NewUploader(s.session)
+	_, err := uploader.Upload(&s3manager.UploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+		ACL:         aws.String(s3.ObjectCannedACLPrivate),
+	})
+	return err
+}
+
+func (s *s3Client) PresignUrl(userID string, fileUrl string) (string, error) {
+	key := keyFromLocation(fileUrl, s.bucketName)
+	if err := checkOwner(userID, key); err != nil {
+		return "", err
+	}
+
+	_, err := s.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("file does not exist: %w", err)
+	}
+
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+
+	urlStr, err := req.Presign(presignExpiry)
+	if err != nil {
+		return "", err
+	}
+
+	return urlStr, nil
+}
+
+func (s *s3Client) Stat(userID string, fileName string) (FileInfo, error) {
+	key := keyFromLocation(fileName, s.bucketName)
+	if err := checkOwner(userID, key); err != nil {
+		return FileInfo{}, err
+	}
+
+	head, err := s.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return FileInfo{}, objectError(err)
+	}
+
+	return FileInfo{
+		Size:        aws.Int64Value(head.ContentLength),
+		ContentType: aws.StringValue(head.ContentType),
+	}, nil
+}
+
+// Open reads length bytes of an object from offset. The body is read after
+// the request that asked for it has returned, so it is fetched without its
+// context.
+func (s *s3Client) Open(userID string, fileName string, offset int64, length int64) (io.ReadCloser, error) {
+	key := keyFromLocation(fileName, s.bucketName)
+	if err := checkOwner(userID, key); err != nil {
+		return nil, err
+	}
+
+	object, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	})
+	if err != nil {
+		return nil, objectError(err)
+	}
+
+	return object.Body, nil
+}
+
+// objectError turns the answer for a missing object into ErrNotFound.
+func objectError(err error) error {
+	var failure awserr.RequestFailure
+	if errors.As(err, &failure) && failure.StatusCode() == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *s3Client) DeleteFile(fileName string) error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(keyFromLocation(fileName, s.bucketName)),
+	})
+
+	return err
+}
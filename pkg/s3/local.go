@@ -0,0 +1,230 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileServer is implemented by backends that serve their own files. The
+// server mounts Serve at LocalRoute.
+type FileServer interface {
+	Serve(ctx *fiber.Ctx) error
+}
+
+const LocalRoute = "/files/*"
+
+// localStorage keeps files under cfg.LocalDir. They are only served through
+// URLs under cfg.LocalURL signed with cfg.LocalSecret, which expire like S3
+// presigned URLs do.
+type localStorage struct {
+	dir     string
+	baseURL string
+	secret  []byte
+}
+
+func newLocal(cfg Config) (ItfS3, error) {
+	if cfg.LocalSecret == "" {
+		return nil, fmt.Errorf("LOCAL_STORAGE_SECRET is required for the local storage driver")
+	}
+	if cfg.LocalURL == "" {
+		return nil, fmt.Errorf("LOCAL_STORAGE_URL is required for the local storage driver")
+	}
+
+	dir, err := filepath.Abs(cfg.LocalDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &localStorage{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(cfg.LocalURL, "/"),
+		secret:  []byte(cfg.LocalSecret),
+	}, nil
+}
+
+func (l *localStorage) UploadFile(userID string, purpose Purpose, file *multipart.FileHeader) (string, error) {
+	return uploadFile(l, userID, purpose, file)
+}
+
+func (l *localStorage) UploadSizes(userID string, purpose Purpose, sizes []Size) (string, error) {
+	return uploadSizes(l, userID, purpose, sizes)
+}
+
+func (l *localStorage) put(key string, contentType string, body io.Reader) error {
+	path := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, body); err != nil {
+		dst.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	return nil
+}
+
+func (l *localStorage) PresignUrl(userID string, fileName string) (string, error) {
+	key, err := l.ownedKey(userID, fileName)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(l.path(key)); err != nil {
+		return "", fmt.Errorf("file does not exist: %w", err)
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(presignExpiry).Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {l.sign(key, expires)},
+	}
+
+	return l.baseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+func (l *localStorage) Stat(userID string, fileName string) (FileInfo, error) {
+	key, err := l.ownedKey(userID, fileName)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	stat, err := os.Stat(l.path(key))
+	if err != nil {
+		return FileInfo{}, fileError(err)
+	}
+
+	return FileInfo{
+		Size:        stat.Size(),
+		ContentType: contentTypeOf(key),
+	}, nil
+}
+
+func (l *localStorage) Open(userID string, fileName string, offset int64, length int64) (io.ReadCloser, error) {
+	key, err := l.ownedKey(userID, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(l.path(key))
+	if err != nil {
+		return nil, fileError(err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, length), file}, nil
+}
+
+func (l *localStorage) DeleteFile(fileName string) error {
+	key := l.key(fileName)
+	if !l.validKey(key) {
+		return fmt.Errorf("invalid file key %q", key)
+	}
+
+	err := os.Remove(l.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Serve sends the file a signed URL points to.
+func (l *localStorage) Serve(ctx *fiber.Ctx) error {
+	key, err := url.PathUnescape(ctx.Params("*"))
+	if err != nil || !l.validKey(key) {
+		return fiber.ErrNotFound
+	}
+
+	expires := ctx.Query("expires")
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return fiber.NewError(fiber.StatusForbidden, ErrInvalidSignature.Error())
+	}
+	if !hmac.Equal([]byte(ctx.Query("signature")), []byte(l.sign(key, expires))) {
+		return fiber.NewError(fiber.StatusForbidden, ErrInvalidSignature.Error())
+	}
+
+	path := l.path(key)
+	if _, err := os.Stat(path); err != nil {
+		return fiber.ErrNotFound
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "private, max-age="+strconv.Itoa(int(presignExpiry.Seconds())))
+	return ctx.SendFile(path)
+}
+
+func (l *localStorage) ownedKey(userID string, fileName string) (string, error) {
+	key := l.key(fileName)
+	if !l.validKey(key) {
+		return "", fmt.Errorf("invalid file key %q", key)
+	}
+	if err := checkOwner(userID, key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// fileError turns a missing file into ErrNotFound.
+func fileError(err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (l *localStorage) key(location string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(location, l.baseURL), "/")
+}
+
+func (l *localStorage) path(key string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(key))
+}
+
+// validKey rejects keys that would leave the storage directory.
+func (l *localStorage) validKey(key string) bool {
+	if key == "" || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *localStorage) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -1,146 +1,310 @@
 package s3
 
 import (
+	awsPkg "ProjectGolang/pkg/aws"
+	"ProjectGolang/pkg/utils"
+	"bytes"
+	"errors"
 	"fmt"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"io"
 	"mime/multipart"
+	"net/http"
 	"net/url"
-	"os"
+	"path"
 	"strings"
 	"time"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
 )
 
+// ItfS3 stores user uploads as private objects. UploadFile returns the key
+// of the object, which is what gets stored; PresignUrl makes a short-lived
+// link to it for the user who owns it, and Stat and Open read it for them
+// directly. All of them also accept the object URLs stored before keys were.
 type ItfS3 interface {
-	UploadFile(file *multipart.FileHeader) (string, error)
-	PresignUrl(fileName string) (string, error)
+	UploadFile(userID string, purpose Purpose, file *multipart.FileHeader) (string, error)
+	UploadSizes(userID string, purpose Purpose, sizes []Size) (string, error)
+	PresignUrl(userID string, fileName string) (string, error)
+	Stat(userID string, fileName string) (FileInfo, error)
+	Open(userID string, fileName string, offset int64, length int64) (io.ReadCloser, error)
 	DeleteFile(fileName string) error
 }
 
-type s3Client struct {
-	client     *s3.S3
-	session    *session.Session
-	bucketName string
+// FileInfo describes a stored object.
+type FileInfo struct {
+	Size        int64
+	ContentType string
 }
 
-func New() (ItfS3, error) {
-	sess, err := newSession()
-	if err != nil {
-		return nil, err
-	}
+var (
+	ErrFileTooLarge        = errors.New("file exceeds the size limit for its purpose")
+	ErrUnsupportedFileType = errors.New("file type is not accepted for its purpose")
+	ErrUnknownPurpose      = errors.New("unknown upload purpose")
+	ErrInvalidSignature    = errors.New("invalid or expired file signature")
+	ErrNotOwner            = errors.New("file belongs to another user")
+	ErrNotFound            = errors.New("file does not exist")
+)
 
-	return &s3Client{
-		client:     s3.New(sess),
-		session:    sess,
-		bucketName: os.Getenv("AWS_BUCKET_NAME"),
-	}, nil
-}
+const presignExpiry = 15 * time.Minute
 
-func (s *s3Client) UploadFile(file *multipart.FileHeader) (string, error) {
-	uploader := s3manager.NewUploader(s.session)
+// Purpose says what an upload is for, which decides the content types and
+// size it may have and where it is stored.
+type Purpose string
 
-	uniqueFileName, err := generateUniqueFileName(file.Filename)
-	if err != nil {
-		return "", err
+const (
+	PurposeProfilePhoto Purpose = "profile_photo"
+	PurposeFacePhoto    Purpose = "face_photo"
+	PurposeKTP          Purpose = "ktp"
+	PurposeSelfie       Purpose = "selfie"
+	PurposeAudio        Purpose = "audio"
+)
+
+type rule struct {
+	maxSize int64
+	// extensions maps each accepted content type, as detected from the
+	// content, to the extension stored objects get.
+	extensions map[string]string
+}
+
+var (
+	// Photos are re-encoded before they are stored.
+	photoTypes = map[string]string{
+		"image/jpeg": ".jpg",
+	}
+	documentTypes = map[string]string{
+		"image/jpeg": ".jpg",
+		"image/png":  ".png",
+	}
+	audioTypes = map[string]string{
+		"audio/mpeg":      ".mp3",
+		"audio/wave":      ".wav",
+		"application/ogg": ".ogg",
+		"audio/mp4":       ".m4a",
+		"audio/flac":      ".flac",
 	}
 
-	src, err := file.Open()
-	if err != nil {
-		return "", err
+	rules = map[Purpose]rule{
+		PurposeProfilePhoto: {maxSize: 5 << 20, extensions: photoTypes},
+		PurposeFacePhoto:    {maxSize: 5 << 20, extensions: photoTypes},
+		PurposeKTP:          {maxSize: 5 << 20, extensions: documentTypes},
+		PurposeSelfie:       {maxSize: 5 << 20, extensions: documentTypes},
+		PurposeAudio:        {maxSize: 10 << 20, extensions: audioTypes},
 	}
-	defer func(src multipart.File) {
-		err := src.Close()
-		if err != nil {
-			fmt.Println("Failed to close file")
-		}
-	}(src)
+)
 
-	uploadOutput, err := uploader.Upload(&s3manager.UploadInput{
-		Bucket: aws.String(s.bucketName),
-		Key:    aws.String(uniqueFileName),
-		Body:   src,
-	})
+const (
+	DriverS3           = "s3"
+	DriverS3Compatible = "s3-compatible"
+	DriverLocal        = "local"
+)
 
-	if err != nil {
-		return "", err
+// Config picks the storage backend and says where it keeps files. Bucket
+// is used by both S3 drivers, Endpoint and ForcePathStyle only by the
+// S3-compatible one, and the Local fields only by the local driver.
+type Config struct {
+	Driver         string `env:"STORAGE_DRIVER" default:"s3"`
+	Bucket         string `env:"AWS_BUCKET_NAME"`
+	Endpoint       string `env:"S3_ENDPOINT"`
+	ForcePathStyle bool   `env:"S3_FORCE_PATH_STYLE" default:"true"`
+	LocalDir       string `env:"LOCAL_STORAGE_DIR" default:"storage"`
+	LocalURL       string `env:"LOCAL_STORAGE_URL"`
+	LocalSecret    string `env:"LOCAL_STORAGE_SECRET" secret:"true"`
+}
+
+// New returns the backend named by cfg.Driver: DriverS3 for AWS,
+// DriverS3Compatible for MinIO and other services at cfg.Endpoint, or
+// DriverLocal for files on disk served through signed URLs.
+func New(cfg Config, credentials awsPkg.Config) (ItfS3, error) {
+	switch cfg.Driver {
+	case DriverS3:
+		return newS3(cfg, credentials, false)
+	case DriverS3Compatible:
+		return newS3(cfg, credentials, true)
+	case DriverLocal:
+		return newLocal(cfg)
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", cfg.Driver)
 	}
+}
+
+// MaxSize returns the largest upload accepted for purpose.
+func MaxSize(purpose Purpose) int64 {
+	return rules[purpose].maxSize
+}
+
+// Size is one of the sizes an image is stored in, such as a thumbnail.
+type Size struct {
+	Name string
+	Data []byte
+}
 
-	return uploadOutput.Location, nil
+// SizeKey returns the key the named size of an image uploaded with
+// UploadSizes is stored under.
+func SizeKey(key string, name string) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "_" + name + ext
 }
 
-func (s *s3Client) PresignUrl(fileUrl string) (string, error) {
-	key := extractKeyFromS3Url(fileUrl)
+// backend stores objects for the functions shared by the backends.
+type backend interface {
+	put(key string, contentType string, body io.Reader) error
+	DeleteFile(fileName string) error
+}
 
-	decodedKey, err := url.QueryUnescape(key)
+func uploadFile(b backend, userID string, purpose Purpose, file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
 	if err != nil {
-		return "", fmt.Errorf("failed to decode S3 key: %w", err)
+		return "", err
 	}
 
-	_, err = s.client.HeadObject(&s3.HeadObjectInput{
-		Bucket: aws.String(s.bucketName),
-		Key:    aws.String(decodedKey),
-	})
+	upload, err := openUpload(userID, purpose, file.Size, src)
 	if err != nil {
-		return "", fmt.Errorf("file does not exist: %w", err)
+		return "", err
 	}
+	defer upload.body.Close()
 
-	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
-		Bucket: aws.String(s.bucketName),
-		Key:    aws.String(decodedKey),
-	})
-
-	urlStr, err := req.Presign(15 * time.Minute)
-	if err != nil {
+	if err := b.put(upload.key, upload.contentType, upload.body); err != nil {
 		return "", err
 	}
 
-	return urlStr, nil
+	return upload.key, nil
 }
 
-func extractKeyFromS3Url(fileUrl string) string {
-	parts := strings.Split(fileUrl, ".com/")
-	if len(parts) > 1 {
-		return parts[1]
+// uploadSizes stores the first size under a new key and the others next to
+// it. Sizes already stored are removed again if a later one fails.
+func uploadSizes(b backend, userID string, purpose Purpose, sizes []Size) (string, error) {
+	if len(sizes) == 0 {
+		return "", errors.New("no sizes to upload")
 	}
-	return fileUrl
-}
 
-func (s *s3Client) DeleteFile(fileName string) error {
-	decodedFileName, err := url.QueryUnescape(fileName)
-	if err != nil {
-		return fmt.Errorf("failed to decode filename: %w", err)
+	var key string
+	var stored []string
+	for i, size := range sizes {
+		upload, err := openUpload(userID, purpose, int64(len(size.Data)), io.NopCloser(bytes.NewReader(size.Data)))
+		if err == nil {
+			if i == 0 {
+				key = upload.key
+			} else {
+				upload.key = SizeKey(key, size.Name)
+			}
+			err = b.put(upload.key, upload.contentType, upload.body)
+		}
+		if err != nil {
+			for _, storedKey := range stored {
+				_ = b.DeleteFile(storedKey)
+			}
+			return "", err
+		}
+		stored = append(stored, upload.key)
 	}
 
-	_, err = s.client.DeleteObject(&s3.DeleteObjectInput{
-		Bucket: aws.String(s.bucketName),
-		Key:    aws.String(decodedFileName),
-	})
+	return key, nil
+}
 
-	return err
+// upload is a checked upload, ready to be stored under key.
+type upload struct {
+	key         string
+	contentType string
+	body        io.ReadCloser
 }
 
-func newSession() (*session.Session, error) {
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(os.Getenv("AWS_REGION")),
-		Credentials: credentials.NewStaticCredentials(
-			os.Getenv("AWS_ACCESS_KEY_ID"),
-			os.Getenv("AWS_SECRET_ACCESS_KEY"),
-			"",
-		),
-	})
+// openUpload checks content against the rule of its purpose, judging its
+// type by the content rather than its name or headers, and gives it a key
+// under the prefix of its user. It takes over closing src.
+func openUpload(userID string, purpose Purpose, size int64, src io.ReadCloser) (*upload, error) {
+	rule, ok := rules[purpose]
+	if !ok {
+		src.Close()
+		return nil, ErrUnknownPurpose
+	}
+	if size > rule.maxSize {
+		src.Close()
+		return nil, ErrFileTooLarge
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		src.Close()
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType := detectContentType(head)
+	ext, ok := rule.extensions[contentType]
+	if !ok {
+		src.Close()
+		return nil, ErrUnsupportedFileType
+	}
 
+	id, err := utils.New().NewULIDFromTimestamp(time.Now())
 	if err != nil {
+		src.Close()
 		return nil, err
 	}
 
-	return sess, nil
+	return &upload{
+		key:         fmt.Sprintf("users/%s/%s/%s%s", userID, purpose, id, ext),
+		contentType: contentType,
+		body: struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(head), io.LimitReader(src, rule.maxSize-int64(n))), src},
+	}, nil
 }
 
-func generateUniqueFileName(fileName string) (string, error) {
-	uniqueFileName := fmt.Sprintf("%s-%s", strings.ReplaceAll(time.Now().String(), " ", ""), fileName)
-	return uniqueFileName, nil
+// detectContentType sniffs the content type, adding the audio formats
+// http.DetectContentType does not know: FLAC, and MP3 without an ID3 tag,
+// which starts with a frame sync. Its MP4 answer is taken as audio, as the
+// only purpose accepting MP4 is audio.
+func detectContentType(head []byte) string {
+	if bytes.HasPrefix(head, []byte("fLaC")) {
+		return "audio/flac"
+	}
+	if len(head) >= 2 && head[0] == 0xFF && head[1]&0xE6 == 0xE2 {
+		return "audio/mpeg"
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if contentType == "video/mp4" {
+		return "audio/mp4"
+	}
+	return contentType
+}
+
+// contentTypeOf returns the content type stored objects with the extension
+// of key were detected as.
+func contentTypeOf(key string) string {
+	ext := path.Ext(key)
+	for _, rule := range rules {
+		for contentType, typeExt := range rule.extensions {
+			if typeExt == ext {
+				return contentType
+			}
+		}
+	}
+	return "application/octet-stream"
+}
+
+// checkOwner refuses keys under the prefix of another user. Keys from before
+// per-user prefixes carry no owner and are left to the caller, which read
+// them from the user's own records.
+func checkOwner(userID string, key string) error {
+	if strings.HasPrefix(key, "users/") && !strings.HasPrefix(key, "users/"+userID+"/") {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// keyFromLocation returns the object key of a stored object URL. Locations
+// that are not URLs are taken to be keys already.
+func keyFromLocation(location string, bucket string) string {
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return location
+	}
+
+	key := strings.TrimPrefix(u.Path, "/")
+	if bucket != "" && !strings.HasPrefix(u.Host, bucket+".") {
+		key = strings.TrimPrefix(key, bucket+"/")
+	}
+	return key
 }
@@ -0,0 +1,83 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+var ErrNotConfigured = errors.New("sms provider not configured")
+
+type ISender interface {
+	Send(ctx context.Context, phoneNumber string, text string) error
+}
+
+type httpSender struct {
+	url    string
+	apiKey string
+	from   string
+	client *http.Client
+}
+
+// Config is the HTTP gateway at URL, authenticated with the bearer token
+// APIKey. Sender is the sender ID shown to the recipient.
+type Config struct {
+	URL    string `env:"SMS_API_URL"`
+	APIKey string `env:"SMS_API_KEY" secret:"true"`
+	Sender string `env:"SMS_SENDER"`
+}
+
+// New sends SMS through the gateway in cfg, failing with ErrNotConfigured
+// when there is none.
+func New(cfg Config) (ISender, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("%w: SMS_API_URL not set", ErrNotConfigured)
+	}
+
+	return &httpSender{
+		url:    cfg.URL,
+		apiKey: cfg.APIKey,
+		from:   cfg.Sender,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type sendRequest struct {
+	To   string `json:"to"`
+	From string `json:"from,omitempty"`
+	Text string `json:"text"`
+}
+
+func (s *httpSender) Send(ctx context.Context, phoneNumber string, text string) error {
+	payload, err := json.Marshal(sendRequest{To: phoneNumber, From: s.from, Text: text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("sms request failed with status %d: %s", resp.StatusCode, raw)
+	}
+
+	return nil
+}
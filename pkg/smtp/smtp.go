@@ -2,39 +2,50 @@ package smtp
 
 import (
 	"fmt"
+	"mime"
 	smtpPkg "net/smtp"
-	"os"
+	"strings"
 )
 
 type ItfSmtp interface {
-	CreateSmtp(userEmail string, otp string) error
+	Send(to string, subject string, body string) error
 }
 
 type smtp struct {
 	auth smtpPkg.Auth
+	addr string
 	mail string
 }
 
-func New() ItfSmtp {
-	mail := os.Getenv("SMTP_MAIL")
-	password := os.Getenv("SMTP_PASSWORD")
-	auth := smtpPkg.PlainAuth("", mail, password, "smtp.gmail.com")
-
-	return &smtp{auth: auth, mail: mail}
+// Config is the mail server to send through, Gmail unless set, and the
+// account to log in with, which is also the sender.
+type Config struct {
+	Host     string `env:"SMTP_HOST" default:"smtp.gmail.com"`
+	Port     string `env:"SMTP_PORT" default:"587"`
+	Mail     string `env:"SMTP_MAIL"`
+	Password string `env:"SMTP_PASSWORD" secret:"true"`
 }
 
-func (s *smtp) CreateSmtp(userEmail string, otp string) error {
-	to := []string{userEmail}
-
-	message := []byte(fmt.Sprintf("To: %s\r\nSubject: Your OTP\r\n\r\nHello %s, this is from %s, this is your OTP: %s",
-		userEmail, userEmail, s.mail, otp))
+func New(cfg Config) ItfSmtp {
+	auth := smtpPkg.PlainAuth("", cfg.Mail, cfg.Password, cfg.Host)
 
-	msgArray := message
+	return &smtp{auth: auth, addr: cfg.Host + ":" + cfg.Port, mail: cfg.Mail}
+}
 
-	err := smtpPkg.SendMail("smtp.gmail.com:587", s.auth, s.mail, to, msgArray)
-	if err != nil {
-		return err
+// Send sends a plain text UTF-8 email.
+func (s *smtp) Send(to string, subject string, body string) error {
+	if s.mail == "" {
+		return fmt.Errorf("smtp sender not configured")
 	}
 
-	return nil
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", s.mail)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return smtpPkg.SendMail(s.addr, s.auth, s.mail, []string{to}, []byte(message.String()))
 }
@@ -0,0 +1,80 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sync/atomic"
+)
+
+const (
+	fakeSampleRate = 8000
+	fakeToneMillis = 60
+)
+
+// FakeSynthesizer works offline. Every word becomes a short tone whose pitch
+// depends on the word, so the same text always yields the same WAV file and
+// different texts yield different ones.
+type FakeSynthesizer struct {
+	calls atomic.Int64
+}
+
+func NewFake() *FakeSynthesizer {
+	return &FakeSynthesizer{}
+}
+
+// Calls is the number of clips synthesized, which shows whether a cache was
+// hit.
+func (f *FakeSynthesizer) Calls() int64 {
+	return f.calls.Load()
+}
+
+func (f *FakeSynthesizer) Voice() string {
+	return "fake"
+}
+
+func (f *FakeSynthesizer) Synthesize(ctx context.Context, text string) ([]byte, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	f.calls.Add(1)
+
+	var samples []int16
+	for _, word := range bytes.Fields([]byte(text)) {
+		sum := sha256.Sum256(word)
+		frequency := 300 + float64(binary.BigEndian.Uint16(sum[:2])%600)
+
+		for i := 0; i < fakeSampleRate*fakeToneMillis/1000; i++ {
+			sample := math.Sin(2 * math.Pi * frequency * float64(i) / fakeSampleRate)
+			samples = append(samples, int16(sample*8000))
+		}
+		// A short silence between words.
+		samples = append(samples, make([]int16, fakeSampleRate/50)...)
+	}
+
+	return encodeWAV(samples), "audio/wav", nil
+}
+
+// encodeWAV wraps mono 16-bit PCM samples in a WAV header.
+func encodeWAV(samples []int16) []byte {
+	dataSize := uint32(len(samples) * 2)
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1))
+	binary.Write(&buf, binary.LittleEndian, uint16(1))
+	binary.Write(&buf, binary.LittleEndian, uint32(fakeSampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(fakeSampleRate*2))
+	binary.Write(&buf, binary.LittleEndian, uint16(2))
+	binary.Write(&buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, dataSize)
+	binary.Write(&buf, binary.LittleEndian, samples)
+
+	return buf.Bytes()
+}
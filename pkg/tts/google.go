@@ -0,0 +1,108 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	googleEndpoint     = "https://texttospeech.googleapis.com/v1/text:synthesize"
+	googleLanguageCode = "id-ID"
+)
+
+type googleSynthesizer struct {
+	apiKey string
+	voice  string
+	client *http.Client
+}
+
+// NewGoogle synthesizes speech with the Google Cloud Text-to-Speech REST API
+// using cfg.APIKey.
+func NewGoogle(cfg Config) (ISynthesizer, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("%w: GOOGLE_TTS_API_KEY not set", ErrNotConfigured)
+	}
+
+	return &googleSynthesizer{
+		apiKey: cfg.APIKey,
+		voice:  cfg.Voice,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type googleSynthesizeRequest struct {
+	Input struct {
+		Text string `json:"text"`
+	} `json:"input"`
+	Voice struct {
+		LanguageCode string `json:"languageCode"`
+		Name         string `json:"name"`
+	} `json:"voice"`
+	AudioConfig struct {
+		AudioEncoding string `json:"audioEncoding"`
+	} `json:"audioConfig"`
+}
+
+type googleSynthesizeResponse struct {
+	AudioContent string `json:"audioContent"`
+}
+
+func (g *googleSynthesizer) Voice() string {
+	return g.voice
+}
+
+func (g *googleSynthesizer) Synthesize(ctx context.Context, text string) ([]byte, string, error) {
+	var body googleSynthesizeRequest
+	body.Input.Text = text
+	body.Voice.LanguageCode = googleLanguageCode
+	body.Voice.Name = g.voice
+	body.AudioConfig.AudioEncoding = "MP3"
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleEndpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Goog-Api-Key", g.apiKey)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("text-to-speech request failed with status %d: %s", resp.StatusCode, raw)
+	}
+
+	var result googleSynthesizeResponse
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, "", err
+	}
+
+	audio, err := base64.StdEncoding.DecodeString(result.AudioContent)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(audio) == 0 {
+		return nil, "", fmt.Errorf("text-to-speech returned no audio")
+	}
+
+	return audio, "audio/mpeg", nil
+}
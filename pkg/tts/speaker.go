@@ -0,0 +1,142 @@
+package tts
+
+import (
+	"ProjectGolang/pkg/redis"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	goredis "github.com/redis/go-redis/v9"
+	"sync"
+	"time"
+)
+
+const (
+	// clipTTL keeps clips in Redis long enough for common phrases to be
+	// synthesized once and then only be served.
+	clipTTL          = 30 * 24 * time.Hour
+	memoryCacheClips = 256
+)
+
+// ISpeaker turns text into clips, synthesizing each phrase only once. The
+// same text always maps to the same clip ID, so clients can cache audio by
+// ID too.
+type ISpeaker interface {
+	Speak(ctx context.Context, text string) (*Clip, error)
+	Clip(ctx context.Context, id string) (*Clip, error)
+}
+
+type speaker struct {
+	synthesizer ISynthesizer
+	redisServer redis.IRedis
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// storedClip is a clip as kept in Redis.
+type storedClip struct {
+	Text     string `json:"text"`
+	MimeType string `json:"mime_type"`
+	Data     []byte `json:"data"`
+}
+
+// NewSpeaker caches the clips of synthesizer in memory and in Redis.
+// redisServer may be nil, in which case clips are only kept in memory.
+func NewSpeaker(synthesizer ISynthesizer, redisServer redis.IRedis) ISpeaker {
+	return &speaker{
+		synthesizer: synthesizer,
+		redisServer: redisServer,
+		entries:     make(map[string]*list.Element),
+		order:       list.New(),
+	}
+}
+
+func (s *speaker) Speak(ctx context.Context, text string) (*Clip, error) {
+	text, err := normalize(text)
+	if err != nil {
+		return nil, err
+	}
+
+	id := clipID(s.synthesizer.Voice(), text)
+	if clip, err := s.Clip(ctx, id); err == nil {
+		return clip, nil
+	} else if !errors.Is(err, ErrClipNotFound) {
+		return nil, err
+	}
+
+	data, mimeType, err := s.synthesizer.Synthesize(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	clip := &Clip{ID: id, Text: text, MimeType: mimeType, Data: data}
+	s.remember(clip)
+
+	if s.redisServer != nil {
+		raw, err := json.Marshal(storedClip{Text: text, MimeType: mimeType, Data: data})
+		if err != nil {
+			return nil, err
+		}
+		// The clip is already usable from memory, so a Redis failure only
+		// costs a later synthesis.
+		_ = s.redisServer.SetOTP(ctx, clipKey(id), string(raw), clipTTL)
+	}
+
+	return clip, nil
+}
+
+func (s *speaker) Clip(ctx context.Context, id string) (*Clip, error) {
+	s.mu.Lock()
+	if elem, ok := s.entries[id]; ok {
+		s.order.MoveToFront(elem)
+		clip := elem.Value.(*Clip)
+		s.mu.Unlock()
+		return clip, nil
+	}
+	s.mu.Unlock()
+
+	if s.redisServer == nil {
+		return nil, ErrClipNotFound
+	}
+
+	raw, err := s.redisServer.GetOTP(ctx, clipKey(id))
+	if errors.Is(err, goredis.Nil) {
+		return nil, ErrClipNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var stored storedClip
+	if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+		return nil, ErrClipNotFound
+	}
+
+	clip := &Clip{ID: id, Text: stored.Text, MimeType: stored.MimeType, Data: stored.Data}
+	s.remember(clip)
+	return clip, nil
+}
+
+// remember adds clip to the in-memory cache, evicting the least recently
+// used clip when it is full.
+func (s *speaker) remember(clip *Clip) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[clip.ID]; ok {
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	s.entries[clip.ID] = s.order.PushFront(clip)
+	if s.order.Len() > memoryCacheClips {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*Clip).ID)
+	}
+}
+
+func clipKey(id string) string {
+	return "tts:clip:" + id
+}
@@ -0,0 +1,68 @@
+package tts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTextLength is the longest text, in characters, that is synthesized.
+// Guidance is meant to be a short phrase.
+const MaxTextLength = 200
+
+const (
+	ProviderGoogle = "google"
+	ProviderFake   = "fake"
+)
+
+// Config picks the synthesizer. Voice is the Indonesian Google voice to
+// speak with.
+type Config struct {
+	Provider string `env:"TTS_PROVIDER" default:"google"`
+	APIKey   string `env:"GOOGLE_TTS_API_KEY" secret:"true"`
+	Voice    string `env:"TTS_VOICE" default:"id-ID-Standard-A"`
+}
+
+var (
+	ErrEmptyText     = errors.New("text to speak is empty")
+	ErrTextTooLong   = errors.New("text to speak is too long")
+	ErrClipNotFound  = errors.New("audio clip not found")
+	ErrNotConfigured = errors.New("text-to-speech provider not configured")
+)
+
+// Clip is synthesized speech. ID identifies the text and voice it was made
+// from, so the same phrase always has the same ID.
+type Clip struct {
+	ID       string
+	Text     string
+	MimeType string
+	Data     []byte
+}
+
+// ISynthesizer turns Indonesian text into audio.
+type ISynthesizer interface {
+	Synthesize(ctx context.Context, text string) (data []byte, mimeType string, err error)
+	// Voice names the voice, so clips of different voices get different IDs.
+	Voice() string
+}
+
+// normalize trims text and collapses its whitespace, so trivially different
+// spellings of a phrase share one clip.
+func normalize(text string) (string, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return "", ErrEmptyText
+	}
+	if utf8.RuneCountInString(text) > MaxTextLength {
+		return "", ErrTextTooLong
+	}
+	return text, nil
+}
+
+func clipID(voice string, text string) string {
+	sum := sha256.Sum256([]byte(voice + "\n" + text))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,195 @@
+package websocketPkg
+
+import (
+	"ProjectGolang/internal/api/detection"
+	"ProjectGolang/internal/entity"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Responder builds the fake AI service's answer to one frame.
+type Responder func(frame []byte) interface{}
+
+// FakeAIServer is a local stand-in for the AI detection services. It speaks
+// their protocol, binary frames for face detection and base64 text frames for
+// KTP and QRIS, answering each frame in order on its connection, so the
+// client and pool can be exercised without the real models.
+type FakeAIServer struct {
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu         sync.RWMutex
+	responders map[detection.DetectionType]Responder
+	delay      time.Duration
+	down       bool
+	conns      map[*websocket.Conn]struct{}
+
+	connections atomic.Int64
+	frames      atomic.Int64
+}
+
+func NewFakeAIServer() *FakeAIServer {
+	f := &FakeAIServer{
+		conns: make(map[*websocket.Conn]struct{}),
+		responders: map[detection.DetectionType]Responder{
+			detection.FaceDetection: func([]byte) interface{} {
+				return entity.DetectionResult{
+					Status:       "PERFECT_POSITION",
+					Instructions: []string{"Hold still"},
+				}
+			},
+			detection.KTPDetection: func([]byte) interface{} {
+				return entity.KTPDetectionResult{
+					Message:    "KTP detected",
+					BBox:       []float64{0.1, 0.2, 0.9, 0.8},
+					Confidence: 0.95,
+				}
+			},
+			detection.QRISDetection: func([]byte) interface{} {
+				return entity.QRISDetectionResult{
+					Message:    "QRIS detected",
+					BBox:       []float64{0.25, 0.25, 0.75, 0.75},
+					Confidence: 0.95,
+				}
+			},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/face/ws", f.handle(detection.FaceDetection))
+	mux.HandleFunc("/api/v1/ktp/ws", f.handle(detection.KTPDetection))
+	mux.HandleFunc("/api/v1/qris/ws", f.handle(detection.QRISDetection))
+	f.server = httptest.NewServer(mux)
+
+	return f
+}
+
+// URLs returns the WebSocket URL of every fake detection service.
+func (f *FakeAIServer) URLs() map[detection.DetectionType]string {
+	base := "ws" + strings.TrimPrefix(f.server.URL, "http")
+	return map[detection.DetectionType]string{
+		detection.FaceDetection: base + "/api/v1/face/ws",
+		detection.KTPDetection:  base + "/api/v1/ktp/ws",
+		detection.QRISDetection: base + "/api/v1/qris/ws",
+	}
+}
+
+// SetResponder replaces the answer to frames of detectionType. The frame is
+// passed decoded, so KTP and QRIS responders see the raw image as well.
+func (f *FakeAIServer) SetResponder(detectionType detection.DetectionType, responder Responder) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responders[detectionType] = responder
+}
+
+// SetDelay makes every frame take at least d to answer.
+func (f *FakeAIServer) SetDelay(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delay = d
+}
+
+// SetDown makes the server refuse new connections, as an AI service that is
+// not running would, until it is called again with false.
+func (f *FakeAIServer) SetDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+// Connections is the number of connections currently open to the server.
+func (f *FakeAIServer) Connections() int64 {
+	return f.connections.Load()
+}
+
+// Frames is the number of frames answered so far.
+func (f *FakeAIServer) Frames() int64 {
+	return f.frames.Load()
+}
+
+// CloseClientConnections drops every open connection, as a restarting AI
+// service would.
+func (f *FakeAIServer) CloseClientConnections() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for conn := range f.conns {
+		conn.Close()
+	}
+}
+
+func (f *FakeAIServer) Close() {
+	f.CloseClientConnections()
+	f.server.Close()
+}
+
+func (f *FakeAIServer) handle(detectionType detection.DetectionType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.RLock()
+		down := f.down
+		f.mu.RUnlock()
+		if down {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		conn, err := f.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		f.mu.Lock()
+		f.conns[conn] = struct{}{}
+		f.mu.Unlock()
+		f.connections.Add(1)
+
+		defer func() {
+			f.mu.Lock()
+			delete(f.conns, conn)
+			f.mu.Unlock()
+			f.connections.Add(-1)
+		}()
+
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			frame := message
+			if messageType == websocket.TextMessage {
+				if frame, err = base64.StdEncoding.DecodeString(string(message)); err != nil {
+					if err := conn.WriteJSON(map[string]string{"error": "invalid base64 frame"}); err != nil {
+						return
+					}
+					continue
+				}
+			}
+
+			f.mu.RLock()
+			responder := f.responders[detectionType]
+			delay := f.delay
+			f.mu.RUnlock()
+
+			time.Sleep(delay)
+
+			response, err := json.Marshal(responder(frame))
+			if err != nil {
+				return
+			}
+
+			f.frames.Add(1)
+			if err := conn.WriteMessage(websocket.TextMessage, response); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package websocketPkg
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// PoolStats describes the connection pool of one AI detection service.
+// QueueDepth is the number of frames waiting for a free connection.
+type PoolStats struct {
+	Size         int     `json:"size"`
+	Open         int64   `json:"open"`
+	Idle         int     `json:"idle"`
+	QueueDepth   int64   `json:"queue_depth"`
+	InFlight     int64   `json:"in_flight"`
+	Requests     uint64  `json:"requests"`
+	Failures     uint64  `json:"failures"`
+	Reconnects   uint64  `json:"reconnects"`
+	AvgWaitMs    float64 `json:"avg_wait_ms"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	MaxLatencyMs float64 `json:"max_latency_ms"`
+}
+
+type poolMetrics struct {
+	open       atomic.Int64
+	waiting    atomic.Int64
+	inFlight   atomic.Int64
+	requests   atomic.Uint64
+	failures   atomic.Uint64
+	reconnects atomic.Uint64
+
+	waits        atomic.Uint64
+	waitTotal    atomic.Int64
+	responses    atomic.Uint64
+	latencyTotal atomic.Int64
+	latencyMax   atomic.Int64
+}
+
+func (m *poolMetrics) observeWait(d time.Duration) {
+	m.waits.Add(1)
+	m.waitTotal.Add(int64(d))
+}
+
+func (m *poolMetrics) observeLatency(d time.Duration) {
+	m.responses.Add(1)
+	m.latencyTotal.Add(int64(d))
+
+	for {
+		current := m.latencyMax.Load()
+		if int64(d) <= current || m.latencyMax.CompareAndSwap(current, int64(d)) {
+			return
+		}
+	}
+}
+
+func (m *poolMetrics) snapshot(size int, idle int) PoolStats {
+	stats := PoolStats{
+		Size:         size,
+		Open:         m.open.Load(),
+		Idle:         idle,
+		QueueDepth:   m.waiting.Load(),
+		InFlight:     m.inFlight.Load(),
+		Requests:     m.requests.Load(),
+		Failures:     m.failures.Load(),
+		Reconnects:   m.reconnects.Load(),
+		MaxLatencyMs: milliseconds(m.latencyMax.Load()),
+	}
+
+	if waits := m.waits.Load(); waits > 0 {
+		stats.AvgWaitMs = milliseconds(m.waitTotal.Load() / int64(waits))
+	}
+	if responses := m.responses.Load(); responses > 0 {
+		stats.AvgLatencyMs = milliseconds(m.latencyTotal.Load() / int64(responses))
+	}
+
+	return stats
+}
+
+func milliseconds(nanos int64) float64 {
+	return float64(nanos) / float64(time.Millisecond)
+}
@@ -0,0 +1,440 @@
+package websocketPkg
+
+import (
+	"ProjectGolang/internal/api/detection"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrBackendUnavailable = errors.New("AI detection service unavailable")
+	ErrPoolClosed         = errors.New("AI detection connection pool closed")
+)
+
+type poolOptions struct {
+	size         int
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	pingInterval time.Duration
+	backoffMin   time.Duration
+	backoffMax   time.Duration
+}
+
+// pool keeps size connections to one AI detection service and hands each of
+// them to one request at a time. The services answer frames in order without
+// echoing an identifier, so every request gets a correlation ID that is set as
+// its connection's in-flight ID: a response is only delivered to the request
+// it belongs to, and a response nobody is waiting for means the connection is
+// out of step and it is discarded.
+//
+// Each slot of the pool redials its connection with exponential backoff when
+// it dies, and idle connections are pinged every pingInterval.
+type pool struct {
+	detectionType detection.DetectionType
+	url           string
+	opts          poolOptions
+	dialer        *websocket.Dialer
+
+	mu        sync.Mutex
+	idle      []*poolConn
+	wake      chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	nextID  atomic.Uint64
+	metrics poolMetrics
+}
+
+type poolConn struct {
+	slot     int
+	conn     *websocket.Conn
+	inFlight atomic.Uint64
+	// lastSeen is when the service last answered on the connection, with a
+	// pong or any message.
+	lastSeen  atomic.Int64
+	responses chan response
+	dead      chan struct{}
+	deadOnce  sync.Once
+}
+
+type response struct {
+	requestID uint64
+	message   []byte
+}
+
+func (pc *poolConn) markDead() {
+	pc.deadOnce.Do(func() {
+		pc.conn.Close()
+		close(pc.dead)
+	})
+}
+
+func (pc *poolConn) isDead() bool {
+	select {
+	case <-pc.dead:
+		return true
+	default:
+		return false
+	}
+}
+
+func newPool(detectionType detection.DetectionType, url string, opts poolOptions) *pool {
+	p := &pool{
+		detectionType: detectionType,
+		url:           url,
+		opts:          opts,
+		dialer: &websocket.Dialer{
+			Proxy:            websocket.DefaultDialer.Proxy,
+			HandshakeTimeout: 10 * time.Second,
+		},
+		wake:   make(chan struct{}, opts.size),
+		closed: make(chan struct{}),
+	}
+
+	for slot := 0; slot < opts.size; slot++ {
+		go p.maintain(slot)
+	}
+	go p.healthCheck()
+
+	return p
+}
+
+// do sends one frame and returns the service's response to it. Every request
+// that does not get its response counts as one failure.
+func (p *pool) do(ctx context.Context, messageType int, payload []byte) ([]byte, error) {
+	requestID := p.nextID.Add(1)
+	name := getDetectionTypeName(p.detectionType)
+
+	p.metrics.requests.Add(1)
+
+	message, err := p.exchange(ctx, requestID, messageType, payload)
+	if err != nil {
+		p.metrics.failures.Add(1)
+		return nil, fmt.Errorf("%s request %d: %w", name, requestID, err)
+	}
+
+	return message, nil
+}
+
+// exchange sends the frame of requestID on an idle connection and waits for
+// the response. The connection is discarded whenever it might be out of step.
+func (p *pool) exchange(ctx context.Context, requestID uint64, messageType int, payload []byte) ([]byte, error) {
+	start := time.Now()
+
+	pc, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.metrics.observeWait(time.Since(start))
+
+	p.metrics.inFlight.Add(1)
+	defer p.metrics.inFlight.Add(-1)
+
+	sent := time.Now()
+	pc.inFlight.Store(requestID)
+
+	pc.conn.SetWriteDeadline(deadline(ctx, p.opts.writeTimeout))
+	if err := pc.conn.WriteMessage(messageType, payload); err != nil {
+		p.discard(pc)
+		return nil, fmt.Errorf("error sending frame: %w", err)
+	}
+
+	timer := time.NewTimer(time.Until(deadline(ctx, p.opts.readTimeout)))
+	defer timer.Stop()
+
+	select {
+	case res := <-pc.responses:
+		if res.requestID != requestID {
+			p.discard(pc)
+			return nil, fmt.Errorf("received the response to request %d", res.requestID)
+		}
+
+		p.metrics.observeLatency(time.Since(sent))
+		p.release(pc)
+		return res.message, nil
+	case <-pc.dead:
+		return nil, errors.New("connection closed before the response")
+	case <-timer.C:
+		p.discard(pc)
+		return nil, fmt.Errorf("no response within %s", p.opts.readTimeout)
+	case <-ctx.Done():
+		p.discard(pc)
+		return nil, ctx.Err()
+	}
+}
+
+// acquire waits for an idle connection. It gives up right away when no
+// connection is open, so frames are not queued behind a reconnect backoff.
+func (p *pool) acquire(ctx context.Context) (*poolConn, error) {
+	var timer *time.Timer
+
+	for {
+		if pc := p.popIdle(); pc != nil {
+			return pc, nil
+		}
+
+		if p.metrics.open.Load() == 0 {
+			return nil, ErrBackendUnavailable
+		}
+
+		if timer == nil {
+			timer = time.NewTimer(p.opts.readTimeout)
+			defer timer.Stop()
+
+			p.metrics.waiting.Add(1)
+			defer p.metrics.waiting.Add(-1)
+		}
+
+		select {
+		case <-p.wake:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, ErrBackendUnavailable
+		case <-p.closed:
+			return nil, ErrPoolClosed
+		}
+	}
+}
+
+func (p *pool) popIdle() *poolConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.idle) > 0 {
+		pc := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if !pc.isDead() {
+			return pc
+		}
+	}
+
+	return nil
+}
+
+func (p *pool) removeIdle(pc *poolConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, idle := range p.idle {
+		if idle == pc {
+			p.idle = append(p.idle[:i], p.idle[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *pool) release(pc *poolConn) {
+	select {
+	case <-p.closed:
+		pc.markDead()
+		return
+	default:
+	}
+
+	p.mu.Lock()
+	p.idle = append(p.idle, pc)
+	p.mu.Unlock()
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// discard closes a connection that can no longer be trusted, so its slot
+// redials. The failed request is counted by do.
+func (p *pool) discard(pc *poolConn) {
+	pc.markDead()
+}
+
+// maintain keeps the connection of one slot open until the pool is closed.
+func (p *pool) maintain(slot int) {
+	name := getDetectionTypeName(p.detectionType)
+	backoff := p.opts.backoffMin
+	first := true
+
+	for {
+		pc, err := p.dial(slot)
+		if err != nil {
+			log.Printf("Connection %d to %s failed: %v, retrying in %s", slot, name, err, backoff)
+
+			select {
+			case <-time.After(jitter(backoff)):
+			case <-p.closed:
+				return
+			}
+
+			backoff *= 2
+			if backoff > p.opts.backoffMax {
+				backoff = p.opts.backoffMax
+			}
+			continue
+		}
+
+		if !first {
+			p.metrics.reconnects.Add(1)
+		}
+		first = false
+		backoff = p.opts.backoffMin
+
+		p.metrics.open.Add(1)
+		go p.read(pc)
+		p.release(pc)
+
+		select {
+		case <-pc.dead:
+			p.removeIdle(pc)
+			p.metrics.open.Add(-1)
+			log.Printf("Connection %d to %s lost, reconnecting", slot, name)
+		case <-p.closed:
+			pc.markDead()
+			p.metrics.open.Add(-1)
+			return
+		}
+	}
+}
+
+func (p *pool) dial(slot int) (*poolConn, error) {
+	conn, _, err := p.dialer.Dial(p.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", p.url, err)
+	}
+
+	conn.SetPingHandler(func(appData string) error {
+		err := conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(p.opts.writeTimeout))
+		if err != nil {
+			log.Printf("Error sending pong: %v", err)
+		}
+		return nil
+	})
+
+	pc := &poolConn{
+		slot:      slot,
+		conn:      conn,
+		responses: make(chan response, 1),
+		dead:      make(chan struct{}),
+	}
+	pc.lastSeen.Store(time.Now().UnixNano())
+
+	conn.SetPongHandler(func(string) error {
+		pc.lastSeen.Store(time.Now().UnixNano())
+		return nil
+	})
+
+	return pc, nil
+}
+
+// read delivers the messages of pc to the request in flight on it, so a
+// connection that closes while idle is noticed straight away.
+func (p *pool) read(pc *poolConn) {
+	defer pc.markDead()
+
+	for {
+		_, message, err := pc.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		// A busy connection is not pinged, its responses show it is alive.
+		pc.lastSeen.Store(time.Now().UnixNano())
+
+		requestID := pc.inFlight.Swap(0)
+		if requestID == 0 {
+			log.Printf("Unsolicited message from %s on connection %d, discarding connection",
+				getDetectionTypeName(p.detectionType), pc.slot)
+			return
+		}
+
+		pc.responses <- response{requestID: requestID, message: message}
+	}
+}
+
+// healthCheck pings the connections that are idle and drops the ones that
+// cannot be written to or have not been heard from for two intervals, so
+// their slots reconnect.
+func (p *pool) healthCheck() {
+	ticker := time.NewTicker(p.opts.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.closed:
+			return
+		}
+
+		p.mu.Lock()
+		idle := p.idle
+		p.idle = nil
+		p.mu.Unlock()
+
+		for _, pc := range idle {
+			if pc.isDead() {
+				continue
+			}
+
+			if time.Since(time.Unix(0, pc.lastSeen.Load())) > 2*p.opts.pingInterval {
+				log.Printf("Nothing heard from %s connection %d, marking connection as dead",
+					getDetectionTypeName(p.detectionType), pc.slot)
+				pc.markDead()
+				continue
+			}
+
+			if err := pc.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(p.opts.writeTimeout)); err != nil {
+				log.Printf("Ping failed for %s connection %d, marking connection as dead: %v",
+					getDetectionTypeName(p.detectionType), pc.slot, err)
+				pc.markDead()
+				continue
+			}
+
+			p.release(pc)
+		}
+	}
+}
+
+// reset drops every idle connection so their slots dial again.
+func (p *pool) reset() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	for _, pc := range idle {
+		pc.markDead()
+	}
+}
+
+func (p *pool) close() {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+		p.reset()
+	})
+}
+
+func (p *pool) stats() PoolStats {
+	p.mu.Lock()
+	idle := len(p.idle)
+	p.mu.Unlock()
+
+	return p.metrics.snapshot(p.opts.size, idle)
+}
+
+// deadline is timeout from now, or the deadline of ctx when that is sooner.
+func deadline(ctx context.Context, timeout time.Duration) time.Time {
+	d := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(d) {
+		return ctxDeadline
+	}
+	return d
+}
+
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
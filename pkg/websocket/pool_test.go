@@ -0,0 +1,190 @@
+package websocketPkg
+
+import (
+	"ProjectGolang/internal/api/detection"
+	"context"
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestPool(t *testing.T, server *FakeAIServer, size int) *pool {
+	t.Helper()
+
+	return newPingedTestPool(t, server, size, time.Minute)
+}
+
+func newPingedTestPool(t *testing.T, server *FakeAIServer, size int, pingInterval time.Duration) *pool {
+	t.Helper()
+
+	p := newPool(detection.KTPDetection, server.URLs()[detection.KTPDetection], poolOptions{
+		size:         size,
+		readTimeout:  200 * time.Millisecond,
+		writeTimeout: 200 * time.Millisecond,
+		pingInterval: pingInterval,
+		backoffMin:   10 * time.Millisecond,
+		backoffMax:   40 * time.Millisecond,
+	})
+	t.Cleanup(p.close)
+
+	return p
+}
+
+func newTestServer(t *testing.T) *FakeAIServer {
+	t.Helper()
+
+	server := NewFakeAIServer()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func waitForOpen(t *testing.T, p *pool, open int64) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		return p.metrics.open.Load() == open
+	}, 2*time.Second, 5*time.Millisecond, "expected %d open connections", open)
+}
+
+func sendFrame(p *pool) ([]byte, error) {
+	return p.do(context.Background(), websocket.TextMessage, []byte("aW1hZ2U="))
+}
+
+func TestPoolReconnectsWithBackoff(t *testing.T) {
+	server := newTestServer(t)
+	server.SetDown(true)
+	p := newTestPool(t, server, 1)
+
+	// Several backoff periods pass without a connection.
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(0), p.metrics.open.Load())
+
+	server.SetDown(false)
+	waitForOpen(t, p, 1)
+
+	_, err := sendFrame(p)
+	require.NoError(t, err)
+
+	server.CloseClientConnections()
+	require.Eventually(t, func() bool {
+		return p.metrics.reconnects.Load() == 1 && p.metrics.open.Load() == 1
+	}, 2*time.Second, 5*time.Millisecond)
+
+	_, err = sendFrame(p)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), p.stats().Failures)
+}
+
+func TestPoolAcquireOnEmptyPool(t *testing.T) {
+	server := newTestServer(t)
+	server.SetDown(true)
+	p := newTestPool(t, server, 2)
+
+	start := time.Now()
+	_, err := sendFrame(p)
+
+	require.ErrorIs(t, err, ErrBackendUnavailable)
+	assert.Less(t, time.Since(start), p.opts.readTimeout, "frames must not wait for a reconnect")
+
+	stats := p.stats()
+	assert.Equal(t, uint64(1), stats.Requests)
+	assert.Equal(t, uint64(1), stats.Failures)
+	assert.Equal(t, int64(0), stats.QueueDepth)
+}
+
+func TestPoolDiscardsOutOfStepConnection(t *testing.T) {
+	server := newTestServer(t)
+	p := newTestPool(t, server, 1)
+	waitForOpen(t, p, 1)
+
+	// A response left over from an earlier request is waiting on the only
+	// connection.
+	require.Eventually(t, func() bool { return p.stats().Idle == 1 }, 2*time.Second, 5*time.Millisecond)
+	pc := p.popIdle()
+	pc.responses <- response{requestID: 999, message: []byte("{}")}
+	p.release(pc)
+
+	_, err := sendFrame(p)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received the response to request 999")
+
+	require.Eventually(t, func() bool {
+		return p.metrics.reconnects.Load() == 1 && p.metrics.open.Load() == 1
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(1), p.stats().Failures)
+
+	_, err = sendFrame(p)
+	require.NoError(t, err)
+}
+
+func TestPoolReadTimeout(t *testing.T) {
+	server := newTestServer(t)
+	server.SetDelay(500 * time.Millisecond)
+	p := newTestPool(t, server, 1)
+	waitForOpen(t, p, 1)
+
+	_, err := sendFrame(p)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no response within")
+	assert.Equal(t, uint64(1), p.stats().Failures)
+
+	// The late response must not reach the next request, so the slot dials a
+	// new connection.
+	require.Eventually(t, func() bool {
+		return p.metrics.reconnects.Load() == 1
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestPoolCountsClosedConnectionOnce(t *testing.T) {
+	server := newTestServer(t)
+	server.SetDelay(100 * time.Millisecond)
+	p := newTestPool(t, server, 1)
+	waitForOpen(t, p, 1)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		server.CloseClientConnections()
+	}()
+
+	_, err := sendFrame(p)
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrBackendUnavailable))
+	assert.Equal(t, uint64(1), p.stats().Failures)
+}
+
+func TestHealthCheckKeepsBusyConnection(t *testing.T) {
+	server := newTestServer(t)
+	// One frame keeps the connection busy for several ping intervals, so no
+	// ping is sent or answered in the meantime.
+	server.SetDelay(100 * time.Millisecond)
+	p := newPingedTestPool(t, server, 1, 20*time.Millisecond)
+	waitForOpen(t, p, 1)
+
+	_, err := sendFrame(p)
+	require.NoError(t, err)
+
+	// The idle connection goes through the health check a few times.
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, uint64(0), p.metrics.reconnects.Load(), "a busy connection must not count as unresponsive")
+
+	_, err = sendFrame(p)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), p.metrics.reconnects.Load())
+}
+
+func TestHealthCheckKeepsIdleConnection(t *testing.T) {
+	server := newTestServer(t)
+	p := newPingedTestPool(t, server, 1, 20*time.Millisecond)
+	waitForOpen(t, p, 1)
+
+	// Answered pings keep an idle connection open.
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, uint64(0), p.metrics.reconnects.Load())
+
+	_, err := sendFrame(p)
+	require.NoError(t, err)
+}
@@ -29,8 +29,9 @@ type whatsappSender struct {
 	client *whatsmeow.Client
 }
 
-func New() (IWhatsappSender, error) {
-	dsn := postgres.FormatDSN()
+// New keeps the WhatsApp session in the application database.
+func New(db postgres.Config) (IWhatsappSender, error) {
+	dsn := postgres.FormatDSN(db)
 
 	dbLog := waLog.Stdout("Database", "INFO", true)
 	container, err := sqlstore.New("postgres", dsn, dbLog)